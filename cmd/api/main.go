@@ -2,34 +2,81 @@
 package main
 
 import (
+	"context"
+	"libranexus/internal/config"
+	"libranexus/internal/gateway"
+	"libranexus/internal/logging"
+	"libranexus/internal/membership"
+	"libranexus/internal/metrics"
+	"libranexus/internal/server"
+	"libranexus/internal/tracing"
 	"log"
 	"net/http"
-	"net/http/httputil"
 	"net/url"
-	"os"
 )
 
 func main() {
-	catalogServiceURL, _ := url.Parse(getEnv("CATALOG_SERVICE_URL", "http://localhost:8081"))
-	circulationServiceURL, _ := url.Parse(getEnv("CIRCULATION_SERVICE_URL", "http://localhost:8082"))
-	membershipServiceURL, _ := url.Parse(getEnv("MEMBERSHIP_SERVICE_URL", "http://localhost:8083"))
+	logging.Setup("gateway")
+	shutdownTracing, err := tracing.Setup(context.Background(), "gateway")
+	if err != nil {
+		log.Fatalf("Failed to configure tracing: %v", err)
+	}
+	defer shutdownTracing(context.Background())
 
-	catalogProxy := httputil.NewSingleHostReverseProxy(catalogServiceURL)
-	circulationProxy := httputil.NewSingleHostReverseProxy(circulationServiceURL)
-	membershipProxy := httputil.NewSingleHostReverseProxy(membershipServiceURL)
+	cfg, err := config.LoadGateway()
+	if err != nil {
+		log.Fatal(err)
+	}
+	catalogServiceURL, _ := url.Parse(cfg.CatalogURL)
+	circulationServiceURL, _ := url.Parse(cfg.CirculationURL)
+	membershipServiceURL, _ := url.Parse(cfg.MembershipURL)
+	publisherServiceURL, _ := url.Parse(cfg.PublisherURL)
 
-	http.Handle("/api/v1/catalog/", http.StripPrefix("/api/v1/catalog", catalogProxy))
-	http.Handle("/api/v1/circulation/", http.StripPrefix("/api/v1/circulation", circulationProxy))
-	http.Handle("/api/v1/members/", http.StripPrefix("/api/v1/members", membershipProxy))
+	catalogProxy := gateway.NewProxy(catalogServiceURL, cfg.Proxy)
+	circulationProxy := gateway.NewProxy(circulationServiceURL, cfg.Proxy)
+	membershipProxy := gateway.NewProxy(membershipServiceURL, cfg.Proxy)
+	publisherProxy := gateway.NewProxy(publisherServiceURL, cfg.Proxy)
+	// Imports and exports move the whole catalog or loan record at once.
+	catalogLongProxy := gateway.NewProxy(catalogServiceURL, cfg.Proxy.Long())
+	circulationLongProxy := gateway.NewProxy(circulationServiceURL, cfg.Proxy.Long())
 
-	port := getEnv("PORT", "8080")
-	log.Printf("API Gateway listening on port %s", port)
-	log.Fatal(http.ListenAndServe(":"+port, nil))
-}
+	// Preflights are answered before authentication, which they cannot pass,
+	// and rate limit responses still carry the CORS headers a browser needs
+	// to read them.
+	cors := gateway.CORS(cfg.AllowedOrigins)
+
+	authenticate := gateway.Authenticate(cfg.Tokens,
+		"/api/v1/members/register",
+		"/api/v1/members/login",
+		"/api/v1/members/password-reset/request",
+		"/api/v1/members/password-reset/confirm",
+	)
+
+	// Anyone may browse the catalog; changing it takes an administrator.
+	catalogRoutes := http.StripPrefix("/api/v1/catalog", catalogProxy)
+	requireAdmin := gateway.RequireRole(membership.RoleAdmin)
+	http.Handle("/api/v1/catalog/", gateway.ReadOnlyPublic(catalogRoutes, authenticate(requireAdmin(catalogRoutes))))
+	catalogLongRoutes := http.StripPrefix("/api/v1/catalog", catalogLongProxy)
+	http.Handle("/api/v1/catalog/items/export", gateway.ReadOnlyPublic(catalogLongRoutes, authenticate(requireAdmin(catalogLongRoutes))))
+	http.Handle("/api/v1/catalog/items/bulk", authenticate(requireAdmin(catalogLongRoutes)))
+	// An item's event history is for auditors, not browsers.
+	http.Handle("/api/v1/catalog/items/{id}/events", authenticate(requireAdmin(catalogRoutes)))
+	http.Handle("/api/v1/circulation/", authenticate(http.StripPrefix("/api/v1/circulation", circulationProxy)))
+	// Every member's loan records are for administrators only.
+	http.Handle("/api/v1/circulation/checkouts/export", authenticate(requireAdmin(http.StripPrefix("/api/v1/circulation", circulationLongProxy))))
+	http.Handle("/api/v1/members/", authenticate(http.StripPrefix("/api/v1/members", membershipProxy)))
+	// Webhook subscriptions see every event, so only administrators manage them.
+	subscriptionRoutes := authenticate(requireAdmin(http.StripPrefix("/api/v1", publisherProxy)))
+	http.Handle("/api/v1/subscriptions", subscriptionRoutes)
+	http.Handle("/api/v1/subscriptions/", subscriptionRoutes)
+
+	http.Handle("/metrics", metrics.Handler())
+	http.HandleFunc("/healthz", server.Liveness)
+	// The gateway holds no connections of its own, so it is ready once it runs.
+	http.Handle("/readyz", server.Readiness(nil))
 
-func getEnv(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+	log.Printf("API Gateway listening on port %s", cfg.Port)
+	if err := server.Run("gateway", cfg.Addr(), tracing.Handler("gateway", metrics.Instrument("gateway", server.LogRequests(server.PropagateMetadata(cors(gateway.LimitClients(cfg.ClientLimits)(http.DefaultServeMux)))))), cfg.DrainTimeout); err != nil {
+		log.Fatalf("API Gateway stopped: %v", err)
 	}
-	return defaultValue
 }
@@ -0,0 +1,119 @@
+// cmd/fines/main.go
+package main
+
+import (
+	"context"
+	"libranexus/internal/circulation"
+	"libranexus/internal/clients"
+	"libranexus/internal/database"
+	"libranexus/internal/logging"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+)
+
+// The fines job runs a single pass and exits, which suits a cron schedule.
+// Each pass accrues overdue fines and releases holds left uncollected past
+// their pickup window. Set FINES_INTERVAL to keep it running and make a pass
+// periodically.
+func main() {
+	logging.Setup("fines")
+	db, err := database.Open()
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	catalogServiceURL := os.Getenv("CATALOG_SERVICE_URL")
+	if catalogServiceURL == "" {
+		catalogServiceURL = "http://localhost:8081"
+	}
+
+	membershipServiceURL := os.Getenv("MEMBERSHIP_SERVICE_URL")
+	if membershipServiceURL == "" {
+		membershipServiceURL = "http://localhost:8083"
+	}
+
+	var opts []circulation.Option
+	if v := os.Getenv("FINE_PER_DAY"); v != "" {
+		finePerDay, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			log.Fatalf("Invalid FINE_PER_DAY: %v", err)
+		}
+		opts = append(opts, circulation.WithFinePerDay(finePerDay))
+	}
+
+	if v := os.Getenv("PICKUP_WINDOW"); v != "" {
+		pickupWindow, err := time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("Invalid PICKUP_WINDOW: %v", err)
+		}
+		opts = append(opts, circulation.WithPickupWindow(pickupWindow))
+	}
+
+	// Membership only accepts fines from administrators and other services.
+	if os.Getenv("SERVICE_TOKEN") == "" {
+		log.Fatal("SERVICE_TOKEN must be set for the fines job to charge fines")
+	}
+
+	es := eventstore.NewEventStore(db)
+	clientOpts, err := clients.ClientOptionsFromEnv("fines")
+	if err != nil {
+		log.Fatalf("Invalid client configuration: %v", err)
+	}
+	catalogClient, err := clients.CatalogClientFromEnv(catalogServiceURL, clientOpts...)
+	if err != nil {
+		log.Fatalf("Invalid catalog client configuration: %v", err)
+	}
+	membershipClient, err := clients.MembershipClientFromEnv(membershipServiceURL, clientOpts...)
+	if err != nil {
+		log.Fatalf("Invalid membership client configuration: %v", err)
+	}
+	notifier, err := circulation.NotifierFromEnv(membershipClient)
+	if err != nil {
+		log.Fatalf("Invalid notifier configuration: %v", err)
+	}
+	opts = append(opts, circulation.WithNotifier(notifier))
+	svc := circulation.NewService(es, db, catalogClient, membershipClient, opts...)
+
+	run := func() {
+		// Each pass is its own correlation, so the fines it charges can be told
+		// apart from earlier runs.
+		runID := uuid.NewString()
+		ctx := eventstore.WithCorrelationID(context.Background(), runID)
+		fined, err := svc.AccrueFines(ctx)
+		if err != nil {
+			log.Printf("Fine accrual %s failed: %v", runID, err)
+		} else {
+			log.Printf("Fine accrual %s complete: %d checkout(s) fined", runID, fined)
+		}
+		released, err := svc.ReleaseUncollectedHolds(ctx)
+		if err != nil {
+			log.Printf("Hold release %s failed: %v", runID, err)
+			return
+		}
+		log.Printf("Hold release %s complete: %d uncollected hold(s) released", runID, released)
+	}
+
+	interval := os.Getenv("FINES_INTERVAL")
+	if interval == "" {
+		run()
+		return
+	}
+
+	every, err := time.ParseDuration(interval)
+	if err != nil {
+		log.Fatalf("Invalid FINES_INTERVAL: %v", err)
+	}
+
+	run()
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for range ticker.C {
+		run()
+	}
+}
@@ -0,0 +1,85 @@
+// cmd/migrate/main.go
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"libranexus/internal/database"
+	"libranexus/internal/logging"
+	"log"
+	"os"
+)
+
+// The migrator manages the schema from the migrations embedded from
+// db/migrations:
+//
+//	migrate up                    apply every pending migration
+//	migrate status                list the migrations and whether each is applied
+//	migrate baseline [-version N] record migrations up to N, by default all of
+//	                              them, as applied without running them
+//
+// Baseline is for databases built before the migrator existed, by Postgres
+// running the files at initialization.
+func main() {
+	logging.Setup("migrate")
+	baselineFlags := flag.NewFlagSet("baseline", flag.ExitOnError)
+	version := baselineFlags.Int("version", 0, "the last migration to record as applied (default: the latest)")
+
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: migrate up | status | baseline [-version N]")
+		os.Exit(2)
+	}
+
+	list, err := database.Migrations()
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	db, err := database.Open()
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	switch cmd := os.Args[1]; cmd {
+	case "up":
+		applied, err := database.Migrate(ctx, db, list)
+		for _, m := range applied {
+			log.Printf("Applied %04d_%s", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		log.Printf("Schema up to date: %d migration(s) applied", len(applied))
+	case "status":
+		history, err := database.MigrationHistory(ctx, db)
+		if err != nil {
+			log.Fatalf("Failed to read migration history: %v", err)
+		}
+		appliedAt := make(map[int]string, len(history))
+		for _, a := range history {
+			appliedAt[a.Version] = a.AppliedAt.Format("2006-01-02 15:04:05 MST")
+		}
+		for _, m := range list {
+			status, ok := appliedAt[m.Version]
+			if !ok {
+				status = "pending"
+			}
+			fmt.Printf("%04d_%-32s %s\n", m.Version, m.Name, status)
+		}
+	case "baseline":
+		baselineFlags.Parse(os.Args[2:])
+		through := *version
+		if through == 0 && len(list) > 0 {
+			through = list[len(list)-1].Version
+		}
+		if err := database.Baseline(ctx, db, list, through); err != nil {
+			log.Fatalf("Baseline failed: %v", err)
+		}
+		log.Printf("Recorded migrations through %04d as applied", through)
+	default:
+		log.Fatalf("Unknown command %q: want up, status or baseline", cmd)
+	}
+}
@@ -0,0 +1,51 @@
+// cmd/projector/main.go
+package main
+
+import (
+	"context"
+	"flag"
+	"libranexus/internal/database"
+	"libranexus/internal/logging"
+	"libranexus/internal/projection"
+	"log"
+	"os"
+
+	"github.com/jules-labs/go-eventstore"
+)
+
+// The projector replays the event store into a fresh copy of the read-model
+// tables. By default it resumes from its last checkpoint; pass -rebuild to
+// drop the projection schema and replay from the first event.
+func main() {
+	logging.Setup("projector")
+	rebuild := flag.Bool("rebuild", false, "drop the projection schema and replay all events")
+	flag.Parse()
+
+	schema := os.Getenv("PROJECTION_SCHEMA")
+	if schema == "" {
+		schema = "projection"
+	}
+
+	db, err := database.Open()
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	es := eventstore.NewEventStore(db)
+	projector := projection.NewProjector(es, db, schema)
+
+	if *rebuild {
+		log.Printf("Resetting projection schema %q", schema)
+		if err := projector.Reset(ctx); err != nil {
+			log.Fatalf("Failed to reset projection: %v", err)
+		}
+	}
+
+	applied, err := projector.Run(ctx)
+	if err != nil {
+		log.Fatalf("Projection failed after %d event(s): %v", applied, err)
+	}
+	log.Printf("Projection %q caught up: %d event(s) applied", schema, applied)
+}
@@ -0,0 +1,99 @@
+// cmd/publisher/main.go
+package main
+
+import (
+	"context"
+	"libranexus/internal/database"
+	"libranexus/internal/logging"
+	"libranexus/internal/metrics"
+	"libranexus/internal/outbox"
+	"libranexus/internal/server"
+	"libranexus/internal/webhook"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/jules-labs/go-eventstore"
+)
+
+// The publisher relays every committed event to NATS, and to the webhook
+// subscriptions registered with it. Events wait in the event store while
+// NATS or a receiver is unreachable and are delivered, in order, once it is
+// back. Run a single instance: a second one would deliver everything twice.
+func main() {
+	logging.Setup("publisher")
+	db, err := database.Open()
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+	metrics.ObserveDB(db)
+
+	natsURL := os.Getenv("NATS_URL")
+	if natsURL == "" {
+		natsURL = "nats://localhost:4222"
+	}
+	publisher, err := outbox.NewNATSPublisher(natsURL)
+	if err != nil {
+		log.Fatalf("Invalid NATS configuration: %v", err)
+	}
+	defer publisher.Close()
+
+	var opts []outbox.Option
+	if v := os.Getenv("PUBLISH_INTERVAL"); v != "" {
+		interval, err := time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("Invalid PUBLISH_INTERVAL: %v", err)
+		}
+		opts = append(opts, outbox.WithPollInterval(interval))
+	}
+
+	store := eventstore.NewEventStore(db)
+	checkpoints := outbox.NewCheckpointStore(db)
+	relay := outbox.NewRelay(store, checkpoints, "nats", outbox.Publish(publisher), opts...)
+	registry := webhook.NewRegistry(db)
+	dispatcher := webhook.NewDispatcher(registry, store, checkpoints)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	relayDone := make(chan struct{})
+	go func() {
+		defer close(relayDone)
+		relay.Run(ctx)
+	}()
+	dispatcherDone := make(chan struct{})
+	go func() {
+		defer close(dispatcherDone)
+		dispatcher.Run(ctx)
+	}()
+
+	webhooks := webhook.NewHandler(registry)
+	router := http.NewServeMux()
+	router.HandleFunc("/subscriptions", webhooks.HandleSubscriptions)
+	router.HandleFunc("/subscriptions/", webhooks.HandleSubscription)
+	router.Handle("/metrics", metrics.Handler())
+	router.HandleFunc("/healthz", server.Liveness)
+	router.Handle("/readyz", server.Readiness(map[string]server.Check{
+		"database": db.PingContext,
+		"nats":     publisher.Ping,
+	}))
+
+	drainTimeout, err := server.DrainTimeoutFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid shutdown configuration: %v", err)
+	}
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8084"
+	}
+
+	log.Printf("Event publisher serving metrics on port %s", port)
+	err = server.Run("publisher", ":"+port, router, drainTimeout)
+	cancel()
+	<-relayDone
+	<-dispatcherDone
+	if err != nil {
+		log.Fatalf("Event publisher stopped: %v", err)
+	}
+}
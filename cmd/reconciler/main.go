@@ -0,0 +1,94 @@
+// cmd/reconciler/main.go
+package main
+
+import (
+	"context"
+	"libranexus/internal/circulation"
+	"libranexus/internal/clients"
+	"libranexus/internal/database"
+	"libranexus/internal/logging"
+	"log"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+)
+
+// defaultGrace is how long an item must go unchanged before the reconciler
+// will correct it, leaving in-flight checkouts and returns to finish.
+const defaultGrace = 5 * time.Minute
+
+// The reconciler runs a single pass over item availability and exits, which
+// suits a cron schedule. Set RECONCILE_INTERVAL to keep it running.
+func main() {
+	logging.Setup("reconciler")
+	db, err := database.Open()
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	catalogServiceURL := os.Getenv("CATALOG_SERVICE_URL")
+	if catalogServiceURL == "" {
+		catalogServiceURL = "http://localhost:8081"
+	}
+
+	membershipServiceURL := os.Getenv("MEMBERSHIP_SERVICE_URL")
+	if membershipServiceURL == "" {
+		membershipServiceURL = "http://localhost:8083"
+	}
+
+	grace := defaultGrace
+	if v := os.Getenv("RECONCILE_GRACE"); v != "" {
+		grace, err = time.ParseDuration(v)
+		if err != nil || grace < 0 {
+			log.Fatalf("Invalid RECONCILE_GRACE: %q", v)
+		}
+	}
+
+	es := eventstore.NewEventStore(db)
+	clientOpts, err := clients.ClientOptionsFromEnv("reconciler")
+	if err != nil {
+		log.Fatalf("Invalid client configuration: %v", err)
+	}
+	catalogClient, err := clients.CatalogClientFromEnv(catalogServiceURL, clientOpts...)
+	if err != nil {
+		log.Fatalf("Invalid catalog client configuration: %v", err)
+	}
+	membershipClient, err := clients.MembershipClientFromEnv(membershipServiceURL, clientOpts...)
+	if err != nil {
+		log.Fatalf("Invalid membership client configuration: %v", err)
+	}
+	svc := circulation.NewService(es, db, catalogClient, membershipClient)
+
+	run := func() {
+		// Each pass is its own correlation, so the corrections it makes can be
+		// told apart from earlier runs.
+		runID := uuid.NewString()
+		corrected, err := svc.ReconcileAvailability(eventstore.WithCorrelationID(context.Background(), runID), grace)
+		if err != nil {
+			log.Printf("Availability reconciliation %s failed: %v", runID, err)
+			return
+		}
+		log.Printf("Availability reconciliation %s complete: %d item(s) corrected", runID, corrected)
+	}
+
+	interval := os.Getenv("RECONCILE_INTERVAL")
+	if interval == "" {
+		run()
+		return
+	}
+
+	every, err := time.ParseDuration(interval)
+	if err != nil {
+		log.Fatalf("Invalid RECONCILE_INTERVAL: %v", err)
+	}
+
+	run()
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for range ticker.C {
+		run()
+	}
+}
@@ -114,7 +114,9 @@ CREATE TABLE checkouts (
 CREATE INDEX idx_checkouts_member ON checkouts (member_id, status);
 CREATE INDEX idx_checkouts_item ON checkouts (item_id, return_date);
 CREATE INDEX idx_checkouts_due_date ON checkouts (due_date) WHERE status = 'active';
-CREATE INDEX idx_checkouts_overdue ON checkouts (due_date, status) WHERE status = 'active' AND due_date < NOW();
+-- Index predicates must be immutable, so overdue queries compare due_date
+-- against NOW() themselves.
+CREATE INDEX idx_checkouts_overdue ON checkouts (due_date, status) WHERE status = 'active';
 
 -- Reservations for waitlist management
 CREATE TABLE reservations (
@@ -183,7 +185,7 @@ CREATE INDEX idx_fine_transactions_member ON fine_transactions (member_id, creat
 -- ============================================================================
 
 CREATE TABLE audit_log (
-    id BIGSERIAL PRIMARY KEY,
+    id BIGSERIAL,
     actor_id UUID,
     action VARCHAR(100) NOT NULL,
     resource_type VARCHAR(50) NOT NULL,
@@ -192,7 +194,9 @@ CREATE TABLE audit_log (
     ip_address INET,
     user_agent TEXT,
     timestamp TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-    success BOOLEAN NOT NULL DEFAULT TRUE
+    success BOOLEAN NOT NULL DEFAULT TRUE,
+    -- A partitioned table's primary key must include the partition key.
+    PRIMARY KEY (id, timestamp)
 ) PARTITION BY RANGE (timestamp);
 
 -- Create monthly partitions (automated partitioning would be handled by a cron job)
@@ -332,18 +336,24 @@ CREATE POLICY checkout_isolation_policy ON checkouts
 -- PERFORMANCE MONITORING
 -- ============================================================================
 
--- View for slow queries monitoring
-CREATE VIEW slow_queries AS
-SELECT
-    query,
-    calls,
-    total_exec_time,
-    mean_exec_time,
-    max_exec_time
-FROM pg_stat_statements
-WHERE mean_exec_time > 100 -- queries taking > 100ms average
-ORDER BY mean_exec_time DESC
-LIMIT 50;
+-- View for slow queries monitoring, where pg_stat_statements is installed
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_stat_statements') THEN
+        CREATE VIEW slow_queries AS
+        SELECT
+            query,
+            calls,
+            total_exec_time,
+            mean_exec_time,
+            max_exec_time
+        FROM pg_stat_statements
+        WHERE mean_exec_time > 100 -- queries taking > 100ms average
+        ORDER BY mean_exec_time DESC
+        LIMIT 50;
+    END IF;
+END
+$$;
 
 -- ============================================================================
 -- SAMPLE DATA FOR TESTING
@@ -357,6 +367,12 @@ INSERT INTO items (isbn, title, author, publisher, published_year, total_copies,
 ('9780544003415', 'The Lord of the Rings', 'J.R.R. Tolkien', 'Mariner Books', 1954, 6, 6),
 ('9780142437230', '1984', 'George Orwell', 'Penguin Books', 1949, 5, 5);
 
--- Grant permissions
-GRANT SELECT, INSERT, UPDATE ON ALL TABLES IN SCHEMA public TO libranexus_app;
-GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO libranexus_app;
\ No newline at end of file
+-- Grant permissions to the application role, where one has been created
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'libranexus_app') THEN
+        GRANT SELECT, INSERT, UPDATE ON ALL TABLES IN SCHEMA public TO libranexus_app;
+        GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO libranexus_app;
+    END IF;
+END
+$$;
@@ -0,0 +1,22 @@
+-- Holds queue for items with no available copies
+
+CREATE TABLE holds (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    member_id UUID NOT NULL,
+    item_id UUID NOT NULL REFERENCES items(id),
+    placed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    fulfilled_at TIMESTAMPTZ,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'fulfilled', 'collected', 'expired', 'cancelled')),
+    version INT NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- A member may only have one outstanding hold per item
+CREATE UNIQUE INDEX idx_holds_member_item_open ON holds (member_id, item_id) WHERE status IN ('pending', 'fulfilled');
+CREATE INDEX idx_holds_member ON holds (member_id, placed_at DESC);
+CREATE INDEX idx_holds_queue ON holds (item_id, placed_at) WHERE status = 'pending';
+
+CREATE TRIGGER update_holds_updated_at BEFORE UPDATE ON holds
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
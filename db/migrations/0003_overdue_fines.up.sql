@@ -0,0 +1,8 @@
+-- Overdue detection and fine accrual
+
+-- Date through which overdue fines have been charged for a checkout
+ALTER TABLE checkouts ADD COLUMN last_fine_date DATE;
+
+-- Charges carry a caller-supplied reference so retried accruals are no-ops
+ALTER TABLE fine_transactions ADD COLUMN reference VARCHAR(200);
+CREATE UNIQUE INDEX idx_fine_transactions_reference ON fine_transactions (reference) WHERE reference IS NOT NULL;
@@ -0,0 +1,8 @@
+-- Projection checkpoints
+
+-- Last event applied by each projection, so rebuilds can resume after a crash
+CREATE TABLE projection_checkpoints (
+    name VARCHAR(100) PRIMARY KEY,
+    last_event_id BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
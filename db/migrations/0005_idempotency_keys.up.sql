@@ -0,0 +1,17 @@
+-- Idempotency keys for retry-safe circulation requests
+
+-- A key is scoped to the endpoint and member that used it. A row with a NULL
+-- status_code is a request still being processed.
+CREATE TABLE idempotency_keys (
+    endpoint VARCHAR(100) NOT NULL,
+    member_id UUID NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    request_hash CHAR(64) NOT NULL,
+    status_code INT,
+    response_body BYTEA,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (endpoint, member_id, key)
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
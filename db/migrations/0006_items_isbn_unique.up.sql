@@ -0,0 +1,8 @@
+-- One catalog item per ISBN
+
+-- ISBNs are stored normalized to ISBN-13, so the constraint also catches the
+-- same book added once as ISBN-10 and once as ISBN-13.
+ALTER TABLE items ADD CONSTRAINT items_isbn_unique UNIQUE (isbn);
+
+-- The constraint's index replaces the plain lookup index.
+DROP INDEX IF EXISTS idx_items_isbn;
@@ -0,0 +1,3 @@
+-- Item categories drive loan periods, e.g. short loans for reference items
+
+ALTER TABLE items ADD COLUMN category VARCHAR(50) NOT NULL DEFAULT 'general';
@@ -0,0 +1,5 @@
+-- Emails are stored lower-cased so addresses differing only in case belong to
+-- one member. This fails if two existing members differ only in case; merge
+-- or rename one of them first.
+
+UPDATE members SET email = LOWER(email) WHERE email <> LOWER(email);
@@ -0,0 +1,13 @@
+-- Single-use password reset tokens
+
+-- Only a SHA-256 of each token is stored, so a leaked table cannot be used to
+-- reset passwords. A token is spent by setting used_at.
+CREATE TABLE password_reset_tokens (
+    token_hash CHAR(64) PRIMARY KEY,
+    member_id UUID NOT NULL REFERENCES members(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_password_reset_tokens_member_id ON password_reset_tokens (member_id);
@@ -0,0 +1,4 @@
+-- Lets a single aggregate type's events be paged in global order without
+-- scanning the rest of the event table, e.g. to rebuild one read model.
+
+CREATE INDEX idx_events_aggregate_type_id ON events (aggregate_type, id);
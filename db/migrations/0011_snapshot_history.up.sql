@@ -0,0 +1,6 @@
+-- Snapshots are keyed by aggregate and version so an aggregate can keep a
+-- history of them. The primary key also serves LoadSnapshot's newest-first
+-- lookup.
+
+ALTER TABLE snapshots DROP CONSTRAINT snapshots_pkey;
+ALTER TABLE snapshots ADD PRIMARY KEY (aggregate_id, version);
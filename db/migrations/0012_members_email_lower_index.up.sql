@@ -0,0 +1,5 @@
+-- Member lookups by email match case-insensitively on LOWER(email), which
+-- this index serves. It is unique so that addresses differing only in case
+-- can never belong to two members, however a row is written.
+
+CREATE UNIQUE INDEX idx_members_email_lower ON members (LOWER(email));
@@ -0,0 +1,11 @@
+-- Tier changes waiting for their effective time, such as a downgrade at
+-- expiry. A member has at most one; the membership service applies each once
+-- it falls due and records it as a MemberTierChanged event.
+CREATE TABLE scheduled_tier_changes (
+    member_id UUID PRIMARY KEY REFERENCES members(id) ON DELETE CASCADE,
+    new_tier VARCHAR(20) NOT NULL CHECK (new_tier IN ('basic', 'premium', 'librarian')),
+    effective_at TIMESTAMPTZ NOT NULL,
+    scheduled_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_scheduled_tier_changes_effective_at ON scheduled_tier_changes (effective_at);
@@ -0,0 +1,3 @@
+-- Why a member was suspended, for staff reviewing the account. Cleared when
+-- the member is reactivated.
+ALTER TABLE members ADD COLUMN suspension_reason TEXT;
@@ -0,0 +1,6 @@
+-- What a member may do beyond borrowing. Administrators manage the catalog
+-- and other members. Librarians were treated as administrators before roles
+-- were stored, so they keep that access.
+ALTER TABLE members ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'member' CHECK (role IN ('member', 'admin'));
+
+UPDATE members SET role = 'admin' WHERE membership_tier = 'librarian';
@@ -0,0 +1,4 @@
+-- Events record the version of their payload's shape, so payloads written
+-- before a schema change can be upcast when they are loaded. Every event
+-- stored so far was written at the first version.
+ALTER TABLE events ADD COLUMN schema_version INT NOT NULL DEFAULT 1;
@@ -0,0 +1,25 @@
+-- Webhook subscriptions: external systems registered to receive every event
+-- of one type. The publisher POSTs each matching event to the URL, signed
+-- with the subscription's secret, and checkpoints each subscription on its
+-- own so one failing receiver does not hold up the rest.
+CREATE TABLE webhook_subscriptions (
+    id UUID PRIMARY KEY,
+    url TEXT NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    secret TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Every delivery attempt, successful or not, for auditing and debugging
+-- receivers. Status code is NULL when no response arrived.
+CREATE TABLE webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id UUID NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id BIGINT NOT NULL,
+    attempt INT NOT NULL,
+    status_code INT,
+    error TEXT,
+    attempted_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhook_deliveries_subscription ON webhook_deliveries (subscription_id, attempted_at DESC);
@@ -0,0 +1,17 @@
+-- Checkout sagas: one row per checkout attempt, recording how far it got, so
+-- an attempt cut short by a crash can be finished or undone by the recovery
+-- worker. The id is the checkout's.
+CREATE TABLE saga_instances (
+    id UUID PRIMARY KEY,
+    member_id UUID NOT NULL,
+    item_id UUID NOT NULL,
+    hold_id UUID,
+    state VARCHAR(20) NOT NULL CHECK (state IN ('pending', 'item_reserved', 'event_appended', 'completed', 'compensating', 'compensated')),
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- The recovery worker only looks at sagas that have not finished.
+CREATE INDEX idx_saga_instances_unfinished ON saga_instances (updated_at)
+    WHERE state NOT IN ('completed', 'compensated');
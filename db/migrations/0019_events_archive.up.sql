@@ -0,0 +1,20 @@
+-- Cold storage for events moved out of the events table by
+-- ArchiveEventsBefore once a snapshot covers them. Rows keep the IDs and
+-- versions they had, so the event store can read them back alongside the
+-- events that remain.
+CREATE TABLE events_archive (
+    id BIGINT NOT NULL,
+    aggregate_id UUID NOT NULL,
+    aggregate_type VARCHAR(50) NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    event_data JSONB NOT NULL,
+    metadata JSONB,
+    version INT NOT NULL,
+    schema_version INT NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ NOT NULL,
+    archived_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (aggregate_id, version)
+);
+
+CREATE INDEX idx_events_archive_id ON events_archive (id);
+CREATE INDEX idx_events_archive_aggregate_type_id ON events_archive (aggregate_type, id);
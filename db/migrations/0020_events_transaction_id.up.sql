@@ -0,0 +1,16 @@
+-- Events record the transaction that appended them, so relays can read the
+-- stream in commit order rather than by ID, which a transaction committing
+-- late could slip behind. The events stored so far all take this
+-- migration's transaction ID, which keeps them in ID order among themselves.
+ALTER TABLE events ADD COLUMN transaction_id XID8 NOT NULL DEFAULT pg_current_xact_id();
+CREATE INDEX idx_events_transaction_id ON events (transaction_id, id);
+
+-- Archived events keep the transaction ID they were appended with.
+ALTER TABLE events_archive ADD COLUMN transaction_id XID8 NOT NULL DEFAULT pg_current_xact_id();
+ALTER TABLE events_archive ALTER COLUMN transaction_id DROP DEFAULT;
+CREATE INDEX idx_events_archive_transaction_id ON events_archive (transaction_id, id);
+
+-- Relay checkpoints become positions in that order. Those saved so far
+-- resume among the existing events, at the event they had reached.
+ALTER TABLE projection_checkpoints ADD COLUMN last_transaction_id XID8 NOT NULL DEFAULT '0';
+UPDATE projection_checkpoints SET last_transaction_id = pg_current_xact_id();
@@ -0,0 +1,3 @@
+-- Stored responses keep the headers that describe their body, such as
+-- Content-Type, so a replay is answered the way the original was.
+ALTER TABLE idempotency_keys ADD COLUMN response_headers JSONB;
@@ -0,0 +1,13 @@
+// db/migrations/migrations.go
+
+// Package migrations embeds the numbered SQL files that build the LibraNexus
+// schema, so the services and cmd/migrate can apply them without the files
+// being deployed alongside the binaries.
+package migrations
+
+import "embed"
+
+// FS holds every NNNN_name.up.sql file in this directory.
+//
+//go:embed *.up.sql
+var FS embed.FS
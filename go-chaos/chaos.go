@@ -24,24 +24,30 @@ type ChaosExperiment struct {
 	Validation  []Assertion
 	Duration    time.Duration
 	BlastRadius float64 // 0.0 to 1.0 (percentage of system affected)
+	// AbortOnViolation ends observation at the first steady-state
+	// violation, rolling back at once instead of waiting out Duration.
+	AbortOnViolation bool
 }
 
 // Metric defines a measurable system property
 type Metric struct {
-	Name      string
+	Name string
+	// Source is where the metric is read from.
+	Source MetricSource
+	// Query reads the metric when it has no Source.
 	Query     func(context.Context) (float64, error)
 	Threshold Threshold
 }
 
 type Threshold struct {
-	Operator string  // >, <, >=, <=, ==
+	Operator string // >, <, >=, <=, ==
 	Value    float64
 }
 
 // Action represents a fault injection or recovery action
 type Action struct {
-	Type       string                 // latency, failure, partition, resource_exhaustion
-	Target     string                 // service/component name
+	Type       string // latency, failure, partition, resource_exhaustion
+	Target     string // service/component name
 	Parameters map[string]interface{}
 	Execute    func(context.Context) error
 }
@@ -92,6 +98,13 @@ type ChaosEngine struct {
 	experiments []ChaosExperiment
 	results     []ExperimentResult
 	mu          sync.Mutex
+
+	// metricsEndpoints maps a service name to its /metrics URL.
+	metricsEndpoints map[string]string
+	// latencyInjector, if set, is what db was opened through.
+	latencyInjector *LatencyInjector
+	// halt cancels the game day in progress.
+	halt context.CancelCauseFunc
 }
 
 func NewChaosEngine(db *sql.DB) *ChaosEngine {
@@ -117,8 +130,15 @@ func (ce *ChaosEngine) GetExperiments() []ChaosExperiment {
 	return ce.experiments
 }
 
-// RunExperiment executes a single chaos experiment
-func (ce *ChaosEngine) RunExperiment(ctx context.Context, exp ChaosExperiment) (*ExperimentResult, error) {
+// ErrGameDayHalted is the error a game day stopped by Halt returns.
+var ErrGameDayHalted = errors.New("game day halted")
+
+// RunExperiment executes a single chaos experiment. Once its chaos is being
+// injected, its rollback actions run however the experiment ends: when
+// observation runs its course, when ctx is cancelled, which ends it early
+// with ctx's cause, or when one of its actions panics, which it returns as an
+// error. Rollback runs under a context that outlives ctx's cancellation.
+func (ce *ChaosEngine) RunExperiment(ctx context.Context, exp ChaosExperiment) (result *ExperimentResult, err error) {
 	ctx, span := ce.tracer.Start(ctx, "chaos.run_experiment",
 		trace.WithAttributes(
 			attribute.String("experiment.name", exp.Name),
@@ -126,7 +146,7 @@ func (ce *ChaosEngine) RunExperiment(ctx context.Context, exp ChaosExperiment) (
 	)
 	defer span.End()
 
-	result := &ExperimentResult{
+	result = &ExperimentResult{
 		ExperimentName: exp.Name,
 		StartTime:      time.Now(),
 		Observations:   make(map[string][]DataPoint),
@@ -142,6 +162,29 @@ func (ce *ChaosEngine) RunExperiment(ctx context.Context, exp ChaosExperiment) (
 	}
 	result.SteadyStateValid = true
 
+	rolledBack := false
+	rollback := func() {
+		if rolledBack {
+			return
+		}
+		rolledBack = true
+		span.AddEvent("rolling_back")
+		ce.rollback(context.WithoutCancel(ctx), exp.Rollback, result, span)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("experiment %s panicked: %v", exp.Name, p)
+			span.RecordError(err)
+			result.ErrorEvents = append(result.ErrorEvents, ErrorEvent{
+				Timestamp: time.Now(),
+				Error:     err.Error(),
+				Component: exp.Name,
+			})
+			rollback()
+			ce.recordResult(result)
+		}
+	}()
+
 	// Phase 2: Inject chaos
 	span.AddEvent("injecting_chaos")
 	for _, action := range exp.Method {
@@ -173,7 +216,7 @@ func (ce *ChaosEngine) RunExperiment(ctx context.Context, exp ChaosExperiment) (
 		case <-ticker.C:
 			// Sample metrics
 			for _, metric := range exp.SteadyState {
-				value, err := metric.Query(ctx)
+				value, err := metric.value(ctx)
 				if err != nil {
 					result.ErrorEvents = append(result.ErrorEvents, ErrorEvent{
 						Timestamp: time.Now(),
@@ -199,6 +242,10 @@ func (ce *ChaosEngine) RunExperiment(ctx context.Context, exp ChaosExperiment) (
 						Actual:     value,
 						Timestamp:  time.Now(),
 					})
+					if exp.AbortOnViolation {
+						span.AddEvent("aborting_on_violation")
+						goto ROLLBACK
+					}
 				} else if !recoveryStart.IsZero() && !systemRecovered {
 					// System recovered
 					mttr := time.Since(recoveryStart)
@@ -211,22 +258,16 @@ func (ce *ChaosEngine) RunExperiment(ctx context.Context, exp ChaosExperiment) (
 
 ROLLBACK:
 	// Phase 4: Rollback chaos injection
-	span.AddEvent("rolling_back")
-	for _, action := range exp.Rollback {
-		if err := action.Execute(ctx); err != nil {
-			span.RecordError(err)
-		}
+	rollback()
+	if ctx.Err() != nil {
+		ce.recordResult(result)
+		return result, context.Cause(ctx)
 	}
 
 	// Phase 5: Validate assertions
 	span.AddEvent("validating_assertions")
 	result.HypothesisHeld = ce.validateAssertions(ctx, exp.Validation, result)
-	result.EndTime = time.Now()
-	result.Duration = result.EndTime.Sub(result.StartTime)
-
-	ce.mu.Lock()
-	ce.results = append(ce.results, *result)
-	ce.mu.Unlock()
+	ce.recordResult(result)
 
 	span.SetAttributes(
 		attribute.Bool("hypothesis_held", result.HypothesisHeld),
@@ -236,11 +277,47 @@ ROLLBACK:
 	return result, nil
 }
 
+// rollback runs each of actions in turn, carrying on past any that fail or
+// panic so that one broken action cannot leave the rest of the chaos in
+// place.
+func (ce *ChaosEngine) rollback(ctx context.Context, actions []Action, result *ExperimentResult, span trace.Span) {
+	for _, action := range actions {
+		if err := runAction(ctx, action); err != nil {
+			result.ErrorEvents = append(result.ErrorEvents, ErrorEvent{
+				Timestamp: time.Now(),
+				Error:     err.Error(),
+				Component: action.Target,
+			})
+			span.RecordError(err)
+		}
+	}
+}
+
+// runAction executes action, turning a panic into an error.
+func runAction(ctx context.Context, action Action) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("%s action on %s panicked: %v", action.Type, action.Target, p)
+		}
+	}()
+	return action.Execute(ctx)
+}
+
+// recordResult finishes result and adds it to the engine's results.
+func (ce *ChaosEngine) recordResult(result *ExperimentResult) {
+	result.EndTime = time.Now()
+	result.Duration = result.EndTime.Sub(result.StartTime)
+
+	ce.mu.Lock()
+	ce.results = append(ce.results, *result)
+	ce.mu.Unlock()
+}
+
 func (ce *ChaosEngine) validateSteadyState(ctx context.Context, metrics []Metric) (bool, []MetricViolation) {
 	violations := make([]MetricViolation, 0)
 
 	for _, metric := range metrics {
-		value, err := metric.Query(ctx)
+		value, err := metric.value(ctx)
 		if err != nil {
 			violations = append(violations, MetricViolation{
 				MetricName: metric.Name,
@@ -302,44 +379,170 @@ func (ce *ChaosEngine) validateAssertions(ctx context.Context, assertions []Asse
 	return true
 }
 
+// defaultGameDayDelay is how long a game day waits between experiments when
+// it sets no delay of its own.
+const defaultGameDayDelay = 30 * time.Second
+
 // GameDay orchestrates a series of chaos experiments.
 type GameDay struct {
-	Name        string
-	Date        time.Time
-	Scenarios   []ChaosExperiment
+	Name         string
+	Date         time.Time
+	Scenarios    []ChaosExperiment
 	Participants []string
-	Runbooks    map[string]string
+	Runbooks     map[string]string
+
+	// Parallel runs the scenarios concurrently, in order, starting each once
+	// the blast radii of those already running leave room for its own
+	// within a total of 1.0. A scenario with a blast radius of 1.0 runs
+	// alone.
+	Parallel bool
+	// Delay is the wait before each scenario after the first: from the end
+	// of the one before it, or, run in parallel, from its start. Zero waits
+	// 30 seconds.
+	Delay time.Duration
+	// DryRun prints what each scenario would do and checks its steady
+	// state, without injecting any faults.
+	DryRun bool
 }
 
 func (ce *ChaosEngine) ExecuteGameDay(ctx context.Context, gameDay GameDay) error {
 	ctx, span := ce.tracer.Start(ctx, "chaos.game_day",
 		trace.WithAttributes(
 			attribute.String("gameday.name", gameDay.Name),
+			attribute.Bool("gameday.parallel", gameDay.Parallel),
+			attribute.Bool("gameday.dry_run", gameDay.DryRun),
 		),
 	)
 	defer span.End()
 
+	ctx, halt := context.WithCancelCause(ctx)
+	defer halt(nil)
+	ce.mu.Lock()
+	ce.halt = halt
+	ce.mu.Unlock()
+
 	fmt.Printf("🎮 Starting Game Day: %s\n", gameDay.Name)
 	fmt.Printf("📅 Date: %s\n", gameDay.Date)
 	fmt.Printf("👥 Participants: %v\n", gameDay.Participants)
 
+	if gameDay.DryRun {
+		ce.planGameDay(ctx, gameDay.Scenarios)
+		return context.Cause(ctx)
+	}
+
+	delay := gameDay.Delay
+	if delay == 0 {
+		delay = defaultGameDayDelay
+	}
+
+	if gameDay.Parallel {
+		ce.executeParallel(ctx, gameDay.Scenarios, delay)
+		return context.Cause(ctx)
+	}
 	for i, scenario := range gameDay.Scenarios {
-		fmt.Printf("\n🔬 Experiment %d/%d: %s\n", i+1, len(gameDay.Scenarios), scenario.Name)
-		fmt.Printf("💡 Hypothesis: %s\n", scenario.Hypothesis)
+		if i > 0 {
+			// Wait between experiments
+			if sleep(ctx, delay) != nil {
+				break
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		ce.runScenario(ctx, i, len(gameDay.Scenarios), scenario)
+	}
+	return context.Cause(ctx)
+}
 
-		result, err := ce.RunExperiment(ctx, scenario)
-		if err != nil {
-			fmt.Printf("❌ Experiment failed: %v\n", err)
-			continue
+// Halt stops the game day in progress: no further experiments start, and
+// those running end early and roll back. The game day returns
+// ErrGameDayHalted once they have. Halt does not wait for that.
+func (ce *ChaosEngine) Halt() {
+	ce.mu.Lock()
+	halt := ce.halt
+	ce.mu.Unlock()
+	if halt != nil {
+		halt(ErrGameDayHalted)
+	}
+}
+
+// executeParallel runs scenarios concurrently, holding back each one until
+// the total blast radius running alongside it would not exceed 1.0. It stops
+// starting them once ctx is done.
+func (ce *ChaosEngine) executeParallel(ctx context.Context, scenarios []ChaosExperiment, delay time.Duration) {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		freed   = sync.NewCond(&mu)
+		running float64
+	)
+	defer wg.Wait()
+
+	for i, scenario := range scenarios {
+		if i > 0 && sleep(ctx, delay) != nil {
+			return
 		}
 
-		ce.printExperimentResult(result)
+		radius := min(max(scenario.BlastRadius, 0), 1)
+		mu.Lock()
+		for running > 0 && running+radius > 1+blastRadiusTolerance {
+			freed.Wait()
+		}
+		if ctx.Err() != nil {
+			mu.Unlock()
+			return
+		}
+		running += radius
+		mu.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ce.runScenario(ctx, i, len(scenarios), scenario)
+
+			mu.Lock()
+			running -= radius
+			mu.Unlock()
+			freed.Broadcast()
+		}()
+	}
+}
 
-		// Wait between experiments
-		time.Sleep(30 * time.Second)
+// blastRadiusTolerance absorbs rounding in summed blast radii, so radii
+// adding up to exactly 1.0 may run together.
+const blastRadiusTolerance = 1e-9
+
+// runScenario runs one of a game day's experiments and reports how it went.
+// The report is printed in one piece, so concurrent scenarios' reports do
+// not interleave.
+func (ce *ChaosEngine) runScenario(ctx context.Context, i, n int, scenario ChaosExperiment) {
+	ce.mu.Lock()
+	fmt.Printf("\n🔬 Experiment %d/%d: %s\n", i+1, n, scenario.Name)
+	fmt.Printf("💡 Hypothesis: %s\n", scenario.Hypothesis)
+	ce.mu.Unlock()
+
+	result, err := ce.RunExperiment(ctx, scenario)
+
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+	if err != nil {
+		fmt.Printf("❌ Experiment %s failed: %v\n", scenario.Name, err)
+		return
 	}
+	fmt.Printf("\n📋 Experiment %s:\n", scenario.Name)
+	ce.printExperimentResult(result)
+}
 
-	return nil
+// sleep waits for d, or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 func (ce *ChaosEngine) printExperimentResult(result *ExperimentResult) {
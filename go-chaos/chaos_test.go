@@ -0,0 +1,146 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParallelGameDayBoundsBlastRadius(t *testing.T) {
+	var (
+		mu         sync.Mutex
+		running    float64
+		maxRunning float64
+	)
+	scenario := func(name string, radius float64) ChaosExperiment {
+		return ChaosExperiment{
+			Name: name,
+			Method: []Action{{Execute: func(context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+				running += radius
+				maxRunning = max(maxRunning, running)
+				return nil
+			}}},
+			Rollback: []Action{{Execute: func(context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+				running -= radius
+				return nil
+			}}},
+			Duration:    100 * time.Millisecond,
+			BlastRadius: radius,
+		}
+	}
+
+	ce := NewChaosEngine(nil)
+	start := time.Now()
+	err := ce.ExecuteGameDay(context.Background(), GameDay{
+		Name:      "parallel",
+		Scenarios: []ChaosExperiment{scenario("a", 0.5), scenario("b", 0.5), scenario("c", 0.6), scenario("d", 0.4)},
+		Parallel:  true,
+		Delay:     time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("ExecuteGameDay: %v", err)
+	}
+	took := time.Since(start)
+
+	if maxRunning > 1+blastRadiusTolerance {
+		t.Errorf("blast radius running at once reached %.2f, want at most 1.0", maxRunning)
+	}
+	if maxRunning < 1-blastRadiusTolerance {
+		t.Errorf("blast radius running at once peaked at %.2f, want scenarios sharing 1.0", maxRunning)
+	}
+	if len(ce.results) != 4 {
+		t.Errorf("recorded %d results, want 4", len(ce.results))
+	}
+	// a and b run together, then c and d: two rounds rather than four.
+	if took >= 400*time.Millisecond {
+		t.Errorf("parallel game day took %s, no faster than running its scenarios in turn", took)
+	}
+}
+
+func TestRunExperimentRollsBackAfterPanic(t *testing.T) {
+	var rolledBack atomic.Bool
+	ce := NewChaosEngine(nil)
+	result, err := ce.RunExperiment(context.Background(), ChaosExperiment{
+		Name: "panicking",
+		Method: []Action{{Execute: func(context.Context) error {
+			panic("injection broke")
+		}}},
+		Rollback: []Action{
+			{Target: "broken", Execute: func(context.Context) error { panic("rollback broke") }},
+			{Execute: func(context.Context) error {
+				rolledBack.Store(true)
+				return nil
+			}},
+		},
+		Duration: time.Minute,
+	})
+	if err == nil || !strings.Contains(err.Error(), "injection broke") {
+		t.Errorf("RunExperiment error = %v, want the panic", err)
+	}
+	if !rolledBack.Load() {
+		t.Error("rollback did not run after the injection panicked")
+	}
+	if result == nil || len(result.ErrorEvents) != 2 {
+		t.Errorf("result = %+v, want the injection and rollback panics recorded", result)
+	}
+	if len(ce.results) != 1 {
+		t.Errorf("recorded %d results, want 1", len(ce.results))
+	}
+}
+
+func TestHaltRollsBackAndStopsGameDay(t *testing.T) {
+	var started, rolledBack atomic.Int32
+	injected := make(chan struct{}, 2)
+	scenario := ChaosExperiment{
+		Name: "long",
+		Method: []Action{{Execute: func(context.Context) error {
+			started.Add(1)
+			injected <- struct{}{}
+			return nil
+		}}},
+		Rollback: []Action{{Execute: func(ctx context.Context) error {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			rolledBack.Add(1)
+			return nil
+		}}},
+		Duration:    time.Minute,
+		BlastRadius: 1.0,
+	}
+
+	ce := NewChaosEngine(nil)
+	done := make(chan error, 1)
+	go func() {
+		done <- ce.ExecuteGameDay(context.Background(), GameDay{
+			Name:      "halted",
+			Scenarios: []ChaosExperiment{scenario, scenario},
+			Delay:     time.Millisecond,
+		})
+	}()
+
+	<-injected
+	ce.Halt()
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrGameDayHalted) {
+			t.Errorf("ExecuteGameDay = %v, want ErrGameDayHalted", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("game day did not stop when halted")
+	}
+	if started.Load() != 1 {
+		t.Errorf("%d experiments started, want only the one running when halted", started.Load())
+	}
+	if rolledBack.Load() != 1 {
+		t.Errorf("%d experiments rolled back under a live context, want 1", rolledBack.Load())
+	}
+}
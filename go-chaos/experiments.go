@@ -17,9 +17,10 @@ func (ce *ChaosEngine) RegisterExperiments() {
 	ce.RegisterExperiment(ce.ResourceExhaustionExperiment())
 }
 
-// DatabaseLatencyExperiment injects latency into database operations
+// DatabaseLatencyExperiment injects latency into database operations made
+// through the engine's LatencyInjector
 func (ce *ChaosEngine) DatabaseLatencyExperiment(targetLatency time.Duration) ChaosExperiment {
-	var originalDB *sql.DB
+	const jitter = 50 * time.Millisecond
 
 	return ChaosExperiment{
 		Name:       "database-latency-injection",
@@ -27,18 +28,20 @@ func (ce *ChaosEngine) DatabaseLatencyExperiment(targetLatency time.Duration) Ch
 		SteadyState: []Metric{
 			{
 				Name: "checkout_success_rate",
-				Query: func(ctx context.Context) (float64, error) {
-					var successRate float64
-					err := ce.db.QueryRowContext(ctx, `
-						SELECT COALESCE(
-							COUNT(*) FILTER (WHERE status = 'active')::float / NULLIF(COUNT(*)::float, 0) * 100,
-							100.0
-						) FROM checkouts WHERE created_at > NOW() - INTERVAL '1 minute'
-					`).Scan(&successRate)
-					return successRate, err
-				},
+				Source: SQLSource{DB: ce.db, Query: `
+					SELECT COALESCE(
+						COUNT(*) FILTER (WHERE status = 'active')::float / NULLIF(COUNT(*)::float, 0) * 100,
+						100.0
+					) FROM checkouts WHERE created_at > NOW() - INTERVAL '1 minute'
+				`},
 				Threshold: Threshold{Operator: ">", Value: 99.0},
 			},
+			{
+				// Violated while the latency is injected, showing it took.
+				Name:      "database_query_latency_ms",
+				Source:    MetricFunc(ce.QueryLatency),
+				Threshold: Threshold{Operator: "<", Value: float64(targetLatency / time.Millisecond)},
+			},
 		},
 		Method: []Action{
 			{
@@ -46,12 +49,14 @@ func (ce *ChaosEngine) DatabaseLatencyExperiment(targetLatency time.Duration) Ch
 				Target: "postgres-primary",
 				Parameters: map[string]interface{}{
 					"latency": targetLatency,
-					"jitter":  50 * time.Millisecond,
+					"jitter":  jitter,
 				},
 				Execute: func(ctx context.Context) error {
-					// Wrap database calls with artificial latency
-					originalDB = ce.db
-					// In production, this would use a proxy or network policy
+					li, err := ce.latency()
+					if err != nil {
+						return err
+					}
+					li.Inject(targetLatency, jitter)
 					return nil
 				},
 			},
@@ -61,7 +66,11 @@ func (ce *ChaosEngine) DatabaseLatencyExperiment(targetLatency time.Duration) Ch
 				Type:   "remove-latency",
 				Target: "postgres-primary",
 				Execute: func(ctx context.Context) error {
-					ce.db = originalDB
+					li, err := ce.latency()
+					if err != nil {
+						return err
+					}
+					li.Remove()
 					return nil
 				},
 			},
@@ -80,6 +89,10 @@ func (ce *ChaosEngine) DatabaseLatencyExperiment(targetLatency time.Duration) Ch
 
 // CircuitBreakerExperiment validates circuit breaker behavior
 func (ce *ChaosEngine) CircuitBreakerExperiment() ChaosExperiment {
+	failedSearches := ce.percentOf("catalog",
+		series{name: "catalog_search_requests_total", labels: map[string]string{"served_by": "failed"}},
+		series{name: "catalog_search_requests_total"},
+		0)
 
 	return ChaosExperiment{
 		Name:       "search-backend-failure",
@@ -87,10 +100,10 @@ func (ce *ChaosEngine) CircuitBreakerExperiment() ChaosExperiment {
 		SteadyState: []Metric{
 			{
 				Name: "search_availability",
-				Query: func(ctx context.Context) (float64, error) {
-					// Would query metrics endpoint
-					return 100.0, nil
-				},
+				Source: MetricFunc(func(ctx context.Context) (float64, error) {
+					failed, err := failedSearches.Value(ctx)
+					return 100.0 - failed, err
+				}),
 				Threshold: Threshold{Operator: ">", Value: 99.0},
 			},
 		},
@@ -138,14 +151,10 @@ func (ce *ChaosEngine) ConcurrentCheckoutRaceConditionTest() ChaosExperiment {
 		SteadyState: []Metric{
 			{
 				Name: "data_consistency",
-				Query: func(ctx context.Context) (float64, error) {
-					var inconsistencies int
-					err := ce.db.QueryRowContext(ctx, `
-						SELECT COUNT(*) FROM items
-						WHERE available < 0 OR available > total_copies
-					`).Scan(&inconsistencies)
-					return float64(inconsistencies), err
-				},
+				Source: SQLSource{DB: ce.db, Query: `
+					SELECT COUNT(*) FROM items
+					WHERE available < 0 OR available > total_copies
+				`},
 				Threshold: Threshold{Operator: "==", Value: 0},
 			},
 		},
@@ -194,13 +203,14 @@ func (ce *ChaosEngine) ConcurrentCheckoutRaceConditionTest() ChaosExperiment {
 func (ce *ChaosEngine) NetworkPartitionExperiment() ChaosExperiment {
 	return ChaosExperiment{
 		Name:       "event-bus-partition",
-		Hypothesis: "Services buffer events and replay when NATS reconnects",
+		Hypothesis: "Events wait in the outbox and are published when NATS reconnects",
 		SteadyState: []Metric{
 			{
 				Name: "event_publish_success_rate",
-				Query: func(ctx context.Context) (float64, error) {
-					return 100.0, nil // Would query NATS metrics
-				},
+				Source: ce.percentOf("publisher",
+					series{name: "events_published_total", labels: map[string]string{"outcome": "ok"}},
+					series{name: "events_published_total"},
+					100),
 				Threshold: Threshold{Operator: "==", Value: 100.0},
 			},
 		},
@@ -249,9 +259,10 @@ func (ce *ChaosEngine) ResourceExhaustionExperiment() ChaosExperiment {
 		SteadyState: []Metric{
 			{
 				Name: "error_rate",
-				Query: func(ctx context.Context) (float64, error) {
-					return 0.0, nil // Would query error metrics
-				},
+				Source: ce.percentOf("circulation",
+					series{name: "http_request_errors_total"},
+					series{name: "http_requests_total"},
+					0),
 				Threshold: Threshold{Operator: "<", Value: 1.0},
 			},
 		},
@@ -260,19 +271,29 @@ func (ce *ChaosEngine) ResourceExhaustionExperiment() ChaosExperiment {
 				Type:   "exhaust-connections",
 				Target: "postgres-connection-pool",
 				Execute: func(ctx context.Context) error {
-					// Open connections and hold them
+					// Open connections and hold them. Each acquisition is bounded so
+					// the experiment stops grabbing connections once the server
+					// refuses or stalls, rather than hanging with it.
 					conns := make([]*sql.Conn, 0)
 					for i := 0; i < 100; i++ {
-						conn, err := ce.db.Conn(ctx)
+						acquireCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+						conn, err := ce.db.Conn(acquireCtx)
+						cancel()
 						if err != nil {
 							break
 						}
 						conns = append(conns, conn)
 					}
+					defer func() {
+						for _, conn := range conns {
+							conn.Close()
+						}
+					}()
+
 					// Hold connections for experiment duration
-					time.Sleep(30 * time.Second)
-					for _, conn := range conns {
-						conn.Close()
+					select {
+					case <-time.After(30 * time.Second):
+					case <-ctx.Done():
 					}
 					return nil
 				},
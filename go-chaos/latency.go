@@ -0,0 +1,263 @@
+// chaos/latency.go
+package chaos
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// errNoLatencyInjector is returned by latency experiments run on an engine
+// with no LatencyInjector installed.
+var errNoLatencyInjector = errors.New("no latency injector installed")
+
+// LatencyInjector is a driver.Connector that delays every query and
+// statement execution made through it while latency is being injected. Open
+// a pool on it with sql.OpenDB and hand that pool to whatever should feel the
+// latency; pings and transaction control are never delayed.
+type LatencyInjector struct {
+	connector driver.Connector
+
+	mu      sync.Mutex
+	enabled bool
+	latency time.Duration
+	jitter  time.Duration
+}
+
+// NewLatencyInjector wraps connector, initially injecting no latency.
+func NewLatencyInjector(connector driver.Connector) *LatencyInjector {
+	return &LatencyInjector{connector: connector}
+}
+
+// Inject starts delaying each query by latency plus up to jitter more,
+// chosen at random per query.
+func (li *LatencyInjector) Inject(latency, jitter time.Duration) {
+	li.mu.Lock()
+	defer li.mu.Unlock()
+	li.enabled = true
+	li.latency = latency
+	li.jitter = jitter
+}
+
+// Remove stops injecting latency. Queries already waiting finish their delay.
+func (li *LatencyInjector) Remove() {
+	li.mu.Lock()
+	defer li.mu.Unlock()
+	li.enabled = false
+}
+
+// delay waits out the injected latency, if any, or until ctx is done.
+func (li *LatencyInjector) delay(ctx context.Context) error {
+	li.mu.Lock()
+	enabled, d := li.enabled, li.latency
+	if enabled && li.jitter > 0 {
+		d += rand.N(li.jitter)
+	}
+	li.mu.Unlock()
+	if !enabled || d <= 0 {
+		return nil
+	}
+	return sleep(ctx, d)
+}
+
+func (li *LatencyInjector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := li.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &latencyConn{Conn: conn, injector: li}, nil
+}
+
+func (li *LatencyInjector) Driver() driver.Driver {
+	return li.connector.Driver()
+}
+
+// SetLatencyInjector installs the injector through which the engine's
+// database pool was opened, for the latency experiments to drive.
+func (ce *ChaosEngine) SetLatencyInjector(li *LatencyInjector) {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+	ce.latencyInjector = li
+}
+
+func (ce *ChaosEngine) latency() (*LatencyInjector, error) {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+	if ce.latencyInjector == nil {
+		return nil, errNoLatencyInjector
+	}
+	return ce.latencyInjector, nil
+}
+
+// QueryLatency times a trivial round trip through the engine's pool, in
+// milliseconds.
+func (ce *ChaosEngine) QueryLatency(ctx context.Context) (float64, error) {
+	start := time.Now()
+	var one int
+	if err := ce.db.QueryRowContext(ctx, `SELECT 1`).Scan(&one); err != nil {
+		return 0, err
+	}
+	return float64(time.Since(start)) / float64(time.Millisecond), nil
+}
+
+// latencyConn delays the queries made on one connection. database/sql uses a
+// connection from one goroutine at a time, so its fields need no locking.
+type latencyConn struct {
+	driver.Conn
+	injector *LatencyInjector
+
+	// delayed is set when a direct query was delayed and then declined by
+	// the driver, so the prepared statement database/sql falls back to is
+	// not delayed a second time.
+	delayed bool
+}
+
+func (c *latencyConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	if err := c.injector.delay(ctx); err != nil {
+		return nil, err
+	}
+	rows, err := queryer.QueryContext(ctx, query, args)
+	c.delayed = errors.Is(err, driver.ErrSkip)
+	return rows, err
+}
+
+func (c *latencyConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	if err := c.injector.delay(ctx); err != nil {
+		return nil, err
+	}
+	result, err := execer.ExecContext(ctx, query, args)
+	c.delayed = errors.Is(err, driver.ErrSkip)
+	return result, err
+}
+
+func (c *latencyConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *latencyConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &latencyStmt{Stmt: stmt, conn: c}, nil
+}
+
+func (c *latencyConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) || opts.ReadOnly {
+		return nil, errors.New("driver does not support transaction options")
+	}
+	return c.Conn.Begin()
+}
+
+func (c *latencyConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *latencyConn) ResetSession(ctx context.Context) error {
+	c.delayed = false
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *latencyConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *latencyConn) CheckNamedValue(v *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(v)
+	}
+	return driver.ErrSkip
+}
+
+// delayStatement waits out the injected latency before a statement runs,
+// unless the direct query it stands in for was already delayed.
+func (c *latencyConn) delayStatement(ctx context.Context) error {
+	if c.delayed {
+		c.delayed = false
+		return nil
+	}
+	return c.injector.delay(ctx)
+}
+
+// latencyStmt delays each execution of a prepared statement.
+type latencyStmt struct {
+	driver.Stmt
+	conn *latencyConn
+}
+
+func (s *latencyStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if err := s.conn.delayStatement(ctx); err != nil {
+		return nil, err
+	}
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		return execer.ExecContext(ctx, args)
+	}
+	values, err := namedValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.Stmt.Exec(values)
+}
+
+func (s *latencyStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if err := s.conn.delayStatement(ctx); err != nil {
+		return nil, err
+	}
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		return queryer.QueryContext(ctx, args)
+	}
+	values, err := namedValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.Stmt.Query(values)
+}
+
+func (s *latencyStmt) CheckNamedValue(v *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(v)
+	}
+	return s.conn.CheckNamedValue(v)
+}
+
+// namedValues strips args to the positional values older drivers take.
+func namedValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("driver does not support named arguments")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
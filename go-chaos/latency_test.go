@@ -0,0 +1,77 @@
+package chaos
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// stubConnector hands out connections that answer every query at once with
+// no rows.
+type stubConnector struct{}
+
+func (stubConnector) Connect(context.Context) (driver.Conn, error) { return stubConn{}, nil }
+func (stubConnector) Driver() driver.Driver                        { return nil }
+
+type stubConn struct{}
+
+func (stubConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (stubConn) Close() error                        { return nil }
+func (stubConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (stubConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return stubRows{}, nil
+}
+
+type stubRows struct{}
+
+func (stubRows) Columns() []string         { return []string{"n"} }
+func (stubRows) Close() error              { return nil }
+func (stubRows) Next([]driver.Value) error { return io.EOF }
+
+func timeQuery(t *testing.T, ctx context.Context, db *sql.DB) (time.Duration, error) {
+	t.Helper()
+	start := time.Now()
+	rows, err := db.QueryContext(ctx, "SELECT 1")
+	if err != nil {
+		return time.Since(start), err
+	}
+	rows.Close()
+	return time.Since(start), nil
+}
+
+func TestLatencyInjectorDelaysQueries(t *testing.T) {
+	injector := NewLatencyInjector(stubConnector{})
+	db := sql.OpenDB(injector)
+	defer db.Close()
+	ctx := context.Background()
+
+	const latency = 50 * time.Millisecond
+	if took, err := timeQuery(t, ctx, db); err != nil || took >= latency {
+		t.Fatalf("query without injected latency took %s (err %v)", took, err)
+	}
+
+	injector.Inject(latency, 10*time.Millisecond)
+	took, err := timeQuery(t, ctx, db)
+	if err != nil {
+		t.Fatalf("query with injected latency: %v", err)
+	}
+	if took < latency {
+		t.Errorf("query with %s injected took only %s", latency, took)
+	}
+
+	shortCtx, cancel := context.WithTimeout(ctx, 5*time.Millisecond)
+	defer cancel()
+	if _, err := timeQuery(t, shortCtx, db); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("query outliving its context = %v, want deadline exceeded", err)
+	}
+
+	injector.Remove()
+	if took, err := timeQuery(t, ctx, db); err != nil || took >= latency {
+		t.Errorf("query after removing latency took %s (err %v)", took, err)
+	}
+}
@@ -0,0 +1,83 @@
+// chaos/plan.go
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SelectExperiments returns the registered experiments with the given names,
+// in the order they were registered. Naming an experiment that is not
+// registered is an error.
+func (ce *ChaosEngine) SelectExperiments(names ...string) ([]ChaosExperiment, error) {
+	want := make(map[string]bool, len(names))
+	for _, name := range names {
+		want[name] = true
+	}
+
+	var selected []ChaosExperiment
+	for _, exp := range ce.GetExperiments() {
+		if want[exp.Name] {
+			selected = append(selected, exp)
+			delete(want, exp.Name)
+		}
+	}
+	if len(want) > 0 {
+		unknown := make([]string, 0, len(want))
+		for name := range want {
+			unknown = append(unknown, name)
+		}
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown experiments: %s", strings.Join(unknown, ", "))
+	}
+	return selected, nil
+}
+
+// planGameDay prints what each of a game day's scenarios would do and
+// checks its steady state, injecting nothing.
+func (ce *ChaosEngine) planGameDay(ctx context.Context, scenarios []ChaosExperiment) {
+	fmt.Printf("🧪 Dry run: no faults will be injected\n")
+	for i, scenario := range scenarios {
+		if ctx.Err() != nil {
+			return
+		}
+		fmt.Printf("\n🔬 Experiment %d/%d: %s\n", i+1, len(scenarios), scenario.Name)
+		fmt.Printf("💡 Hypothesis: %s\n", scenario.Hypothesis)
+		fmt.Printf("   Duration %s, blast radius %.2f\n", scenario.Duration, scenario.BlastRadius)
+		for _, action := range scenario.Method {
+			fmt.Printf("   Would inject: %s\n", describeAction(action))
+		}
+		for _, action := range scenario.Rollback {
+			fmt.Printf("   Would roll back: %s\n", describeAction(action))
+		}
+
+		if valid, violations := ce.validateSteadyState(ctx, scenario.SteadyState); valid {
+			fmt.Printf("✅ Steady state valid\n")
+		} else {
+			fmt.Printf("⚠️  Steady state invalid - the experiment would abort\n")
+			for _, v := range violations {
+				fmt.Printf("   - %s: expected %.2f, got %.2f\n", v.MetricName, v.Expected, v.Actual)
+			}
+		}
+	}
+}
+
+// describeAction renders an action as its type, target and parameters.
+func describeAction(action Action) string {
+	desc := fmt.Sprintf("%s on %s", action.Type, action.Target)
+	if len(action.Parameters) == 0 {
+		return desc
+	}
+	keys := make([]string, 0, len(action.Parameters))
+	for k := range action.Parameters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	params := make([]string, len(keys))
+	for i, k := range keys {
+		params[i] = fmt.Sprintf("%s=%v", k, action.Parameters[k])
+	}
+	return fmt.Sprintf("%s (%s)", desc, strings.Join(params, ", "))
+}
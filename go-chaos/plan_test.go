@@ -0,0 +1,65 @@
+package chaos
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDryRunInjectsNothing(t *testing.T) {
+	var injected, checked int
+	ce := NewChaosEngine(nil)
+	ce.RegisterExperiment(ChaosExperiment{
+		Name: "latency",
+		SteadyState: []Metric{{
+			Name: "m",
+			Source: MetricFunc(func(context.Context) (float64, error) {
+				checked++
+				return 1, nil
+			}),
+			Threshold: Threshold{Operator: "==", Value: 1},
+		}},
+		Method: []Action{{Type: "inject-latency", Target: "db", Execute: func(context.Context) error {
+			injected++
+			return nil
+		}}},
+		Duration: time.Minute,
+	})
+	ce.RegisterExperiment(ChaosExperiment{Name: "partition"})
+
+	scenarios, err := ce.SelectExperiments("latency")
+	if err != nil {
+		t.Fatalf("SelectExperiments: %v", err)
+	}
+	if len(scenarios) != 1 || scenarios[0].Name != "latency" {
+		t.Fatalf("selected %+v, want only latency", scenarios)
+	}
+	if _, err := ce.SelectExperiments("latency", "missing"); err == nil {
+		t.Error("selecting an unregistered experiment succeeded")
+	}
+
+	err = ce.ExecuteGameDay(context.Background(), GameDay{Name: "dry", Scenarios: scenarios, DryRun: true})
+	if err != nil {
+		t.Fatalf("ExecuteGameDay: %v", err)
+	}
+	if injected != 0 {
+		t.Errorf("dry run injected %d faults", injected)
+	}
+	if checked != 1 {
+		t.Errorf("dry run checked steady state %d times, want 1", checked)
+	}
+	if len(ce.results) != 0 {
+		t.Errorf("dry run recorded %d results", len(ce.results))
+	}
+}
+
+func TestDescribeAction(t *testing.T) {
+	got := describeAction(Action{
+		Type:       "inject-latency",
+		Target:     "postgres-primary",
+		Parameters: map[string]interface{}{"latency": 250 * time.Millisecond, "jitter": 50 * time.Millisecond},
+	})
+	if want := "inject-latency on postgres-primary (jitter=50ms, latency=250ms)"; got != want {
+		t.Errorf("describeAction = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,97 @@
+// chaos/report.go
+package chaos
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Formats ExportResults can write.
+const (
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+)
+
+// ExportResults writes the results of every experiment run so far to w, in
+// order, as either FormatJSON, carrying each result in full, or
+// FormatMarkdown, a summary for people to read.
+func (ce *ChaosEngine) ExportResults(w io.Writer, format string) error {
+	ce.mu.Lock()
+	results := make([]ExperimentResult, len(ce.results))
+	copy(results, ce.results)
+	ce.mu.Unlock()
+
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Results []ExperimentResult `json:"results"`
+		}{results})
+	case FormatMarkdown:
+		_, err := io.WriteString(w, markdownReport(results))
+		return err
+	default:
+		return fmt.Errorf("unknown report format %q", format)
+	}
+}
+
+// markdownReport summarises results as a table of outcomes, followed by the
+// violations and errors each experiment saw.
+func markdownReport(results []ExperimentResult) string {
+	var b strings.Builder
+	b.WriteString("# Chaos Experiment Results\n\n")
+	if len(results) == 0 {
+		b.WriteString("No experiments were run.\n")
+		return b.String()
+	}
+
+	b.WriteString("| Experiment | Hypothesis held | Steady state | Violations | MTTR | Duration |\n")
+	b.WriteString("|---|---|---|---|---|---|\n")
+	for _, r := range results {
+		mttr := "-"
+		if r.MTTR != nil {
+			mttr = r.MTTR.Round(time.Millisecond).String()
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %d | %s | %s |\n",
+			r.ExperimentName, yesNo(r.HypothesisHeld), validity(r.SteadyStateValid),
+			len(r.Violations), mttr, r.Duration.Round(time.Millisecond))
+	}
+
+	for _, r := range results {
+		if len(r.Violations) == 0 && len(r.ErrorEvents) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s\n", r.ExperimentName)
+		if len(r.Violations) > 0 {
+			b.WriteString("\n### Violations\n\n")
+			for _, v := range r.Violations {
+				fmt.Fprintf(&b, "- %s %s: expected %.2f, got %.2f\n", v.Timestamp.Format(time.RFC3339), v.MetricName, v.Expected, v.Actual)
+			}
+		}
+		if len(r.ErrorEvents) > 0 {
+			b.WriteString("\n### Errors\n\n")
+			for _, e := range r.ErrorEvents {
+				fmt.Fprintf(&b, "- %s %s: %s\n", e.Timestamp.Format(time.RFC3339), e.Component, e.Error)
+			}
+		}
+	}
+	return b.String()
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func validity(valid bool) string {
+	if valid {
+		return "valid"
+	}
+	return "invalid"
+}
@@ -0,0 +1,79 @@
+package chaos
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportResults(t *testing.T) {
+	mttr := 1500 * time.Millisecond
+	ce := NewChaosEngine(nil)
+	ce.results = []ExperimentResult{
+		{
+			ExperimentName:   "database-latency-injection",
+			HypothesisHeld:   true,
+			SteadyStateValid: true,
+			Duration:         time.Minute,
+			Violations: []MetricViolation{
+				{MetricName: "database_query_latency_ms", Expected: 250, Actual: 301.5},
+			},
+			Observations: map[string][]DataPoint{
+				"database_query_latency_ms": {{Value: 301.5}},
+			},
+			MTTR: &mttr,
+		},
+		{ExperimentName: "search-backend-failure"},
+	}
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := ce.ExportResults(&buf, FormatJSON); err != nil {
+			t.Fatalf("ExportResults: %v", err)
+		}
+		var report struct {
+			Results []ExperimentResult `json:"results"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+			t.Fatalf("decoding report: %v", err)
+		}
+		if len(report.Results) != 2 {
+			t.Fatalf("report has %d results, want 2", len(report.Results))
+		}
+		got := report.Results[0]
+		if got.MTTR == nil || *got.MTTR != mttr {
+			t.Errorf("MTTR = %v, want %s", got.MTTR, mttr)
+		}
+		if len(got.Violations) != 1 || got.Violations[0].Actual != 301.5 {
+			t.Errorf("violations = %+v, want the one recorded", got.Violations)
+		}
+		if len(got.Observations["database_query_latency_ms"]) != 1 {
+			t.Errorf("observations = %+v, want the one recorded", got.Observations)
+		}
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := ce.ExportResults(&buf, FormatMarkdown); err != nil {
+			t.Fatalf("ExportResults: %v", err)
+		}
+		report := buf.String()
+		for _, want := range []string{
+			"| database-latency-injection | yes | valid | 1 | 1.5s | 1m0s |",
+			"| search-backend-failure | no | invalid | 0 | - | 0s |",
+			"database_query_latency_ms: expected 250.00, got 301.50",
+		} {
+			if !strings.Contains(report, want) {
+				t.Errorf("report lacks %q:\n%s", want, report)
+			}
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		if err := ce.ExportResults(&bytes.Buffer{}, "yaml"); err == nil {
+			t.Error("ExportResults accepted an unknown format")
+		}
+	})
+}
@@ -0,0 +1,180 @@
+// chaos/scrape.go
+package chaos
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const scrapeTimeout = 5 * time.Second
+
+// SetMetricsEndpoint tells the engine where a service's Prometheus /metrics
+// endpoint is, for experiments whose metrics are scraped from it.
+func (ce *ChaosEngine) SetMetricsEndpoint(service, url string) {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+	if ce.metricsEndpoints == nil {
+		ce.metricsEndpoints = make(map[string]string)
+	}
+	ce.metricsEndpoints[service] = url
+}
+
+func (ce *ChaosEngine) metricsEndpoint(service string) (string, error) {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+	url, ok := ce.metricsEndpoints[service]
+	if !ok {
+		return "", fmt.Errorf("no metrics endpoint configured for %s", service)
+	}
+	return url, nil
+}
+
+// series selects the samples of one metric whose labels include all of the
+// given name/value pairs.
+type series struct {
+	name   string
+	labels map[string]string
+}
+
+// scrapeSum fetches a service's metrics and returns the sum of the selected
+// samples. A metric that has not been reported yet sums to zero.
+func (ce *ChaosEngine) scrapeSum(ctx context.Context, service string, sel series) (float64, error) {
+	url, err := ce.metricsEndpoint(service)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, scrapeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("scrape %s: %w", service, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("scrape %s: unexpected status code: %d", service, resp.StatusCode)
+	}
+
+	var sum float64
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		name, labels, value, ok := parseSample(scanner.Text())
+		if ok && name == sel.name && matchLabels(labels, sel.labels) {
+			sum += value
+		}
+	}
+	return sum, scanner.Err()
+}
+
+// percentOf returns a MetricSource reporting part as a percentage of whole over the
+// interval since the previous call, so historical traffic does not mask what
+// happens during the experiment. It reports idle until the first interval
+// with traffic.
+func (ce *ChaosEngine) percentOf(service string, part, whole series, idle float64) MetricSource {
+	var mu sync.Mutex
+	var lastPart, lastWhole float64
+	var primed bool
+	last := idle
+
+	return MetricFunc(func(ctx context.Context) (float64, error) {
+		p, err := ce.scrapeSum(ctx, service, part)
+		if err != nil {
+			return 0, err
+		}
+		w, err := ce.scrapeSum(ctx, service, whole)
+		if err != nil {
+			return 0, err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if primed && w > lastWhole {
+			last = 100 * (p - lastPart) / (w - lastWhole)
+		}
+		lastPart, lastWhole, primed = p, w, true
+		return last, nil
+	})
+}
+
+// parseSample parses one line of the Prometheus text format. Comments, blank
+// lines and malformed lines report ok=false.
+func parseSample(line string) (name string, labels map[string]string, value float64, ok bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", nil, 0, false
+	}
+
+	rest := line
+	if i := strings.IndexByte(line, '{'); i >= 0 {
+		end := strings.LastIndexByte(line, '}')
+		if end < i {
+			return "", nil, 0, false
+		}
+		name = line[:i]
+		labels = parseLabels(line[i+1 : end])
+		rest = line[end+1:]
+	} else {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return "", nil, 0, false
+		}
+		name, rest = fields[0], strings.Join(fields[1:], " ")
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return "", nil, 0, false
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return "", nil, 0, false
+	}
+	return name, labels, value, true
+}
+
+func parseLabels(s string) map[string]string {
+	labels := make(map[string]string)
+	for s != "" {
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 || eq+1 >= len(s) || s[eq+1] != '"' {
+			break
+		}
+		key := strings.TrimSpace(s[:eq])
+
+		var value strings.Builder
+		i := eq + 2
+		for ; i < len(s) && s[i] != '"'; i++ {
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+				if s[i] == 'n' {
+					value.WriteByte('\n')
+					continue
+				}
+			}
+			value.WriteByte(s[i])
+		}
+		labels[key] = value.String()
+
+		s = strings.TrimLeft(s[min(i+1, len(s)):], ", ")
+	}
+	return labels
+}
+
+func matchLabels(labels, want map[string]string) bool {
+	for k, v := range want {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
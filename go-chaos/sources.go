@@ -0,0 +1,137 @@
+// chaos/sources.go
+package chaos
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// MetricSource yields the current value of a steady-state metric.
+type MetricSource interface {
+	Value(ctx context.Context) (float64, error)
+}
+
+// MetricFunc adapts a function to a MetricSource.
+type MetricFunc func(ctx context.Context) (float64, error)
+
+func (f MetricFunc) Value(ctx context.Context) (float64, error) {
+	return f(ctx)
+}
+
+// value reads the metric from its Source, or through Query if it has none.
+func (m Metric) value(ctx context.Context) (float64, error) {
+	switch {
+	case m.Source != nil:
+		return m.Source.Value(ctx)
+	case m.Query != nil:
+		return m.Query(ctx)
+	default:
+		return 0, fmt.Errorf("metric %s has no source", m.Name)
+	}
+}
+
+// SQLSource reads a metric as the single number a query returns.
+type SQLSource struct {
+	DB    *sql.DB
+	Query string
+	Args  []interface{}
+}
+
+func (s SQLSource) Value(ctx context.Context) (float64, error) {
+	if s.DB == nil {
+		return 0, errors.New("sql source has no database")
+	}
+	var v float64
+	if err := s.DB.QueryRowContext(ctx, s.Query, s.Args...).Scan(&v); err != nil {
+		return 0, fmt.Errorf("sql source: %w", err)
+	}
+	return v, nil
+}
+
+// PrometheusSource reads a metric by running a PromQL instant query against
+// a Prometheus server. A vector result reads as the sum of its samples, so a
+// query matching no series reads as zero.
+type PrometheusSource struct {
+	// URL is the server's base URL, such as http://prometheus:9090.
+	URL   string
+	Query string
+	// Client sends the query. Nil uses http.DefaultClient.
+	Client *http.Client
+}
+
+func (s PrometheusSource) Value(ctx context.Context) (float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, scrapeTimeout)
+	defer cancel()
+	endpoint := strings.TrimSuffix(s.URL, "/") + "/api/v1/query?" + url.Values{"query": {s.Query}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return 0, err
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("prometheus query: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+		Data   struct {
+			ResultType string          `json:"resultType"`
+			Result     json.RawMessage `json:"result"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, fmt.Errorf("prometheus query: unexpected response (status code %d): %w", resp.StatusCode, err)
+	}
+	if body.Status != "success" {
+		return 0, fmt.Errorf("prometheus query: %s", body.Error)
+	}
+
+	switch body.Data.ResultType {
+	case "scalar":
+		var sample [2]interface{}
+		if err := json.Unmarshal(body.Data.Result, &sample); err != nil {
+			return 0, fmt.Errorf("prometheus query: %w", err)
+		}
+		return sampleValue(sample)
+	case "vector":
+		var series []struct {
+			Value [2]interface{} `json:"value"`
+		}
+		if err := json.Unmarshal(body.Data.Result, &series); err != nil {
+			return 0, fmt.Errorf("prometheus query: %w", err)
+		}
+		var sum float64
+		for _, s := range series {
+			v, err := sampleValue(s.Value)
+			if err != nil {
+				return 0, err
+			}
+			sum += v
+		}
+		return sum, nil
+	default:
+		return 0, fmt.Errorf("prometheus query: unsupported result type %q", body.Data.ResultType)
+	}
+}
+
+// sampleValue reads the value out of a Prometheus [timestamp, "value"] pair.
+func sampleValue(sample [2]interface{}) (float64, error) {
+	s, ok := sample[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("prometheus query: malformed sample %v", sample)
+	}
+	return strconv.ParseFloat(s, 64)
+}
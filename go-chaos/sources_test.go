@@ -0,0 +1,69 @@
+package chaos
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPrometheusSource(t *testing.T) {
+	responses := map[string]string{
+		"vector": `{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"outcome":"ok"},"value":[1700000000.1,"97.5"]},
+			{"metric":{"outcome":"error"},"value":[1700000000.1,"2.5"]}]}}`,
+		"empty":  `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+		"scalar": `{"status":"success","data":{"resultType":"scalar","result":[1700000000.1,"42"]}}`,
+		"bad":    `{"status":"error","errorType":"bad_data","error":"parse error"}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query" {
+			http.NotFound(w, r)
+			return
+		}
+		body, ok := responses[r.URL.Query().Get("query")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("query") == "bad" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	for query, want := range map[string]float64{"vector": 100, "empty": 0, "scalar": 42} {
+		got, err := PrometheusSource{URL: srv.URL + "/", Query: query}.Value(context.Background())
+		if err != nil {
+			t.Errorf("%s query: %v", query, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("%s query = %v, want %v", query, got, want)
+		}
+	}
+
+	if _, err := (PrometheusSource{URL: srv.URL, Query: "bad"}).Value(context.Background()); err == nil {
+		t.Error("failed query read as a value")
+	}
+}
+
+func TestMetricValuePrefersSource(t *testing.T) {
+	m := Metric{
+		Name:   "m",
+		Source: MetricFunc(func(context.Context) (float64, error) { return 1, nil }),
+		Query:  func(context.Context) (float64, error) { return 2, nil },
+	}
+	if v, err := m.value(context.Background()); err != nil || v != 1 {
+		t.Errorf("value = %v, %v; want the source's 1", v, err)
+	}
+	m.Source = nil
+	if v, err := m.value(context.Background()); err != nil || v != 2 {
+		t.Errorf("value without a source = %v, %v; want the query's 2", v, err)
+	}
+	m.Query = nil
+	if _, err := m.value(context.Background()); err == nil {
+		t.Error("metric with neither source nor query read as a value")
+	}
+}
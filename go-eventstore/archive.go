@@ -0,0 +1,66 @@
+package eventstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ArchiveEventsBefore moves events recorded before cutoff from the events
+// table to events_archive, in one transaction, and returns how many it
+// moved. Only events a snapshot already covers are moved, so reconstituting
+// an aggregate from its latest snapshot never touches the archive; older
+// events stay in place until a snapshot reaches them. Each aggregate's
+// newest event also stays, since appends take the next version from it.
+//
+// Archived events keep their IDs, versions and transaction IDs; LoadEvents,
+// StreamEvents, StreamCommitted and LoadEventsByType read them back alongside
+// the events that remain.
+func (es *EventStore) ArchiveEventsBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	ctx, span := es.tracer.Start(ctx, "eventstore.archive",
+		trace.WithAttributes(attribute.String("cutoff", cutoff.UTC().Format(time.RFC3339))),
+	)
+	defer span.End()
+
+	tx, err := es.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		WITH covered AS (
+			SELECT aggregate_id, MAX(version) AS version
+			FROM snapshots
+			GROUP BY aggregate_id
+		), moved AS (
+			DELETE FROM events e
+			USING covered
+			WHERE e.aggregate_id = covered.aggregate_id
+			  AND e.created_at < $1
+			  AND e.version <= covered.version
+			  AND e.version < (SELECT MAX(version) FROM events latest WHERE latest.aggregate_id = e.aggregate_id)
+			RETURNING e.id, e.aggregate_id, e.aggregate_type, e.event_type, e.event_data, e.metadata, e.version, e.schema_version, e.created_at, e.transaction_id
+		)
+		INSERT INTO events_archive (id, aggregate_id, aggregate_type, event_type, event_data, metadata, version, schema_version, created_at, transaction_id)
+		SELECT id, aggregate_id, aggregate_type, event_type, event_data, metadata, version, schema_version, created_at, transaction_id
+		FROM moved
+	`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("archive events: %w", err)
+	}
+	archived, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("archive events: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int64("events.archived", archived))
+	return int(archived), nil
+}
@@ -0,0 +1,107 @@
+package eventstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestArchiveEventsBeforeMovesOnlySnapshottedEvents(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store := NewEventStore(db)
+	ctx := context.Background()
+
+	covered, uncovered, whole := uuid.New(), uuid.New(), uuid.New()
+	appendAggregateEvents(t, store, covered, 10)
+	appendAggregateEvents(t, store, uncovered, 5)
+	appendAggregateEvents(t, store, whole, 3)
+	saveSnapshots(t, store, covered, 6)
+	saveSnapshots(t, store, whole, 3)
+
+	if _, err := store.ArchiveEventsBefore(ctx, time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("ArchiveEventsBefore failed: %v", err)
+	}
+
+	for _, tt := range []struct {
+		id                 uuid.UUID
+		retained, archived string
+	}{
+		{covered, "[7 8 9 10]", "[1 2 3 4 5 6]"},
+		{uncovered, "[1 2 3 4 5]", "[]"},
+		{whole, "[3]", "[1 2]"}, // the newest event stays for appends
+	} {
+		if got := tableVersions(t, db, "events", tt.id); got != tt.retained {
+			t.Errorf("events retained = %s, want %s", got, tt.retained)
+		}
+		if got := tableVersions(t, db, "events_archive", tt.id); got != tt.archived {
+			t.Errorf("events archived = %s, want %s", got, tt.archived)
+		}
+	}
+
+	for _, tt := range []struct {
+		from, to int
+		want     string
+	}{
+		{0, 0, "[1 2 3 4 5 6 7 8 9 10]"},
+		{3, 8, "[3 4 5 6 7 8]"},
+		{2, 4, "[2 3 4]"},
+		{8, 0, "[8 9 10]"},
+	} {
+		events, err := store.LoadEvents(ctx, covered, tt.from, tt.to)
+		if err != nil {
+			t.Fatalf("LoadEvents failed: %v", err)
+		}
+		if got := versionsOf(events); got != tt.want {
+			t.Errorf("LoadEvents(%d, %d) = %s, want %s", tt.from, tt.to, got, tt.want)
+		}
+	}
+
+	if v, err := store.GetCurrentVersion(ctx, whole); err != nil || v != 3 {
+		t.Fatalf("GetCurrentVersion = %d, %v; want 3", v, err)
+	}
+	if err := store.AppendEvents(ctx, whole, "test_aggregate", 2, []Event{{EventType: "TestEvent", EventData: json.RawMessage(`{}`)}}); err != ErrConcurrencyConflict {
+		t.Fatalf("append at an archived version = %v, want ErrConcurrencyConflict", err)
+	}
+}
+
+func appendAggregateEvents(t *testing.T, store *EventStore, aggregateID uuid.UUID, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		eventData, _ := json.Marshal(TestEvent{Message: fmt.Sprintf("event %d", i)})
+		if err := store.AppendEvents(context.Background(), aggregateID, "test_aggregate", i, []Event{{EventType: "TestEvent", EventData: eventData}}); err != nil {
+			t.Fatalf("AppendEvents failed: %v", err)
+		}
+	}
+}
+
+func tableVersions(t *testing.T, db *sql.DB, table string, aggregateID uuid.UUID) string {
+	t.Helper()
+	rows, err := db.Query(`SELECT version FROM `+table+` WHERE aggregate_id = $1 ORDER BY version`, aggregateID)
+	if err != nil {
+		t.Fatalf("query %s: %v", table, err)
+	}
+	defer rows.Close()
+	versions := []int{}
+	for rows.Next() {
+		var v int
+		if err := rows.Scan(&v); err != nil {
+			t.Fatalf("scan version: %v", err)
+		}
+		versions = append(versions, v)
+	}
+	return fmt.Sprint(versions)
+}
+
+func versionsOf(events []Event) string {
+	versions := make([]int, len(events))
+	for i, e := range events {
+		versions[i] = e.Version
+	}
+	return fmt.Sprint(versions)
+}
@@ -0,0 +1,76 @@
+package eventstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AggregateEvents is one aggregate's share of a batch append.
+type AggregateEvents struct {
+	AggregateID     uuid.UUID
+	AggregateType   string
+	ExpectedVersion int
+	Events          []Event
+}
+
+// AppendBatch appends events to several aggregates in one transaction, at
+// the store's isolation level (serializable by default). Each aggregate's
+// expected version is checked as in AppendEvents; if any check fails, nothing
+// in the batch is written and ErrConcurrencyConflict is returned. An
+// aggregate may appear more than once, with each later entry expecting the
+// version the earlier ones leave behind.
+func (es *EventStore) AppendBatch(ctx context.Context, batch []AggregateEvents) (err error) {
+	defer func(start time.Time) {
+		for _, entry := range batch {
+			es.observeAppend(entry.AggregateType, start, err)
+		}
+	}(time.Now())
+
+	ctx, span := es.tracer.Start(ctx, "eventstore.append_batch",
+		trace.WithAttributes(attribute.Int("aggregate.count", len(batch))),
+	)
+	defer span.End()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	tx, err := es.db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: es.isolation,
+	})
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for i, entry := range batch {
+		span.AddEvent("aggregate.append", trace.WithAttributes(
+			attribute.String("aggregate.id", entry.AggregateID.String()),
+			attribute.String("aggregate.type", entry.AggregateType),
+			attribute.Int("expected.version", entry.ExpectedVersion),
+			attribute.Int("event.count", len(entry.Events)),
+		))
+		if err := es.appendInTx(ctx, tx, span, entry.AggregateID, entry.AggregateType, entry.ExpectedVersion, entry.Events); err != nil {
+			if err == ErrConcurrencyConflict || err == ErrSerializationFailure {
+				return err
+			}
+			return fmt.Errorf("append to aggregate %d of batch: %w", i, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		if isSerializationFailure(err) {
+			return ErrSerializationFailure
+		}
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	span.SetAttributes(attribute.Bool("append.success", true))
+	return nil
+}
@@ -0,0 +1,87 @@
+package eventstore
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Position is a place in the committed stream, which orders events by the
+// transaction that appended them and then by ID. Event IDs are assigned at
+// insert, so a transaction that commits late can make an event visible
+// behind ones a reader has already passed; the committed stream only ever
+// grows at its end, so a reader resuming from its position misses nothing.
+type Position struct {
+	TransactionID uint64 `json:"transaction_id"`
+	EventID       int64  `json:"event_id"`
+}
+
+// CommittedEvent is an event read from the committed stream, with its place
+// there.
+type CommittedEvent struct {
+	Event
+	Position Position
+}
+
+// StreamCommitted returns up to batchSize events after pos in the committed
+// stream. Only events appended by transactions older than every transaction
+// still running are returned, so no event can later appear before the last
+// one returned. Archived events are included, as in StreamEvents.
+func (es *EventStore) StreamCommitted(ctx context.Context, after Position, batchSize int) ([]CommittedEvent, error) {
+	ctx, span := es.tracer.Start(ctx, "eventstore.stream_committed",
+		trace.WithAttributes(
+			attribute.Int64("from.transaction_id", int64(after.TransactionID)),
+			attribute.Int64("from.id", after.EventID),
+			attribute.Int("batch.size", batchSize),
+		),
+	)
+	defer span.End()
+
+	// Archived events were committed long before they were moved, so only
+	// the events table needs holding back to the oldest running transaction.
+	rows, err := es.db.QueryContext(ctx, `
+		SELECT id, aggregate_id, aggregate_type, event_type, event_data, metadata, version, schema_version, created_at, transaction_id
+		FROM (
+			(SELECT id, aggregate_id, aggregate_type, event_type, event_data, metadata, version, schema_version, created_at, transaction_id
+			FROM events
+			WHERE (transaction_id, id) > ($1::xid8, $2)
+			  AND transaction_id < pg_snapshot_xmin(pg_current_snapshot())
+			ORDER BY transaction_id ASC, id ASC
+			LIMIT $3)
+			UNION ALL
+			(SELECT id, aggregate_id, aggregate_type, event_type, event_data, metadata, version, schema_version, created_at, transaction_id
+			FROM events_archive
+			WHERE (transaction_id, id) > ($1::xid8, $2)
+			ORDER BY transaction_id ASC, id ASC
+			LIMIT $3)
+		) page
+		ORDER BY transaction_id ASC, id ASC
+		LIMIT $3
+	`, strconv.FormatUint(after.TransactionID, 10), after.EventID, batchSize)
+	if err != nil {
+		return nil, fmt.Errorf("query committed events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []CommittedEvent
+	for rows.Next() {
+		var transactionID uint64
+		event, err := es.scanEvent(rows, &transactionID)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, CommittedEvent{
+			Event:    event,
+			Position: Position{TransactionID: transactionID, EventID: event.ID},
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate events: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("events.streamed", len(events)))
+	return events, nil
+}
@@ -0,0 +1,75 @@
+package eventstore
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// streamCommittedToEnd reads the committed stream from pos until it is
+// exhausted and returns the events it read.
+func streamCommittedToEnd(t *testing.T, store *EventStore, pos Position) []CommittedEvent {
+	t.Helper()
+	var all []CommittedEvent
+	for {
+		events, err := store.StreamCommitted(context.Background(), pos, 100)
+		if err != nil {
+			t.Fatalf("StreamCommitted failed: %v", err)
+		}
+		all = append(all, events...)
+		if len(events) < 100 {
+			return all
+		}
+		pos = events[len(events)-1].Position
+	}
+}
+
+func TestStreamCommittedWaitsForEarlierTransactions(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store := NewEventStore(db)
+	ctx := context.Background()
+
+	var start Position
+	if seen := streamCommittedToEnd(t, store, start); len(seen) > 0 {
+		start = seen[len(seen)-1].Position
+	}
+
+	// The slow transaction appends first and so takes the lower event ID,
+	// but commits after the fast one.
+	slow, fast := uuid.New(), uuid.New()
+	eventData, _ := json.Marshal(TestEvent{Message: "appended"})
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	defer tx.Rollback()
+	if err := store.AppendEventsTx(ctx, tx, slow, "test_aggregate", 0, []Event{{EventType: "TestEvent", EventData: eventData}}); err != nil {
+		t.Fatalf("AppendEventsTx failed: %v", err)
+	}
+	appendAggregateEvents(t, store, fast, 1)
+
+	for _, event := range streamCommittedToEnd(t, store, start) {
+		if event.AggregateID == fast {
+			t.Fatalf("event %d was streamed while an earlier transaction was still running", event.ID)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	var order []uuid.UUID
+	for _, event := range streamCommittedToEnd(t, store, start) {
+		if event.AggregateID == slow || event.AggregateID == fast {
+			order = append(order, event.AggregateID)
+		}
+		if event.Position.EventID != event.ID {
+			t.Errorf("event %d has position %+v", event.ID, event.Position)
+		}
+	}
+	if len(order) != 2 || order[0] != slow || order[1] != fast {
+		t.Fatalf("expected the slow transaction's event and then the fast one's, got %v (slow %s, fast %s)", order, slow, fast)
+	}
+}
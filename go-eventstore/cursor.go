@@ -0,0 +1,70 @@
+package eventstore
+
+import "context"
+
+// defaultCursorBatchSize is the batch size of a cursor created without one.
+const defaultCursorBatchSize = 100
+
+// Cursor reads the event stream in global ID order, one batch at a time,
+// remembering where the last batch ended. It is not safe for concurrent use.
+type Cursor struct {
+	store         *EventStore
+	aggregateType string
+	batchSize     int
+	position      int64
+}
+
+// CursorOption configures a Cursor.
+type CursorOption func(*Cursor)
+
+// WithAggregateType limits a cursor to the events of one aggregate type.
+func WithAggregateType(aggregateType string) CursorOption {
+	return func(c *Cursor) {
+		c.aggregateType = aggregateType
+	}
+}
+
+// NewCursor returns a cursor over the events after fromID, read batchSize at
+// a time. Start from zero to read the whole stream. A batch size below one
+// uses the default of 100.
+func (es *EventStore) NewCursor(fromID int64, batchSize int, opts ...CursorOption) *Cursor {
+	if batchSize < 1 {
+		batchSize = defaultCursorBatchSize
+	}
+	c := &Cursor{store: es, batchSize: batchSize, position: fromID}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Next returns the next batch of events and whether more were already
+// waiting behind it. Once hasMore is false the cursor has caught up with the
+// stream; calling Next again later picks up events appended since. The
+// cursor only advances past a batch that was returned without error.
+func (c *Cursor) Next(ctx context.Context) (events []Event, hasMore bool, err error) {
+	// Asking for one event beyond the batch tells whether another batch
+	// follows without a further round trip.
+	if c.aggregateType != "" {
+		events, err = c.store.LoadEventsByType(ctx, c.aggregateType, c.position, c.batchSize+1)
+	} else {
+		events, err = c.store.StreamEvents(ctx, c.position, c.batchSize+1)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(events) > c.batchSize {
+		events, hasMore = events[:c.batchSize], true
+	}
+	if len(events) > 0 {
+		c.position = events[len(events)-1].ID
+	}
+	return events, hasMore, nil
+}
+
+// Position returns the ID of the last event the cursor has returned, or the
+// ID it started from if it has returned none.
+func (c *Cursor) Position() int64 {
+	return c.position
+}
@@ -0,0 +1,97 @@
+package eventstore
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func appendTestEvents(t *testing.T, store *EventStore, aggregateType string, n int) {
+	t.Helper()
+	eventData, _ := json.Marshal(TestEvent{Message: "cursor"})
+	for i := 0; i < n; i++ {
+		if err := store.AppendEvents(context.Background(), uuid.New(), aggregateType, 0, []Event{{EventType: "TestEvent", EventData: eventData}}); err != nil {
+			t.Fatalf("AppendEvents failed: %v", err)
+		}
+	}
+}
+
+func TestCursorStreamsAcrossBatches(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store := NewEventStore(db)
+
+	aggregateType := "cursor_" + uuid.NewString()
+	appendTestEvents(t, store, aggregateType, 5)
+	appendTestEvents(t, store, "other_aggregate", 2)
+
+	cursor := store.NewCursor(0, 2, WithAggregateType(aggregateType))
+	var sizes []int
+	var seen []int64
+	for {
+		events, hasMore, err := cursor.Next(context.Background())
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		sizes = append(sizes, len(events))
+		for _, e := range events {
+			if e.AggregateType != aggregateType {
+				t.Fatalf("got an event of type %q", e.AggregateType)
+			}
+			seen = append(seen, e.ID)
+		}
+		if !hasMore {
+			break
+		}
+	}
+
+	if len(sizes) != 3 || sizes[0] != 2 || sizes[1] != 2 || sizes[2] != 1 {
+		t.Fatalf("expected batches of 2, 2 and 1, got %v", sizes)
+	}
+	for i := 1; i < len(seen); i++ {
+		if seen[i] <= seen[i-1] {
+			t.Fatalf("events out of order: %v", seen)
+		}
+	}
+	if cursor.Position() != seen[len(seen)-1] {
+		t.Fatalf("expected position %d, got %d", seen[len(seen)-1], cursor.Position())
+	}
+
+	// A caught-up cursor returns nothing until more events arrive.
+	events, hasMore, err := cursor.Next(context.Background())
+	if err != nil || len(events) != 0 || hasMore {
+		t.Fatalf("expected an empty final batch, got %d events, hasMore %v, err %v", len(events), hasMore, err)
+	}
+	appendTestEvents(t, store, aggregateType, 1)
+	events, hasMore, err = cursor.Next(context.Background())
+	if err != nil || len(events) != 1 || hasMore {
+		t.Fatalf("expected the new event, got %d events, hasMore %v, err %v", len(events), hasMore, err)
+	}
+}
+
+func TestCursorWithoutFilterReadsEveryType(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store := NewEventStore(db)
+
+	marker := "cursor_" + uuid.NewString()
+	appendTestEvents(t, store, marker, 1)
+	events, _, err := store.NewCursor(0, 1, WithAggregateType(marker)).Next(context.Background())
+	if err != nil || len(events) != 1 {
+		t.Fatalf("expected the marker event, got %d events, err %v", len(events), err)
+	}
+
+	appendTestEvents(t, store, "cursor_a", 2)
+	appendTestEvents(t, store, "cursor_b", 1)
+
+	// Exactly one batch remains, so the cursor knows it has caught up.
+	events, hasMore, err := store.NewCursor(events[0].ID, 3).Next(context.Background())
+	if err != nil {
+		t.Fatalf("Next failed: %v", err)
+	}
+	if len(events) != 3 || hasMore {
+		t.Fatalf("expected the 3 later events and no more, got %d events, hasMore %v", len(events), hasMore)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,6 +20,11 @@ var (
 	ErrConcurrencyConflict = errors.New("concurrency conflict: version mismatch")
 	ErrAggregateNotFound   = errors.New("aggregate not found")
 	ErrInvalidVersion      = errors.New("invalid version number")
+	// ErrSerializationFailure means Postgres aborted the append's
+	// transaction to keep it serializable with, or to break a deadlock with,
+	// a concurrent one. Unlike ErrConcurrencyConflict the expected version may
+	// still be right; retrying after a backoff usually succeeds.
+	ErrSerializationFailure = errors.New("serialization failure: transaction aborted by a concurrent one")
 )
 
 // Event represents a domain event with full metadata
@@ -30,25 +36,90 @@ type Event struct {
 	EventData     json.RawMessage        `json:"event_data" db:"event_data"`
 	Metadata      map[string]interface{} `json:"metadata" db:"metadata"`
 	Version       int                    `json:"version" db:"version"`
-	CreatedAt     time.Time              `json:"created_at" db:"created_at"`
+	// SchemaVersion is the version of EventData's shape. Loaded events are
+	// upcast to their type's current schema; appended events left at zero
+	// are recorded at it.
+	SchemaVersion int       `json:"schema_version" db:"schema_version"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
 }
 
 // EventStore provides ACID guarantees for event sourcing
 type EventStore struct {
-	db     *sql.DB
-	tracer trace.Tracer
+	db           *sql.DB
+	tracer       trace.Tracer
+	observer     AppendObserver
+	snapshotMode SnapshotMode
+	isolation    sql.IsolationLevel
+	now          func() time.Time
+
+	upcasters      map[upcasterKey]Upcaster
+	schemaVersions map[string]int
+
+	stmtMu sync.Mutex
+	stmts  map[string]*sql.Stmt
+}
+
+// Option configures an EventStore.
+type Option func(*EventStore)
+
+// WithIsolation sets the isolation level of the transactions AppendEvents and
+// AppendBatch open. The default, sql.LevelSerializable, also rejects appends
+// whose events were decided from data another transaction has since
+// changed, at the price of more appends failing with ErrSerializationFailure
+// under contention. Every
+// level still rejects two appends of the same version through the
+// (aggregate_id, version) key, so sql.LevelReadCommitted is enough when the
+// expected version is the only thing the events depend on.
+func WithIsolation(level sql.IsolationLevel) Option {
+	return func(es *EventStore) {
+		es.isolation = level
+	}
+}
+
+// WithClock overrides the time source that stamps appended events and saved
+// snapshots, so tests can control their created_at. Append latencies are
+// still measured against the wall clock.
+func WithClock(now func() time.Time) Option {
+	return func(es *EventStore) {
+		es.now = now
+	}
+}
+
+// AppendObserver is called after every AppendEvents and AppendEventsTx call,
+// and once per entry of an AppendBatch, with how long it took and the error it
+// returned, if any.
+type AppendObserver func(aggregateType string, duration time.Duration, err error)
+
+// ObserveAppends installs an observer for appends, typically to record
+// metrics. It must be called before the store is used.
+func (es *EventStore) ObserveAppends(fn AppendObserver) {
+	es.observer = fn
+}
+
+func (es *EventStore) observeAppend(aggregateType string, start time.Time, err error) {
+	if es.observer != nil {
+		es.observer(aggregateType, time.Since(start), err)
+	}
 }
 
 // NewEventStore creates a new event store with connection pooling
-func NewEventStore(db *sql.DB) *EventStore {
-	return &EventStore{
-		db:     db,
-		tracer: otel.Tracer("libranexus/eventstore"),
+func NewEventStore(db *sql.DB, opts ...Option) *EventStore {
+	es := &EventStore{
+		db:        db,
+		tracer:    otel.Tracer("libranexus/eventstore"),
+		isolation: sql.LevelSerializable,
+		now:       time.Now,
 	}
+	for _, opt := range opts {
+		opt(es)
+	}
+	return es
 }
 
 // AppendEvents atomically appends events with optimistic concurrency control
-func (es *EventStore) AppendEvents(ctx context.Context, aggregateID uuid.UUID, aggregateType string, expectedVersion int, events []Event) error {
+func (es *EventStore) AppendEvents(ctx context.Context, aggregateID uuid.UUID, aggregateType string, expectedVersion int, events []Event) (err error) {
+	defer func(start time.Time) { es.observeAppend(aggregateType, start, err) }(time.Now())
+
 	ctx, span := es.tracer.Start(ctx, "eventstore.append",
 		trace.WithAttributes(
 			attribute.String("aggregate.id", aggregateID.String()),
@@ -59,23 +130,62 @@ func (es *EventStore) AppendEvents(ctx context.Context, aggregateID uuid.UUID, a
 	)
 	defer span.End()
 
-	// Begin transaction with serializable isolation
 	tx, err := es.db.BeginTx(ctx, &sql.TxOptions{
-		Isolation: sql.LevelSerializable,
+		Isolation: es.isolation,
 	})
 	if err != nil {
 		return fmt.Errorf("begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
+	if err := es.appendInTx(ctx, tx, span, aggregateID, aggregateType, expectedVersion, events); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		if isSerializationFailure(err) {
+			return ErrSerializationFailure
+		}
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	span.SetAttributes(attribute.Bool("append.success", true))
+	return nil
+}
+
+// AppendEventsTx appends events inside a transaction owned by the caller, so
+// they commit or roll back together with the caller's other writes. The
+// caller picks the isolation level; a concurrent append of the same version
+// is still rejected by the (aggregate_id, version) key.
+func (es *EventStore) AppendEventsTx(ctx context.Context, tx *sql.Tx, aggregateID uuid.UUID, aggregateType string, expectedVersion int, events []Event) (err error) {
+	defer func(start time.Time) { es.observeAppend(aggregateType, start, err) }(time.Now())
+
+	ctx, span := es.tracer.Start(ctx, "eventstore.append_tx",
+		trace.WithAttributes(
+			attribute.String("aggregate.id", aggregateID.String()),
+			attribute.String("aggregate.type", aggregateType),
+			attribute.Int("expected.version", expectedVersion),
+			attribute.Int("event.count", len(events)),
+		),
+	)
+	defer span.End()
+
+	return es.appendInTx(ctx, tx, span, aggregateID, aggregateType, expectedVersion, events)
+}
+
+// appendInTx runs the version check and inserts the events on tx.
+func (es *EventStore) appendInTx(ctx context.Context, tx *sql.Tx, span trace.Span, aggregateID uuid.UUID, aggregateType string, expectedVersion int, events []Event) error {
 	// Verify current version
 	var currentVersion int
-	err = tx.QueryRowContext(ctx, `
+	err := tx.QueryRowContext(ctx, `
 		SELECT COALESCE(MAX(version), 0)
 		FROM events
 		WHERE aggregate_id = $1
 	`, aggregateID).Scan(&currentVersion)
 
+	if isSerializationFailure(err) {
+		return ErrSerializationFailure
+	}
 	if err != nil && err != sql.ErrNoRows {
 		return fmt.Errorf("query current version: %w", err)
 	}
@@ -91,8 +201,8 @@ func (es *EventStore) AppendEvents(ctx context.Context, aggregateID uuid.UUID, a
 
 	// Insert events atomically
 	stmt, err := tx.PrepareContext(ctx, `
-		INSERT INTO events (aggregate_id, aggregate_type, event_type, event_data, metadata, version, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO events (aggregate_id, aggregate_type, event_type, event_data, metadata, version, schema_version, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id
 	`)
 	if err != nil {
@@ -102,7 +212,14 @@ func (es *EventStore) AppendEvents(ctx context.Context, aggregateID uuid.UUID, a
 
 	for i, event := range events {
 		version := expectedVersion + i + 1
-		metadataJSON, _ := json.Marshal(event.Metadata)
+		metadataJSON, err := json.Marshal(withContextMetadata(ctx, event.Metadata))
+		if err != nil {
+			return fmt.Errorf("marshal metadata for event %d: %w", i, err)
+		}
+		schemaVersion := event.SchemaVersion
+		if schemaVersion == 0 {
+			schemaVersion = es.schemaVersion(event.EventType)
+		}
 
 		var eventID int64
 		err = stmt.QueryRowContext(
@@ -113,14 +230,18 @@ func (es *EventStore) AppendEvents(ctx context.Context, aggregateID uuid.UUID, a
 			event.EventData,
 			metadataJSON,
 			version,
-			time.Now().UTC(),
+			schemaVersion,
+			es.now().UTC(),
 		).Scan(&eventID)
 
 		if err != nil {
-			// Check for unique constraint violation (race condition)
-			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			// A unique violation means a concurrent append took the version.
+			if pqCode(err) == "23505" {
 				return ErrConcurrencyConflict
 			}
+			if isSerializationFailure(err) {
+				return ErrSerializationFailure
+			}
 			return fmt.Errorf("insert event %d: %w", i, err)
 		}
 
@@ -130,84 +251,86 @@ func (es *EventStore) AppendEvents(ctx context.Context, aggregateID uuid.UUID, a
 			attribute.String("event.type", event.EventType),
 		))
 	}
+	return nil
+}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit transaction: %w", err)
+// LoadEventsOptions tunes what LoadEventsWithOptions reads.
+type LoadEventsOptions struct {
+	// IncludeMetadata reads and decodes each event's Metadata. Leave it
+	// unset when only the events' data is needed, as when reconstituting an
+	// aggregate.
+	IncludeMetadata bool
+}
+
+// LoadEvents retrieves all events for an aggregate with optional version range,
+// metadata included. Events moved to the archive are read back alongside
+// those that remain.
+func (es *EventStore) LoadEvents(ctx context.Context, aggregateID uuid.UUID, fromVersion, toVersion int) ([]Event, error) {
+	return es.LoadEventsWithOptions(ctx, aggregateID, fromVersion, toVersion, LoadEventsOptions{IncludeMetadata: true})
+}
+
+// LoadEventsWithOptions is LoadEvents reading only what opts asks for.
+// Events loaded without metadata have a nil Metadata.
+func (es *EventStore) LoadEventsWithOptions(ctx context.Context, aggregateID uuid.UUID, fromVersion, toVersion int, opts LoadEventsOptions) ([]Event, error) {
+	var events []Event
+	err := es.LoadEventsFuncWithOptions(ctx, aggregateID, fromVersion, toVersion, opts, func(event Event) error {
+		events = append(events, event)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return events, nil
+}
 
-	span.SetAttributes(attribute.Bool("append.success", true))
-	return nil
+// LoadEventsFunc is LoadEvents calling fn with each event in turn, in version
+// order, instead of collecting them, so an aggregate can be folded without
+// holding all of its events at once. It stops at the first error fn returns
+// and returns that error as it is.
+func (es *EventStore) LoadEventsFunc(ctx context.Context, aggregateID uuid.UUID, fromVersion, toVersion int, fn func(Event) error) error {
+	return es.LoadEventsFuncWithOptions(ctx, aggregateID, fromVersion, toVersion, LoadEventsOptions{IncludeMetadata: true}, fn)
 }
 
-// LoadEvents retrieves all events for an aggregate with optional version range
-func (es *EventStore) LoadEvents(ctx context.Context, aggregateID uuid.UUID, fromVersion, toVersion int) ([]Event, error) {
+// LoadEventsFuncWithOptions is LoadEventsFunc reading only what opts asks
+// for.
+func (es *EventStore) LoadEventsFuncWithOptions(ctx context.Context, aggregateID uuid.UUID, fromVersion, toVersion int, opts LoadEventsOptions, fn func(Event) error) error {
 	ctx, span := es.tracer.Start(ctx, "eventstore.load",
 		trace.WithAttributes(
 			attribute.String("aggregate.id", aggregateID.String()),
 			attribute.Int("from.version", fromVersion),
 			attribute.Int("to.version", toVersion),
+			attribute.Bool("include.metadata", opts.IncludeMetadata),
 		),
 	)
 	defer span.End()
 
-	query := `
-		SELECT id, aggregate_id, aggregate_type, event_type, event_data, metadata, version, created_at
-		FROM events
-		WHERE aggregate_id = $1
-		AND version >= $2
-	`
-
-	args := []interface{}{aggregateID, fromVersion}
-
-	if toVersion > 0 {
-		query += " AND version <= $3"
-		args = append(args, toVersion)
-	}
-
-	query += " ORDER BY version ASC"
-
-	rows, err := es.db.QueryContext(ctx, query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("query events: %w", err)
-	}
-	defer rows.Close()
-
-	var events []Event
-	for rows.Next() {
-		var event Event
-		var metadataJSON []byte
-
-		err := rows.Scan(
-			&event.ID,
-			&event.AggregateID,
-			&event.AggregateType,
-			&event.EventType,
-			&event.EventData,
-			&metadataJSON,
-			&event.Version,
-			&event.CreatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("scan event: %w", err)
-		}
+	loaded := 0
+	err := es.eachInRange(ctx, loadQuery{metadata: opts.IncludeMetadata}, aggregateID, fromVersion, toVersion, func(event Event) error {
+		loaded++
+		return fn(event)
+	})
 
-		if len(metadataJSON) > 0 {
-			json.Unmarshal(metadataJSON, &event.Metadata)
-		}
+	span.SetAttributes(attribute.Int("events.loaded", loaded))
+	return err
+}
 
-		events = append(events, event)
-	}
+// GetCurrentVersion returns the latest version for an aggregate
+func (es *EventStore) GetCurrentVersion(ctx context.Context, aggregateID uuid.UUID) (int, error) {
+	return es.currentVersion(ctx, es.db, aggregateID)
+}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterate events: %w", err)
-	}
+// GetCurrentVersionTx is GetCurrentVersion read through the caller's
+// transaction, so the version can be read under locks the transaction holds
+// and appended against with AppendEventsTx.
+func (es *EventStore) GetCurrentVersionTx(ctx context.Context, tx *sql.Tx, aggregateID uuid.UUID) (int, error) {
+	return es.currentVersion(ctx, tx, aggregateID)
+}
 
-	span.SetAttributes(attribute.Int("events.loaded", len(events)))
-	return events, nil
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 }
 
-// GetCurrentVersion returns the latest version for an aggregate
-func (es *EventStore) GetCurrentVersion(ctx context.Context, aggregateID uuid.UUID) (int, error) {
+func (es *EventStore) currentVersion(ctx context.Context, q queryRower, aggregateID uuid.UUID) (int, error) {
 	ctx, span := es.tracer.Start(ctx, "eventstore.get_version",
 		trace.WithAttributes(
 			attribute.String("aggregate.id", aggregateID.String()),
@@ -216,7 +339,7 @@ func (es *EventStore) GetCurrentVersion(ctx context.Context, aggregateID uuid.UU
 	defer span.End()
 
 	var version int
-	err := es.db.QueryRowContext(ctx, `
+	err := q.QueryRowContext(ctx, `
 		SELECT COALESCE(MAX(version), 0)
 		FROM events
 		WHERE aggregate_id = $1
@@ -230,7 +353,9 @@ func (es *EventStore) GetCurrentVersion(ctx context.Context, aggregateID uuid.UU
 	return version, nil
 }
 
-// StreamEvents provides a cursor-based event stream for projections
+// StreamEvents provides a cursor-based event stream for projections,
+// including archived events so a projection can still be rebuilt from the
+// start.
 func (es *EventStore) StreamEvents(ctx context.Context, fromID int64, batchSize int) ([]Event, error) {
 	ctx, span := es.tracer.Start(ctx, "eventstore.stream",
 		trace.WithAttributes(
@@ -240,10 +365,23 @@ func (es *EventStore) StreamEvents(ctx context.Context, fromID int64, batchSize
 	)
 	defer span.End()
 
+	// Each table gives up at most a page in ID order, so the merge costs the
+	// same however many events either holds.
 	rows, err := es.db.QueryContext(ctx, `
-		SELECT id, aggregate_id, aggregate_type, event_type, event_data, metadata, version, created_at
-		FROM events
-		WHERE id > $1
+		SELECT id, aggregate_id, aggregate_type, event_type, event_data, metadata, version, schema_version, created_at
+		FROM (
+			(SELECT id, aggregate_id, aggregate_type, event_type, event_data, metadata, version, schema_version, created_at
+			FROM events
+			WHERE id > $1
+			ORDER BY id ASC
+			LIMIT $2)
+			UNION ALL
+			(SELECT id, aggregate_id, aggregate_type, event_type, event_data, metadata, version, schema_version, created_at
+			FROM events_archive
+			WHERE id > $1
+			ORDER BY id ASC
+			LIMIT $2)
+		) page
 		ORDER BY id ASC
 		LIMIT $2
 	`, fromID, batchSize)
@@ -253,36 +391,114 @@ func (es *EventStore) StreamEvents(ctx context.Context, fromID int64, batchSize
 	}
 	defer rows.Close()
 
+	events, err := es.scanEvents(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("events.streamed", len(events)))
+	return events, nil
+}
+
+// LoadEventsByType pages through the events of every aggregate of one type in
+// global ID order, for rebuilding a single type's read model. Pass the ID of
+// the last event returned to fetch the next page; an empty result means the
+// stream is exhausted.
+func (es *EventStore) LoadEventsByType(ctx context.Context, aggregateType string, fromID int64, batchSize int) ([]Event, error) {
+	ctx, span := es.tracer.Start(ctx, "eventstore.load_by_type",
+		trace.WithAttributes(
+			attribute.String("aggregate.type", aggregateType),
+			attribute.Int64("from.id", fromID),
+			attribute.Int("batch.size", batchSize),
+		),
+	)
+	defer span.End()
+
+	// Served by the (aggregate_type, id) indexes, so a page costs the same
+	// however many events of other types either table holds. Archived events
+	// are merged in, as in StreamEvents.
+	rows, err := es.db.QueryContext(ctx, `
+		SELECT id, aggregate_id, aggregate_type, event_type, event_data, metadata, version, schema_version, created_at
+		FROM (
+			(SELECT id, aggregate_id, aggregate_type, event_type, event_data, metadata, version, schema_version, created_at
+			FROM events
+			WHERE aggregate_type = $1 AND id > $2
+			ORDER BY id ASC
+			LIMIT $3)
+			UNION ALL
+			(SELECT id, aggregate_id, aggregate_type, event_type, event_data, metadata, version, schema_version, created_at
+			FROM events_archive
+			WHERE aggregate_type = $1 AND id > $2
+			ORDER BY id ASC
+			LIMIT $3)
+		) page
+		ORDER BY id ASC
+		LIMIT $3
+	`, aggregateType, fromID, batchSize)
+	if err != nil {
+		return nil, fmt.Errorf("query events by type: %w", err)
+	}
+	defer rows.Close()
+
+	events, err := es.scanEvents(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("events.loaded", len(events)))
+	return events, nil
+}
+
+// scanEvents reads events selected in the column order StreamEvents uses,
+// upcasting each to its type's current schema.
+func (es *EventStore) scanEvents(rows *sql.Rows) ([]Event, error) {
 	var events []Event
 	for rows.Next() {
-		var event Event
-		var metadataJSON []byte
-
-		err := rows.Scan(
-			&event.ID,
-			&event.AggregateID,
-			&event.AggregateType,
-			&event.EventType,
-			&event.EventData,
-			&metadataJSON,
-			&event.Version,
-			&event.CreatedAt,
-		)
+		event, err := es.scanEvent(rows)
 		if err != nil {
-			return nil, fmt.Errorf("scan event: %w", err)
-		}
-
-		if len(metadataJSON) > 0 {
-			json.Unmarshal(metadataJSON, &event.Metadata)
+			return nil, err
 		}
-
 		events = append(events, event)
 	}
-
-	span.SetAttributes(attribute.Int("events.streamed", len(events)))
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate events: %w", err)
+	}
 	return events, nil
 }
 
+// scanEvent reads the event at the current row, as scanEvents does. Columns
+// selected after the event's are scanned into extra.
+func (es *EventStore) scanEvent(rows *sql.Rows, extra ...interface{}) (Event, error) {
+	var event Event
+	var metadataJSON []byte
+
+	dest := append([]interface{}{
+		&event.ID,
+		&event.AggregateID,
+		&event.AggregateType,
+		&event.EventType,
+		&event.EventData,
+		&metadataJSON,
+		&event.Version,
+		&event.SchemaVersion,
+		&event.CreatedAt,
+	}, extra...)
+	err := rows.Scan(dest...)
+	if err != nil {
+		return Event{}, fmt.Errorf("scan event: %w", err)
+	}
+
+	if len(metadataJSON) > 0 {
+		if err := json.Unmarshal(metadataJSON, &event.Metadata); err != nil {
+			return Event{}, fmt.Errorf("decode metadata of event %d: %w", event.ID, err)
+		}
+	}
+	if err := es.upcast(&event); err != nil {
+		return Event{}, err
+	}
+	return event, nil
+}
+
 // Snapshot support for performance optimization
 type Snapshot struct {
 	AggregateID   uuid.UUID       `json:"aggregate_id"`
@@ -292,28 +508,100 @@ type Snapshot struct {
 	CreatedAt     time.Time       `json:"created_at"`
 }
 
-// SaveSnapshot stores aggregate state for faster reconstitution
+// SnapshotMode decides how many snapshots SaveSnapshot keeps per aggregate.
+type SnapshotMode int
+
+const (
+	// SnapshotLatest keeps only each aggregate's newest snapshot.
+	SnapshotLatest SnapshotMode = iota
+	// SnapshotHistory keeps every snapshot until PruneSnapshots removes it,
+	// so past states stay available for auditing.
+	SnapshotHistory
+)
+
+// SetSnapshotMode chooses whether older snapshots are replaced or kept. The
+// default is SnapshotLatest. It must be called before the store is used.
+func (es *EventStore) SetSnapshotMode(mode SnapshotMode) {
+	es.snapshotMode = mode
+}
+
+// SaveSnapshot stores aggregate state for faster reconstitution. A snapshot
+// older than one already stored is ignored, as is a second snapshot at the
+// same version, which replays the same events and so holds the same state.
+// In SnapshotLatest mode the snapshots it supersedes are deleted in the same
+// transaction.
 func (es *EventStore) SaveSnapshot(ctx context.Context, snapshot Snapshot) error {
 	ctx, span := es.tracer.Start(ctx, "eventstore.save_snapshot")
 	defer span.End()
 
-	_, err := es.db.ExecContext(ctx, `
-		INSERT INTO snapshots (aggregate_id, aggregate_type, version, state, created_at)
-		VALUES ($1, $2, $3, $4, $5)
-		ON CONFLICT (aggregate_id) DO UPDATE
-		SET version = EXCLUDED.version,
-		    state = EXCLUDED.state,
-		    created_at = EXCLUDED.created_at
-		WHERE snapshots.version < EXCLUDED.version
-	`, snapshot.AggregateID, snapshot.AggregateType, snapshot.Version, snapshot.State, time.Now().UTC())
+	tx, err := es.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO snapshots (aggregate_id, aggregate_type, version, state, created_at)
+		SELECT $1, $2, $3, $4, $5
+		WHERE NOT EXISTS (
+			SELECT 1 FROM snapshots WHERE aggregate_id = $1 AND version >= $3
+		)
+		ON CONFLICT (aggregate_id, version) DO NOTHING
+	`, snapshot.AggregateID, snapshot.AggregateType, snapshot.Version, snapshot.State, es.now().UTC())
 	if err != nil {
 		return fmt.Errorf("save snapshot: %w", err)
 	}
 
+	if es.snapshotMode == SnapshotLatest {
+		_, err = tx.ExecContext(ctx, `
+			DELETE FROM snapshots WHERE aggregate_id = $1 AND version < $2
+		`, snapshot.AggregateID, snapshot.Version)
+		if err != nil {
+			return fmt.Errorf("replace snapshot: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
 	return nil
 }
 
+// PruneSnapshots deletes all but each aggregate's keepPerAggregate newest
+// snapshots and returns how many were deleted.
+func (es *EventStore) PruneSnapshots(ctx context.Context, keepPerAggregate int) (int, error) {
+	ctx, span := es.tracer.Start(ctx, "eventstore.prune_snapshots",
+		trace.WithAttributes(attribute.Int("keep.per_aggregate", keepPerAggregate)),
+	)
+	defer span.End()
+
+	if keepPerAggregate < 1 {
+		return 0, fmt.Errorf("prune snapshots: must keep at least one snapshot per aggregate, got %d", keepPerAggregate)
+	}
+
+	res, err := es.db.ExecContext(ctx, `
+		DELETE FROM snapshots s
+		USING (
+			SELECT aggregate_id, version,
+			       ROW_NUMBER() OVER (PARTITION BY aggregate_id ORDER BY version DESC) AS rank
+			FROM snapshots
+		) ranked
+		WHERE s.aggregate_id = ranked.aggregate_id
+		  AND s.version = ranked.version
+		  AND ranked.rank > $1
+	`, keepPerAggregate)
+	if err != nil {
+		return 0, fmt.Errorf("prune snapshots: %w", err)
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("prune snapshots: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int64("snapshots.deleted", deleted))
+	return int(deleted), nil
+}
+
 // LoadSnapshot retrieves the latest snapshot for fast replay
 func (es *EventStore) LoadSnapshot(ctx context.Context, aggregateID uuid.UUID) (*Snapshot, error) {
 	ctx, span := es.tracer.Start(ctx, "eventstore.load_snapshot")
@@ -324,6 +612,8 @@ func (es *EventStore) LoadSnapshot(ctx context.Context, aggregateID uuid.UUID) (
 		SELECT aggregate_id, aggregate_type, version, state, created_at
 		FROM snapshots
 		WHERE aggregate_id = $1
+		ORDER BY version DESC
+		LIMIT 1
 	`, aggregateID).Scan(
 		&snapshot.AggregateID,
 		&snapshot.AggregateType,
@@ -340,4 +630,33 @@ func (es *EventStore) LoadSnapshot(ctx context.Context, aggregateID uuid.UUID) (
 	}
 
 	return &snapshot, nil
-}
\ No newline at end of file
+}
+
+// SnapshotDue reports whether an append taking an aggregate from version
+// fromVersion to toVersion crossed a multiple of every, and so should be
+// followed by a snapshot. It is always false when every is not positive.
+func SnapshotDue(fromVersion, toVersion, every int) bool {
+	if every <= 0 {
+		return false
+	}
+	return toVersion/every > fromVersion/every
+}
+
+// isSerializationFailure reports whether Postgres aborted the transaction
+// because it could not be serialized with a concurrent one (SQLSTATE 40001)
+// or to break a deadlock (40P01).
+func isSerializationFailure(err error) bool {
+	switch pqCode(err) {
+	case "40001", "40P01":
+		return true
+	}
+	return false
+}
+
+func pqCode(err error) pq.ErrorCode {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code
+	}
+	return ""
+}
@@ -4,9 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"runtime"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	_ "github.com/lib/pq"
@@ -61,9 +65,36 @@ func setupTestDB(t testing.TB) *sql.DB {
 			event_data JSONB NOT NULL,
 			metadata JSONB,
 			version INT NOT NULL,
+			schema_version INT NOT NULL DEFAULT 1,
 			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
 			UNIQUE (aggregate_id, version)
 		);
+		ALTER TABLE events ADD COLUMN IF NOT EXISTS schema_version INT NOT NULL DEFAULT 1;
+		ALTER TABLE events ADD COLUMN IF NOT EXISTS transaction_id XID8 NOT NULL DEFAULT pg_current_xact_id();
+		CREATE INDEX IF NOT EXISTS idx_events_aggregate_type_id ON events (aggregate_type, id);
+		CREATE INDEX IF NOT EXISTS idx_events_transaction_id ON events (transaction_id, id);
+		CREATE TABLE IF NOT EXISTS events_archive (
+			id BIGINT NOT NULL,
+			aggregate_id UUID NOT NULL,
+			aggregate_type TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			event_data JSONB NOT NULL,
+			metadata JSONB,
+			version INT NOT NULL,
+			schema_version INT NOT NULL DEFAULT 1,
+			created_at TIMESTAMPTZ NOT NULL,
+			archived_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (aggregate_id, version)
+		);
+		ALTER TABLE events_archive ADD COLUMN IF NOT EXISTS transaction_id XID8 NOT NULL DEFAULT '0';
+		CREATE TABLE IF NOT EXISTS snapshots (
+			aggregate_id UUID NOT NULL,
+			aggregate_type TEXT NOT NULL,
+			version INT NOT NULL,
+			state JSONB NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (aggregate_id, version)
+		);
 	`)
 	if err != nil {
 		t.Fatalf("failed to create schema: %v", err)
@@ -108,8 +139,42 @@ func BenchmarkLoadEvents(b *testing.B) {
 	db := setupTestDB(b)
 	defer db.Close()
 	store := NewEventStore(db)
+	aggregateID := seedLoadBenchmark(b, store)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := store.LoadEvents(context.Background(), aggregateID, 0, 0)
+		if err != nil {
+			b.Fatalf("LoadEvents failed: %v", err)
+		}
+	}
+}
+
+// BenchmarkLoadEventsWithoutMetadata loads the same events as
+// BenchmarkLoadEvents, skipping their metadata as reconstitution does.
+func BenchmarkLoadEventsWithoutMetadata(b *testing.B) {
+	db := setupTestDB(b)
+	defer db.Close()
+	store := NewEventStore(db)
+	aggregateID := seedLoadBenchmark(b, store)
 
-	// Setup: create an aggregate with 10 events
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := store.LoadEventsWithOptions(context.Background(), aggregateID, 0, 0, LoadEventsOptions{})
+		if err != nil {
+			b.Fatalf("LoadEventsWithOptions failed: %v", err)
+		}
+	}
+}
+
+// seedLoadBenchmark creates an aggregate with 10 events, each carrying the
+// metadata a traced request would give it.
+func seedLoadBenchmark(b *testing.B, store *EventStore) uuid.UUID {
+	b.Helper()
 	aggregateID := uuid.New()
 	aggregateType := "test_aggregate"
 	for i := 0; i < 10; i++ {
@@ -118,6 +183,11 @@ func BenchmarkLoadEvents(b *testing.B) {
 			{
 				EventType: "TestEvent",
 				EventData: eventData,
+				Metadata: map[string]interface{}{
+					MetadataCorrelationID: uuid.NewString(),
+					MetadataCausationID:   uuid.NewString(),
+					MetadataActorID:       uuid.NewString(),
+				},
 			},
 		}
 		err := store.AppendEvents(context.Background(), aggregateID, aggregateType, i, events)
@@ -125,14 +195,465 @@ func BenchmarkLoadEvents(b *testing.B) {
 			b.Fatalf("failed to setup events for benchmark: %v", err)
 		}
 	}
+	return aggregateID
+}
 
-	b.ReportAllocs()
-	b.ResetTimer()
+// BenchmarkLoad10kEvents compares the peak heap of loading a 10,000-event
+// aggregate into a slice against folding it through LoadEventsFunc.
+func BenchmarkLoad10kEvents(b *testing.B) {
+	db := setupTestDB(b)
+	defer db.Close()
+	store := NewEventStore(db)
 
-	for i := 0; i < b.N; i++ {
-		_, err := store.LoadEvents(context.Background(), aggregateID, 0, 0)
+	aggregateID := uuid.New()
+	const total, batch = 10000, 500
+	for version := 0; version < total; version += batch {
+		events := make([]Event, batch)
+		for i := range events {
+			eventData, _ := json.Marshal(TestEvent{Message: fmt.Sprintf("event %d", version+i)})
+			events[i] = Event{EventType: "TestEvent", EventData: eventData}
+		}
+		if err := store.AppendEvents(context.Background(), aggregateID, "test_aggregate", version, events); err != nil {
+			b.Fatalf("failed to setup events for benchmark: %v", err)
+		}
+	}
+
+	loads := map[string]func(sample func()) error{
+		"slice": func(sample func()) error {
+			events, err := store.LoadEvents(context.Background(), aggregateID, 0, 0)
+			sample()
+			if err == nil && len(events) != total {
+				err = fmt.Errorf("loaded %d events, want %d", len(events), total)
+			}
+			return err
+		},
+		"func": func(sample func()) error {
+			n := 0
+			return store.LoadEventsFunc(context.Background(), aggregateID, 0, 0, func(Event) error {
+				if n++; n%batch == 0 {
+					sample()
+				}
+				return nil
+			})
+		},
+	}
+	for _, name := range []string{"slice", "func"} {
+		load := loads[name]
+		b.Run(name, func(b *testing.B) {
+			var stats runtime.MemStats
+			var peak uint64
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				runtime.GC()
+				runtime.ReadMemStats(&stats)
+				base := stats.HeapAlloc
+				b.StartTimer()
+
+				err := load(func() {
+					b.StopTimer()
+					runtime.ReadMemStats(&stats)
+					if stats.HeapAlloc > base && stats.HeapAlloc-base > peak {
+						peak = stats.HeapAlloc - base
+					}
+					b.StartTimer()
+				})
+				if err != nil {
+					b.Fatalf("load failed: %v", err)
+				}
+			}
+			b.ReportMetric(float64(peak), "peak-heap-B")
+		})
+	}
+}
+
+func TestAppendEventsWithRetryResolvesConflicts(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store := NewEventStore(db)
+
+	aggregateID := uuid.New()
+	const writers = 5
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- store.AppendEventsWithRetry(context.Background(), aggregateID, "test_aggregate", 20, func(currentVersion int) ([]Event, error) {
+				eventData, _ := json.Marshal(TestEvent{Message: fmt.Sprintf("writer %d", i)})
+				return []Event{{EventType: "TestEvent", EventData: eventData}}, nil
+			})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
 		if err != nil {
-			b.Fatalf("LoadEvents failed: %v", err)
+			t.Fatalf("AppendEventsWithRetry failed: %v", err)
+		}
+	}
+
+	version, err := store.GetCurrentVersion(context.Background(), aggregateID)
+	if err != nil {
+		t.Fatalf("GetCurrentVersion failed: %v", err)
+	}
+	if version != writers {
+		t.Fatalf("expected version %d, got %d", writers, version)
+	}
+}
+
+func TestAppendEventsWithRetryGivesUp(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store := NewEventStore(db)
+
+	aggregateID := uuid.New()
+	attempts := 0
+	err := store.AppendEventsWithRetry(context.Background(), aggregateID, "test_aggregate", 2, func(currentVersion int) ([]Event, error) {
+		attempts++
+		// Sneak in a competing write so every attempt conflicts.
+		eventData, _ := json.Marshal(TestEvent{Message: "competitor"})
+		if err := store.AppendEvents(context.Background(), aggregateID, "test_aggregate", currentVersion, []Event{{EventType: "TestEvent", EventData: eventData}}); err != nil {
+			return nil, err
+		}
+		return []Event{{EventType: "TestEvent", EventData: eventData}}, nil
+	})
+
+	if !errors.Is(err, ErrConcurrencyConflict) {
+		t.Fatalf("expected ErrConcurrencyConflict, got %v", err)
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestMetadataSurvivesRoundTrip(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store := NewEventStore(db)
+
+	ctx := WithCorrelationID(context.Background(), "req-1")
+	ctx = WithCausationID(ctx, "checkout-1")
+	ctx = WithActorID(ctx, "member-1")
+
+	aggregateID := uuid.New()
+	eventData, _ := json.Marshal(TestEvent{Message: "traced"})
+	events := []Event{{EventType: "TestEvent", EventData: eventData, Metadata: map[string]interface{}{"source": "test"}}}
+	if err := store.AppendEvents(ctx, aggregateID, "test_aggregate", 0, events); err != nil {
+		t.Fatalf("AppendEvents failed: %v", err)
+	}
+
+	loaded, err := store.LoadEvents(context.Background(), aggregateID, 0, 0)
+	if err != nil {
+		t.Fatalf("LoadEvents failed: %v", err)
+	}
+	if len(loaded) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(loaded))
+	}
+	e := loaded[0]
+	if e.CorrelationID() != "req-1" || e.CausationID() != "checkout-1" || e.ActorID() != "member-1" {
+		t.Fatalf("unexpected metadata: %v", e.Metadata)
+	}
+	if e.Metadata["source"] != "test" {
+		t.Fatalf("event's own metadata lost: %v", e.Metadata)
+	}
+}
+
+func TestWithClockStampsAppendedEvents(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := NewEventStore(db, WithClock(func() time.Time { return now }))
+
+	aggregateID := uuid.New()
+	eventData, _ := json.Marshal(TestEvent{Message: "stamped"})
+	if err := store.AppendEvents(context.Background(), aggregateID, "test_aggregate", 0, []Event{{EventType: "TestEvent", EventData: eventData}}); err != nil {
+		t.Fatalf("AppendEvents failed: %v", err)
+	}
+
+	loaded, err := store.LoadEvents(context.Background(), aggregateID, 0, 0)
+	if err != nil {
+		t.Fatalf("LoadEvents failed: %v", err)
+	}
+	if len(loaded) != 1 || !loaded[0].CreatedAt.Equal(now) {
+		t.Fatalf("expected one event created at %v, got %v", now, loaded)
+	}
+}
+
+func TestAppendBatchCommitsEveryAggregate(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store := NewEventStore(db)
+
+	itemID, checkoutID := uuid.New(), uuid.New()
+	eventData, _ := json.Marshal(TestEvent{Message: "batched"})
+	if err := store.AppendEvents(context.Background(), itemID, "item", 0, []Event{{EventType: "TestEvent", EventData: eventData}}); err != nil {
+		t.Fatalf("AppendEvents failed: %v", err)
+	}
+
+	err := store.AppendBatch(context.Background(), []AggregateEvents{
+		{AggregateID: itemID, AggregateType: "item", ExpectedVersion: 1, Events: []Event{{EventType: "TestEvent", EventData: eventData}}},
+		{AggregateID: checkoutID, AggregateType: "checkout", ExpectedVersion: 0, Events: []Event{{EventType: "TestEvent", EventData: eventData}}},
+	})
+	if err != nil {
+		t.Fatalf("AppendBatch failed: %v", err)
+	}
+
+	for id, want := range map[uuid.UUID]int{itemID: 2, checkoutID: 1} {
+		version, err := store.GetCurrentVersion(context.Background(), id)
+		if err != nil {
+			t.Fatalf("GetCurrentVersion failed: %v", err)
+		}
+		if version != want {
+			t.Fatalf("expected version %d, got %d", want, version)
+		}
+	}
+}
+
+func TestAppendBatchRollsBackOnConflict(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store := NewEventStore(db)
+
+	itemID, checkoutID := uuid.New(), uuid.New()
+	eventData, _ := json.Marshal(TestEvent{Message: "batched"})
+
+	// The item is at version 0, so the second entry's check fails and the
+	// checkout's events must not be written either.
+	err := store.AppendBatch(context.Background(), []AggregateEvents{
+		{AggregateID: checkoutID, AggregateType: "checkout", ExpectedVersion: 0, Events: []Event{{EventType: "TestEvent", EventData: eventData}}},
+		{AggregateID: itemID, AggregateType: "item", ExpectedVersion: 3, Events: []Event{{EventType: "TestEvent", EventData: eventData}}},
+	})
+	if !errors.Is(err, ErrConcurrencyConflict) {
+		t.Fatalf("expected ErrConcurrencyConflict, got %v", err)
+	}
+
+	version, err := store.GetCurrentVersion(context.Background(), checkoutID)
+	if err != nil {
+		t.Fatalf("GetCurrentVersion failed: %v", err)
+	}
+	if version != 0 {
+		t.Fatalf("expected the checkout to be untouched, got version %d", version)
+	}
+}
+
+func TestLoadEventsByTypePagesOneType(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store := NewEventStore(db)
+
+	aggregateType := "typed_" + uuid.NewString()
+	eventData, _ := json.Marshal(TestEvent{Message: "typed"})
+	for i := 0; i < 3; i++ {
+		if err := store.AppendEvents(context.Background(), uuid.New(), aggregateType, 0, []Event{{EventType: "TestEvent", EventData: eventData}}); err != nil {
+			t.Fatalf("AppendEvents failed: %v", err)
+		}
+		if err := store.AppendEvents(context.Background(), uuid.New(), "other_aggregate", 0, []Event{{EventType: "TestEvent", EventData: eventData}}); err != nil {
+			t.Fatalf("AppendEvents failed: %v", err)
+		}
+	}
+
+	first, err := store.LoadEventsByType(context.Background(), aggregateType, 0, 2)
+	if err != nil {
+		t.Fatalf("LoadEventsByType failed: %v", err)
+	}
+	if len(first) != 2 {
+		t.Fatalf("expected a page of 2 events, got %d", len(first))
+	}
+	rest, err := store.LoadEventsByType(context.Background(), aggregateType, first[1].ID, 2)
+	if err != nil {
+		t.Fatalf("LoadEventsByType failed: %v", err)
+	}
+	if len(rest) != 1 {
+		t.Fatalf("expected 1 remaining event, got %d", len(rest))
+	}
+	for _, e := range append(first, rest...) {
+		if e.AggregateType != aggregateType {
+			t.Fatalf("got an event of type %q", e.AggregateType)
+		}
+	}
+}
+
+func saveSnapshots(t *testing.T, store *EventStore, aggregateID uuid.UUID, versions ...int) {
+	t.Helper()
+	for _, v := range versions {
+		state, _ := json.Marshal(map[string]int{"version": v})
+		err := store.SaveSnapshot(context.Background(), Snapshot{AggregateID: aggregateID, AggregateType: "test_aggregate", Version: v, State: state})
+		if err != nil {
+			t.Fatalf("SaveSnapshot failed: %v", err)
+		}
+	}
+}
+
+func countSnapshots(t *testing.T, db *sql.DB, aggregateID uuid.UUID) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM snapshots WHERE aggregate_id = $1`, aggregateID).Scan(&n); err != nil {
+		t.Fatalf("count snapshots: %v", err)
+	}
+	return n
+}
+
+func TestSaveSnapshotKeepsOnlyLatestByDefault(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store := NewEventStore(db)
+
+	aggregateID := uuid.New()
+	saveSnapshots(t, store, aggregateID, 10, 20, 15)
+
+	if n := countSnapshots(t, db, aggregateID); n != 1 {
+		t.Fatalf("expected 1 snapshot, got %d", n)
+	}
+	snapshot, err := store.LoadSnapshot(context.Background(), aggregateID)
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+	if snapshot.Version != 20 {
+		t.Fatalf("expected version 20, got %d", snapshot.Version)
+	}
+}
+
+func TestSaveSnapshotIgnoresSameVersionAndReplacesWithNewer(t *testing.T) {
+	for _, mode := range []SnapshotMode{SnapshotLatest, SnapshotHistory} {
+		db := setupTestDB(t)
+		store := NewEventStore(db)
+		store.SetSnapshotMode(mode)
+
+		aggregateID := uuid.New()
+		saveSnapshots(t, store, aggregateID, 10)
+		// Saving the same version again, as a retried write does, is ignored
+		// without error and leaves the first snapshot's state in place.
+		retried, _ := json.Marshal(map[string]int{"version": 10, "retry": 1})
+		if err := store.SaveSnapshot(context.Background(), Snapshot{AggregateID: aggregateID, AggregateType: "test_aggregate", Version: 10, State: retried}); err != nil {
+			t.Fatalf("mode %d: saving version 10 again failed: %v", mode, err)
+		}
+		if n := countSnapshots(t, db, aggregateID); n != 1 {
+			t.Fatalf("mode %d: expected 1 snapshot after saving version 10 twice, got %d", mode, n)
+		}
+		if state := loadSnapshotState(t, store, aggregateID); state["retry"] != 0 {
+			t.Fatalf("mode %d: expected the second version 10 snapshot to be ignored, got state %v", mode, state)
+		}
+
+		saveSnapshots(t, store, aggregateID, 20)
+		if state := loadSnapshotState(t, store, aggregateID); state["version"] != 20 {
+			t.Fatalf("mode %d: expected the version 20 snapshot to replace version 10, got state %v", mode, state)
+		}
+		db.Close()
+	}
+}
+
+// loadSnapshotState returns the state of the aggregate's latest snapshot.
+func loadSnapshotState(t *testing.T, store *EventStore, aggregateID uuid.UUID) map[string]int {
+	t.Helper()
+	snapshot, err := store.LoadSnapshot(context.Background(), aggregateID)
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+	var state map[string]int
+	if err := json.Unmarshal(snapshot.State, &state); err != nil {
+		t.Fatalf("unmarshal state: %v", err)
+	}
+	return state
+}
+
+func TestPruneSnapshotsKeepsNewestPerAggregate(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store := NewEventStore(db)
+	store.SetSnapshotMode(SnapshotHistory)
+
+	busy, quiet := uuid.New(), uuid.New()
+	saveSnapshots(t, store, busy, 10, 20, 30, 40)
+	saveSnapshots(t, store, quiet, 5)
+
+	if _, err := store.PruneSnapshots(context.Background(), 2); err != nil {
+		t.Fatalf("PruneSnapshots failed: %v", err)
+	}
+
+	var versions []int
+	rows, err := db.Query(`SELECT version FROM snapshots WHERE aggregate_id = $1 ORDER BY version`, busy)
+	if err != nil {
+		t.Fatalf("query snapshots: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var v int
+		if err := rows.Scan(&v); err != nil {
+			t.Fatalf("scan snapshot: %v", err)
 		}
+		versions = append(versions, v)
+	}
+	if fmt.Sprint(versions) != "[30 40]" {
+		t.Fatalf("expected versions [30 40] to survive, got %v", versions)
+	}
+	if n := countSnapshots(t, db, quiet); n != 1 {
+		t.Fatalf("expected the quiet aggregate's only snapshot to survive, got %d", n)
+	}
+
+	snapshot, err := store.LoadSnapshot(context.Background(), busy)
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+	if snapshot.Version != 40 {
+		t.Fatalf("expected version 40, got %d", snapshot.Version)
+	}
+}
+
+func TestPruneSnapshotsRejectsKeepingNone(t *testing.T) {
+	store := NewEventStore(nil)
+	if _, err := store.PruneSnapshots(context.Background(), 0); err == nil {
+		t.Fatal("expected an error when keeping no snapshots")
+	}
+}
+
+func TestConcurrentSerializableAppendsReportSerializationFailure(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store := NewEventStore(db)
+	ctx := context.Background()
+
+	// Each transaction reads the aggregate the other appends to. Once the
+	// first commits, the second's append closes a read/write cycle that
+	// Postgres can only resolve by aborting it.
+	a, b := uuid.New(), uuid.New()
+	eventData, _ := json.Marshal(TestEvent{Message: "skew"})
+	events := []Event{{EventType: "TestEvent", EventData: eventData}}
+
+	first, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer first.Rollback()
+	second, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer second.Rollback()
+
+	var version int
+	if err := first.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM events WHERE aggregate_id = $1`, b).Scan(&version); err != nil {
+		t.Fatalf("read b: %v", err)
+	}
+	if err := second.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM events WHERE aggregate_id = $1`, a).Scan(&version); err != nil {
+		t.Fatalf("read a: %v", err)
+	}
+
+	if err := store.AppendEventsTx(ctx, first, a, "test_aggregate", 0, events); err != nil {
+		t.Fatalf("first append failed: %v", err)
+	}
+	if err := first.Commit(); err != nil {
+		t.Fatalf("first commit failed: %v", err)
+	}
+
+	err = store.AppendEventsTx(ctx, second, b, "test_aggregate", 0, events)
+	if !errors.Is(err, ErrSerializationFailure) {
+		t.Fatalf("expected ErrSerializationFailure, got %v", err)
+	}
+	if errors.Is(err, ErrConcurrencyConflict) {
+		t.Fatal("a serialization failure must not look like a version conflict")
 	}
 }
@@ -0,0 +1,121 @@
+package eventstore
+
+import "context"
+
+// Metadata keys set on appended events from the request context.
+const (
+	MetadataCorrelationID = "correlation_id"
+	MetadataCausationID   = "causation_id"
+	MetadataActorID       = "actor_id"
+	MetadataRequestID     = "request_id"
+)
+
+// SystemActorID is the actor recorded for events the system writes on its
+// own behalf, such as those of scheduled jobs, rather than for a member.
+const SystemActorID = "system"
+
+type metadataKey string
+
+// WithCorrelationID returns a context whose appended events carry id as their
+// correlation ID, grouping every event written on behalf of one request.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, metadataKey(MetadataCorrelationID), id)
+}
+
+// WithCausationID returns a context whose appended events carry id as their
+// causation ID: the event or command that caused them.
+func WithCausationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, metadataKey(MetadataCausationID), id)
+}
+
+// WithActorID returns a context whose appended events record id as the member
+// acting, or SystemActorID when no member is.
+func WithActorID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, metadataKey(MetadataActorID), id)
+}
+
+// WithRequestID returns a context whose appended events record id as the
+// HTTP request that wrote them.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, metadataKey(MetadataRequestID), id)
+}
+
+// CorrelationIDFromContext returns the correlation ID set on ctx, if any.
+func CorrelationIDFromContext(ctx context.Context) string {
+	return metadataValue(ctx, MetadataCorrelationID)
+}
+
+// CausationIDFromContext returns the causation ID set on ctx, if any.
+func CausationIDFromContext(ctx context.Context) string {
+	return metadataValue(ctx, MetadataCausationID)
+}
+
+// ActorIDFromContext returns the actor ID set on ctx, if any.
+func ActorIDFromContext(ctx context.Context) string {
+	return metadataValue(ctx, MetadataActorID)
+}
+
+// RequestIDFromContext returns the request ID set on ctx, if any.
+func RequestIDFromContext(ctx context.Context) string {
+	return metadataValue(ctx, MetadataRequestID)
+}
+
+// MetadataFromContext returns the metadata set on ctx, or nil if there is
+// none.
+func MetadataFromContext(ctx context.Context) map[string]interface{} {
+	var metadata map[string]interface{}
+	for _, key := range []string{MetadataCorrelationID, MetadataCausationID, MetadataActorID, MetadataRequestID} {
+		if v := metadataValue(ctx, key); v != "" {
+			if metadata == nil {
+				metadata = make(map[string]interface{})
+			}
+			metadata[key] = v
+		}
+	}
+	return metadata
+}
+
+func metadataValue(ctx context.Context, key string) string {
+	v, _ := ctx.Value(metadataKey(key)).(string)
+	return v
+}
+
+// withContextMetadata merges the context's metadata into an event's own.
+// Values already set on the event win. The event's map is not modified.
+func withContextMetadata(ctx context.Context, metadata map[string]interface{}) map[string]interface{} {
+	fromCtx := MetadataFromContext(ctx)
+	if len(fromCtx) == 0 {
+		return metadata
+	}
+	for k, v := range metadata {
+		fromCtx[k] = v
+	}
+	return fromCtx
+}
+
+// CorrelationID returns the event's correlation ID, if it has one.
+func (e Event) CorrelationID() string {
+	return e.metadataString(MetadataCorrelationID)
+}
+
+// CausationID returns the event's causation ID, if it has one.
+func (e Event) CausationID() string {
+	return e.metadataString(MetadataCausationID)
+}
+
+// ActorID returns the ID of the member who caused the event, SystemActorID
+// for an event the system wrote on its own behalf, or "" if not recorded.
+func (e Event) ActorID() string {
+	return e.metadataString(MetadataActorID)
+}
+
+// RequestID returns the ID of the HTTP request that wrote the event, if
+// recorded.
+func (e Event) RequestID() string {
+	return e.metadataString(MetadataRequestID)
+}
+
+func (e Event) metadataString(key string) string {
+	v, _ := e.Metadata[key].(string)
+	return v
+}
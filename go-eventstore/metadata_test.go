@@ -0,0 +1,45 @@
+package eventstore
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestMetadataFromContext(t *testing.T) {
+	if md := MetadataFromContext(context.Background()); md != nil {
+		t.Fatalf("expected no metadata, got %v", md)
+	}
+
+	ctx := WithCorrelationID(context.Background(), "req-1")
+	ctx = WithActorID(ctx, "member-1")
+	ctx = WithRequestID(ctx, "http-1")
+	want := map[string]interface{}{
+		MetadataCorrelationID: "req-1",
+		MetadataActorID:       "member-1",
+		MetadataRequestID:     "http-1",
+	}
+	if md := MetadataFromContext(ctx); !reflect.DeepEqual(md, want) {
+		t.Fatalf("expected %v, got %v", want, md)
+	}
+}
+
+func TestWithContextMetadataKeepsEventValues(t *testing.T) {
+	ctx := WithCorrelationID(context.Background(), "req-1")
+	ctx = WithCausationID(ctx, "checkout-1")
+
+	own := map[string]interface{}{MetadataCausationID: "compensation-1", "source": "reconciler"}
+	merged := withContextMetadata(ctx, own)
+
+	want := map[string]interface{}{
+		MetadataCorrelationID: "req-1",
+		MetadataCausationID:   "compensation-1",
+		"source":              "reconciler",
+	}
+	if !reflect.DeepEqual(merged, want) {
+		t.Fatalf("expected %v, got %v", want, merged)
+	}
+	if len(own) != 2 {
+		t.Fatalf("event's metadata was modified: %v", own)
+	}
+}
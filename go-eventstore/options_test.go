@@ -0,0 +1,43 @@
+package eventstore
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestNewEventStoreDefaultsToSerializable(t *testing.T) {
+	if es := NewEventStore(nil); es.isolation != sql.LevelSerializable {
+		t.Fatalf("expected serializable isolation, got %v", es.isolation)
+	}
+	if es := NewEventStore(nil, WithIsolation(sql.LevelReadCommitted)); es.isolation != sql.LevelReadCommitted {
+		t.Fatalf("expected read committed isolation, got %v", es.isolation)
+	}
+}
+
+func TestIsSerializationFailure(t *testing.T) {
+	serialization := &pq.Error{Code: "40001"}
+	if !isSerializationFailure(serialization) {
+		t.Fatal("expected SQLSTATE 40001 to be a serialization failure")
+	}
+	if !isSerializationFailure(fmt.Errorf("commit: %w", serialization)) {
+		t.Fatal("expected a wrapped serialization failure to be recognised")
+	}
+	if !isSerializationFailure(&pq.Error{Code: "40P01"}) {
+		t.Fatal("expected a deadlock to be a serialization failure")
+	}
+	if isSerializationFailure(&pq.Error{Code: "23505"}) || isSerializationFailure(nil) {
+		t.Fatal("expected other errors not to be serialization failures")
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	if !Retryable(ErrConcurrencyConflict) || !Retryable(fmt.Errorf("append: %w", ErrSerializationFailure)) {
+		t.Fatal("expected conflicts and serialization failures to be retryable")
+	}
+	if Retryable(ErrAggregateNotFound) || Retryable(nil) {
+		t.Fatal("expected other errors not to be retryable")
+	}
+}
@@ -0,0 +1,107 @@
+package eventstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	retryBaseDelay = 10 * time.Millisecond
+	retryMaxDelay  = 1 * time.Second
+)
+
+// BuildEventsFunc produces the events to append given the aggregate's current version.
+type BuildEventsFunc func(currentVersion int) ([]Event, error)
+
+// AppendEventsWithRetry appends events built against the aggregate's latest
+// version, retrying with exponential backoff and jitter whenever a concurrent
+// writer wins the optimistic concurrency check or Postgres aborts the append
+// with a serialization failure. The build callback is invoked
+// again on every attempt so it can re-validate against fresh state.
+func (es *EventStore) AppendEventsWithRetry(ctx context.Context, aggregateID uuid.UUID, aggregateType string, maxRetries int, build BuildEventsFunc) error {
+	ctx, span := es.tracer.Start(ctx, "eventstore.append_with_retry",
+		trace.WithAttributes(
+			attribute.String("aggregate.id", aggregateID.String()),
+			attribute.String("aggregate.type", aggregateType),
+			attribute.Int("max.retries", maxRetries),
+		),
+	)
+	defer span.End()
+
+	attempts := 0
+	err := RetryOnConflict(ctx, maxRetries, func() error {
+		if attempts > 0 {
+			span.AddEvent("conflict.retry", trace.WithAttributes(attribute.Int("attempt", attempts)))
+		}
+		attempts++
+
+		version, err := es.GetCurrentVersion(ctx, aggregateID)
+		if err != nil {
+			return err
+		}
+
+		events, err := build(version)
+		if err != nil {
+			return err
+		}
+
+		return es.AppendEvents(ctx, aggregateID, aggregateType, version, events)
+	})
+	span.SetAttributes(attribute.Int("attempts", attempts))
+	return err
+}
+
+// RetryOnConflict calls attempt until it returns anything other than
+// ErrConcurrencyConflict or ErrSerializationFailure, backing off
+// exponentially (with jitter) between tries, for at most maxRetries retries. Callers that append inside their own
+// transaction with AppendEventsTx use it to retry the whole transaction.
+func RetryOnConflict(ctx context.Context, maxRetries int, attempt func() error) error {
+	var lastErr error
+	for i := 0; i <= maxRetries; i++ {
+		if i > 0 {
+			if err := sleepWithContext(ctx, backoff(i)); err != nil {
+				return fmt.Errorf("retry aborted after %d attempts: %w", i, lastErr)
+			}
+		}
+
+		lastErr = attempt()
+		if !Retryable(lastErr) {
+			return lastErr
+		}
+	}
+
+	return fmt.Errorf("giving up after %d attempts: %w", maxRetries+1, lastErr)
+}
+
+// backoff returns an exponentially growing delay with full jitter.
+func backoff(attempt int) time.Duration {
+	delay := retryBaseDelay << (attempt - 1)
+	if delay <= 0 || delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(delay)) + 1)
+}
+
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Retryable reports whether err is worth retrying against fresh state: a lost
+// version check or a transaction Postgres aborted for serializability.
+func Retryable(err error) bool {
+	return errors.Is(err, ErrConcurrencyConflict) || errors.Is(err, ErrSerializationFailure)
+}
@@ -0,0 +1,40 @@
+package eventstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRetryOnConflict(t *testing.T) {
+	attempts := 0
+	err := RetryOnConflict(context.Background(), 3, func() error {
+		attempts++
+		if attempts < 3 {
+			return ErrConcurrencyConflict
+		}
+		return nil
+	})
+	if err != nil || attempts != 3 {
+		t.Fatalf("expected success on attempt 3, got %v after %d attempts", err, attempts)
+	}
+
+	attempts = 0
+	boom := errors.New("boom")
+	err = RetryOnConflict(context.Background(), 3, func() error {
+		attempts++
+		return boom
+	})
+	if !errors.Is(err, boom) || attempts != 1 {
+		t.Fatalf("expected other errors to stop immediately, got %v after %d attempts", err, attempts)
+	}
+
+	attempts = 0
+	err = RetryOnConflict(context.Background(), 2, func() error {
+		attempts++
+		return ErrConcurrencyConflict
+	})
+	if !errors.Is(err, ErrConcurrencyConflict) || attempts != 3 {
+		t.Fatalf("expected to give up after 3 attempts, got %v after %d attempts", err, attempts)
+	}
+}
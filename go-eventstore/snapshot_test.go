@@ -0,0 +1,22 @@
+package eventstore
+
+import "testing"
+
+func TestSnapshotDue(t *testing.T) {
+	tests := []struct {
+		from, to, every int
+		want            bool
+	}{
+		{from: 48, to: 49, every: 50, want: false},
+		{from: 49, to: 50, every: 50, want: true},
+		{from: 50, to: 51, every: 50, want: false},
+		{from: 48, to: 53, every: 50, want: true},
+		{from: 99, to: 100, every: 50, want: true},
+		{from: 49, to: 50, every: 0, want: false},
+	}
+	for _, tt := range tests {
+		if got := SnapshotDue(tt.from, tt.to, tt.every); got != tt.want {
+			t.Errorf("SnapshotDue(%d, %d, %d) = %v, want %v", tt.from, tt.to, tt.every, got, tt.want)
+		}
+	}
+}
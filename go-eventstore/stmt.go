@@ -0,0 +1,97 @@
+package eventstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// stmt returns query prepared on the store's database, preparing it the
+// first time it is asked for, so hot queries are parsed and planned once
+// rather than on every call.
+func (es *EventStore) stmt(ctx context.Context, query string) (*sql.Stmt, error) {
+	es.stmtMu.Lock()
+	defer es.stmtMu.Unlock()
+	if stmt, ok := es.stmts[query]; ok {
+		return stmt, nil
+	}
+	stmt, err := es.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if es.stmts == nil {
+		es.stmts = make(map[string]*sql.Stmt)
+	}
+	es.stmts[query] = stmt
+	return stmt, nil
+}
+
+// loadQuery picks one of the queries that load an aggregate's events.
+type loadQuery struct {
+	bounded  bool // stop at an upper version
+	metadata bool // read each event's metadata
+}
+
+// loadQueries holds the text of every loadQuery, built once so each load
+// reuses the same text, and with it the same prepared statement. Each reads
+// the archive alongside the events table; both halves are served by their
+// table's (aggregate_id, version) primary key, so an aggregate with nothing
+// archived costs one index probe more.
+var loadQueries = func() map[loadQuery]string {
+	queries := make(map[loadQuery]string)
+	for _, bounded := range []bool{false, true} {
+		for _, metadata := range []bool{false, true} {
+			metadataColumn := "metadata"
+			if !metadata {
+				metadataColumn = "NULL::jsonb"
+			}
+			selectFrom := func(table string) string {
+				query := "SELECT id, aggregate_id, aggregate_type, event_type, event_data, " + metadataColumn +
+					", version, schema_version, created_at FROM " + table +
+					" WHERE aggregate_id = $1 AND version >= $2"
+				if bounded {
+					query += " AND version <= $3"
+				}
+				return query
+			}
+			queries[loadQuery{bounded: bounded, metadata: metadata}] = selectFrom("events") +
+				" UNION ALL " + selectFrom("events_archive") + " ORDER BY version ASC"
+		}
+	}
+	return queries
+}()
+
+// eachInRange calls fn with each of an aggregate's events from fromVersion
+// to toVersion, or to its last when toVersion is not positive, reading what
+// q asks for. An error from fn is returned as it is.
+func (es *EventStore) eachInRange(ctx context.Context, q loadQuery, aggregateID uuid.UUID, fromVersion, toVersion int, fn func(Event) error) error {
+	args := []interface{}{aggregateID, fromVersion}
+	if q.bounded = toVersion > 0; q.bounded {
+		args = append(args, toVersion)
+	}
+	stmt, err := es.stmt(ctx, loadQueries[q])
+	if err != nil {
+		return fmt.Errorf("prepare event query: %w", err)
+	}
+
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return fmt.Errorf("query events: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		event, err := es.scanEvent(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate events: %w", err)
+	}
+	return nil
+}
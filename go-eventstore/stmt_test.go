@@ -0,0 +1,29 @@
+package eventstore
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadQueries(t *testing.T) {
+	seen := make(map[string]bool)
+	for q, query := range loadQueries {
+		if seen[query] {
+			t.Errorf("%+v shares its query with another variant", q)
+		}
+		seen[query] = true
+
+		if !strings.Contains(query, "FROM events ") || !strings.Contains(query, "FROM events_archive ") {
+			t.Errorf("%+v does not read both the events and the archive: %s", q, query)
+		}
+		if got := strings.Contains(query, "version <= $3"); got != q.bounded {
+			t.Errorf("%+v bounds the version = %v: %s", q, got, query)
+		}
+		if got := !strings.Contains(query, "NULL::jsonb"); got != q.metadata {
+			t.Errorf("%+v reads metadata = %v: %s", q, got, query)
+		}
+	}
+	if len(loadQueries) != 4 {
+		t.Errorf("built %d load queries, want 4", len(loadQueries))
+	}
+}
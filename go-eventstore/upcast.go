@@ -0,0 +1,68 @@
+package eventstore
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// An Upcaster rewrites an event payload recorded at one schema version into
+// the shape of the next.
+type Upcaster func(data json.RawMessage) (json.RawMessage, error)
+
+// IdentityUpcaster leaves a payload as it is. Register it for a version step
+// whose new shape still reads the old payloads, such as one that only adds an
+// optional field.
+func IdentityUpcaster(data json.RawMessage) (json.RawMessage, error) {
+	return data, nil
+}
+
+type upcasterKey struct {
+	eventType string
+	version   int
+}
+
+// WithUpcaster registers up to bring eventType payloads from schema version
+// fromVersion to fromVersion+1. Register one upcaster per step: an event
+// recorded at version 1 is passed through the 1, 2, ... upcasters in turn
+// until none is registered for its version. An event type's current schema
+// version is one past its last registered step, or 1 with none, and events
+// appended without a SchemaVersion are recorded at it.
+func WithUpcaster(eventType string, fromVersion int, up Upcaster) Option {
+	return func(es *EventStore) {
+		if es.upcasters == nil {
+			es.upcasters = make(map[upcasterKey]Upcaster)
+		}
+		es.upcasters[upcasterKey{eventType, fromVersion}] = up
+		if fromVersion+1 > es.schemaVersions[eventType] {
+			if es.schemaVersions == nil {
+				es.schemaVersions = make(map[string]int)
+			}
+			es.schemaVersions[eventType] = fromVersion + 1
+		}
+	}
+}
+
+// schemaVersion returns the schema version new events of eventType are
+// recorded at.
+func (es *EventStore) schemaVersion(eventType string) int {
+	if v, ok := es.schemaVersions[eventType]; ok {
+		return v
+	}
+	return 1
+}
+
+// upcast brings a loaded event's payload up to its type's current schema.
+func (es *EventStore) upcast(event *Event) error {
+	for {
+		up, ok := es.upcasters[upcasterKey{event.EventType, event.SchemaVersion}]
+		if !ok {
+			return nil
+		}
+		data, err := up(event.EventData)
+		if err != nil {
+			return fmt.Errorf("upcast event %d (%s) from schema version %d: %w", event.ID, event.EventType, event.SchemaVersion, err)
+		}
+		event.EventData = data
+		event.SchemaVersion++
+	}
+}
@@ -0,0 +1,101 @@
+package eventstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// renameField returns an upcaster that moves a payload field to a new name.
+func renameField(from, to string) Upcaster {
+	return func(data json.RawMessage) (json.RawMessage, error) {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, err
+		}
+		if v, ok := fields[from]; ok {
+			fields[to] = v
+			delete(fields, from)
+		}
+		return json.Marshal(fields)
+	}
+}
+
+func TestUpcastAppliesEachStepInTurn(t *testing.T) {
+	es := NewEventStore(nil,
+		WithUpcaster("ItemAdded", 1, renameField("name", "title")),
+		WithUpcaster("ItemAdded", 2, IdentityUpcaster),
+	)
+	if v := es.schemaVersion("ItemAdded"); v != 3 {
+		t.Fatalf("expected current schema version 3, got %d", v)
+	}
+	if v := es.schemaVersion("ItemRemoved"); v != 1 {
+		t.Fatalf("expected an event type without upcasters at version 1, got %d", v)
+	}
+
+	event := Event{EventType: "ItemAdded", EventData: json.RawMessage(`{"name":"Emma"}`), SchemaVersion: 1}
+	if err := es.upcast(&event); err != nil {
+		t.Fatalf("upcast failed: %v", err)
+	}
+	if event.SchemaVersion != 3 {
+		t.Fatalf("expected schema version 3, got %d", event.SchemaVersion)
+	}
+	if string(event.EventData) != `{"title":"Emma"}` {
+		t.Fatalf("unexpected payload %s", event.EventData)
+	}
+
+	current := Event{EventType: "ItemAdded", EventData: json.RawMessage(`{"name":"kept"}`), SchemaVersion: 3}
+	if err := es.upcast(&current); err != nil || string(current.EventData) != `{"name":"kept"}` {
+		t.Fatalf("expected a current event to be left alone, got %s, %v", current.EventData, err)
+	}
+}
+
+func TestUpcastReportsFailures(t *testing.T) {
+	broken := errors.New("broken")
+	es := NewEventStore(nil, WithUpcaster("ItemAdded", 1, func(json.RawMessage) (json.RawMessage, error) {
+		return nil, broken
+	}))
+
+	event := Event{ID: 7, EventType: "ItemAdded", EventData: json.RawMessage(`{}`), SchemaVersion: 1}
+	if err := es.upcast(&event); !errors.Is(err, broken) {
+		t.Fatalf("expected the upcaster's error, got %v", err)
+	}
+}
+
+func TestLoadEventsUpcastsOldPayloads(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	aggregateID := uuid.New()
+	old := NewEventStore(db)
+	if err := old.AppendEvents(context.Background(), aggregateID, "test_aggregate", 0, []Event{{EventType: "Renamed", EventData: json.RawMessage(`{"name":"Emma"}`)}}); err != nil {
+		t.Fatalf("AppendEvents failed: %v", err)
+	}
+
+	store := NewEventStore(db, WithUpcaster("Renamed", 1, renameField("name", "title")))
+	if err := store.AppendEvents(context.Background(), aggregateID, "test_aggregate", 1, []Event{{EventType: "Renamed", EventData: json.RawMessage(`{"title":"Persuasion"}`)}}); err != nil {
+		t.Fatalf("AppendEvents failed: %v", err)
+	}
+
+	events, err := store.LoadEvents(context.Background(), aggregateID, 1, 0)
+	if err != nil {
+		t.Fatalf("LoadEvents failed: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	for i, want := range []string{"Emma", "Persuasion"} {
+		var payload struct {
+			Title string `json:"title"`
+		}
+		if err := json.Unmarshal(events[i].EventData, &payload); err != nil || payload.Title != want {
+			t.Fatalf("event %d: expected title %q, got %s", i, want, events[i].EventData)
+		}
+		if events[i].SchemaVersion != 2 {
+			t.Fatalf("event %d: expected schema version 2, got %d", i, events[i].SchemaVersion)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+// internal/cache/lru.go
+
+// Package cache provides the small in-memory caches the services keep in
+// front of their read models.
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LRU caches up to a fixed number of versioned values for a TTL, evicting
+// the least recently used when full. Each value carries the version of the
+// entity it holds. Invalidate drops a key's value and refuses any older one
+// for the rest of the TTL, so a read that raced a write cannot put back what
+// the write replaced. A nil *LRU caches nothing, so a disabled cache needs
+// no checks at its call sites.
+type LRU[K comparable, V any] struct {
+	size int
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List // of *entry[K, V], most recently used first
+	entries map[K]*list.Element
+}
+
+type entry[K comparable, V any] struct {
+	key     K
+	value   V
+	version int
+	// present is false for an entry only recording, after Invalidate, the
+	// oldest version Put may still cache.
+	present bool
+	expires time.Time
+}
+
+// NewLRU returns a cache of up to size values, each kept for ttl. It
+// returns nil, a cache that caches nothing, if either is not positive.
+func NewLRU[K comparable, V any](size int, ttl time.Duration) *LRU[K, V] {
+	if size <= 0 || ttl <= 0 {
+		return nil
+	}
+	return &LRU[K, V]{
+		size:    size,
+		ttl:     ttl,
+		now:     time.Now,
+		order:   list.New(),
+		entries: make(map[K]*list.Element),
+	}
+}
+
+// Get returns the value cached for key, if there is one and it has not
+// expired.
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	var zero V
+	if c == nil {
+		return zero, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.lookup(key)
+	if e == nil || !e.Value.(*entry[K, V]).present {
+		return zero, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*entry[K, V]).value, true
+}
+
+// Put caches value, at version, for key. It is ignored if a newer version
+// is cached or key has been invalidated at a newer version.
+func (c *LRU[K, V]) Put(key K, value V, version int) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e := c.lookup(key); e != nil && e.Value.(*entry[K, V]).version > version {
+		return
+	}
+	c.set(&entry[K, V]{key: key, value: value, version: version, present: true})
+}
+
+// Invalidate drops the value cached for key, written as of version, and
+// refuses values older than version until the TTL has passed. Invalidating
+// at a version that is then never written, as when the write rolls back,
+// only costs misses.
+func (c *LRU[K, V]) Invalidate(key K, version int) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e := c.lookup(key); e != nil && e.Value.(*entry[K, V]).version > version {
+		version = e.Value.(*entry[K, V]).version
+	}
+	c.set(&entry[K, V]{key: key, version: version})
+}
+
+// Len returns how many keys the cache holds, counting invalidated ones.
+func (c *LRU[K, V]) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// lookup returns key's element, removing it instead if it has expired.
+func (c *LRU[K, V]) lookup(key K) *list.Element {
+	e, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	if !c.now().Before(e.Value.(*entry[K, V]).expires) {
+		c.remove(e)
+		return nil
+	}
+	return e
+}
+
+// set stores ent as the most recently used, evicting the least recently
+// used entry if the cache is full.
+func (c *LRU[K, V]) set(ent *entry[K, V]) {
+	ent.expires = c.now().Add(c.ttl)
+	if e, ok := c.entries[ent.key]; ok {
+		e.Value = ent
+		c.order.MoveToFront(e)
+		return
+	}
+	if c.order.Len() >= c.size {
+		c.remove(c.order.Back())
+	}
+	c.entries[ent.key] = c.order.PushFront(ent)
+}
+
+func (c *LRU[K, V]) remove(e *list.Element) {
+	c.order.Remove(e)
+	delete(c.entries, e.Value.(*entry[K, V]).key)
+}
@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// clock is a settable time source for expiring entries.
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time { return c.t }
+
+func newTestLRU(size int, ttl time.Duration) (*LRU[string, string], *clock) {
+	clk := &clock{t: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)}
+	c := NewLRU[string, string](size, ttl)
+	c.now = clk.now
+	return c, clk
+}
+
+func TestLRUGetAndExpiry(t *testing.T) {
+	c, clk := newTestLRU(2, time.Minute)
+	c.Put("a", "a1", 1)
+
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, "a1", v)
+
+	clk.t = clk.t.Add(time.Minute)
+	_, ok = c.Get("a")
+	assert.False(t, ok, "an entry should expire after its TTL")
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c, _ := newTestLRU(2, time.Minute)
+	c.Put("a", "a1", 1)
+	c.Put("b", "b1", 1)
+	c.Get("a")
+	c.Put("c", "c1", 1)
+
+	_, ok := c.Get("b")
+	assert.False(t, ok, "b was used least recently")
+	_, ok = c.Get("a")
+	assert.True(t, ok)
+	_, ok = c.Get("c")
+	assert.True(t, ok)
+}
+
+func TestLRURefusesOlderVersions(t *testing.T) {
+	c, clk := newTestLRU(2, time.Minute)
+	c.Put("a", "a2", 2)
+	c.Put("a", "a1", 1)
+	v, _ := c.Get("a")
+	assert.Equal(t, "a2", v, "an older version should not replace a newer one")
+
+	c.Invalidate("a", 3)
+	_, ok := c.Get("a")
+	assert.False(t, ok, "an invalidated key should miss")
+
+	// A read of version 2 that started before the write finishes after it.
+	c.Put("a", "a2", 2)
+	_, ok = c.Get("a")
+	assert.False(t, ok, "a version older than the invalidation should be refused")
+
+	c.Put("a", "a3", 3)
+	v, ok = c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, "a3", v)
+
+	c.Invalidate("a", 2)
+	c.Put("a", "a2", 2)
+	_, ok = c.Get("a")
+	assert.False(t, ok, "invalidating at an older version should keep the newer floor")
+
+	clk.t = clk.t.Add(time.Minute)
+	c.Put("a", "a1", 1)
+	_, ok = c.Get("a")
+	assert.True(t, ok, "an invalidation should lapse with the TTL")
+}
+
+func TestNilLRUCachesNothing(t *testing.T) {
+	assert.Nil(t, NewLRU[string, string](0, time.Minute))
+	assert.Nil(t, NewLRU[string, string](10, 0))
+
+	var c *LRU[string, string]
+	c.Put("a", "a1", 1)
+	c.Invalidate("a", 2)
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+}
@@ -0,0 +1,105 @@
+// internal/catalog/adjust.go
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"libranexus/internal/database"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+)
+
+// AdjustCopies changes an item's total and available copies by the given
+// deltas, for a reason such as copies being lost, damaged or newly acquired.
+// A lost copy on the shelf is (-1, -1); one lost on loan is (-1, 0). The
+// adjustment is rejected with ErrInvalidCopyCounts if it would leave fewer
+// than zero or more than the total available. Concurrent changes to the item
+// are retried against its latest counts, and each adjustment is also written
+// to the inventory corrections trail.
+func (s *service) AdjustCopies(ctx context.Context, id uuid.UUID, totalDelta, availableDelta int, reason string) (*Item, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: missing reason", ErrInvalidAdjustment)
+	}
+	if totalDelta == 0 && availableDelta == 0 {
+		return nil, fmt.Errorf("%w: nothing to adjust", ErrInvalidAdjustment)
+	}
+
+	var adjusted Item
+	var adjustedFrom int
+	err := eventstore.RetryOnConflict(ctx, maxAppendRetries, func() error {
+		item, err := s.readItem(ctx, id)
+		if err != nil {
+			return err
+		}
+		adjusted = *item
+		adjusted.TotalCopies += totalDelta
+		adjusted.Available += availableDelta
+		if err := checkCopyCounts(adjusted.TotalCopies, adjusted.Available); err != nil {
+			return err
+		}
+		adjusted.Version++
+		adjustedFrom = item.Version
+
+		jsonData, err := json.Marshal(CopiesAdjustedEvent{
+			ID:             id,
+			TotalDelta:     totalDelta,
+			AvailableDelta: availableDelta,
+			NewTotal:       adjusted.TotalCopies,
+			NewAvailable:   adjusted.Available,
+			Reason:         reason,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal event data: %w", err)
+		}
+		event := eventstore.Event{
+			AggregateID:   id,
+			AggregateType: "item",
+			EventType:     "CopiesAdjusted",
+			EventData:     jsonData,
+			Version:       adjusted.Version,
+		}
+
+		return database.InTx(ctx, s.db, func(tx *sql.Tx) error {
+			if err := s.appendItemEventsTx(ctx, tx, id, item.Version, []eventstore.Event{event}); err != nil {
+				return fmt.Errorf("failed to append event: %w", err)
+			}
+			query := `
+				UPDATE items
+				SET total_copies = $1, available = $2, version = $3, updated_at = NOW()
+				WHERE id = $4 AND version = $5
+			`
+			if _, err := tx.ExecContext(ctx, query, adjusted.TotalCopies, adjusted.Available, adjusted.Version, id, item.Version); err != nil {
+				return fmt.Errorf("failed to update read model: %w", err)
+			}
+			query = `
+				INSERT INTO inventory_corrections (item_id, previous_copies, new_copies, reason, created_by)
+				VALUES ($1, $2, $3, $4, $5)
+			`
+			if _, err := tx.ExecContext(ctx, query, id, item.TotalCopies, adjusted.TotalCopies, reason, actorID(ctx)); err != nil {
+				return fmt.Errorf("failed to record inventory correction: %w", err)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.snapshotIfDue(ctx, id, adjustedFrom, adjusted.Version)
+	s.reindexItem(ctx, id)
+
+	return &adjusted, nil
+}
+
+// actorID returns the member acting on ctx, or nil when there is none.
+func actorID(ctx context.Context) *uuid.UUID {
+	id, err := uuid.Parse(eventstore.ActorIDFromContext(ctx))
+	if err != nil {
+		return nil
+	}
+	return &id
+}
@@ -0,0 +1,97 @@
+package catalog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckCopyCounts(t *testing.T) {
+	assert.NoError(t, checkCopyCounts(3, 0))
+	assert.NoError(t, checkCopyCounts(3, 3))
+	assert.NoError(t, checkCopyCounts(0, 0))
+	assert.ErrorIs(t, checkCopyCounts(3, 4), ErrInvalidCopyCounts)
+	assert.ErrorIs(t, checkCopyCounts(3, -1), ErrInvalidCopyCounts)
+	assert.ErrorIs(t, checkCopyCounts(-1, 0), ErrInvalidCopyCounts)
+	assert.ErrorIs(t, checkCopyCounts(-1, -1), ErrInvalidCopyCounts)
+}
+
+func TestAddItemRejectsTooFewCopies(t *testing.T) {
+	tests := []struct {
+		minCopies, total int
+		wantErr          bool
+	}{
+		{minCopies: DefaultMinCopies, total: -1, wantErr: true},
+		{minCopies: DefaultMinCopies, total: 0, wantErr: true},
+		{minCopies: 0, total: -1, wantErr: true},
+		{minCopies: 3, total: 2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		s := &service{minCopies: tt.minCopies}
+		_, err := s.AddItem(context.Background(), "9780141439518", "Emma", "Jane Austen", "", tt.total)
+		assert.ErrorIs(t, err, ErrInvalidItem, "%d copies with a minimum of %d", tt.total, tt.minCopies)
+	}
+
+	// At the minimum the check passes and the service goes on to the database.
+	assert.NoError(t, checkNewCopies(DefaultMinCopies, DefaultMinCopies))
+	assert.NoError(t, checkNewCopies(0, 0))
+}
+
+func TestWithMinCopiesIgnoresNegative(t *testing.T) {
+	s := &service{minCopies: DefaultMinCopies}
+	WithMinCopies(-1)(s)
+	assert.Equal(t, DefaultMinCopies, s.minCopies)
+	WithMinCopies(0)(s)
+	assert.Equal(t, 0, s.minCopies)
+}
+
+func TestAdjustCopiesRejectsEmptyAdjustments(t *testing.T) {
+	s := &service{}
+
+	_, err := s.AdjustCopies(context.Background(), uuid.New(), -1, -1, "  ")
+	assert.ErrorIs(t, err, ErrInvalidAdjustment, "a reason is required")
+
+	_, err = s.AdjustCopies(context.Background(), uuid.New(), 0, 0, "lost")
+	assert.ErrorIs(t, err, ErrInvalidAdjustment)
+}
+
+func TestUpdateItemCopiesRejectsMoreAvailableThanTotal(t *testing.T) {
+	err := (&service{}).UpdateItemCopies(context.Background(), uuid.New(), 2, 3, 0)
+	assert.ErrorIs(t, err, ErrInvalidCopyCounts)
+}
+
+func TestUpdateItemCopiesRejectsNegativeCounts(t *testing.T) {
+	err := (&service{}).UpdateItemCopies(context.Background(), uuid.New(), -1, -1, 0)
+	assert.ErrorIs(t, err, ErrInvalidCopyCounts)
+	err = (&service{}).UpdateItemCopies(context.Background(), uuid.New(), 2, -1, 0)
+	assert.ErrorIs(t, err, ErrInvalidCopyCounts)
+}
+
+func TestItemApplyCopiesAdjusted(t *testing.T) {
+	id := uuid.New()
+
+	item := &Item{}
+	require.NoError(t, item.Apply(itemEvent(t, id, "ItemAdded", 1, ItemAddedEvent{ID: id, ISBN: "9780141439518", Title: "Emma", TotalCopies: 3})))
+	require.NoError(t, item.Apply(itemEvent(t, id, "CopiesAdjusted", 2, CopiesAdjustedEvent{ID: id, TotalDelta: -1, AvailableDelta: -1, NewTotal: 2, NewAvailable: 2, Reason: "lost"})))
+
+	assert.Equal(t, 2, item.TotalCopies)
+	assert.Equal(t, 2, item.Available)
+	assert.Equal(t, 2, item.Version)
+}
+
+func TestItemApplyClampsAvailable(t *testing.T) {
+	id := uuid.New()
+
+	item := &Item{}
+	require.NoError(t, item.Apply(itemEvent(t, id, "ItemAdded", 1, ItemAddedEvent{ID: id, ISBN: "9780141439518", Title: "Emma", TotalCopies: 1})))
+	require.NoError(t, item.Apply(itemEvent(t, id, "ItemCopyReserved", 2, ItemCopyReservedEvent{ID: id})))
+	require.NoError(t, item.Apply(itemEvent(t, id, "ItemCopyReserved", 3, ItemCopyReservedEvent{ID: id})))
+	assert.Equal(t, 0, item.Available, "a reservation of a copy that is not there should not go negative")
+
+	require.NoError(t, item.Apply(itemEvent(t, id, "ItemCopiesUpdated", 4, ItemCopiesUpdatedEvent{ID: id, NewTotal: 2, NewAvailable: 3})))
+	assert.Equal(t, 2, item.Available, "available should not exceed the total")
+}
@@ -0,0 +1,85 @@
+// internal/catalog/details.go
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"libranexus/internal/database"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+)
+
+// UpdateItemDetails corrects an item's title, author, publisher or year of
+// publication, changing only the fields the patch sets. The change is made
+// against the item's current version, or against patch.ExpectedVersion when
+// that is positive; either way it fails with ErrVersionConflict if the item
+// changes first.
+func (s *service) UpdateItemDetails(ctx context.Context, id uuid.UUID, patch ItemDetailsPatch) (*Item, error) {
+	patch, err := patch.normalize(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	item, err := s.readItem(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if patch.ExpectedVersion > 0 && patch.ExpectedVersion != item.Version {
+		return nil, ErrVersionConflict
+	}
+
+	eventData := ItemDetailsUpdatedEvent{
+		ID:            id,
+		Title:         patch.Title,
+		Author:        patch.Author,
+		Publisher:     patch.Publisher,
+		PublishedYear: patch.PublishedYear,
+	}
+	jsonData, err := json.Marshal(eventData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	updated := *item
+	eventData.applyTo(&updated)
+	updated.Version++
+
+	event := eventstore.Event{
+		AggregateID:   id,
+		AggregateType: "item",
+		EventType:     "ItemDetailsUpdated",
+		EventData:     jsonData,
+		Version:       updated.Version,
+	}
+
+	err = database.InTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.appendItemEventsTx(ctx, tx, id, item.Version, []eventstore.Event{event}); err != nil {
+			return fmt.Errorf("failed to append event: %w", err)
+		}
+		query := `
+			UPDATE items
+			SET title = COALESCE($1, title), author = COALESCE($2, author),
+			    publisher = COALESCE($3, publisher), published_year = COALESCE($4, published_year),
+			    version = $5, updated_at = NOW()
+			WHERE id = $6 AND version = $7
+		`
+		if _, err := tx.ExecContext(ctx, query, patch.Title, patch.Author, patch.Publisher, patch.PublishedYear, updated.Version, id, item.Version); err != nil {
+			return fmt.Errorf("failed to update read model: %w", err)
+		}
+		return nil
+	})
+	if errors.Is(err, eventstore.ErrConcurrencyConflict) {
+		return nil, ErrVersionConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.snapshotIfDue(ctx, id, item.Version, updated.Version)
+	s.reindexItem(ctx, id)
+
+	return &updated, nil
+}
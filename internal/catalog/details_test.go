@@ -0,0 +1,48 @@
+package catalog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestItemDetailsPatchNormalize(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	str := func(s string) *string { return &s }
+	year := func(y int) *int { return &y }
+
+	patch, err := ItemDetailsPatch{Title: str("  Emma "), Publisher: str(" Penguin"), PublishedYear: year(2024)}.normalize(now)
+	require.NoError(t, err)
+	assert.Equal(t, "Emma", *patch.Title)
+	assert.Equal(t, "Penguin", *patch.Publisher)
+	assert.Nil(t, patch.Author)
+
+	for name, patch := range map[string]ItemDetailsPatch{
+		"empty":        {},
+		"blank title":  {Title: str("  ")},
+		"future year":  {PublishedYear: year(2025)},
+		"year zero":    {PublishedYear: year(0)},
+		"only version": {ExpectedVersion: 3},
+	} {
+		_, err := patch.normalize(now)
+		assert.ErrorIs(t, err, ErrInvalidItemDetails, name)
+	}
+}
+
+func TestItemApplyDetailsUpdatedChangesOnlySetFields(t *testing.T) {
+	id := uuid.New()
+	title, year := "Pride and Prejudice", 1813
+
+	item := &Item{}
+	require.NoError(t, item.Apply(itemEvent(t, id, "ItemAdded", 1, ItemAddedEvent{ID: id, ISBN: "9780141439518", Title: "Pride and Prejudce", Author: "Jane Austen", TotalCopies: 2})))
+	require.NoError(t, item.Apply(itemEvent(t, id, "ItemDetailsUpdated", 2, ItemDetailsUpdatedEvent{ID: id, Title: &title, PublishedYear: &year})))
+
+	assert.Equal(t, "Pride and Prejudice", item.Title)
+	assert.Equal(t, "Jane Austen", item.Author)
+	assert.Empty(t, item.Publisher)
+	assert.Equal(t, 1813, item.PublishedYear)
+	assert.Equal(t, 2, item.Version)
+}
@@ -2,47 +2,415 @@
 package catalog
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jules-labs/go-eventstore"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 100
+)
+
+// DefaultCategory is the category of items added without one.
+const DefaultCategory = "general"
+
+// Per-row outcomes of a bulk import.
+const (
+	ImportCreated = "created"
+	ImportFailed  = "failed"
+)
+
+var (
+	ErrInvalidSearchParams = errors.New("invalid search parameters")
+	ErrVersionConflict     = errors.New("item version conflict: item was modified concurrently")
+	ErrNoCopiesAvailable   = errors.New("no copies of the item are available")
+	ErrAllCopiesAvailable  = errors.New("every copy of the item is already available")
+	ErrInvalidISBN         = errors.New("invalid ISBN")
+	ErrDuplicateISBN       = errors.New("an item with this ISBN already exists")
+	ErrInvalidItem         = errors.New("invalid item")
+	ErrItemNotFound        = errors.New("item not found")
+	ErrItemNotRetired      = errors.New("item is not retired")
+	ErrInvalidItemDetails  = errors.New("invalid item details")
+	ErrInvalidCopyCounts   = errors.New("invalid copy counts: available copies must be between zero and the total")
+	ErrInvalidAdjustment   = errors.New("invalid copy adjustment")
+	ErrInvalidEventRange   = errors.New("invalid event version range")
+	ErrNoItemState         = errors.New("item has no state at that point")
+)
+
 // Item represents a book or other library item.
 type Item struct {
-	ID             uuid.UUID `json:"id"`
-	ISBN           string    `json:"isbn"`
-	Title          string    `json:"title"`
-	Author         string    `json:"author"`
-	Publisher      string    `json:"publisher,omitempty"`
-	PublishedYear  int       `json:"published_year,omitempty"`
-	TotalCopies    int       `json:"total_copies"`
-	Available      int       `json:"available"`
-	Status         string    `json:"status"`
-	Version        int       `json:"version"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID            uuid.UUID `json:"id"`
+	ISBN          string    `json:"isbn"`
+	Title         string    `json:"title"`
+	Author        string    `json:"author"`
+	Publisher     string    `json:"publisher,omitempty"`
+	PublishedYear int       `json:"published_year,omitempty"`
+	Category      string    `json:"category"`
+	TotalCopies   int       `json:"total_copies"`
+	Available     int       `json:"available"`
+	Status        string    `json:"status"`
+	Version       int       `json:"version"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// SearchParams describes a page of catalog search results.
+type SearchParams struct {
+	Query  string
+	Status string
+	Author string
+	// IncludeRetired also matches retired items. It is implied when Status
+	// is set.
+	IncludeRetired bool
+	// AvailableOnly matches only active items with a copy on the shelf.
+	AvailableOnly bool
+	// Sort orders the results: title, published_year, or either prefixed
+	// with "-" for descending. Empty orders by relevance.
+	Sort   string
+	Limit  int
+	Offset int
+}
+
+// searchSorts maps each allowed sort to its ORDER BY clause in a database
+// search, whose query text is $1. Ties fall back to title and ID so that
+// pages are stable.
+var searchSorts = map[string]string{
+	"":                "ts_rank(to_tsvector('english', title) || to_tsvector('english', author), websearch_to_tsquery('english', $1)) DESC, title, id",
+	"title":           "title, id",
+	"-title":          "title DESC, id",
+	"published_year":  "published_year NULLS LAST, title, id",
+	"-published_year": "published_year DESC NULLS LAST, title, id",
+}
+
+// normalize validates the parameters, applying the default limit when none is
+// given and capping it at the maximum page size.
+func (p *SearchParams) normalize() error {
+	p.Query = strings.TrimSpace(p.Query)
+	if p.Query == "" {
+		return fmt.Errorf("%w: missing search query", ErrInvalidSearchParams)
+	}
+	if p.Limit < 0 {
+		return fmt.Errorf("%w: limit must be non-negative", ErrInvalidSearchParams)
+	}
+	if p.Offset < 0 {
+		return fmt.Errorf("%w: offset must be non-negative", ErrInvalidSearchParams)
+	}
+	if _, ok := searchSorts[p.Sort]; !ok {
+		return fmt.Errorf("%w: unknown sort %q", ErrInvalidSearchParams, p.Sort)
+	}
+	if p.Limit == 0 {
+		p.Limit = defaultSearchLimit
+	}
+	if p.Limit > maxSearchLimit {
+		p.Limit = maxSearchLimit
+	}
+	return nil
+}
+
+// ListParams describes a page of the catalog, browsed without a search query.
+type ListParams struct {
+	Status         string
+	Author         string
+	IncludeRetired bool
+	AvailableOnly  bool
+	// Sort is one of the sorts SearchParams allows, defaulting to title.
+	Sort   string
+	Limit  int
+	Offset int
+}
+
+// normalize validates the parameters, applying the default sort and limit and
+// capping the limit at the maximum page size.
+func (p *ListParams) normalize() error {
+	if p.Limit < 0 {
+		return fmt.Errorf("%w: limit must be non-negative", ErrInvalidSearchParams)
+	}
+	if p.Offset < 0 {
+		return fmt.Errorf("%w: offset must be non-negative", ErrInvalidSearchParams)
+	}
+	if p.Sort == "" {
+		p.Sort = "title"
+	}
+	if _, ok := searchSorts[p.Sort]; !ok {
+		return fmt.Errorf("%w: unknown sort %q", ErrInvalidSearchParams, p.Sort)
+	}
+	if p.Limit == 0 {
+		p.Limit = defaultSearchLimit
+	}
+	if p.Limit > maxSearchLimit {
+		p.Limit = maxSearchLimit
+	}
+	return nil
+}
+
+// SearchResult is one page of search results along with the total match count.
+type SearchResult struct {
+	Items  []*Item `json:"items"`
+	Total  int     `json:"total"`
+	Limit  int     `json:"limit"`
+	Offset int     `json:"offset"`
+}
+
+// Discrepancy is an item whose available count disagrees with the copies
+// circulation has out. Corrected reports whether reconciliation reset it.
+type Discrepancy struct {
+	ItemID      uuid.UUID `json:"item_id"`
+	TotalCopies int       `json:"total_copies"`
+	Available   int       `json:"available"`
+	Expected    int       `json:"expected"`
+	Version     int       `json:"version"`
+	Corrected   bool      `json:"corrected"`
+}
+
+// checkCopyCounts reports whether total and available copies satisfy
+// 0 <= available <= total.
+func checkCopyCounts(total, available int) error {
+	if total < 0 {
+		return fmt.Errorf("%w: %d total copies", ErrInvalidCopyCounts, total)
+	}
+	if available < 0 || available > total {
+		return fmt.Errorf("%w: %d available of %d", ErrInvalidCopyCounts, available, total)
+	}
+	return nil
+}
+
+// ItemDetailsPatch changes an item's bibliographic details. Only the fields
+// that are set change. A positive ExpectedVersion makes the change conditional
+// on the item still being at that version.
+type ItemDetailsPatch struct {
+	Title           *string `json:"title,omitempty"`
+	Author          *string `json:"author,omitempty"`
+	Publisher       *string `json:"publisher,omitempty"`
+	PublishedYear   *int    `json:"published_year,omitempty"`
+	ExpectedVersion int     `json:"expected_version,omitempty"`
+}
+
+// normalize validates the patch as of now, trimming the strings it sets.
+func (p ItemDetailsPatch) normalize(now time.Time) (ItemDetailsPatch, error) {
+	if p.Title == nil && p.Author == nil && p.Publisher == nil && p.PublishedYear == nil {
+		return p, fmt.Errorf("%w: nothing to update", ErrInvalidItemDetails)
+	}
+	p.Title, p.Author, p.Publisher = trimSpace(p.Title), trimSpace(p.Author), trimSpace(p.Publisher)
+	if p.Title != nil && *p.Title == "" {
+		return p, fmt.Errorf("%w: title must not be blank", ErrInvalidItemDetails)
+	}
+	if p.PublishedYear != nil && (*p.PublishedYear < 1 || *p.PublishedYear > now.Year()) {
+		return p, fmt.Errorf("%w: published_year must be between 1 and %d", ErrInvalidItemDetails, now.Year())
+	}
+	return p, nil
+}
+
+func trimSpace(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*s)
+	return &trimmed
+}
+
+// NewItem is a single row of a bulk import.
+type NewItem struct {
+	ISBN        string `json:"isbn"`
+	Title       string `json:"title"`
+	Author      string `json:"author"`
+	Category    string `json:"category,omitempty"`
+	TotalCopies int    `json:"total_copies"`
+}
+
+// normalize validates the row, which must have at least minCopies copies,
+// and returns it with its ISBN normalized.
+func (n NewItem) normalize(minCopies int) (NewItem, error) {
+	isbn, err := NormalizeISBN(n.ISBN)
+	if err != nil {
+		return n, err
+	}
+	n.ISBN = isbn
+	n.Category = NormalizeCategory(n.Category)
+	if strings.TrimSpace(n.Title) == "" {
+		return n, fmt.Errorf("%w: missing title", ErrInvalidItem)
+	}
+	if err := checkNewCopies(n.TotalCopies, minCopies); err != nil {
+		return n, err
+	}
+	return n, nil
+}
+
+// clampAvailable limits available copies to [0, total]. Writes refuse counts
+// outside that range, so clamping only matters for events recorded before
+// they did; a replayed item then never shows copies it cannot have.
+func clampAvailable(available, total int) int {
+	return max(0, min(available, total))
+}
+
+// checkNewCopies reports whether a new item's total copies are at least
+// minCopies.
+func checkNewCopies(total, minCopies int) error {
+	if total < minCopies {
+		return fmt.Errorf("%w: total_copies must be at least %d", ErrInvalidItem, minCopies)
+	}
+	return nil
+}
+
+// NormalizeCategory lower-cases a category, defaulting it when blank.
+func NormalizeCategory(category string) string {
+	category = strings.ToLower(strings.TrimSpace(category))
+	if category == "" {
+		return DefaultCategory
+	}
+	return category
+}
+
+// ImportResult reports what happened to one row of a bulk import. Index is
+// the row's position in the request.
+type ImportResult struct {
+	Index  int        `json:"index"`
+	ID     *uuid.UUID `json:"id,omitempty"`
+	Status string     `json:"status"`
+	Error  string     `json:"error,omitempty"`
+}
+
+// Apply folds a single event into the item's state. Events must be applied in
+// version order, starting from an empty item or a snapshot.
+func (i *Item) Apply(event eventstore.Event) error {
+	switch event.EventType {
+	case "ItemAdded":
+		var e ItemAddedEvent
+		if err := json.Unmarshal(event.EventData, &e); err != nil {
+			return fmt.Errorf("failed to decode %s: %w", event.EventType, err)
+		}
+		i.ID = e.ID
+		i.ISBN = e.ISBN
+		i.Title = e.Title
+		i.Author = e.Author
+		i.Category = NormalizeCategory(e.Category)
+		i.TotalCopies = e.TotalCopies
+		i.Available = e.TotalCopies
+		i.Status = "active"
+		i.CreatedAt = event.CreatedAt
+	case "ItemCopiesUpdated":
+		var e ItemCopiesUpdatedEvent
+		if err := json.Unmarshal(event.EventData, &e); err != nil {
+			return fmt.Errorf("failed to decode %s: %w", event.EventType, err)
+		}
+		i.TotalCopies = e.NewTotal
+		i.Available = clampAvailable(e.NewAvailable, e.NewTotal)
+	case "ItemDetailsUpdated":
+		var e ItemDetailsUpdatedEvent
+		if err := json.Unmarshal(event.EventData, &e); err != nil {
+			return fmt.Errorf("failed to decode %s: %w", event.EventType, err)
+		}
+		e.applyTo(i)
+	case "CopiesAdjusted":
+		var e CopiesAdjustedEvent
+		if err := json.Unmarshal(event.EventData, &e); err != nil {
+			return fmt.Errorf("failed to decode %s: %w", event.EventType, err)
+		}
+		i.TotalCopies = e.NewTotal
+		i.Available = clampAvailable(e.NewAvailable, e.NewTotal)
+	case "ItemCopyReserved":
+		i.Available = clampAvailable(i.Available-1, i.TotalCopies)
+	case "ItemCopyReleased":
+		i.Available = clampAvailable(i.Available+1, i.TotalCopies)
+	case "ItemRemoved":
+		var e ItemRemovedEvent
+		if err := json.Unmarshal(event.EventData, &e); err != nil {
+			return fmt.Errorf("failed to decode %s: %w", event.EventType, err)
+		}
+		i.Status = e.Status
+	case "ItemRestored":
+		i.Status = "active"
+	default:
+		return fmt.Errorf("unknown item event type %q", event.EventType)
+	}
+
+	i.Version = event.Version
+	i.UpdatedAt = event.CreatedAt
+	return nil
 }
 
 // Event represents a domain event related to a catalog item.
 type Event struct {
-	Type string      `json:"type"`
-	Data interface{} `json:"data"`
+	Type      string    `json:"type"`
+	Version   int       `json:"version"`
+	Timestamp time.Time `json:"timestamp"`
+	// Actor is the member who caused the event, eventstore.SystemActorID
+	// for one the system wrote on its own behalf, or empty for events
+	// recorded before actors were.
+	Actor string      `json:"actor,omitempty"`
+	Data  interface{} `json:"data"`
 }
 
 // ItemAddedEvent is published when a new item is added.
 type ItemAddedEvent struct {
-	ID            uuid.UUID `json:"id"`
-	ISBN          string    `json:"isbn"`
-	Title         string    `json:"title"`
-	Author        string    `json:"author"`
-	TotalCopies   int       `json:"total_copies"`
+	ID          uuid.UUID `json:"id"`
+	ISBN        string    `json:"isbn"`
+	Title       string    `json:"title"`
+	Author      string    `json:"author"`
+	Category    string    `json:"category,omitempty"`
+	TotalCopies int       `json:"total_copies"`
 }
 
 // ItemCopiesUpdatedEvent is published when the number of copies changes.
 type ItemCopiesUpdatedEvent struct {
-	ID          uuid.UUID `json:"id"`
-	NewTotal    int       `json:"new_total"`
-	NewAvailable int      `json:"new_available"`
+	ID           uuid.UUID `json:"id"`
+	NewTotal     int       `json:"new_total"`
+	NewAvailable int       `json:"new_available"`
+}
+
+// CopiesAdjustedEvent is published when staff add or write off copies, such
+// as copies lost or damaged. It records the change, why it was made, and the
+// counts it left.
+type CopiesAdjustedEvent struct {
+	ID             uuid.UUID `json:"id"`
+	TotalDelta     int       `json:"total_delta"`
+	AvailableDelta int       `json:"available_delta"`
+	NewTotal       int       `json:"new_total"`
+	NewAvailable   int       `json:"new_available"`
+	Reason         string    `json:"reason"`
+}
+
+// ItemDetailsUpdatedEvent is published when an item's bibliographic details
+// are corrected. It carries only the fields that changed.
+type ItemDetailsUpdatedEvent struct {
+	ID            uuid.UUID `json:"id"`
+	Title         *string   `json:"title,omitempty"`
+	Author        *string   `json:"author,omitempty"`
+	Publisher     *string   `json:"publisher,omitempty"`
+	PublishedYear *int      `json:"published_year,omitempty"`
+}
+
+func (e ItemDetailsUpdatedEvent) applyTo(i *Item) {
+	if e.Title != nil {
+		i.Title = *e.Title
+	}
+	if e.Author != nil {
+		i.Author = *e.Author
+	}
+	if e.Publisher != nil {
+		i.Publisher = *e.Publisher
+	}
+	if e.PublishedYear != nil {
+		i.PublishedYear = *e.PublishedYear
+	}
+}
+
+// ItemCopyReservedEvent is published when a single copy is taken out of the
+// available pool. It records a decrement rather than absolute counts, so
+// concurrent reservations fold to the same state in any order.
+type ItemCopyReservedEvent struct {
+	ID uuid.UUID `json:"id"`
+}
+
+// ItemCopyReleasedEvent is published when a single copy is put back into the
+// available pool, undoing an ItemCopyReservedEvent.
+type ItemCopyReleasedEvent struct {
+	ID uuid.UUID `json:"id"`
 }
 
 // ItemRemovedEvent is published when an item is retired from the catalog.
@@ -50,3 +418,8 @@ type ItemRemovedEvent struct {
 	ID     uuid.UUID `json:"id"`
 	Status string    `json:"status"`
 }
+
+// ItemRestoredEvent is published when a retired item returns to circulation.
+type ItemRestoredEvent struct {
+	ID uuid.UUID `json:"id"`
+}
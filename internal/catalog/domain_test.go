@@ -0,0 +1,232 @@
+package catalog
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func itemEvent(t testing.TB, id uuid.UUID, eventType string, version int, data interface{}) eventstore.Event {
+	t.Helper()
+	jsonData, err := json.Marshal(data)
+	require.NoError(t, err)
+	return eventstore.Event{
+		AggregateID:   id,
+		AggregateType: "item",
+		EventType:     eventType,
+		EventData:     jsonData,
+		Version:       version,
+		CreatedAt:     time.Date(2024, 1, version, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestItemApplyFoldsEvents(t *testing.T) {
+	id := uuid.New()
+	events := []eventstore.Event{
+		itemEvent(t, id, "ItemAdded", 1, ItemAddedEvent{ID: id, ISBN: "9780141439518", Title: "Pride and Prejudice", Author: "Jane Austen", TotalCopies: 5}),
+		itemEvent(t, id, "ItemCopiesUpdated", 2, ItemCopiesUpdatedEvent{ID: id, NewTotal: 5, NewAvailable: 4}),
+		itemEvent(t, id, "ItemCopiesUpdated", 3, ItemCopiesUpdatedEvent{ID: id, NewTotal: 6, NewAvailable: 5}),
+		itemEvent(t, id, "ItemCopyReserved", 4, ItemCopyReservedEvent{ID: id}),
+		itemEvent(t, id, "ItemCopyReserved", 5, ItemCopyReservedEvent{ID: id}),
+		itemEvent(t, id, "ItemCopyReleased", 6, ItemCopyReleasedEvent{ID: id}),
+		itemEvent(t, id, "ItemRemoved", 7, ItemRemovedEvent{ID: id, Status: "retired"}),
+	}
+
+	item := &Item{}
+	for _, event := range events {
+		require.NoError(t, item.Apply(event))
+	}
+
+	assert.Equal(t, id, item.ID)
+	assert.Equal(t, "Pride and Prejudice", item.Title)
+	assert.Equal(t, 6, item.TotalCopies)
+	assert.Equal(t, 4, item.Available)
+	assert.Equal(t, "retired", item.Status)
+	assert.Equal(t, 7, item.Version)
+	assert.Equal(t, events[0].CreatedAt, item.CreatedAt)
+	assert.Equal(t, events[6].CreatedAt, item.UpdatedAt)
+}
+
+func TestItemApplyResumesFromSnapshotState(t *testing.T) {
+	id := uuid.New()
+	snapshot := &Item{ID: id, Title: "The Great Gatsby", TotalCopies: 3, Available: 1, Status: "active", Version: 7}
+
+	state, err := json.Marshal(snapshot)
+	require.NoError(t, err)
+	item := &Item{}
+	require.NoError(t, json.Unmarshal(state, item))
+
+	require.NoError(t, item.Apply(itemEvent(t, id, "ItemCopiesUpdated", 8, ItemCopiesUpdatedEvent{ID: id, NewTotal: 3, NewAvailable: 2})))
+
+	assert.Equal(t, "The Great Gatsby", item.Title)
+	assert.Equal(t, 2, item.Available)
+	assert.Equal(t, 8, item.Version)
+}
+
+func TestItemApplyRestoresRetiredItem(t *testing.T) {
+	id := uuid.New()
+	item := &Item{ID: id, Status: "active", Version: 1}
+
+	require.NoError(t, item.Apply(itemEvent(t, id, "ItemRemoved", 2, ItemRemovedEvent{ID: id, Status: "retired"})))
+	require.NoError(t, item.Apply(itemEvent(t, id, "ItemRestored", 3, ItemRestoredEvent{ID: id})))
+
+	assert.Equal(t, "active", item.Status)
+	assert.Equal(t, 3, item.Version)
+}
+
+func TestItemApplyRejectsUnknownEvent(t *testing.T) {
+	item := &Item{}
+	err := item.Apply(itemEvent(t, uuid.New(), "ItemTeleported", 1, struct{}{}))
+	assert.Error(t, err)
+}
+
+func TestSearchParamsNormalize(t *testing.T) {
+	tests := []struct {
+		name       string
+		params     SearchParams
+		wantErr    bool
+		wantLimit  int
+		wantOffset int
+	}{
+		{"defaults limit", SearchParams{Query: "austen"}, false, defaultSearchLimit, 0},
+		{"keeps limit", SearchParams{Query: "austen", Limit: 25, Offset: 50}, false, 25, 50},
+		{"caps limit", SearchParams{Query: "austen", Limit: 1000}, false, maxSearchLimit, 0},
+		{"rejects negative offset", SearchParams{Query: "austen", Offset: -1}, true, 0, 0},
+		{"rejects negative limit", SearchParams{Query: "austen", Limit: -5}, true, 0, 0},
+		{"rejects missing query", SearchParams{}, true, 0, 0},
+		{"rejects blank query", SearchParams{Query: "   "}, true, 0, 0},
+		{"accepts allowed sort", SearchParams{Query: "austen", Sort: "-published_year"}, false, defaultSearchLimit, 0},
+		{"rejects unknown sort", SearchParams{Query: "austen", Sort: "title; DROP TABLE items"}, true, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := tt.params
+			err := params.normalize()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidSearchParams)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantLimit, params.Limit)
+			assert.Equal(t, tt.wantOffset, params.Offset)
+		})
+	}
+}
+
+// itemHistory is an item added at version 1 whose copies then change once per
+// event, up to the given version.
+func itemHistory(tb testing.TB, id uuid.UUID, versions int) []eventstore.Event {
+	events := []eventstore.Event{itemEvent(tb, id, "ItemAdded", 1, ItemAddedEvent{ID: id, ISBN: "9780141439518", Title: "Pride and Prejudice", Author: "Jane Austen", TotalCopies: 5})}
+	for v := 2; v <= versions; v++ {
+		events = append(events, itemEvent(tb, id, "ItemCopiesUpdated", v, ItemCopiesUpdatedEvent{ID: id, NewTotal: 5, NewAvailable: v % 5}))
+	}
+	return events
+}
+
+// The replay benchmarks fold a 1000-event item from nothing and from a
+// snapshot taken DefaultSnapshotInterval events back. They leave out loading
+// the events, which in production scales the same way and costs far more.
+func BenchmarkReplayFromZero(b *testing.B) {
+	events := itemHistory(b, uuid.New(), 1000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		item := &Item{}
+		for _, event := range events {
+			if err := item.Apply(event); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkReplayFromSnapshot(b *testing.B) {
+	events := itemHistory(b, uuid.New(), 1000)
+	tail := len(events) - DefaultSnapshotInterval
+	snapshot := &Item{}
+	for _, event := range events[:tail] {
+		require.NoError(b, snapshot.Apply(event))
+	}
+	state, err := json.Marshal(snapshot)
+	require.NoError(b, err)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		item := &Item{}
+		if err := json.Unmarshal(state, item); err != nil {
+			b.Fatal(err)
+		}
+		for _, event := range events[tail:] {
+			if err := item.Apply(event); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestReplayFromSnapshotMatchesFullReplay(t *testing.T) {
+	id := uuid.New()
+	events := itemHistory(t, id, 120)
+
+	full := &Item{}
+	for _, event := range events {
+		require.NoError(t, full.Apply(event))
+	}
+
+	snapshot := &Item{}
+	for _, event := range events[:100] {
+		require.NoError(t, snapshot.Apply(event))
+	}
+	state, err := json.Marshal(snapshot)
+	require.NoError(t, err)
+	resumed := &Item{}
+	require.NoError(t, json.Unmarshal(state, resumed))
+	for _, event := range events[100:] {
+		require.NoError(t, resumed.Apply(event))
+	}
+
+	assert.Equal(t, full, resumed)
+}
+
+func TestItemAsOfFoldsEventsRecordedByThen(t *testing.T) {
+	id := uuid.New()
+	events := []eventstore.Event{
+		itemEvent(t, id, "ItemAdded", 1, ItemAddedEvent{ID: id, ISBN: "9780141439518", Title: "Emma", Author: "Jane Austen", TotalCopies: 2}),
+		itemEvent(t, id, "ItemCopyReserved", 2, ItemCopyReservedEvent{ID: id}),
+		itemEvent(t, id, "ItemCopyReserved", 3, ItemCopyReservedEvent{ID: id}),
+	}
+
+	tests := []struct {
+		name          string
+		asOf          time.Time
+		wantVersion   int
+		wantAvailable int
+	}{
+		{"before it was added", time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC), 0, 0},
+		{"the moment it was added", events[0].CreatedAt, 1, 2},
+		{"between events", events[1].CreatedAt.Add(time.Hour), 2, 1},
+		{"after the latest event", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), 3, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			past := eventsAsOf(events, tt.asOf)
+			if tt.wantVersion == 0 {
+				assert.Empty(t, past)
+				return
+			}
+			item, err := foldItem(past)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantVersion, item.Version)
+			assert.Equal(t, tt.wantAvailable, item.Available)
+		})
+	}
+}
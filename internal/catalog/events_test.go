@@ -0,0 +1,36 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetItemEventsRejectsInvalidRanges(t *testing.T) {
+	s := &service{}
+	for _, r := range [][2]int{{-1, 0}, {0, -1}, {5, 2}} {
+		_, err := s.GetItemEvents(context.Background(), uuid.New(), r[0], r[1])
+		assert.ErrorIs(t, err, ErrInvalidEventRange, "from %d to %d", r[0], r[1])
+	}
+}
+
+func TestAuditEvents(t *testing.T) {
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	events := auditEvents([]eventstore.Event{
+		{EventType: "ItemAdded", Version: 1, CreatedAt: at, EventData: json.RawMessage(`{"title":"Emma"}`)},
+		{EventType: "ItemCopyReserved", Version: 2, CreatedAt: at.Add(time.Hour), EventData: json.RawMessage(`{}`),
+			Metadata: map[string]interface{}{eventstore.MetadataActorID: eventstore.SystemActorID}},
+	})
+
+	b, err := json.Marshal(events)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"type": "ItemAdded", "version": 1, "timestamp": "2025-03-01T12:00:00Z", "data": {"title": "Emma"}},
+		{"type": "ItemCopyReserved", "version": 2, "timestamp": "2025-03-01T13:00:00Z", "actor": "system", "data": {}}
+	]`, string(b))
+}
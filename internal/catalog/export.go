@@ -0,0 +1,67 @@
+// internal/catalog/export.go
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"libranexus/internal/export"
+	"strconv"
+	"time"
+)
+
+// itemColumns heads a CSV export of the catalog.
+var itemColumns = []string{
+	"id", "isbn", "title", "author", "publisher", "published_year", "category",
+	"total_copies", "available", "status", "version", "created_at", "updated_at",
+}
+
+// itemRow is an item as one row of an export.
+type itemRow struct {
+	*Item
+}
+
+func (r itemRow) CSVRecord() []string {
+	year := ""
+	if r.PublishedYear != 0 {
+		year = strconv.Itoa(r.PublishedYear)
+	}
+	return []string{
+		r.ID.String(), r.ISBN, r.Title, r.Author, r.Publisher, year, r.Category,
+		strconv.Itoa(r.TotalCopies), strconv.Itoa(r.Available), r.Status, strconv.Itoa(r.Version),
+		export.Time(r.CreatedAt), export.Time(r.UpdatedAt),
+	}
+}
+
+// ExportItems calls fn with every item changed at or after since, retired
+// ones included, in the order they changed. Items are read one at a time, so
+// fn can stream them out; an error from fn stops the export and is returned.
+// Passing the time an earlier export started picks up where it left off.
+func (s *service) ExportItems(ctx context.Context, since time.Time, fn func(*Item) error) error {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, isbn, title, author, COALESCE(publisher, ''), COALESCE(published_year, 0),
+		       category, total_copies, available, status, version, created_at, updated_at
+		FROM items
+		WHERE updated_at >= $1
+		ORDER BY updated_at, id
+	`, since)
+	if err != nil {
+		return fmt.Errorf("failed to export items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item := &Item{}
+		err := rows.Scan(&item.ID, &item.ISBN, &item.Title, &item.Author, &item.Publisher, &item.PublishedYear,
+			&item.Category, &item.TotalCopies, &item.Available, &item.Status, &item.Version, &item.CreatedAt, &item.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to scan item: %w", err)
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to export items: %w", err)
+	}
+	return nil
+}
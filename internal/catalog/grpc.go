@@ -0,0 +1,55 @@
+// internal/catalog/grpc.go
+package catalog
+
+import (
+	"context"
+	"libranexus/internal/rpc"
+	"libranexus/internal/rpc/catalogpb"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// RegisterGRPC serves svc's item reads on s, for services that call the
+// catalog over gRPC instead of its JSON API.
+func RegisterGRPC(s grpc.ServiceRegistrar, svc Service) {
+	catalogpb.RegisterCatalogServer(s, &grpcServer{service: svc})
+}
+
+type grpcServer struct {
+	catalogpb.UnimplementedCatalogServer
+	service Service
+}
+
+func (g *grpcServer) GetItem(ctx context.Context, req *catalogpb.GetItemRequest) (*catalogpb.Item, error) {
+	id, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid item ID")
+	}
+	item, err := g.service.GetItem(ctx, id, req.GetIncludeRetired())
+	if err != nil {
+		return nil, rpc.Error(err, ErrItemNotFound)
+	}
+	return itemToProto(item), nil
+}
+
+func itemToProto(item *Item) *catalogpb.Item {
+	return &catalogpb.Item{
+		Id:            item.ID.String(),
+		Isbn:          item.ISBN,
+		Title:         item.Title,
+		Author:        item.Author,
+		Publisher:     item.Publisher,
+		PublishedYear: int32(item.PublishedYear),
+		Category:      item.Category,
+		TotalCopies:   int32(item.TotalCopies),
+		Available:     int32(item.Available),
+		Status:        item.Status,
+		Version:       int32(item.Version),
+		CreatedAt:     timestamppb.New(item.CreatedAt),
+		UpdatedAt:     timestamppb.New(item.UpdatedAt),
+	}
+}
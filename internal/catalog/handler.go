@@ -2,13 +2,51 @@
 package catalog
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"libranexus/internal/export"
+	"libranexus/internal/httperr"
+	"libranexus/internal/logging"
+	"libranexus/internal/validate"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/google/uuid"
 )
 
+const (
+	// maxImportLineSize bounds a single line of a newline-delimited bulk import.
+	maxImportLineSize = 1 << 20
+	// maxImportBodySize bounds a whole bulk import, which may run well past
+	// httperr.MaxBodyBytes.
+	maxImportBodySize = 64 << 20
+)
+
+// errorRules maps the catalog's errors to HTTP responses.
+var errorRules = httperr.Rules{
+	{Err: ErrItemNotFound, Status: http.StatusNotFound, Code: "item_not_found"},
+	{Err: ErrNoItemState, Status: http.StatusNotFound, Code: "no_item_state"},
+	{Err: ErrInvalidISBN, Status: http.StatusBadRequest, Code: "invalid_isbn"},
+	{Err: ErrInvalidItem, Status: http.StatusBadRequest, Code: "invalid_item"},
+	{Err: ErrInvalidItemDetails, Status: http.StatusBadRequest, Code: "invalid_item_details"},
+	{Err: ErrInvalidCopyCounts, Status: http.StatusBadRequest, Code: "invalid_copy_counts"},
+	{Err: ErrInvalidAdjustment, Status: http.StatusBadRequest, Code: "invalid_adjustment"},
+	{Err: ErrInvalidSearchParams, Status: http.StatusBadRequest, Code: "invalid_search"},
+	{Err: ErrInvalidEventRange, Status: http.StatusBadRequest, Code: "invalid_event_range"},
+	{Err: ErrDuplicateISBN, Status: http.StatusConflict, Code: "duplicate_isbn"},
+	{Err: ErrVersionConflict, Status: http.StatusConflict, Code: "version_conflict"},
+	{Err: ErrNoCopiesAvailable, Status: http.StatusConflict, Code: "no_copies_available"},
+	{Err: ErrAllCopiesAvailable, Status: http.StatusConflict, Code: "all_copies_available"},
+	{Err: ErrItemNotRetired, Status: http.StatusConflict, Code: "item_not_retired"},
+}
+
 type Handler struct {
 	service Service
 }
@@ -19,52 +57,248 @@ func NewHandler(service Service) *Handler {
 
 func (h *Handler) HandleItems(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
+	case http.MethodGet:
+		h.handleListItems(w, r)
 	case http.MethodPost:
 		h.handleAddItem(w, r)
 	default:
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		httperr.MethodNotAllowed(w)
 	}
 }
 
 func (h *Handler) HandleItem(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/items/")
+	idStr, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/items/"), "/")
+	if idStr == "bulk" && action == "" {
+		if r.Method != http.MethodPost {
+			httperr.MethodNotAllowed(w)
+			return
+		}
+		h.handleBulkImport(w, r)
+		return
+	}
+
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		http.Error(w, "invalid item ID", http.StatusBadRequest)
+		httperr.BadRequest(w, "invalid item ID")
 		return
 	}
 
-	switch r.Method {
-	case http.MethodGet:
-		h.handleGetItem(w, r, id)
-	case http.MethodPatch:
-		h.handleUpdateItemCopies(w, r, id)
-	case http.MethodDelete:
-		h.handleRemoveItem(w, r, id)
+	switch action {
+	case "":
+		switch r.Method {
+		case http.MethodGet:
+			h.handleGetItem(w, r, id)
+		case http.MethodPatch:
+			h.handleUpdateItemCopies(w, r, id)
+		case http.MethodDelete:
+			h.handleRemoveItem(w, r, id)
+		default:
+			httperr.MethodNotAllowed(w)
+		}
+	case "events":
+		if r.Method != http.MethodGet {
+			httperr.MethodNotAllowed(w)
+			return
+		}
+		h.handleGetItemEvents(w, r, id)
+	case "details":
+		if r.Method != http.MethodPatch {
+			httperr.MethodNotAllowed(w)
+			return
+		}
+		h.handleUpdateItemDetails(w, r, id)
+	case "adjust-copies":
+		if r.Method != http.MethodPost {
+			httperr.MethodNotAllowed(w)
+			return
+		}
+		h.handleAdjustCopies(w, r, id)
+	case "restore":
+		if r.Method != http.MethodPost {
+			httperr.MethodNotAllowed(w)
+			return
+		}
+		h.handleRestoreItem(w, r, id)
+	case "reserve-copy":
+		if r.Method != http.MethodPost {
+			httperr.MethodNotAllowed(w)
+			return
+		}
+		h.handleReserveCopy(w, r, id)
+	case "release-copy":
+		if r.Method != http.MethodPost {
+			httperr.MethodNotAllowed(w)
+			return
+		}
+		h.handleReleaseCopy(w, r, id)
 	default:
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		httperr.NotFound(w)
+	}
+}
+
+// HandleExportItems serves GET /items/export, the whole catalog as a CSV or
+// JSON download, streamed as it is read. A since parameter limits it to
+// items changed at or after that time.
+func (h *Handler) HandleExportItems(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httperr.MethodNotAllowed(w)
+		return
+	}
+
+	q := r.URL.Query()
+	format, err := export.ParseFormat(q.Get("format"))
+	if err != nil {
+		httperr.BadRequest(w, err.Error())
+		return
+	}
+	since, err := export.ParseSince(q.Get("since"))
+	if err != nil {
+		httperr.BadRequest(w, "invalid since")
+		return
+	}
+
+	out := export.NewWriter(w, format, "catalog", itemColumns)
+	err = h.service.ExportItems(r.Context(), since, func(item *Item) error {
+		return out.Write(itemRow{item})
+	})
+	if err == nil {
+		err = out.Close()
+	}
+	if err != nil {
+		if !out.Started() {
+			errorRules.Write(w, err)
+			return
+		}
+		logging.FromContext(r.Context()).Error("catalog export failed", "err", err)
+		out.Abort()
 	}
 }
 
 func (h *Handler) HandleSearch(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		httperr.MethodNotAllowed(w)
+		return
+	}
+
+	q := r.URL.Query()
+	params := SearchParams{
+		Query:  q.Get("q"),
+		Status: q.Get("status"),
+		Author: q.Get("author"),
+		Sort:   q.Get("sort"),
+	}
+	if params.Query == "" {
+		httperr.BadRequest(w, "missing search query")
+		return
+	}
+
+	var err error
+	if params.IncludeRetired, err = boolParam(q.Get("include_retired")); err != nil {
+		httperr.BadRequest(w, "invalid include_retired")
+		return
+	}
+	if params.AvailableOnly, err = boolParam(q.Get("available_only")); err != nil {
+		httperr.BadRequest(w, "invalid available_only")
+		return
+	}
+	if params.Limit, err = intParam(q.Get("limit")); err != nil {
+		httperr.BadRequest(w, "invalid limit")
+		return
+	}
+	if params.Offset, err = intParam(q.Get("offset")); err != nil {
+		httperr.BadRequest(w, "invalid offset")
+		return
+	}
+
+	result, err := h.service.Search(r.Context(), params)
+	if err != nil {
+		errorRules.Write(w, err)
 		return
 	}
 
-	query := r.URL.Query().Get("q")
-	if query == "" {
-		http.Error(w, "missing search query", http.StatusBadRequest)
+	json.NewEncoder(w).Encode(result)
+}
+
+// HandleReconcile reports items whose availability has drifted from the
+// copies circulation has out. With correct=true the drift is also repaired.
+func (h *Handler) HandleReconcile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httperr.MethodNotAllowed(w)
 		return
 	}
 
-	items, err := h.service.Search(r.Context(), query)
+	correct, err := boolParam(r.URL.Query().Get("correct"))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		httperr.BadRequest(w, "invalid correct")
+		return
+	}
+
+	discrepancies, err := h.service.ReconcileAvailability(r.Context(), correct)
+	if err != nil {
+		errorRules.Write(w, err)
+		return
+	}
+
+	resp := struct {
+		Discrepancies []Discrepancy `json:"discrepancies"`
+		Corrected     int           `json:"corrected"`
+	}{Discrepancies: discrepancies}
+	if resp.Discrepancies == nil {
+		resp.Discrepancies = []Discrepancy{}
+	}
+	for _, d := range discrepancies {
+		if d.Corrected {
+			resp.Corrected++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) handleListItems(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	params := ListParams{
+		Status: q.Get("status"),
+		Author: q.Get("author"),
+		Sort:   q.Get("sort"),
+	}
+
+	var err error
+	if params.IncludeRetired, err = boolParam(q.Get("include_retired")); err != nil {
+		httperr.BadRequest(w, "invalid include_retired")
+		return
+	}
+	if params.AvailableOnly, err = boolParam(q.Get("available_only")); err != nil {
+		httperr.BadRequest(w, "invalid available_only")
+		return
+	}
+	if params.Limit, err = intParam(q.Get("limit")); err != nil {
+		httperr.BadRequest(w, "invalid limit")
+		return
+	}
+	if params.Offset, err = intParam(q.Get("offset")); err != nil {
+		httperr.BadRequest(w, "invalid offset")
 		return
 	}
 
-	json.NewEncoder(w).Encode(items)
+	// Normalizing here lets the response echo the page actually served,
+	// after defaults and caps.
+	if err := params.normalize(); err != nil {
+		errorRules.Write(w, err)
+		return
+	}
+	items, total, err := h.service.ListItems(r.Context(), params)
+	if err != nil {
+		errorRules.Write(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(struct {
+		Items  []*Item `json:"items"`
+		Total  int     `json:"total"`
+		Limit  int     `json:"limit"`
+		Offset int     `json:"offset"`
+	}{Items: items, Total: total, Limit: params.Limit, Offset: params.Offset})
 }
 
 func (h *Handler) handleAddItem(w http.ResponseWriter, r *http.Request) {
@@ -72,17 +306,24 @@ func (h *Handler) handleAddItem(w http.ResponseWriter, r *http.Request) {
 		ISBN        string `json:"isbn"`
 		Title       string `json:"title"`
 		Author      string `json:"author"`
+		Category    string `json:"category"`
 		TotalCopies int    `json:"total_copies"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if !httperr.DecodeJSON(w, r, &req) {
+		return
+	}
+	var invalid validate.Fields
+	invalid.Required("isbn", req.ISBN)
+	invalid.Required("title", req.Title)
+	invalid.Required("author", req.Author)
+	if invalid.Reject(w) {
 		return
 	}
 
-	item, err := h.service.AddItem(r.Context(), req.ISBN, req.Title, req.Author, req.TotalCopies)
+	item, err := h.service.AddItem(r.Context(), req.ISBN, req.Title, req.Author, req.Category, req.TotalCopies)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		errorRules.Write(w, err)
 		return
 	}
 
@@ -90,40 +331,274 @@ func (h *Handler) handleAddItem(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(item)
 }
 
+// handleGetItem serves an item's current state, or with as_of (an RFC 3339
+// time) or as_of_version, its state at that point, retired or not.
 func (h *Handler) handleGetItem(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
-	item, err := h.service.GetItem(r.Context(), id)
+	q := r.URL.Query()
+	includeRetired, err := boolParam(q.Get("include_retired"))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		httperr.BadRequest(w, "invalid include_retired")
+		return
+	}
+
+	var item *Item
+	switch asOf, asOfVersion := q.Get("as_of"), q.Get("as_of_version"); {
+	case asOf != "" && asOfVersion != "":
+		httperr.BadRequest(w, "as_of and as_of_version cannot be combined")
+		return
+	case asOf != "":
+		t, perr := time.Parse(time.RFC3339, asOf)
+		if perr != nil {
+			httperr.BadRequest(w, "invalid as_of")
+			return
+		}
+		item, err = h.service.GetItemAsOf(r.Context(), id, t)
+	case asOfVersion != "":
+		version, perr := strconv.Atoi(asOfVersion)
+		if perr != nil {
+			httperr.BadRequest(w, "invalid as_of_version")
+			return
+		}
+		item, err = h.service.GetItemAtVersion(r.Context(), id, version)
+	default:
+		item, err = h.service.GetItem(r.Context(), id, includeRetired)
+	}
+	if err != nil {
+		errorRules.Write(w, err)
 		return
 	}
 
 	json.NewEncoder(w).Encode(item)
 }
 
+// handleGetItemEvents serves an item's event history for auditing, limited
+// by the optional from_version and to_version parameters.
+func (h *Handler) handleGetItemEvents(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	q := r.URL.Query()
+	fromVersion, err := intParam(q.Get("from_version"))
+	if err != nil {
+		httperr.BadRequest(w, "invalid from_version")
+		return
+	}
+	toVersion, err := intParam(q.Get("to_version"))
+	if err != nil {
+		httperr.BadRequest(w, "invalid to_version")
+		return
+	}
+
+	events, err := h.service.GetItemEvents(r.Context(), id, fromVersion, toVersion)
+	if err != nil {
+		errorRules.Write(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(struct {
+		Events []Event `json:"events"`
+	}{Events: events})
+}
+
 func (h *Handler) handleUpdateItemCopies(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
 	var req struct {
-		TotalCopies int `json:"total_copies"`
-		Available   int `json:"available"`
+		TotalCopies     int `json:"total_copies"`
+		Available       int `json:"available"`
+		ExpectedVersion int `json:"expected_version"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if !httperr.DecodeJSON(w, r, &req) {
 		return
 	}
 
-	if err := h.service.UpdateItemCopies(r.Context(), id, req.TotalCopies, req.Available); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.service.UpdateItemCopies(r.Context(), id, req.TotalCopies, req.Available, req.ExpectedVersion); err != nil {
+		errorRules.Write(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
 
+func (h *Handler) handleAdjustCopies(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	var req struct {
+		TotalDelta     int    `json:"total_delta"`
+		AvailableDelta int    `json:"available_delta"`
+		Reason         string `json:"reason"`
+	}
+	if !httperr.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	item, err := h.service.AdjustCopies(r.Context(), id, req.TotalDelta, req.AvailableDelta, req.Reason)
+	if err != nil {
+		errorRules.Write(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(item)
+}
+
+func (h *Handler) handleUpdateItemDetails(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	var patch ItemDetailsPatch
+	if !httperr.DecodeJSON(w, r, &patch) {
+		return
+	}
+
+	item, err := h.service.UpdateItemDetails(r.Context(), id, patch)
+	if err != nil {
+		errorRules.Write(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(item)
+}
+
+// handleBulkImport accepts a JSON array of items or newline-delimited JSON,
+// one item per line, and reports the outcome of every row.
+func (h *Handler) handleBulkImport(w http.ResponseWriter, r *http.Request) {
+	items, err := decodeNewItems(http.MaxBytesReader(w, r.Body, maxImportBodySize))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		httperr.Error(w, http.StatusRequestEntityTooLarge, "body_too_large", fmt.Sprintf("import exceeds %d bytes", tooLarge.Limit))
+		return
+	}
+	if err != nil {
+		httperr.BadRequest(w, err.Error())
+		return
+	}
+	if len(items) == 0 {
+		httperr.BadRequest(w, "no items to import")
+		return
+	}
+
+	results, err := h.service.AddItems(r.Context(), items)
+	if err != nil {
+		errorRules.Write(w, err)
+		return
+	}
+
+	resp := struct {
+		Succeeded int            `json:"succeeded"`
+		Failed    int            `json:"failed"`
+		Results   []ImportResult `json:"results"`
+	}{Results: results}
+	for _, result := range results {
+		if result.Status == ImportCreated {
+			resp.Succeeded++
+		} else {
+			resp.Failed++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// decodeNewItems reads a bulk-import body. A body starting with '[' is a JSON
+// array; anything else is read as newline-delimited JSON, skipping blank lines.
+func decodeNewItems(r io.Reader) ([]NewItem, error) {
+	br := bufio.NewReader(r)
+	first, err := peekNonSpace(br)
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var items []NewItem
+	if first == '[' {
+		dec := json.NewDecoder(br)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&items); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("invalid JSON array: %s", httperr.DescribeDecodeError(err))
+		}
+		return items, nil
+	}
+
+	scanner := bufio.NewScanner(br)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var item NewItem
+		if err := httperr.StrictUnmarshal(text, &item); err != nil {
+			return nil, fmt.Errorf("invalid JSON on line %d: %s", line, httperr.DescribeDecodeError(err))
+		}
+		items = append(items, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// peekNonSpace skips leading whitespace and returns the next byte without
+// consuming it.
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		if !unicode.IsSpace(rune(b[0])) {
+			return b[0], nil
+		}
+		br.ReadByte()
+	}
+}
+
+func (h *Handler) handleReserveCopy(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	if err := h.service.ReserveCopy(r.Context(), id); err != nil {
+		errorRules.Write(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) handleReleaseCopy(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	if err := h.service.ReleaseCopy(r.Context(), id); err != nil {
+		errorRules.Write(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (h *Handler) handleRemoveItem(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
 	if err := h.service.RemoveItem(r.Context(), id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		errorRules.Write(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) handleRestoreItem(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	if err := h.service.RestoreItem(r.Context(), id); err != nil {
+		errorRules.Write(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// boolParam parses an optional boolean query parameter, treating empty as false.
+func boolParam(v string) (bool, error) {
+	if v == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(v)
+}
+
+// intParam parses an optional integer query parameter, treating empty as zero.
+func intParam(v string) (int, error) {
+	if v == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(v)
+}
@@ -0,0 +1,60 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type addingService struct {
+	Service
+	added int
+}
+
+func (a *addingService) AddItem(ctx context.Context, isbn, title, author, category string, totalCopies int) (*Item, error) {
+	a.added++
+	return &Item{ISBN: isbn, Title: title, Author: author, TotalCopies: totalCopies, Available: totalCopies}, nil
+}
+
+func TestHandleAddItemRequiredFields(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantFields map[string]string
+	}{
+		{"valid", `{"isbn":"9780141439518","title":"Emma","author":"Jane Austen","total_copies":2}`, http.StatusCreated, nil},
+		{"missing isbn", `{"title":"Emma","author":"Jane Austen","total_copies":2}`, http.StatusBadRequest, map[string]string{"isbn": "is required"}},
+		{"blank title", `{"isbn":"9780141439518","title":" ","author":"Jane Austen","total_copies":2}`, http.StatusBadRequest, map[string]string{"title": "is required"}},
+		{"missing author", `{"isbn":"9780141439518","title":"Emma","total_copies":2}`, http.StatusBadRequest, map[string]string{"author": "is required"}},
+		{"empty object", `{}`, http.StatusBadRequest, map[string]string{"isbn": "is required", "title": "is required", "author": "is required"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &addingService{}
+			rec := httptest.NewRecorder()
+			NewHandler(svc).HandleItems(rec, httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantFields == nil {
+				assert.Equal(t, 1, svc.added)
+				return
+			}
+			assert.Equal(t, 0, svc.added, "an invalid request should not reach the service")
+			var resp struct {
+				Code   string            `json:"code"`
+				Fields map[string]string `json:"fields"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, "invalid_fields", resp.Code)
+			assert.Equal(t, tt.wantFields, resp.Fields)
+		})
+	}
+}
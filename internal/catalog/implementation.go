@@ -5,34 +5,122 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/jules-labs/go-eventstore"
+	"libranexus/internal/cache"
+	"libranexus/internal/database"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+	"github.com/lib/pq"
 )
 
+// maxAppendRetries bounds how often a conflicting append is retried.
+const maxAppendRetries = 5
+
+// DefaultSnapshotInterval is how many events an item may accumulate beyond
+// its latest snapshot before a fresh one is saved.
+const DefaultSnapshotInterval = 50
+
+// defaultImportBatchSize is how many bulk-import rows share a transaction.
+const defaultImportBatchSize = 100
+
+// DefaultMinCopies is the fewest copies a new item may be added with unless
+// configured otherwise. An item with none could never be checked out.
+const DefaultMinCopies = 1
+
 // service implements the Service interface.
 type service struct {
-	eventStore *eventstore.EventStore
-	db         *sql.DB
+	eventStore       *eventstore.EventStore
+	db               *sql.DB
+	snapshotInterval int
+	importBatchSize  int
+	minCopies        int
+	searchBackend    SearchBackend
+	copyCounter      CopyCounter
+	reconcileGrace   time.Duration
+	searchCache      searchCache
+	itemCache        *cache.LRU[uuid.UUID, Item]
+}
+
+// Option configures optional catalog service behaviour.
+type Option func(*service)
+
+// WithSnapshotInterval sets how often items are snapshotted: whenever a write
+// takes an item's version past a multiple of n, and whenever reconstitution
+// has to replay n or more events. Zero disables snapshotting.
+func WithSnapshotInterval(n int) Option {
+	return func(s *service) {
+		s.snapshotInterval = n
+	}
+}
+
+// WithImportBatchSize sets how many bulk-import rows are committed together.
+func WithImportBatchSize(n int) Option {
+	return func(s *service) {
+		if n > 0 {
+			s.importBatchSize = n
+		}
+	}
+}
+
+// WithMinCopies sets the fewest copies AddItem and bulk imports accept for
+// a new item. Zero allows items to be catalogued before any copies arrive;
+// a negative n is ignored.
+func WithMinCopies(n int) Option {
+	return func(s *service) {
+		if n >= 0 {
+			s.minCopies = n
+		}
+	}
 }
 
 // NewService creates a new catalog service instance.
-func NewService(es *eventstore.EventStore, db *sql.DB) Service {
-	return &service{
-		eventStore: es,
-		db:         db,
+func NewService(es *eventstore.EventStore, db *sql.DB, opts ...Option) Service {
+	s := &service{
+		eventStore:       es,
+		db:               db,
+		snapshotInterval: DefaultSnapshotInterval,
+		importBatchSize:  defaultImportBatchSize,
+		minCopies:        DefaultMinCopies,
+		copyCounter:      readModelCounter{db: db},
+		reconcileGrace:   defaultReconcileGrace,
+		searchCache:      searchCache{ttl: DefaultSearchCacheTTL},
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 // AddItem creates a new item in the catalog.
-func (s *service) AddItem(ctx context.Context, isbn, title, author string, totalCopies int) (*Item, error) {
+// The ISBN is normalized to ISBN-13 and must not already be in the catalog.
+func (s *service) AddItem(ctx context.Context, isbn, title, author, category string, totalCopies int) (*Item, error) {
+	isbn, err := NormalizeISBN(isbn)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkNewCopies(totalCopies, s.minCopies); err != nil {
+		return nil, err
+	}
+	category = NormalizeCategory(category)
+
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM items WHERE isbn = $1)`, isbn).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check ISBN: %w", err)
+	}
+	if exists {
+		return nil, ErrDuplicateISBN
+	}
+
 	id := uuid.New()
 	eventData := ItemAddedEvent{
 		ID:          id,
 		ISBN:        isbn,
 		Title:       title,
 		Author:      author,
+		Category:    category,
 		TotalCopies: totalCopies,
 	}
 
@@ -49,42 +137,75 @@ func (s *service) AddItem(ctx context.Context, isbn, title, author string, total
 		Version:       1,
 	}
 
-	// Append the event to the event store
-	if err := s.eventStore.AppendEvents(ctx, id, "item", 0, []eventstore.Event{event}); err != nil {
-		return nil, fmt.Errorf("failed to append event: %w", err)
-	}
-
-	// Update the read model (this would typically be done by a separate projector)
 	item := &Item{
 		ID:          id,
 		ISBN:        isbn,
 		Title:       title,
 		Author:      author,
+		Category:    category,
 		TotalCopies: totalCopies,
 		Available:   totalCopies,
 		Status:      "active",
 		Version:     1,
 	}
-	if err := s.insertItemIntoReadModel(ctx, item); err != nil {
-		return nil, fmt.Errorf("failed to update read model: %w", err)
+
+	// The event and the read model commit together, so a crash between the
+	// two cannot leave an event with no item or an item with no history.
+	err = database.InTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.appendItemEventsTx(ctx, tx, id, 0, []eventstore.Event{event}); err != nil {
+			return fmt.Errorf("failed to append event: %w", err)
+		}
+		if err := insertItemIntoReadModel(ctx, tx, item); err != nil {
+			// Lost a race with a concurrent add of the same ISBN.
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+				return ErrDuplicateISBN
+			}
+			return fmt.Errorf("failed to update read model: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	s.indexItems(ctx, item)
 
 	return item, nil
 }
 
-func (s *service) insertItemIntoReadModel(ctx context.Context, item *Item) error {
+// execer is satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func insertItemIntoReadModel(ctx context.Context, db execer, item *Item) error {
 	query := `
-		INSERT INTO items (id, isbn, title, author, total_copies, available, status, version)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO items (id, isbn, title, author, category, total_copies, available, status, version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
-	_, err := s.db.ExecContext(ctx, query, item.ID, item.ISBN, item.Title, item.Author, item.TotalCopies, item.Available, item.Status, item.Version)
+	_, err := db.ExecContext(ctx, query, item.ID, item.ISBN, item.Title, item.Author, item.Category, item.TotalCopies, item.Available, item.Status, item.Version)
 	return err
 }
 
-// GetItem retrieves an item from the catalog by its ID.
-func (s *service) GetItem(ctx context.Context, id uuid.UUID) (*Item, error) {
+// GetItem retrieves an item from the catalog by its ID. Retired items are
+// reported as not found unless includeRetired is set.
+func (s *service) GetItem(ctx context.Context, id uuid.UUID, includeRetired bool) (*Item, error) {
+	item, err := s.cachedItem(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if item.Status == "retired" && !includeRetired {
+		return nil, fmt.Errorf("%w: %s", ErrItemNotFound, id)
+	}
+	return item, nil
+}
+
+// readItem reads an item, retired or not, from the read model. Writes read
+// through it rather than GetItem, so they never start from a cached item.
+func (s *service) readItem(ctx context.Context, id uuid.UUID) (*Item, error) {
 	query := `
-		SELECT id, isbn, title, author, total_copies, available, status, version, created_at, updated_at
+		SELECT id, isbn, title, author, COALESCE(publisher, ''), COALESCE(published_year, 0),
+		       category, total_copies, available, status, version, created_at, updated_at
 		FROM items
 		WHERE id = $1
 	`
@@ -94,6 +215,9 @@ func (s *service) GetItem(ctx context.Context, id uuid.UUID) (*Item, error) {
 		&item.ISBN,
 		&item.Title,
 		&item.Author,
+		&item.Publisher,
+		&item.PublishedYear,
+		&item.Category,
 		&item.TotalCopies,
 		&item.Available,
 		&item.Status,
@@ -103,18 +227,23 @@ func (s *service) GetItem(ctx context.Context, id uuid.UUID) (*Item, error) {
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("item with ID %s not found", id)
+			return nil, fmt.Errorf("%w: %s", ErrItemNotFound, id)
 		}
 		return nil, fmt.Errorf("failed to get item from read model: %w", err)
 	}
-
 	return item, nil
 }
 
-// UpdateItemCopies updates the number of copies for an item.
-func (s *service) UpdateItemCopies(ctx context.Context, id uuid.UUID, newTotal, newAvailable int) error {
-	item, err := s.GetItem(ctx, id)
-	if err != nil {
+// UpdateItemCopies updates the number of copies for an item. A positive
+// expectedVersion makes the update conditional: it fails with
+// ErrVersionConflict unless the item is still at that version. Zero applies
+// the update against whatever the latest version is. Staff changes should go
+// through AdjustCopies, which records why the counts changed.
+func (s *service) UpdateItemCopies(ctx context.Context, id uuid.UUID, newTotal, newAvailable, expectedVersion int) error {
+	if err := checkCopyCounts(newTotal, newAvailable); err != nil {
+		return err
+	}
+	if _, err := s.GetItem(ctx, id, true); err != nil {
 		return err
 	}
 
@@ -129,31 +258,143 @@ func (s *service) UpdateItemCopies(ctx context.Context, id uuid.UUID, newTotal,
 		return fmt.Errorf("failed to marshal event data: %w", err)
 	}
 
-	event := eventstore.Event{
-		AggregateID:   id,
-		AggregateType: "item",
-		EventType:     "ItemCopiesUpdated",
-		EventData:     jsonData,
-		Version:       item.Version + 1,
+	var appendedAt int
+	apply := func(currentVersion int) error {
+		appendedAt = currentVersion
+		newVersion := currentVersion + 1
+		event := eventstore.Event{
+			AggregateID:   id,
+			AggregateType: "item",
+			EventType:     "ItemCopiesUpdated",
+			EventData:     jsonData,
+			Version:       newVersion,
+		}
+		return database.InTx(ctx, s.db, func(tx *sql.Tx) error {
+			if err := s.appendItemEventsTx(ctx, tx, id, currentVersion, []eventstore.Event{event}); err != nil {
+				return fmt.Errorf("failed to append event: %w", err)
+			}
+			query := `
+				UPDATE items
+				SET total_copies = $1, available = $2, version = $4, updated_at = NOW()
+				WHERE id = $3 AND version < $4
+			`
+			if _, err := tx.ExecContext(ctx, query, newTotal, newAvailable, id, newVersion); err != nil {
+				return fmt.Errorf("failed to update read model: %w", err)
+			}
+			return nil
+		})
 	}
 
-	if err := s.eventStore.AppendEvents(ctx, id, "item", item.Version, []eventstore.Event{event}); err != nil {
-		return fmt.Errorf("failed to append event: %w", err)
+	if expectedVersion > 0 {
+		err = apply(expectedVersion)
+		if errors.Is(err, eventstore.ErrConcurrencyConflict) {
+			return ErrVersionConflict
+		}
+	} else {
+		// Concurrent updates to the same item are retried against the latest version
+		// rather than failing the caller on the first conflict.
+		err = eventstore.RetryOnConflict(ctx, maxAppendRetries, func() error {
+			version, err := s.eventStore.GetCurrentVersion(ctx, id)
+			if err != nil {
+				return err
+			}
+			return apply(version)
+		})
 	}
+	if err != nil {
+		return err
+	}
+	s.snapshotIfDue(ctx, id, appendedAt, appendedAt+1)
+	s.reindexItem(ctx, id)
+	return nil
+}
 
-	// Update read model
-	query := `
+// ReserveCopy atomically takes one copy out of the available pool. The
+// decrement and the availability check happen in a single statement, so two
+// concurrent reservations can never both claim the last copy. It returns
+// ErrNoCopiesAvailable when every copy is already out or the item is not
+// active.
+func (s *service) ReserveCopy(ctx context.Context, id uuid.UUID) error {
+	return s.moveCopy(ctx, id, "ItemCopyReserved", ItemCopyReservedEvent{ID: id}, `
 		UPDATE items
-		SET total_copies = $1, available = $2, version = version + 1, updated_at = NOW()
-		WHERE id = $3 AND version = $4
-	`
-	_, err = s.db.ExecContext(ctx, query, newTotal, newAvailable, id, item.Version)
+		SET available = available - 1, version = $2, updated_at = NOW()
+		WHERE id = $1 AND available > 0 AND status = 'active'
+	`, ErrNoCopiesAvailable)
+}
+
+// ReleaseCopy atomically puts one copy back into the available pool, as
+// ReserveCopy takes one out, so a return never conflicts with reservations
+// of the same item made meanwhile. Copies of retired items can still come
+// back. It returns ErrAllCopiesAvailable when no copy is out.
+func (s *service) ReleaseCopy(ctx context.Context, id uuid.UUID) error {
+	return s.moveCopy(ctx, id, "ItemCopyReleased", ItemCopyReleasedEvent{ID: id}, `
+		UPDATE items
+		SET available = available + 1, version = $2, updated_at = NOW()
+		WHERE id = $1 AND available < total_copies
+	`, ErrAllCopiesAvailable)
+}
+
+// moveCopy runs update, which moves one copy of the item into or out of the
+// available pool at version $2 if its condition holds, and records
+// eventType for it. It returns refused when the condition does not hold.
+func (s *service) moveCopy(ctx context.Context, id uuid.UUID, eventType string, data interface{}, update string, refused error) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	var movedAt int
+	err = eventstore.RetryOnConflict(ctx, maxAppendRetries, func() error {
+		return database.InTx(ctx, s.db, func(tx *sql.Tx) error {
+			// Moves of the item's copies queue on its lock and read the version
+			// only once they hold it, so they follow one another instead of
+			// racing to the same version and retrying. Writers that do not
+			// take the lock can still win the version, hence the retry.
+			return database.WithItemLock(ctx, tx, id, func() error {
+				version, err := s.eventStore.GetCurrentVersionTx(ctx, tx, id)
+				if err != nil {
+					return err
+				}
+				movedAt = version
+				newVersion := version + 1
+
+				res, err := tx.ExecContext(ctx, update, id, newVersion)
+				if err != nil {
+					return fmt.Errorf("failed to move copy: %w", err)
+				}
+				if n, _ := res.RowsAffected(); n == 0 {
+					return refused
+				}
+
+				event := eventstore.Event{
+					AggregateID:   id,
+					AggregateType: "item",
+					EventType:     eventType,
+					EventData:     jsonData,
+					Version:       newVersion,
+				}
+				if err := s.appendItemEventsTx(ctx, tx, id, version, []eventstore.Event{event}); err != nil {
+					return fmt.Errorf("failed to append event: %w", err)
+				}
+				return nil
+			})
+		})
+	})
+	if errors.Is(err, refused) {
+		// The condition failed, or there is no such item.
+		if _, err := s.GetItem(ctx, id, true); err != nil {
+			return err
+		}
+	}
+	if err == nil {
+		s.snapshotIfDue(ctx, id, movedAt, movedAt+1)
+	}
 	return err
 }
 
 // RemoveItem marks an item as retired.
 func (s *service) RemoveItem(ctx context.Context, id uuid.UUID) error {
-	item, err := s.GetItem(ctx, id)
+	item, err := s.readItem(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -176,47 +417,138 @@ func (s *service) RemoveItem(ctx context.Context, id uuid.UUID) error {
 		Version:       item.Version + 1,
 	}
 
-	if err := s.eventStore.AppendEvents(ctx, id, "item", item.Version, []eventstore.Event{event}); err != nil {
-		return fmt.Errorf("failed to append event: %w", err)
+	err = database.InTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.appendItemEventsTx(ctx, tx, id, item.Version, []eventstore.Event{event}); err != nil {
+			return fmt.Errorf("failed to append event: %w", err)
+		}
+		query := `
+			UPDATE items
+			SET status = 'retired', version = version + 1, updated_at = NOW()
+			WHERE id = $1 AND version = $2
+		`
+		if _, err := tx.ExecContext(ctx, query, id, item.Version); err != nil {
+			return fmt.Errorf("failed to update read model: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
-
-	// Update read model
-	query := `
-		UPDATE items
-		SET status = 'retired', version = version + 1, updated_at = NOW()
-		WHERE id = $1 AND version = $2
-	`
-	_, err = s.db.ExecContext(ctx, query, id, item.Version)
-	return err
+	s.snapshotIfDue(ctx, id, item.Version, item.Version+1)
+	s.reindexItem(ctx, id)
+	return nil
 }
 
-// Search finds items in the catalog.
-func (s *service) Search(ctx context.Context, query string) ([]*Item, error) {
-	return s.searchDatabase(ctx, query)
+// RestoreItem returns a retired item to circulation.
+func (s *service) RestoreItem(ctx context.Context, id uuid.UUID) error {
+	item, err := s.readItem(ctx, id)
+	if err != nil {
+		return err
+	}
+	if item.Status != "retired" {
+		return ErrItemNotRetired
+	}
+
+	jsonData, err := json.Marshal(ItemRestoredEvent{ID: id})
+	if err != nil {
+		return fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	event := eventstore.Event{
+		AggregateID:   id,
+		AggregateType: "item",
+		EventType:     "ItemRestored",
+		EventData:     jsonData,
+		Version:       item.Version + 1,
+	}
+
+	err = database.InTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.appendItemEventsTx(ctx, tx, id, item.Version, []eventstore.Event{event}); err != nil {
+			return fmt.Errorf("failed to append event: %w", err)
+		}
+		query := `
+			UPDATE items
+			SET status = 'active', version = version + 1, updated_at = NOW()
+			WHERE id = $1 AND version = $2
+		`
+		if _, err := tx.ExecContext(ctx, query, id, item.Version); err != nil {
+			return fmt.Errorf("failed to update read model: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.snapshotIfDue(ctx, id, item.Version, item.Version+1)
+	s.reindexItem(ctx, id)
+	return nil
 }
 
-func (s *service) searchDatabase(ctx context.Context, query string) ([]*Item, error) {
+func (s *service) searchDatabase(ctx context.Context, params SearchParams) (*SearchResult, error) {
+	where, args := searchFilter(params)
+
+	result := &SearchResult{Items: make([]*Item, 0), Limit: params.Limit, Offset: params.Offset}
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM items"+where, args...).Scan(&result.Total); err != nil {
+		return nil, fmt.Errorf("database search failed: %w", err)
+	}
+
+	// The ORDER BY comes from the searchSorts allowlist, never from the caller.
 	dbQuery := `
-		SELECT id, isbn, title, author, total_copies, available, status
-		FROM items
-		WHERE to_tsvector('english', title) @@ to_tsquery('english', $1)
-		OR to_tsvector('english', author) @@ to_tsquery('english', $1)
-		LIMIT 10
-	`
-	rows, err := s.db.QueryContext(ctx, dbQuery, query)
+		SELECT id, isbn, title, author, COALESCE(publisher, ''), COALESCE(published_year, 0), category, total_copies, available, status
+		FROM items` + where + fmt.Sprintf(`
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, searchSorts[params.Sort], len(args)+1, len(args)+2)
+	rows, err := s.db.QueryContext(ctx, dbQuery, append(args, params.Limit, params.Offset)...)
 	if err != nil {
 		return nil, fmt.Errorf("database search failed: %w", err)
 	}
 	defer rows.Close()
 
-	var items []*Item
 	for rows.Next() {
 		item := &Item{}
-		if err := rows.Scan(&item.ID, &item.ISBN, &item.Title, &item.Author, &item.TotalCopies, &item.Available, &item.Status); err != nil {
+		if err := rows.Scan(&item.ID, &item.ISBN, &item.Title, &item.Author, &item.Publisher, &item.PublishedYear, &item.Category, &item.TotalCopies, &item.Available, &item.Status); err != nil {
 			return nil, fmt.Errorf("failed to scan item: %w", err)
 		}
-		items = append(items, item)
+		result.Items = append(result.Items, item)
 	}
 
-	return items, nil
+	return result, rows.Err()
+}
+
+// searchFilter builds the WHERE clause of a database search and its
+// arguments, the query text always being $1. The text is parsed with
+// websearch_to_tsquery, which accepts anything a person might type: words
+// are ANDed, quoted phrases and "or" and "-" work as on the web, and stray
+// punctuation is ignored rather than a syntax error. Text with no searchable
+// words, such as only stop words, matches nothing.
+func searchFilter(params SearchParams) (string, []interface{}) {
+	where := `
+		WHERE (to_tsvector('english', title) @@ websearch_to_tsquery('english', $1)
+		OR to_tsvector('english', author) @@ websearch_to_tsquery('english', $1))
+	`
+	filters, args := itemFilters([]interface{}{params.Query}, params.Status, params.IncludeRetired, params.Author, params.AvailableOnly)
+	return where + filters, args
+}
+
+// itemFilters appends the conditions on status, author and availability
+// shared by searches and listings to a WHERE clause whose arguments so far
+// are args. Unless status is given or includeRetired is set, retired items
+// are left out.
+func itemFilters(args []interface{}, status string, includeRetired bool, author string, availableOnly bool) (string, []interface{}) {
+	var filters string
+	if status != "" {
+		args = append(args, status)
+		filters += fmt.Sprintf(" AND status = $%d", len(args))
+	} else if !includeRetired {
+		filters += " AND status <> 'retired'"
+	}
+	if author != "" {
+		args = append(args, author)
+		filters += fmt.Sprintf(" AND author ILIKE '%%' || $%d || '%%'", len(args))
+	}
+	if availableOnly {
+		filters += " AND available > 0 AND status = 'active'"
+	}
+	return filters, args
 }
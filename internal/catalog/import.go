@@ -0,0 +1,184 @@
+// internal/catalog/import.go
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+	"github.com/lib/pq"
+)
+
+// importRow is a validated bulk-import row waiting to be written.
+type importRow struct {
+	index int
+	item  NewItem
+}
+
+// AddItems imports many items at once. Rows are validated up front and
+// invalid ones are reported without stopping the import; the rest are
+// written in batches, each batch's events and read-model rows committing in a
+// single transaction. A batch that fails to commit marks all of its rows as
+// failed. The returned error is only set when the context ends the import
+// early.
+func (s *service) AddItems(ctx context.Context, items []NewItem) ([]ImportResult, error) {
+	results, rows := validateImport(items, s.minCopies)
+
+	for start := 0; start < len(rows); start += s.importBatchSize {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		end := start + s.importBatchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		batch := rows[start:end]
+
+		created, err := s.importBatch(ctx, batch, results)
+		if err != nil {
+			for _, row := range batch {
+				if results[row.index].Status == "" {
+					setImportFailed(&results[row.index], fmt.Errorf("failed to import batch: %w", err))
+				}
+			}
+			continue
+		}
+		indexed := make([]*Item, 0, len(created))
+		for _, row := range batch {
+			if item, ok := created[row.index]; ok {
+				results[row.index].ID = &item.ID
+				results[row.index].Status = ImportCreated
+				indexed = append(indexed, item)
+			}
+		}
+		s.indexItems(ctx, indexed...)
+	}
+
+	return results, nil
+}
+
+// validateImport normalizes every row, failing rows that are invalid or that
+// repeat an ISBN seen earlier in the same import.
+func validateImport(items []NewItem, minCopies int) ([]ImportResult, []importRow) {
+	results := make([]ImportResult, len(items))
+	rows := make([]importRow, 0, len(items))
+	seen := make(map[string]int, len(items))
+
+	for i, raw := range items {
+		results[i].Index = i
+
+		item, err := raw.normalize(minCopies)
+		if err != nil {
+			setImportFailed(&results[i], err)
+			continue
+		}
+		if first, ok := seen[item.ISBN]; ok {
+			setImportFailed(&results[i], fmt.Errorf("%w: same ISBN as row %d", ErrDuplicateISBN, first))
+			continue
+		}
+		seen[item.ISBN] = i
+		rows = append(rows, importRow{index: i, item: item})
+	}
+
+	return results, rows
+}
+
+// importBatch writes one batch in a single transaction. Rows whose ISBN is
+// already in the catalog are failed in results; the items created are
+// returned by row index once the transaction has committed.
+func (s *service) importBatch(ctx context.Context, batch []importRow, results []ImportResult) (map[int]*Item, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	isbns := make([]string, len(batch))
+	for i, row := range batch {
+		isbns[i] = row.item.ISBN
+	}
+	existing, err := existingISBNs(ctx, tx, isbns)
+	if err != nil {
+		return nil, err
+	}
+
+	created := make(map[int]*Item, len(batch))
+	for _, row := range batch {
+		if existing[row.item.ISBN] {
+			setImportFailed(&results[row.index], ErrDuplicateISBN)
+			continue
+		}
+
+		id := uuid.New()
+		jsonData, err := json.Marshal(ItemAddedEvent{
+			ID:          id,
+			ISBN:        row.item.ISBN,
+			Title:       row.item.Title,
+			Author:      row.item.Author,
+			Category:    row.item.Category,
+			TotalCopies: row.item.TotalCopies,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal event data: %w", err)
+		}
+
+		event := eventstore.Event{
+			AggregateID:   id,
+			AggregateType: "item",
+			EventType:     "ItemAdded",
+			EventData:     jsonData,
+			Version:       1,
+		}
+		if err := s.appendItemEventsTx(ctx, tx, id, 0, []eventstore.Event{event}); err != nil {
+			return nil, fmt.Errorf("failed to append event: %w", err)
+		}
+
+		item := &Item{
+			ID:          id,
+			ISBN:        row.item.ISBN,
+			Title:       row.item.Title,
+			Author:      row.item.Author,
+			Category:    row.item.Category,
+			TotalCopies: row.item.TotalCopies,
+			Available:   row.item.TotalCopies,
+			Status:      "active",
+			Version:     1,
+		}
+		if err := insertItemIntoReadModel(ctx, tx, item); err != nil {
+			return nil, fmt.Errorf("failed to update read model: %w", err)
+		}
+		created[row.index] = item
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func existingISBNs(ctx context.Context, tx *sql.Tx, isbns []string) (map[string]bool, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT isbn FROM items WHERE isbn = ANY($1)`, pq.Array(isbns))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check ISBNs: %w", err)
+	}
+	defer rows.Close()
+
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var isbn string
+		if err := rows.Scan(&isbn); err != nil {
+			return nil, fmt.Errorf("failed to scan ISBN: %w", err)
+		}
+		existing[isbn] = true
+	}
+	return existing, rows.Err()
+}
+
+func setImportFailed(result *ImportResult, err error) {
+	result.Status = ImportFailed
+	result.Error = err.Error()
+}
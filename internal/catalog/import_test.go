@@ -0,0 +1,135 @@
+package catalog
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateImport(t *testing.T) {
+	items := []NewItem{
+		{ISBN: "978-0-14-143951-8", Title: "Pride and Prejudice", Author: "Jane Austen", TotalCopies: 2},
+		{ISBN: "not-an-isbn", Title: "Broken", TotalCopies: 1},
+		{ISBN: "0141439513", Title: "Pride and Prejudice (again)", TotalCopies: 1},
+		{ISBN: "9780743273565", Title: "", TotalCopies: 1},
+		{ISBN: "9780743273565", Title: "The Great Gatsby", TotalCopies: -1},
+		{ISBN: "9780061120084", Title: "To Kill a Mockingbird", TotalCopies: 4},
+	}
+
+	results, rows := validateImport(items, DefaultMinCopies)
+
+	require.Len(t, results, len(items))
+	for i, result := range results {
+		assert.Equal(t, i, result.Index)
+	}
+
+	require.Len(t, rows, 2)
+	assert.Equal(t, 0, rows[0].index)
+	assert.Equal(t, "9780141439518", rows[0].item.ISBN)
+	assert.Equal(t, 5, rows[1].index)
+
+	for _, i := range []int{1, 2, 3, 4} {
+		assert.Equal(t, ImportFailed, results[i].Status, "row %d", i)
+		assert.NotEmpty(t, results[i].Error, "row %d", i)
+	}
+	assert.Contains(t, results[1].Error, ErrInvalidISBN.Error())
+	assert.Contains(t, results[2].Error, "same ISBN as row 0")
+	assert.Contains(t, results[3].Error, "missing title")
+	assert.Contains(t, results[4].Error, "at least 1")
+
+	// Rows still to be written have no outcome yet.
+	assert.Empty(t, results[0].Status)
+	assert.Empty(t, results[5].Status)
+}
+
+func TestValidateImportMinCopies(t *testing.T) {
+	items := []NewItem{
+		{ISBN: "9780141439518", Title: "Pride and Prejudice", TotalCopies: 0},
+		{ISBN: "9780743273565", Title: "The Great Gatsby", TotalCopies: 1},
+		{ISBN: "9780061120084", Title: "To Kill a Mockingbird", TotalCopies: -1},
+	}
+
+	tests := []struct {
+		minCopies int
+		wantRows  []int
+	}{
+		{minCopies: DefaultMinCopies, wantRows: []int{1}},
+		{minCopies: 0, wantRows: []int{0, 1}},
+		{minCopies: 2, wantRows: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("min %d", tt.minCopies), func(t *testing.T) {
+			results, rows := validateImport(items, tt.minCopies)
+			var got []int
+			for _, row := range rows {
+				got = append(got, row.index)
+			}
+			assert.Equal(t, tt.wantRows, got)
+			for i, result := range results {
+				if result.Status == ImportFailed {
+					assert.Contains(t, result.Error, fmt.Sprintf("total_copies must be at least %d", tt.minCopies), "row %d", i)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeNewItems(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "json array",
+			body: ` [{"isbn":"9780141439518","title":"A"},{"isbn":"9780743273565","title":"B"}]`,
+			want: []string{"A", "B"},
+		},
+		{
+			name: "ndjson with blank lines",
+			body: "{\"isbn\":\"9780141439518\",\"title\":\"A\"}\n\n{\"isbn\":\"9780743273565\",\"title\":\"B\"}\n",
+			want: []string{"A", "B"},
+		},
+		{
+			name: "empty body",
+			body: "  \n",
+		},
+		{
+			name:    "malformed ndjson line",
+			body:    "{\"title\":\"A\"}\n{\"title\":\n",
+			wantErr: "line 2",
+		},
+		{
+			name:    "malformed array",
+			body:    `[{"title":"A"},`,
+			wantErr: "invalid JSON array",
+		},
+		{
+			name:    "unknown field",
+			body:    "{\"title\":\"A\",\"pages\":12}\n",
+			wantErr: `line 1: unknown field "pages"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := decodeNewItems(strings.NewReader(tt.body))
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			var titles []string
+			for _, item := range items {
+				titles = append(titles, item.Title)
+			}
+			assert.Equal(t, tt.want, titles)
+		})
+	}
+}
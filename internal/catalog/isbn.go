@@ -0,0 +1,80 @@
+// internal/catalog/isbn.go
+package catalog
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NormalizeISBN validates an ISBN-10 or ISBN-13, ignoring hyphens and spaces,
+// and returns it as a bare 13-digit ISBN. Both the check digit and, for
+// ISBN-13, the 978/979 prefix are verified.
+func NormalizeISBN(raw string) (string, error) {
+	isbn := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, raw)
+
+	switch len(isbn) {
+	case 10:
+		if !validISBN10(isbn) {
+			return "", fmt.Errorf("%w: %q", ErrInvalidISBN, raw)
+		}
+		prefixed := "978" + isbn[:9]
+		return prefixed + string(isbn13CheckDigit(prefixed)), nil
+	case 13:
+		if !allDigits(isbn) || !(strings.HasPrefix(isbn, "978") || strings.HasPrefix(isbn, "979")) {
+			return "", fmt.Errorf("%w: %q", ErrInvalidISBN, raw)
+		}
+		if isbn13CheckDigit(isbn[:12]) != isbn[12] {
+			return "", fmt.Errorf("%w: %q", ErrInvalidISBN, raw)
+		}
+		return isbn, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidISBN, raw)
+	}
+}
+
+// validISBN10 checks the mod-11 checksum; the last character may be X (10).
+func validISBN10(isbn string) bool {
+	if !allDigits(isbn[:9]) {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(isbn[i]-'0') * (10 - i)
+	}
+	switch c := isbn[9]; {
+	case c == 'X' || c == 'x':
+		sum += 10
+	case c >= '0' && c <= '9':
+		sum += int(c - '0')
+	default:
+		return false
+	}
+	return sum%11 == 0
+}
+
+// isbn13CheckDigit computes the check digit for the first 12 digits.
+func isbn13CheckDigit(digits string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		d := int(digits[i] - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+func allDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,41 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeISBN(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{"isbn-13", "9780141439518", "9780141439518", false},
+		{"isbn-13 with hyphens", "978-0-14-143951-8", "9780141439518", false},
+		{"isbn-10 converted", "0141439513", "9780141439518", false},
+		{"isbn-10 with X check digit", "0-8044-2957-X", "9780804429573", false},
+		{"isbn-10 lowercase x", "080442957x", "9780804429573", false},
+		{"979 prefix", "9791034304677", "9791034304677", false},
+		{"bad isbn-13 check digit", "9780141439519", "", true},
+		{"bad isbn-10 check digit", "0141439514", "", true},
+		{"unknown prefix", "9770141439518", "", true},
+		{"letters", "97801414395AB", "", true},
+		{"wrong length", "12345", "", true},
+		{"empty", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeISBN(tt.raw)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidISBN)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
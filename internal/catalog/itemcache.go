@@ -0,0 +1,66 @@
+// internal/catalog/itemcache.go
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"libranexus/internal/cache"
+	"libranexus/internal/metrics"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+)
+
+// DefaultItemCacheSize is how many items the item cache holds unless
+// configured otherwise.
+const DefaultItemCacheSize = 10000
+
+// itemCacheRequests counts GetItem lookups in the item cache by whether
+// they were served from it.
+var itemCacheRequests = metrics.NewCounter("catalog_item_cache_requests_total",
+	"Item reads looked up in the item cache, by result (hit or miss).",
+	"result")
+
+// WithItemCache makes GetItem serve items from an in-memory cache of up to
+// size items, each kept for ttl. Every write through this service
+// invalidates the item it changes, so ttl only bounds how long a change
+// made through another replica can go unseen. Without it, or with a size
+// or ttl that is not positive, every read goes to the read model.
+func WithItemCache(size int, ttl time.Duration) Option {
+	return func(s *service) {
+		s.itemCache = cache.NewLRU[uuid.UUID, Item](size, ttl)
+	}
+}
+
+// cachedItem returns an item, retired or not, from the item cache, reading
+// it from the read model and caching it on a miss. Callers get their own
+// copy.
+func (s *service) cachedItem(ctx context.Context, id uuid.UUID) (*Item, error) {
+	if s.itemCache == nil {
+		return s.readItem(ctx, id)
+	}
+	if item, ok := s.itemCache.Get(id); ok {
+		itemCacheRequests.Inc("hit")
+		return &item, nil
+	}
+	itemCacheRequests.Inc("miss")
+	item, err := s.readItem(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.itemCache.Put(id, *item, item.Version)
+	return item, nil
+}
+
+// appendItemEventsTx appends events to an item in tx and invalidates the
+// item's cached state at the version they take it to. Doing so before the
+// transaction commits is safe: reads racing the commit see the old version,
+// which the cache then refuses.
+func (s *service) appendItemEventsTx(ctx context.Context, tx *sql.Tx, id uuid.UUID, expectedVersion int, events []eventstore.Event) error {
+	if err := s.eventStore.AppendEventsTx(ctx, tx, id, "item", expectedVersion, events); err != nil {
+		return err
+	}
+	s.itemCache.Invalidate(id, expectedVersion+len(events))
+	return nil
+}
@@ -0,0 +1,53 @@
+// internal/catalog/list.go
+package catalog
+
+import (
+	"context"
+	"fmt"
+)
+
+// ListItems returns one page of the catalog, filtered and sorted as params
+// asks, along with how many items match across all pages. Retired items are
+// left out unless params asks for them.
+func (s *service) ListItems(ctx context.Context, params ListParams) ([]*Item, int, error) {
+	if err := params.normalize(); err != nil {
+		return nil, 0, err
+	}
+
+	filters, args := itemFilters(nil, params.Status, params.IncludeRetired, params.Author, params.AvailableOnly)
+	where := " WHERE TRUE" + filters
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM items"+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count items: %w", err)
+	}
+
+	// The ORDER BY comes from the searchSorts allowlist, never from the caller.
+	query := `
+		SELECT id, isbn, title, author, COALESCE(publisher, ''), COALESCE(published_year, 0),
+		       category, total_copies, available, status, version, created_at, updated_at
+		FROM items` + where + fmt.Sprintf(`
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, searchSorts[params.Sort], len(args)+1, len(args)+2)
+	rows, err := s.db.QueryContext(ctx, query, append(args, params.Limit, params.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list items: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]*Item, 0)
+	for rows.Next() {
+		item := &Item{}
+		err := rows.Scan(&item.ID, &item.ISBN, &item.Title, &item.Author, &item.Publisher, &item.PublishedYear,
+			&item.Category, &item.TotalCopies, &item.Available, &item.Status, &item.Version, &item.CreatedAt, &item.UpdatedAt)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to list items: %w", err)
+	}
+	return items, total, nil
+}
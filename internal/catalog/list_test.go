@@ -0,0 +1,56 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListParamsNormalize(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    ListParams
+		wantErr   bool
+		wantSort  string
+		wantLimit int
+	}{
+		{"defaults sort and limit", ListParams{}, false, "title", defaultSearchLimit},
+		{"keeps sort and limit", ListParams{Sort: "-published_year", Limit: 25}, false, "-published_year", 25},
+		{"caps limit", ListParams{Limit: 1000}, false, "title", maxSearchLimit},
+		{"rejects negative limit", ListParams{Limit: -1}, true, "", 0},
+		{"rejects negative offset", ListParams{Offset: -1}, true, "", 0},
+		{"rejects unknown sort", ListParams{Sort: "id; DROP TABLE items"}, true, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := tt.params
+			err := params.normalize()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidSearchParams)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSort, params.Sort)
+			assert.Equal(t, tt.wantLimit, params.Limit)
+		})
+	}
+}
+
+func TestItemFiltersHideRetiredItemsByDefault(t *testing.T) {
+	filters, args := itemFilters(nil, "", false, "", false)
+	assert.Equal(t, " AND status <> 'retired'", filters)
+	assert.Empty(t, args)
+
+	filters, _ = itemFilters(nil, "", true, "", false)
+	assert.Empty(t, filters)
+}
+
+func TestItemFiltersNumberArgumentsFromOne(t *testing.T) {
+	filters, args := itemFilters(nil, "retired", false, "austen", true)
+	assert.Contains(t, filters, "status = $1")
+	assert.Contains(t, filters, "$2")
+	assert.Contains(t, filters, "available > 0")
+	assert.Equal(t, []interface{}{"retired", "austen"}, args)
+}
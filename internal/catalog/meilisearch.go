@@ -0,0 +1,193 @@
+// internal/catalog/meilisearch.go
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const defaultMeilisearchTimeout = 2 * time.Second
+
+// MeilisearchBackend is a SearchBackend that keeps items in a Meilisearch
+// index, searching titles and authors and filtering on status.
+type MeilisearchBackend struct {
+	baseURL    string
+	apiKey     string
+	index      string
+	httpClient *http.Client
+}
+
+// NewMeilisearchBackend creates a backend for the given index. The API key may
+// be empty for an unsecured instance.
+func NewMeilisearchBackend(baseURL, apiKey, index string) *MeilisearchBackend {
+	return &MeilisearchBackend{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		index:      index,
+		httpClient: &http.Client{Timeout: defaultMeilisearchTimeout},
+	}
+}
+
+// meilisearchDocument is how an item is stored in the index.
+type meilisearchDocument struct {
+	ID            uuid.UUID `json:"id"`
+	ISBN          string    `json:"isbn"`
+	Title         string    `json:"title"`
+	Author        string    `json:"author"`
+	Publisher     string    `json:"publisher,omitempty"`
+	PublishedYear int       `json:"published_year,omitempty"`
+	Category      string    `json:"category"`
+	TotalCopies   int       `json:"total_copies"`
+	Available     int       `json:"available"`
+	Status        string    `json:"status"`
+}
+
+// meilisearchSorts maps each sort SearchParams allows to Meilisearch's
+// syntax. An empty sort keeps Meilisearch's relevance ranking.
+var meilisearchSorts = map[string]string{
+	"title":           "title:asc",
+	"-title":          "title:desc",
+	"published_year":  "published_year:asc",
+	"-published_year": "published_year:desc",
+}
+
+// Configure creates the index if needed and sets which attributes are
+// searchable and filterable. It is safe to call on every startup.
+func (m *MeilisearchBackend) Configure(ctx context.Context) error {
+	err := m.do(ctx, http.MethodPost, "/indexes", map[string]string{
+		"uid":        m.index,
+		"primaryKey": "id",
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+
+	err = m.do(ctx, http.MethodPatch, "/indexes/"+url.PathEscape(m.index)+"/settings", map[string][]string{
+		"searchableAttributes": {"title", "author"},
+		"filterableAttributes": {"status", "available"},
+		"sortableAttributes":   {"title", "published_year"},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to update index settings: %w", err)
+	}
+	return nil
+}
+
+// Search queries the index. Author filters use substring matching in the
+// database, which Meilisearch filters cannot express, so those searches are
+// declined and served by the database instead.
+func (m *MeilisearchBackend) Search(ctx context.Context, params SearchParams) (*SearchResult, error) {
+	if params.Author != "" {
+		return nil, errUnsupportedSearch
+	}
+
+	req := map[string]interface{}{
+		"q":      params.Query,
+		"limit":  params.Limit,
+		"offset": params.Offset,
+	}
+	var filters []string
+	if params.Status != "" {
+		filters = append(filters, fmt.Sprintf("status = %q", params.Status))
+	} else if !params.IncludeRetired {
+		filters = append(filters, `status != "retired"`)
+	}
+	if params.AvailableOnly {
+		filters = append(filters, `available > 0 AND status = "active"`)
+	}
+	if len(filters) > 0 {
+		req["filter"] = strings.Join(filters, " AND ")
+	}
+	if sort, ok := meilisearchSorts[params.Sort]; ok {
+		req["sort"] = []string{sort}
+	}
+
+	var resp struct {
+		Hits               []meilisearchDocument `json:"hits"`
+		EstimatedTotalHits int                   `json:"estimatedTotalHits"`
+	}
+	if err := m.do(ctx, http.MethodPost, "/indexes/"+url.PathEscape(m.index)+"/search", req, &resp); err != nil {
+		return nil, err
+	}
+
+	result := &SearchResult{
+		Items:  make([]*Item, 0, len(resp.Hits)),
+		Total:  resp.EstimatedTotalHits,
+		Limit:  params.Limit,
+		Offset: params.Offset,
+	}
+	for _, hit := range resp.Hits {
+		result.Items = append(result.Items, &Item{
+			ID:            hit.ID,
+			ISBN:          hit.ISBN,
+			Title:         hit.Title,
+			Author:        hit.Author,
+			Publisher:     hit.Publisher,
+			PublishedYear: hit.PublishedYear,
+			Category:      NormalizeCategory(hit.Category),
+			TotalCopies:   hit.TotalCopies,
+			Available:     hit.Available,
+			Status:        hit.Status,
+		})
+	}
+	return result, nil
+}
+
+// IndexItems adds or replaces the items' documents. Meilisearch applies the
+// write asynchronously, so a search right after may not see it yet.
+func (m *MeilisearchBackend) IndexItems(ctx context.Context, items []*Item) error {
+	docs := make([]meilisearchDocument, len(items))
+	for i, item := range items {
+		docs[i] = meilisearchDocument{
+			ID:            item.ID,
+			ISBN:          item.ISBN,
+			Title:         item.Title,
+			Author:        item.Author,
+			Publisher:     item.Publisher,
+			PublishedYear: item.PublishedYear,
+			Category:      item.Category,
+			TotalCopies:   item.TotalCopies,
+			Available:     item.Available,
+			Status:        item.Status,
+		}
+	}
+	return m.do(ctx, http.MethodPost, "/indexes/"+url.PathEscape(m.index)+"/documents", docs, nil)
+}
+
+func (m *MeilisearchBackend) do(ctx context.Context, method, path string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, m.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if m.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+m.apiKey)
+	}
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("meilisearch %s %s: unexpected status code: %d", method, path, resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,110 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeilisearchBackendSearch(t *testing.T) {
+	id := uuid.New()
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/indexes/items/search", r.URL.Path)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"hits": []map[string]interface{}{
+				{"id": id, "isbn": "9780141439518", "title": "Pride and Prejudice", "author": "Jane Austen", "total_copies": 5, "available": 3, "status": "active"},
+			},
+			"estimatedTotalHits": 12,
+		})
+	}))
+	defer server.Close()
+
+	backend := NewMeilisearchBackend(server.URL+"/", "secret", "items")
+	result, err := backend.Search(context.Background(), SearchParams{Query: "pride", Status: "active", Limit: 10, Offset: 20})
+	require.NoError(t, err)
+
+	assert.Equal(t, "pride", got["q"])
+	assert.Equal(t, `status = "active"`, got["filter"])
+	assert.EqualValues(t, 20, got["offset"])
+
+	assert.Equal(t, 12, result.Total)
+	assert.Equal(t, 20, result.Offset)
+	require.Len(t, result.Items, 1)
+	assert.Equal(t, id, result.Items[0].ID)
+	assert.Equal(t, 3, result.Items[0].Available)
+}
+
+func TestMeilisearchBackendHidesRetiredItems(t *testing.T) {
+	tests := []struct {
+		name       string
+		params     SearchParams
+		wantFilter interface{}
+	}{
+		{"hidden by default", SearchParams{Query: "pride"}, `status != "retired"`},
+		{"included on request", SearchParams{Query: "pride", IncludeRetired: true}, nil},
+		{"explicit status wins", SearchParams{Query: "pride", Status: "retired"}, `status = "retired"`},
+		{"available only", SearchParams{Query: "pride", AvailableOnly: true}, `status != "retired" AND available > 0 AND status = "active"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]interface{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+				w.Write([]byte(`{"hits":[]}`))
+			}))
+			defer server.Close()
+
+			backend := NewMeilisearchBackend(server.URL, "", "items")
+			_, err := backend.Search(context.Background(), tt.params)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFilter, got["filter"])
+		})
+	}
+}
+
+func TestMeilisearchBackendSorts(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = nil
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.Write([]byte(`{"hits":[]}`))
+	}))
+	defer server.Close()
+	backend := NewMeilisearchBackend(server.URL, "", "items")
+
+	_, err := backend.Search(context.Background(), SearchParams{Query: "pride", Sort: "-published_year"})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"published_year:desc"}, got["sort"])
+
+	_, err = backend.Search(context.Background(), SearchParams{Query: "pride"})
+	require.NoError(t, err)
+	assert.NotContains(t, got, "sort", "relevance ranking is kept without a sort")
+}
+
+func TestMeilisearchBackendDeclinesAuthorFilter(t *testing.T) {
+	backend := NewMeilisearchBackend("http://unused.invalid", "", "items")
+	_, err := backend.Search(context.Background(), SearchParams{Query: "pride", Author: "austen", Limit: 10})
+	assert.ErrorIs(t, err, errUnsupportedSearch)
+}
+
+func TestMeilisearchBackendReportsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	backend := NewMeilisearchBackend(server.URL, "", "items")
+	_, err := backend.Search(context.Background(), SearchParams{Query: "pride", Limit: 10})
+	assert.ErrorContains(t, err, "503")
+	assert.Error(t, backend.IndexItems(context.Background(), []*Item{{ID: uuid.New()}}))
+}
@@ -0,0 +1,151 @@
+// internal/catalog/reconcile.go
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"libranexus/internal/logging"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// defaultReconcileGrace is how long an item must go unchanged before
+// reconciliation looks at it.
+const defaultReconcileGrace = 5 * time.Minute
+
+// CopyCounter reports how many copies of each item are out of the library,
+// keyed by item. Items with none out may be left out of the map.
+type CopyCounter interface {
+	CopiesOut(ctx context.Context) (map[uuid.UUID]int, error)
+}
+
+// WithCopyCounter sets where reconciliation learns how many copies are out.
+// By default it reads circulation's read model from the shared database.
+func WithCopyCounter(c CopyCounter) Option {
+	return func(s *service) {
+		s.copyCounter = c
+	}
+}
+
+// WithReconcileGrace sets how long an item must go unchanged before
+// reconciliation considers it, so a checkout or return caught between its
+// catalog and circulation writes is not mistaken for drift.
+func WithReconcileGrace(d time.Duration) Option {
+	return func(s *service) {
+		if d >= 0 {
+			s.reconcileGrace = d
+		}
+	}
+}
+
+// ReconcileAvailability compares each active item's available count with its
+// total copies less the copies out, and reports the items that disagree.
+// With correct set, each discrepancy is also reset through UpdateItemCopies,
+// conditional on the version it was found at, so the fix is recorded as an
+// ItemCopiesUpdated event and loses to any concurrent change.
+func (s *service) ReconcileAvailability(ctx context.Context, correct bool) ([]Discrepancy, error) {
+	out, err := s.copyCounter.CopiesOut(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count copies out: %w", err)
+	}
+
+	query := `
+		SELECT id, total_copies, available, version
+		FROM items
+		WHERE status = 'active' AND updated_at < $1
+		ORDER BY id
+	`
+	rows, err := s.db.QueryContext(ctx, query, time.Now().Add(-s.reconcileGrace))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*Item
+	for rows.Next() {
+		item := &Item{}
+		if err := rows.Scan(&item.ID, &item.TotalCopies, &item.Available, &item.Version); err != nil {
+			return nil, fmt.Errorf("failed to scan item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list items: %w", err)
+	}
+
+	discrepancies := findDiscrepancies(items, out)
+	if !correct {
+		return discrepancies, nil
+	}
+	for i := range discrepancies {
+		d := &discrepancies[i]
+		logger := logging.FromContext(ctx).With("item_id", d.ItemID, "available", d.Available, "expected", d.Expected)
+		err := s.UpdateItemCopies(ctx, d.ItemID, d.TotalCopies, d.Expected, d.Version)
+		if errors.Is(err, ErrVersionConflict) {
+			logger.Info("skipped availability correction for changed item")
+			continue
+		}
+		if err != nil {
+			return discrepancies, fmt.Errorf("failed to correct item %s: %w", d.ItemID, err)
+		}
+		logger.Warn("corrected item availability")
+		d.Corrected = true
+	}
+	return discrepancies, nil
+}
+
+// findDiscrepancies returns the items whose available count is not their
+// total copies less the copies out, never expecting fewer than none.
+func findDiscrepancies(items []*Item, out map[uuid.UUID]int) []Discrepancy {
+	var discrepancies []Discrepancy
+	for _, item := range items {
+		expected := max(item.TotalCopies-out[item.ID], 0)
+		if item.Available == expected {
+			continue
+		}
+		discrepancies = append(discrepancies, Discrepancy{
+			ItemID:      item.ID,
+			TotalCopies: item.TotalCopies,
+			Available:   item.Available,
+			Expected:    expected,
+			Version:     item.Version,
+		})
+	}
+	return discrepancies
+}
+
+// readModelCounter counts copies out from circulation's tables: those on
+// open checkouts and those set aside for fulfilled holds.
+type readModelCounter struct {
+	db *sql.DB
+}
+
+func (c readModelCounter) CopiesOut(ctx context.Context) (map[uuid.UUID]int, error) {
+	query := `
+		SELECT item_id, COUNT(*) FROM (
+			SELECT item_id FROM checkouts WHERE status IN ('active', 'overdue')
+			UNION ALL
+			SELECT item_id FROM holds WHERE status = 'fulfilled'
+		) copies_out
+		GROUP BY item_id
+	`
+	rows, err := c.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[uuid.UUID]int)
+	for rows.Next() {
+		var id uuid.UUID
+		var n int
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, err
+		}
+		out[id] = n
+	}
+	return out, rows.Err()
+}
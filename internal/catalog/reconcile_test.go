@@ -0,0 +1,40 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindDiscrepancies(t *testing.T) {
+	balanced := &Item{ID: uuid.New(), TotalCopies: 3, Available: 1, Version: 4}
+	leaked := &Item{ID: uuid.New(), TotalCopies: 3, Available: 0, Version: 7}
+	overReleased := &Item{ID: uuid.New(), TotalCopies: 2, Available: 1, Version: 2}
+	overLent := &Item{ID: uuid.New(), TotalCopies: 1, Available: 1, Version: 3}
+	out := map[uuid.UUID]int{
+		balanced.ID:     2,
+		leaked.ID:       1,
+		overReleased.ID: 2,
+		overLent.ID:     2,
+	}
+
+	got := findDiscrepancies([]*Item{balanced, leaked, overReleased, overLent}, out)
+
+	assert.Equal(t, []Discrepancy{
+		{ItemID: leaked.ID, TotalCopies: 3, Available: 0, Expected: 2, Version: 7},
+		{ItemID: overReleased.ID, TotalCopies: 2, Available: 1, Expected: 0, Version: 2},
+		{ItemID: overLent.ID, TotalCopies: 1, Available: 1, Expected: 0, Version: 3},
+	}, got)
+}
+
+func TestFindDiscrepanciesTreatsMissingItemsAsFullyAvailable(t *testing.T) {
+	untouched := &Item{ID: uuid.New(), TotalCopies: 2, Available: 2}
+	drifted := &Item{ID: uuid.New(), TotalCopies: 2, Available: 1}
+
+	got := findDiscrepancies([]*Item{untouched, drifted}, nil)
+
+	assert.Len(t, got, 1)
+	assert.Equal(t, drifted.ID, got[0].ItemID)
+	assert.Equal(t, 2, got[0].Expected)
+}
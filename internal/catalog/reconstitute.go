@@ -0,0 +1,168 @@
+// internal/catalog/reconstitute.go
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+)
+
+// ReconstituteItem rebuilds an item's state from the event store rather than
+// the read model: it starts from the latest snapshot, if any, and folds in the
+// events recorded after it. When enough events had to be replayed, a fresh
+// snapshot is saved so the next reconstitution is cheaper.
+func (s *service) ReconstituteItem(ctx context.Context, id uuid.UUID) (*Item, error) {
+	item, replayed, err := s.replayItem(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.snapshotInterval > 0 && replayed >= s.snapshotInterval {
+		if err := s.saveItemSnapshot(ctx, item); err != nil {
+			// The reconstituted state is still correct; only the next replay is slower.
+			log.Printf("Failed to save snapshot for item %s: %v", id, err)
+		}
+	}
+
+	return item, nil
+}
+
+// GetItemAsOf rebuilds an item as it stood at asOf by folding its events in
+// version order, up to the first one recorded after asOf. It returns
+// ErrNoItemState if the item had not been added by then.
+func (s *service) GetItemAsOf(ctx context.Context, id uuid.UUID, asOf time.Time) (*Item, error) {
+	events, err := s.loadItemHistory(ctx, id, 0)
+	if err != nil {
+		return nil, err
+	}
+	events = eventsAsOf(events, asOf)
+	if len(events) == 0 {
+		return nil, fmt.Errorf("%w: %s as of %s", ErrNoItemState, id, asOf.Format(time.RFC3339))
+	}
+	return foldItem(events)
+}
+
+// eventsAsOf returns the events, in version order, that precede the first
+// one recorded after asOf.
+func eventsAsOf(events []eventstore.Event, asOf time.Time) []eventstore.Event {
+	n := 0
+	for n < len(events) && !events[n].CreatedAt.After(asOf) {
+		n++
+	}
+	return events[:n]
+}
+
+// GetItemAtVersion rebuilds an item as it stood once its event at version
+// had been applied. It returns ErrNoItemState if the item never reached
+// that version.
+func (s *service) GetItemAtVersion(ctx context.Context, id uuid.UUID, version int) (*Item, error) {
+	if version < 1 {
+		return nil, fmt.Errorf("%w: version %d", ErrInvalidEventRange, version)
+	}
+	events, err := s.loadItemHistory(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+	if events[len(events)-1].Version != version {
+		return nil, fmt.Errorf("%w: %s at version %d", ErrNoItemState, id, version)
+	}
+	return foldItem(events)
+}
+
+// loadItemHistory loads an item's events from the first up to toVersion, or
+// all of them when toVersion is zero. Snapshots are not used: they only
+// hold the item's latest state.
+func (s *service) loadItemHistory(ctx context.Context, id uuid.UUID, toVersion int) ([]eventstore.Event, error) {
+	events, err := s.eventStore.LoadEventsWithOptions(ctx, id, 1, toVersion, eventstore.LoadEventsOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load events: %w", err)
+	}
+	if len(events) == 0 || events[0].AggregateType != "item" {
+		return nil, fmt.Errorf("%w: %s", ErrItemNotFound, id)
+	}
+	return events, nil
+}
+
+// foldItem applies events, in version order from the first, to an empty item.
+func foldItem(events []eventstore.Event) (*Item, error) {
+	item := &Item{}
+	for _, event := range events {
+		if err := item.Apply(event); err != nil {
+			return nil, fmt.Errorf("failed to apply event %d: %w", event.Version, err)
+		}
+	}
+	return item, nil
+}
+
+// snapshotIfDue saves a fresh snapshot of the item when an append taking it
+// from version from to version to crossed a multiple of the snapshot
+// interval, so replay cost stays bounded even for items that are written far
+// more often than they are reconstituted. Failures are only logged: the
+// append has already committed.
+func (s *service) snapshotIfDue(ctx context.Context, id uuid.UUID, from, to int) {
+	if !eventstore.SnapshotDue(from, to, s.snapshotInterval) {
+		return
+	}
+	item, _, err := s.replayItem(ctx, id)
+	if err == nil {
+		err = s.saveItemSnapshot(ctx, item)
+	}
+	if err != nil {
+		log.Printf("Failed to save snapshot for item %s: %v", id, err)
+	}
+}
+
+// replayItem folds the events recorded after the item's latest snapshot into
+// the snapshot's state as they are read, and reports how many it replayed.
+func (s *service) replayItem(ctx context.Context, id uuid.UUID) (*Item, int, error) {
+	item := &Item{}
+
+	snapshot, err := s.eventStore.LoadSnapshot(ctx, id)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load snapshot: %w", err)
+	}
+	if snapshot != nil {
+		if err := json.Unmarshal(snapshot.State, item); err != nil {
+			return nil, 0, fmt.Errorf("failed to decode snapshot: %w", err)
+		}
+	}
+
+	replayed := 0
+	var applyErr error
+	err = s.eventStore.LoadEventsFuncWithOptions(ctx, id, item.Version+1, 0, eventstore.LoadEventsOptions{}, func(event eventstore.Event) error {
+		if applyErr = item.Apply(event); applyErr != nil {
+			return fmt.Errorf("failed to apply event %d: %w", event.Version, applyErr)
+		}
+		replayed++
+		return nil
+	})
+	if err != nil {
+		if applyErr != nil {
+			return nil, 0, err
+		}
+		return nil, 0, fmt.Errorf("failed to load events: %w", err)
+	}
+	if snapshot == nil && replayed == 0 {
+		return nil, 0, fmt.Errorf("%w: %s", ErrItemNotFound, id)
+	}
+	return item, replayed, nil
+}
+
+func (s *service) saveItemSnapshot(ctx context.Context, item *Item) error {
+	state, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+
+	return s.eventStore.SaveSnapshot(ctx, eventstore.Snapshot{
+		AggregateID:   item.ID,
+		AggregateType: "item",
+		Version:       item.Version,
+		State:         state,
+	})
+}
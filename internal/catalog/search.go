@@ -0,0 +1,109 @@
+// internal/catalog/search.go
+package catalog
+
+import (
+	"context"
+	"errors"
+	"libranexus/internal/metrics"
+	"log"
+
+	"github.com/google/uuid"
+)
+
+// errUnsupportedSearch is returned by a search backend for queries it cannot
+// answer exactly; Search then serves them from the database.
+var errUnsupportedSearch = errors.New("search not supported by backend")
+
+// SearchBackend is an external full-text index that answers catalog searches
+// ahead of the database. The read model stays the source of truth: the
+// backend is kept in sync on a best-effort basis and Search falls back to the
+// database whenever the backend fails.
+type SearchBackend interface {
+	Search(ctx context.Context, params SearchParams) (*SearchResult, error)
+	IndexItems(ctx context.Context, items []*Item) error
+}
+
+var (
+	// searchRequests counts searches by what served them: the backend, the
+	// database, a cached database result, or none of them.
+	// search_availability is the share not "failed".
+	searchRequests = metrics.NewCounter("catalog_search_requests_total",
+		"Catalog searches, by what served them (backend, database, cache or failed).",
+		"served_by")
+	searchBackendErrors = metrics.NewCounter("catalog_search_backend_errors_total",
+		"Searches the backend failed to answer and the database served instead.")
+	searchIndexErrors = metrics.NewCounter("catalog_search_index_errors_total",
+		"Failed attempts to push items to the search backend.")
+)
+
+// WithSearchBackend makes Search try the backend before the database and
+// keeps the backend indexed as items are added or change.
+func WithSearchBackend(b SearchBackend) Option {
+	return func(s *service) {
+		s.searchBackend = b
+	}
+}
+
+// Search finds items in the catalog, one page at a time.
+func (s *service) Search(ctx context.Context, params SearchParams) (*SearchResult, error) {
+	if err := params.normalize(); err != nil {
+		return nil, err
+	}
+
+	if s.searchBackend != nil {
+		result, err := s.searchBackend.Search(ctx, params)
+		if err == nil {
+			searchRequests.Inc("backend")
+			return result, nil
+		}
+		if !errors.Is(err, errUnsupportedSearch) {
+			searchBackendErrors.Inc()
+			log.Printf("Search backend failed, falling back to database: %v", err)
+		}
+	}
+
+	result, cached, err := s.searchCache.search(ctx, params, s.searchDatabase)
+	if err != nil {
+		searchRequests.Inc("failed")
+		return nil, err
+	}
+	if cached {
+		searchRequests.Inc("cache")
+	} else {
+		searchRequests.Inc("database")
+	}
+	return result, nil
+}
+
+// indexItems pushes items to the search backend and drops cached database
+// searches, which may no longer match them. Failures are logged rather than
+// returned: the write has already been recorded, and searches keep working
+// through the database fallback.
+func (s *service) indexItems(ctx context.Context, items ...*Item) {
+	if len(items) == 0 {
+		return
+	}
+	s.searchCache.invalidate()
+	if s.searchBackend == nil {
+		return
+	}
+	if err := s.searchBackend.IndexItems(ctx, items); err != nil {
+		searchIndexErrors.Inc()
+		log.Printf("Failed to index %d item(s) in search backend: %v", len(items), err)
+	}
+}
+
+// reindexItem re-reads an item from the read model and pushes it to the
+// search backend, dropping cached database searches either way.
+func (s *service) reindexItem(ctx context.Context, id uuid.UUID) {
+	s.searchCache.invalidate()
+	if s.searchBackend == nil {
+		return
+	}
+	item, err := s.readItem(ctx, id)
+	if err != nil {
+		log.Printf("Failed to load item %s for indexing: %v", id, err)
+		return
+	}
+	s.indexItems(ctx, item)
+}
@@ -0,0 +1,24 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchFilterPassesQueryTextWhole(t *testing.T) {
+	for _, q := range []string{"pride prejudice", `"pride and prejudice"`, "austen -emma", "pride & prejudice!", "the"} {
+		where, args := searchFilter(SearchParams{Query: q})
+		assert.Contains(t, where, "websearch_to_tsquery('english', $1)", q)
+		assert.NotContains(t, where, " to_tsquery(", "to_tsquery rejects plain phrases")
+		assert.Equal(t, []interface{}{q}, args)
+	}
+}
+
+func TestSearchFilterNumbersArguments(t *testing.T) {
+	where, args := searchFilter(SearchParams{Query: "emma", Status: "active", Author: "austen", AvailableOnly: true})
+	assert.Contains(t, where, "status = $2")
+	assert.Contains(t, where, "$3")
+	assert.Contains(t, where, "available > 0")
+	assert.Equal(t, []interface{}{"emma", "active", "austen"}, args)
+}
@@ -0,0 +1,127 @@
+// internal/catalog/searchcache.go
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultSearchCacheTTL is how long a database search result is reused.
+// Copies reserved and released in that time are not reflected in it.
+const DefaultSearchCacheTTL = 2 * time.Second
+
+// maxSearchCacheEntries bounds how many distinct searches are cached, so a
+// stream of one-off queries cannot grow the cache without limit.
+const maxSearchCacheEntries = 1000
+
+// WithSearchCacheTTL sets how long database search results are reused for
+// identical searches. Zero or less disables the cache; concurrent identical
+// searches still share one query.
+func WithSearchCacheTTL(ttl time.Duration) Option {
+	return func(s *service) {
+		s.searchCache.ttl = ttl
+	}
+}
+
+// searchCache answers identical database searches from one query: searches
+// made while one is in flight wait for and share its result, and results
+// are then kept for ttl. Item changes that searches can see invalidate it.
+// Cached results are shared between callers and must not be modified.
+type searchCache struct {
+	ttl    time.Duration
+	flight singleflight.Group
+
+	mu         sync.Mutex
+	generation uint64
+	entries    map[string]searchCacheEntry
+}
+
+type searchCacheEntry struct {
+	result  *SearchResult
+	expires time.Time
+}
+
+// search returns the result of load for params, reusing a cached or
+// in-flight one. It reports whether the result came from the cache. The
+// shared query runs to completion even if the caller that started it goes
+// away, so the callers still waiting on it are not failed.
+func (c *searchCache) search(ctx context.Context, params SearchParams, load func(context.Context, SearchParams) (*SearchResult, error)) (*SearchResult, bool, error) {
+	key := searchKey(params)
+
+	c.mu.Lock()
+	generation := c.generation
+	if entry, ok := c.entries[key]; ok && time.Now().Before(entry.expires) {
+		c.mu.Unlock()
+		return entry.result, true, nil
+	}
+	c.mu.Unlock()
+
+	// A search begun before an invalidation must not be joined after it.
+	flight := c.flight.DoChan(fmt.Sprintf("%d|%s", generation, key), func() (interface{}, error) {
+		result, err := load(context.WithoutCancel(ctx), params)
+		if err == nil {
+			c.store(key, generation, result)
+		}
+		return result, err
+	})
+	select {
+	case res := <-flight:
+		if res.Err != nil {
+			return nil, false, res.Err
+		}
+		return res.Val.(*SearchResult), false, nil
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	}
+}
+
+// store caches result under key, unless the cache has been invalidated
+// since the search producing it began.
+func (c *searchCache) store(key string, generation uint64, result *SearchResult) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	now := time.Now()
+	if len(c.entries) >= maxSearchCacheEntries {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= maxSearchCacheEntries {
+			return
+		}
+	}
+	if c.entries == nil {
+		c.entries = make(map[string]searchCacheEntry)
+	}
+	c.entries[key] = searchCacheEntry{result: result, expires: now.Add(c.ttl)}
+}
+
+// invalidate drops every cached result and detaches searches in flight, so
+// later searches see the change that prompted it.
+func (c *searchCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.entries = nil
+}
+
+// searchKey identifies the results of normalized params. Query text is
+// matched regardless of case and spacing, and the author regardless of
+// case, so both are folded to match.
+func searchKey(p SearchParams) string {
+	query := strings.Join(strings.Fields(strings.ToLower(p.Query)), " ")
+	return fmt.Sprintf("%q|%q|%q|%t|%t|%q|%d|%d",
+		query, p.Status, strings.ToLower(p.Author), p.IncludeRetired, p.AvailableOnly, p.Sort, p.Limit, p.Offset)
+}
@@ -0,0 +1,145 @@
+package catalog
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingSearch is a database search that takes delay and counts its calls.
+func countingSearch(calls *atomic.Int64, delay time.Duration) func(context.Context, SearchParams) (*SearchResult, error) {
+	return func(ctx context.Context, params SearchParams) (*SearchResult, error) {
+		calls.Add(1)
+		time.Sleep(delay)
+		return &SearchResult{Limit: params.Limit}, nil
+	}
+}
+
+func TestSearchCacheSharesConcurrentSearches(t *testing.T) {
+	var calls atomic.Int64
+	c := &searchCache{}
+	load := countingSearch(&calls, 100*time.Millisecond)
+
+	var wg sync.WaitGroup
+	for _, q := range []string{"pride prejudice", "Pride  Prejudice", " pride PREJUDICE"} {
+		for range 5 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _, err := c.search(context.Background(), SearchParams{Query: q, Limit: 20}, load)
+				assert.NoError(t, err)
+			}()
+		}
+	}
+	wg.Wait()
+	assert.EqualValues(t, 1, calls.Load(), "identical searches in flight should share one query")
+
+	// Without a TTL nothing is kept once the query is done.
+	c.search(context.Background(), SearchParams{Query: "pride prejudice", Limit: 20}, load)
+	assert.EqualValues(t, 2, calls.Load())
+}
+
+func TestSearchCacheReusesResultsUntilInvalidated(t *testing.T) {
+	var calls atomic.Int64
+	c := &searchCache{ttl: time.Minute}
+	load := countingSearch(&calls, 0)
+	params := SearchParams{Query: "emma", Limit: 20}
+
+	_, cached, err := c.search(context.Background(), params, load)
+	assert.NoError(t, err)
+	assert.False(t, cached)
+	_, cached, _ = c.search(context.Background(), params, load)
+	assert.True(t, cached)
+	_, cached, _ = c.search(context.Background(), SearchParams{Query: "emma", Limit: 10}, load)
+	assert.False(t, cached, "a different page is a different search")
+	assert.EqualValues(t, 2, calls.Load())
+
+	c.invalidate()
+	_, cached, _ = c.search(context.Background(), params, load)
+	assert.False(t, cached, "an invalidated result should be searched again")
+	assert.EqualValues(t, 3, calls.Load())
+}
+
+func TestSearchCacheDropsResultsOfSearchesInvalidatedInFlight(t *testing.T) {
+	c := &searchCache{ttl: time.Minute}
+	params := SearchParams{Query: "emma", Limit: 20}
+	_, _, err := c.search(context.Background(), params, func(context.Context, SearchParams) (*SearchResult, error) {
+		c.invalidate() // an item changes while the search runs
+		return &SearchResult{}, nil
+	})
+	assert.NoError(t, err)
+
+	var calls atomic.Int64
+	_, cached, _ := c.search(context.Background(), params, countingSearch(&calls, 0))
+	assert.False(t, cached, "a result from before the invalidation should not be kept")
+	assert.EqualValues(t, 1, calls.Load())
+}
+
+func TestSearchCacheOutlivesCallerThatStartedIt(t *testing.T) {
+	c := &searchCache{}
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	load := func(ctx context.Context, _ SearchParams) (*SearchResult, error) {
+		<-release
+		return &SearchResult{}, ctx.Err()
+	}
+
+	first := make(chan error)
+	go func() {
+		_, _, err := c.search(ctx, SearchParams{Query: "emma"}, load)
+		first <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	second := make(chan error)
+	go func() {
+		_, _, err := c.search(context.Background(), SearchParams{Query: "emma"}, load)
+		second <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-first, context.Canceled)
+	close(release)
+	assert.NoError(t, <-second, "the shared query should not fail with the first caller's context")
+}
+
+// BenchmarkSearchCache runs many concurrent searches for a few popular
+// terms against a database search taking a millisecond, reporting how many
+// database queries each search cost with and without the cache.
+func BenchmarkSearchCache(b *testing.B) {
+	terms := []string{"pride prejudice", "emma", "persuasion", "sense sensibility"}
+	for _, bc := range []struct {
+		name   string
+		direct bool
+		ttl    time.Duration
+	}{
+		{name: "uncached", direct: true},
+		{name: "singleflight"},
+		{name: "singleflight+ttl", ttl: DefaultSearchCacheTTL},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			var calls atomic.Int64
+			c := &searchCache{ttl: bc.ttl}
+			load := countingSearch(&calls, time.Millisecond)
+			var n atomic.Int64
+			b.SetParallelism(16)
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					params := SearchParams{Query: terms[n.Add(1)%int64(len(terms))], Limit: 20}
+					if bc.direct {
+						load(context.Background(), params)
+						continue
+					}
+					if _, _, err := c.search(context.Background(), params, load); err != nil {
+						b.Error(err)
+					}
+				}
+			})
+			b.ReportMetric(float64(calls.Load())/float64(b.N), "db-queries/op")
+		})
+	}
+}
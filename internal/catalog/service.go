@@ -3,15 +3,29 @@ package catalog
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 // Service defines the interface for the catalog service.
 type Service interface {
-	AddItem(ctx context.Context, isbn, title, author string, totalCopies int) (*Item, error)
-	GetItem(ctx context.Context, id uuid.UUID) (*Item, error)
-	UpdateItemCopies(ctx context.Context, id uuid.UUID, newTotal, newAvailable int) error
+	AddItem(ctx context.Context, isbn, title, author, category string, totalCopies int) (*Item, error)
+	AddItems(ctx context.Context, items []NewItem) ([]ImportResult, error)
+	GetItem(ctx context.Context, id uuid.UUID, includeRetired bool) (*Item, error)
+	ReconstituteItem(ctx context.Context, id uuid.UUID) (*Item, error)
+	GetItemAsOf(ctx context.Context, id uuid.UUID, asOf time.Time) (*Item, error)
+	GetItemAtVersion(ctx context.Context, id uuid.UUID, version int) (*Item, error)
+	GetItemEvents(ctx context.Context, id uuid.UUID, fromVersion, toVersion int) ([]Event, error)
+	UpdateItemCopies(ctx context.Context, id uuid.UUID, newTotal, newAvailable, expectedVersion int) error
+	AdjustCopies(ctx context.Context, id uuid.UUID, totalDelta, availableDelta int, reason string) (*Item, error)
+	UpdateItemDetails(ctx context.Context, id uuid.UUID, patch ItemDetailsPatch) (*Item, error)
+	ReserveCopy(ctx context.Context, id uuid.UUID) error
+	ReleaseCopy(ctx context.Context, id uuid.UUID) error
 	RemoveItem(ctx context.Context, id uuid.UUID) error
-	Search(ctx context.Context, query string) ([]*Item, error)
+	RestoreItem(ctx context.Context, id uuid.UUID) error
+	Search(ctx context.Context, params SearchParams) (*SearchResult, error)
+	ListItems(ctx context.Context, params ListParams) (items []*Item, total int, err error)
+	ExportItems(ctx context.Context, since time.Time, fn func(*Item) error) error
+	ReconcileAvailability(ctx context.Context, correct bool) ([]Discrepancy, error)
 }
@@ -0,0 +1,135 @@
+// internal/circulation/calendar.go
+package circulation
+
+import (
+	"fmt"
+	"libranexus/internal/catalog"
+	"libranexus/internal/membership"
+	"os"
+	"strings"
+	"time"
+)
+
+// maxClosedRun bounds how far a due date is rolled forward, so a calendar
+// closed for good cannot stall a checkout.
+const maxClosedRun = 366
+
+// Calendar tells which days the library is open, so loans do not fall due
+// on a day they cannot be returned.
+type Calendar interface {
+	IsOpen(day time.Time) bool
+}
+
+// AlwaysOpen is the calendar of a library that never closes. It is the
+// default, leaving due dates a whole number of loan periods out.
+type AlwaysOpen struct{}
+
+func (AlwaysOpen) IsOpen(day time.Time) bool {
+	return true
+}
+
+// ClosureCalendar closes the library on certain weekdays and holidays, as
+// dated in its location.
+type ClosureCalendar struct {
+	location       *time.Location
+	closedWeekdays map[time.Weekday]bool
+	holidays       map[string]bool
+}
+
+// NewClosureCalendar creates a calendar closed every closedWeekday and on
+// each holiday, with days reckoned in loc.
+func NewClosureCalendar(loc *time.Location, closedWeekdays []time.Weekday, holidays []time.Time) (*ClosureCalendar, error) {
+	c := &ClosureCalendar{
+		location:       loc,
+		closedWeekdays: make(map[time.Weekday]bool),
+		holidays:       make(map[string]bool),
+	}
+	for _, d := range closedWeekdays {
+		c.closedWeekdays[d] = true
+	}
+	if len(c.closedWeekdays) == 7 {
+		return nil, fmt.Errorf("the library must open on at least one weekday")
+	}
+	for _, h := range holidays {
+		c.holidays[h.Format(time.DateOnly)] = true
+	}
+	return c, nil
+}
+
+func (c *ClosureCalendar) IsOpen(day time.Time) bool {
+	local := day.In(c.location)
+	return !c.closedWeekdays[local.Weekday()] && !c.holidays[local.Format(time.DateOnly)]
+}
+
+// NextOpenDay returns t, or t moved forward a day at a time, keeping its
+// time of day, until it lands on a day cal has the library open.
+func NextOpenDay(cal Calendar, t time.Time) time.Time {
+	for i := 0; i < maxClosedRun && !cal.IsOpen(t); i++ {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+// dueDate is when a loan of item to member taken out at start falls due: one
+// loan period later, rolled forward to the next day the library is open.
+func (s *service) dueDate(start time.Time, member *membership.Member, item *catalog.Item) time.Time {
+	return NextOpenDay(s.calendar, start.Add(s.loanPolicy.LoanPeriod(member.MembershipTier, item.Category)))
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// CalendarFromEnv builds the library's calendar from LIBRARY_CLOSED_WEEKDAYS,
+// such as "sunday,monday", and LIBRARY_HOLIDAYS, such as
+// "2024-12-25,2025-01-01", with days reckoned in LIBRARY_TIMEZONE (UTC by
+// default). With neither list set the library is always open.
+func CalendarFromEnv() (Calendar, error) {
+	closedDays, holidayList := os.Getenv("LIBRARY_CLOSED_WEEKDAYS"), os.Getenv("LIBRARY_HOLIDAYS")
+	if closedDays == "" && holidayList == "" {
+		return AlwaysOpen{}, nil
+	}
+
+	loc := time.UTC
+	if v := os.Getenv("LIBRARY_TIMEZONE"); v != "" {
+		var err error
+		if loc, err = time.LoadLocation(v); err != nil {
+			return nil, fmt.Errorf("invalid LIBRARY_TIMEZONE: %w", err)
+		}
+	}
+
+	var closed []time.Weekday
+	for _, name := range splitList(closedDays) {
+		d, ok := weekdays[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("invalid LIBRARY_CLOSED_WEEKDAYS: unknown weekday %q", name)
+		}
+		closed = append(closed, d)
+	}
+	var holidays []time.Time
+	for _, date := range splitList(holidayList) {
+		h, err := time.Parse(time.DateOnly, date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LIBRARY_HOLIDAYS: %q is not a YYYY-MM-DD date", date)
+		}
+		holidays = append(holidays, h)
+	}
+	return NewClosureCalendar(loc, closed, holidays)
+}
+
+// splitList splits a comma-separated list, dropping blank entries.
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,78 @@
+package circulation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"libranexus/internal/catalog"
+	"libranexus/internal/membership"
+)
+
+func TestNextOpenDay(t *testing.T) {
+	cal, err := NewClosureCalendar(time.UTC, []time.Weekday{time.Sunday, time.Monday}, []time.Time{time.Date(2024, 3, 26, 0, 0, 0, 0, time.UTC)})
+	require.NoError(t, err)
+
+	saturday := time.Date(2024, 3, 23, 15, 30, 0, 0, time.UTC)
+	assert.Equal(t, saturday, NextOpenDay(cal, saturday), "open days are kept")
+	assert.Equal(t, time.Date(2024, 3, 27, 15, 30, 0, 0, time.UTC), NextOpenDay(cal, saturday.AddDate(0, 0, 1)),
+		"skips Sunday, Monday and the Tuesday holiday, keeping the time of day")
+	assert.Equal(t, saturday, NextOpenDay(AlwaysOpen{}, saturday))
+}
+
+func TestClosureCalendarUsesLibraryTimeZone(t *testing.T) {
+	loc := time.FixedZone("UTC+10", 10*3600)
+	cal, err := NewClosureCalendar(loc, []time.Weekday{time.Sunday}, nil)
+	require.NoError(t, err)
+
+	// 20:00 UTC on Saturday is already Sunday morning at the library.
+	assert.False(t, cal.IsOpen(time.Date(2024, 3, 23, 20, 0, 0, 0, time.UTC)))
+	assert.True(t, cal.IsOpen(time.Date(2024, 3, 23, 10, 0, 0, 0, time.UTC)))
+}
+
+func TestNewClosureCalendarRejectsNoOpenDays(t *testing.T) {
+	_, err := NewClosureCalendar(time.UTC, []time.Weekday{0, 1, 2, 3, 4, 5, 6}, nil)
+	assert.Error(t, err)
+}
+
+func TestDueDateRollsPastClosures(t *testing.T) {
+	cal, err := NewClosureCalendar(time.UTC, []time.Weekday{time.Sunday}, nil)
+	require.NoError(t, err)
+	s := &service{loanPolicy: DefaultLoanPolicy(), calendar: cal}
+	member, item := &membership.Member{MembershipTier: "basic"}, &catalog.Item{Category: "general"}
+
+	// Two weeks from a Sunday is a Sunday, so the loan falls due on Monday.
+	sunday := time.Date(2024, 3, 24, 11, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 4, 8, 11, 0, 0, 0, time.UTC), s.dueDate(sunday, member, item))
+
+	s.calendar = AlwaysOpen{}
+	assert.Equal(t, sunday.Add(defaultLoanPeriod), s.dueDate(sunday, member, item))
+}
+
+func TestCalendarFromEnv(t *testing.T) {
+	t.Setenv("LIBRARY_CLOSED_WEEKDAYS", "")
+	t.Setenv("LIBRARY_HOLIDAYS", "")
+	cal, err := CalendarFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, AlwaysOpen{}, cal)
+
+	t.Setenv("LIBRARY_CLOSED_WEEKDAYS", "Sunday, monday")
+	t.Setenv("LIBRARY_HOLIDAYS", "2024-12-25")
+	t.Setenv("LIBRARY_TIMEZONE", "Europe/London")
+	cal, err = CalendarFromEnv()
+	require.NoError(t, err)
+	assert.False(t, cal.IsOpen(time.Date(2024, 12, 25, 12, 0, 0, 0, time.UTC)))
+	assert.False(t, cal.IsOpen(time.Date(2024, 3, 25, 12, 0, 0, 0, time.UTC)), "a Monday")
+	assert.True(t, cal.IsOpen(time.Date(2024, 3, 26, 12, 0, 0, 0, time.UTC)))
+
+	t.Setenv("LIBRARY_CLOSED_WEEKDAYS", "caturday")
+	_, err = CalendarFromEnv()
+	assert.Error(t, err)
+
+	t.Setenv("LIBRARY_CLOSED_WEEKDAYS", "")
+	t.Setenv("LIBRARY_HOLIDAYS", "25/12/2024")
+	_, err = CalendarFromEnv()
+	assert.Error(t, err)
+}
@@ -0,0 +1,222 @@
+package circulation
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"libranexus/internal/catalog"
+	"libranexus/internal/clients/clientmocks"
+	"libranexus/internal/membership"
+)
+
+// scriptedDB is a database/sql connector answering each statement by the
+// first rule whose match the statement contains, so the checkout saga can
+// run without Postgres. Statements no rule matches fail the test.
+type scriptedDB struct {
+	t     *testing.T
+	rules []scriptRule
+
+	mu         sync.Mutex
+	sagaStates []string                  // states the saga was moved to, in order
+	args       map[string][]driver.Value // arguments of the last statement each rule answered
+}
+
+// scriptRule answers statements containing match with rows, or with err.
+type scriptRule struct {
+	match string
+	rows  [][]driver.Value
+	err   error
+}
+
+func openScriptedDB(t *testing.T, rules ...scriptRule) (*sql.DB, *scriptedDB) {
+	d := &scriptedDB{t: t, rules: rules}
+	db := sql.OpenDB(d)
+	t.Cleanup(func() { db.Close() })
+	return db, d
+}
+
+func (d *scriptedDB) answer(query string, args []driver.NamedValue) (scriptRule, error) {
+	if strings.Contains(query, "UPDATE saga_instances") {
+		d.mu.Lock()
+		d.sagaStates = append(d.sagaStates, fmt.Sprint(args[1].Value))
+		d.mu.Unlock()
+	}
+	for _, r := range d.rules {
+		if strings.Contains(query, r.match) {
+			d.mu.Lock()
+			if d.args == nil {
+				d.args = make(map[string][]driver.Value)
+			}
+			d.args[r.match] = make([]driver.Value, len(args))
+			for i, a := range args {
+				d.args[r.match][i] = a.Value
+			}
+			d.mu.Unlock()
+			return r, r.err
+		}
+	}
+	d.t.Errorf("unexpected statement: %s", query)
+	return scriptRule{}, errors.New("unexpected statement")
+}
+
+// argsOf returns the arguments of the last statement the rule matching
+// match answered.
+func (d *scriptedDB) argsOf(match string) []driver.Value {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.args[match]
+}
+
+func (d *scriptedDB) states() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.sagaStates...)
+}
+
+func (d *scriptedDB) Connect(context.Context) (driver.Conn, error) { return scriptedConn{d}, nil }
+func (d *scriptedDB) Driver() driver.Driver                        { return nil }
+
+type scriptedConn struct{ d *scriptedDB }
+
+func (c scriptedConn) Prepare(query string) (driver.Stmt, error) {
+	return scriptedStmt{c.d, query}, nil
+}
+func (c scriptedConn) Close() error              { return nil }
+func (c scriptedConn) Begin() (driver.Tx, error) { return scriptedTx{}, nil }
+
+func (c scriptedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if _, err := c.d.answer(query, args); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c scriptedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	r, err := c.d.answer(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &scriptedRows{rows: r.rows}, nil
+}
+
+type scriptedStmt struct {
+	d     *scriptedDB
+	query string
+}
+
+func (s scriptedStmt) Close() error  { return nil }
+func (s scriptedStmt) NumInput() int { return -1 }
+
+func (s scriptedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return scriptedConn{s.d}.ExecContext(context.Background(), s.query, named(args))
+}
+
+func (s scriptedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return scriptedConn{s.d}.QueryContext(context.Background(), s.query, named(args))
+}
+
+func named(args []driver.Value) []driver.NamedValue {
+	nv := make([]driver.NamedValue, len(args))
+	for i, a := range args {
+		nv[i] = driver.NamedValue{Ordinal: i + 1, Value: a}
+	}
+	return nv
+}
+
+type scriptedTx struct{}
+
+func (scriptedTx) Commit() error   { return nil }
+func (scriptedTx) Rollback() error { return nil }
+
+type scriptedRows struct{ rows [][]driver.Value }
+
+func (r *scriptedRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return []string{"unused"}
+	}
+	return make([]string, len(r.rows[0]))
+}
+
+func (r *scriptedRows) Close() error { return nil }
+
+func (r *scriptedRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// checkoutRules script a checkout by a member with nothing out and no hold
+// waiting, whose saga bookkeeping always succeeds, ahead of rules.
+func checkoutRules(rules ...scriptRule) []scriptRule {
+	return append([]scriptRule{
+		{match: "COUNT(*) FROM checkouts", rows: [][]driver.Value{{int64(0)}}},
+		{match: "FROM holds"},
+		{match: "INSERT INTO saga_instances"},
+		{match: "UPDATE saga_instances"},
+		{match: "pg_advisory_xact_lock"},
+	}, rules...)
+}
+
+func newCompensationTest(t *testing.T, rules ...scriptRule) (Service, *scriptedDB, *clientmocks.Catalog, catalog.Item, membership.Member) {
+	db, script := openScriptedDB(t, checkoutRules(rules...)...)
+	item := catalog.Item{ID: uuid.New(), Status: "active", Category: "fiction", TotalCopies: 3, Available: 2, Version: 5}
+	member := membership.Member{ID: uuid.New(), Status: "active", MembershipTier: "standard", MaxCheckouts: 5, ExpiresAt: time.Now().AddDate(1, 0, 0)}
+	cat := clientmocks.NewCatalog(item)
+	svc := NewService(eventstore.NewEventStore(db), db, cat, clientmocks.NewMembership(member))
+	return svc, script, cat, item, member
+}
+
+func TestCheckoutReleasesCopyWhenEventAppendFails(t *testing.T) {
+	appendErr := errors.New("disk full")
+	// Recording the compensation also reads the events table; failing it
+	// is only logged.
+	svc, script, cat, item, member := newCompensationTest(t, scriptRule{match: "FROM events", err: appendErr})
+
+	_, err := svc.CheckoutItem(context.Background(), member.ID, item.ID)
+	require.ErrorIs(t, err, appendErr)
+
+	after, _ := cat.Item(item.ID)
+	assert.Equal(t, item.Available, after.Available, "the reserved copy should be back on the shelf")
+	assert.Equal(t, item.TotalCopies, after.TotalCopies)
+	assert.Equal(t, []string{"GetItem", "ReserveCopy", "ReleaseCopy"}, cat.Calls())
+	assert.Equal(t, []string{string(sagaItemReserved), string(sagaCompensating), string(sagaCompensated)}, script.states())
+}
+
+func TestCheckoutLeavesSagaCompensatingWhenReleaseFails(t *testing.T) {
+	svc, script, cat, item, member := newCompensationTest(t, scriptRule{match: "FROM events", err: errors.New("disk full")})
+	cat.ReleaseCopyErr = errors.New("catalog unreachable")
+
+	_, err := svc.CheckoutItem(context.Background(), member.ID, item.ID)
+	require.Error(t, err)
+
+	after, _ := cat.Item(item.ID)
+	assert.Equal(t, item.Available-1, after.Available, "the copy stays reserved until recovery releases it")
+	assert.Equal(t, []string{string(sagaItemReserved), string(sagaCompensating)}, script.states(),
+		"the saga should be left for the recovery worker")
+}
+
+func TestCheckoutCompensatesWithoutReleaseWhenNoCopyWasReserved(t *testing.T) {
+	svc, script, cat, item, member := newCompensationTest(t)
+	cat.ReserveCopyErr = catalog.ErrNoCopiesAvailable
+
+	_, err := svc.CheckoutItem(context.Background(), member.ID, item.ID)
+	assert.ErrorIs(t, err, ErrItemUnavailable)
+	assert.Equal(t, []string{"GetItem", "ReserveCopy"}, cat.Calls(), "nothing was taken, so nothing is put back")
+	assert.Equal(t, []string{string(sagaCompensated)}, script.states())
+}
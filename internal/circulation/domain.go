@@ -2,23 +2,180 @@
 package circulation
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"libranexus/internal/membership"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
 )
 
+const (
+	defaultHistoryLimit = 20
+	maxHistoryLimit     = 100
+)
+
+var (
+	ErrItemUnavailable     = errors.New("item is not available")
+	ErrItemAvailable       = errors.New("item is available for checkout; no hold needed")
+	ErrDuplicateHold       = errors.New("member already has an open hold on this item")
+	ErrItemNotCheckoutable = errors.New("item cannot be checked out")
+	ErrMemberNotEligible   = errors.New("member is not eligible for checkout")
+	ErrMembershipExpired   = errors.New("membership has expired")
+	// ErrCheckoutNotFound means there is no open checkout to act on: the ID
+	// is unknown or the item has already been returned.
+	ErrCheckoutNotFound     = errors.New("checkout not found or already returned")
+	ErrRenewalLimitReached  = errors.New("checkout has reached its renewal limit")
+	ErrItemOnHold           = errors.New("item has pending holds and cannot be renewed")
+	ErrFinesOutstanding     = errors.New("overdue checkout cannot be renewed until fines are paid")
+	ErrCheckoutLimitReached = errors.New("checkout limit reached")
+	ErrInvalidFilter        = errors.New("limit and offset must be non-negative")
+	ErrNotCheckoutHolder    = errors.New("checkout is held by another member")
+)
+
+// Caller is who a request acts for: the member the gateway authenticated and
+// their role.
+type Caller struct {
+	MemberID uuid.UUID
+	Role     string
+}
+
+// mayActFor reports whether the caller may act on a checkout held by
+// memberID. Only its holder and administrators may.
+func (c Caller) mayActFor(memberID uuid.UUID) bool {
+	return c.MemberID == memberID || c.Role == membership.RoleAdmin
+}
+
+// CheckoutLimitError reports that a member already has as many items out as
+// their tier allows. It matches ErrCheckoutLimitReached.
+type CheckoutLimitError struct {
+	Count int `json:"count"`
+	Limit int `json:"limit"`
+}
+
+func (e *CheckoutLimitError) Error() string {
+	return fmt.Sprintf("%s: %d of %d items checked out", ErrCheckoutLimitReached, e.Count, e.Limit)
+}
+
+func (e *CheckoutLimitError) Is(target error) bool {
+	return target == ErrCheckoutLimitReached
+}
+
 // Checkout represents an item checked out by a member.
 type Checkout struct {
 	ID           uuid.UUID `json:"id"`
 	MemberID     uuid.UUID `json:"member_id"`
 	ItemID       uuid.UUID `json:"item_id"`
+	ItemTitle    string    `json:"item_title,omitempty"`
 	CheckoutDate time.Time `json:"checkout_date"`
 	DueDate      time.Time `json:"due_date"`
 	ReturnDate   time.Time `json:"return_date,omitempty"`
+	RenewalCount int       `json:"renewal_count"`
 	Status       string    `json:"status"`
 	Version      int       `json:"version"`
 }
 
+// Apply folds a single event into the checkout's state. Events must be
+// applied in version order, starting from an empty checkout or a snapshot.
+// Only the event store's fields are rebuilt; ItemTitle comes from the catalog.
+func (c *Checkout) Apply(event eventstore.Event) error {
+	switch event.EventType {
+	case "ItemCheckedOut":
+		var e ItemCheckedOutEvent
+		if err := json.Unmarshal(event.EventData, &e); err != nil {
+			return fmt.Errorf("failed to decode %s: %w", event.EventType, err)
+		}
+		c.ID = e.CheckoutID
+		c.MemberID = e.MemberID
+		c.ItemID = e.ItemID
+		c.CheckoutDate = event.CreatedAt
+		c.DueDate = e.DueDate
+		c.Status = "active"
+	case "CheckoutRenewed":
+		var e CheckoutRenewedEvent
+		if err := json.Unmarshal(event.EventData, &e); err != nil {
+			return fmt.Errorf("failed to decode %s: %w", event.EventType, err)
+		}
+		c.DueDate = e.DueDate
+		c.RenewalCount = e.RenewalCount
+		c.Status = "active"
+	case "ItemOverdue":
+		c.Status = "overdue"
+	case "ItemReturned":
+		var e ItemReturnedEvent
+		if err := json.Unmarshal(event.EventData, &e); err != nil {
+			return fmt.Errorf("failed to decode %s: %w", event.EventType, err)
+		}
+		c.ReturnDate = e.ReturnDate
+		c.Status = "returned"
+	case "CheckoutCompensated", "CompensationFailed":
+		// The checkout was never opened; only the attempt is on record.
+		var e CheckoutCompensatedEvent
+		if err := json.Unmarshal(event.EventData, &e); err != nil {
+			return fmt.Errorf("failed to decode %s: %w", event.EventType, err)
+		}
+		c.ID = e.CheckoutID
+		c.MemberID = e.MemberID
+		c.ItemID = e.ItemID
+		c.Status = "compensated"
+		if event.EventType == "CompensationFailed" {
+			c.Status = "compensation_failed"
+		}
+	default:
+		return fmt.Errorf("unknown checkout event type %q", event.EventType)
+	}
+
+	c.Version = event.Version
+	return nil
+}
+
+// CheckoutFilter selects a page of a member's checkout history.
+type CheckoutFilter struct {
+	ActiveOnly bool
+	Limit      int
+	Offset     int
+}
+
+// normalize validates the filter, applying the default page size when none
+// is given and capping it at the maximum.
+func (f *CheckoutFilter) normalize() error {
+	if f.Limit < 0 || f.Offset < 0 {
+		return ErrInvalidFilter
+	}
+	if f.Limit == 0 {
+		f.Limit = defaultHistoryLimit
+	}
+	if f.Limit > maxHistoryLimit {
+		f.Limit = maxHistoryLimit
+	}
+	return nil
+}
+
+// CheckoutView is a checkout with the details a front-end shows alongside
+// it, joined from the catalog's and membership's read models. Details of an
+// item or member missing from them are left empty.
+type CheckoutView struct {
+	Checkout
+	ItemAuthor string `json:"item_author,omitempty"`
+	MemberName string `json:"member_name,omitempty"`
+}
+
+// Hold represents a member's place in the queue for an unavailable item.
+// ExpiresAt is when a pending hold lapses or, once fulfilled, when the copy
+// set aside is released if not collected.
+type Hold struct {
+	ID          uuid.UUID  `json:"id"`
+	MemberID    uuid.UUID  `json:"member_id"`
+	ItemID      uuid.UUID  `json:"item_id"`
+	PlacedAt    time.Time  `json:"placed_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	FulfilledAt *time.Time `json:"fulfilled_at,omitempty"`
+	Status      string     `json:"status"`
+	Version     int        `json:"version"`
+}
+
 // Event represents a domain event related to circulation.
 type Event struct {
 	Type string      `json:"type"`
@@ -40,3 +197,75 @@ type ItemReturnedEvent struct {
 	ItemID     uuid.UUID `json:"item_id"`
 	ReturnDate time.Time `json:"return_date"`
 }
+
+// CheckoutRenewedEvent is published when a checkout's due date is extended.
+type CheckoutRenewedEvent struct {
+	CheckoutID   uuid.UUID `json:"checkout_id"`
+	MemberID     uuid.UUID `json:"member_id"`
+	ItemID       uuid.UUID `json:"item_id"`
+	DueDate      time.Time `json:"due_date"`
+	RenewalCount int       `json:"renewal_count"`
+}
+
+// CheckoutCompensatedEvent is recorded when a checkout failed after its copy
+// was reserved and the copy was released again. Reason is why the checkout
+// failed.
+type CheckoutCompensatedEvent struct {
+	CheckoutID uuid.UUID `json:"checkout_id"`
+	MemberID   uuid.UUID `json:"member_id"`
+	ItemID     uuid.UUID `json:"item_id"`
+	Reason     string    `json:"reason"`
+}
+
+// CompensationFailedEvent is recorded when a failed checkout's reserved copy
+// could not be released, leaving the item's availability one too low until
+// the reconciler corrects it.
+type CompensationFailedEvent struct {
+	CheckoutID uuid.UUID `json:"checkout_id"`
+	MemberID   uuid.UUID `json:"member_id"`
+	ItemID     uuid.UUID `json:"item_id"`
+	Reason     string    `json:"reason"`
+	Error      string    `json:"error"`
+}
+
+// ItemHeldEvent is published when a member places a hold on an item.
+type ItemHeldEvent struct {
+	HoldID    uuid.UUID `json:"hold_id"`
+	MemberID  uuid.UUID `json:"member_id"`
+	ItemID    uuid.UUID `json:"item_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// HoldFulfilledEvent is published when a returned copy is set aside for a
+// hold. PickupBy is when the copy is released if not collected; events from
+// before pickup windows leave it zero.
+type HoldFulfilledEvent struct {
+	HoldID      uuid.UUID `json:"hold_id"`
+	MemberID    uuid.UUID `json:"member_id"`
+	ItemID      uuid.UUID `json:"item_id"`
+	FulfilledAt time.Time `json:"fulfilled_at"`
+	PickupBy    time.Time `json:"pickup_by"`
+}
+
+// HoldCollectedEvent is published when a member checks out the copy held for them.
+type HoldCollectedEvent struct {
+	HoldID     uuid.UUID `json:"hold_id"`
+	CheckoutID uuid.UUID `json:"checkout_id"`
+}
+
+// HoldExpiredEvent is published when a pending hold lapses before being
+// fulfilled, or a fulfilled one is not collected within its pickup window.
+type HoldExpiredEvent struct {
+	HoldID uuid.UUID `json:"hold_id"`
+}
+
+// ItemOverdueEvent is published each time overdue fines are charged for a checkout.
+type ItemOverdueEvent struct {
+	CheckoutID   uuid.UUID `json:"checkout_id"`
+	MemberID     uuid.UUID `json:"member_id"`
+	ItemID       uuid.UUID `json:"item_id"`
+	DueDate      time.Time `json:"due_date"`
+	DaysCharged  int       `json:"days_charged"`
+	FineAmount   float64   `json:"fine_amount"`
+	FinedThrough time.Time `json:"fined_through"`
+}
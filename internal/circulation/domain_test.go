@@ -0,0 +1,88 @@
+package circulation
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func checkoutEvent(t *testing.T, id uuid.UUID, eventType string, version int, data interface{}) eventstore.Event {
+	t.Helper()
+	jsonData, err := json.Marshal(data)
+	require.NoError(t, err)
+	return eventstore.Event{
+		AggregateID:   id,
+		AggregateType: "checkout",
+		EventType:     eventType,
+		EventData:     jsonData,
+		Version:       version,
+		CreatedAt:     time.Date(2024, 1, version, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestCheckoutApplyFoldsEvents(t *testing.T) {
+	id, memberID, itemID := uuid.New(), uuid.New(), uuid.New()
+	due := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	renewedDue := due.Add(14 * 24 * time.Hour)
+	returned := time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC)
+	events := []eventstore.Event{
+		checkoutEvent(t, id, "ItemCheckedOut", 1, ItemCheckedOutEvent{CheckoutID: id, MemberID: memberID, ItemID: itemID, DueDate: due}),
+		checkoutEvent(t, id, "CheckoutRenewed", 2, CheckoutRenewedEvent{CheckoutID: id, DueDate: renewedDue, RenewalCount: 1}),
+		checkoutEvent(t, id, "ItemOverdue", 3, ItemOverdueEvent{CheckoutID: id, DaysCharged: 1}),
+		checkoutEvent(t, id, "ItemReturned", 4, ItemReturnedEvent{CheckoutID: id, ReturnDate: returned}),
+	}
+
+	checkout := &Checkout{}
+	for _, event := range events {
+		require.NoError(t, checkout.Apply(event))
+	}
+
+	assert.Equal(t, id, checkout.ID)
+	assert.Equal(t, memberID, checkout.MemberID)
+	assert.Equal(t, itemID, checkout.ItemID)
+	assert.Equal(t, events[0].CreatedAt, checkout.CheckoutDate)
+	assert.Equal(t, renewedDue, checkout.DueDate)
+	assert.Equal(t, 1, checkout.RenewalCount)
+	assert.Equal(t, returned, checkout.ReturnDate)
+	assert.Equal(t, "returned", checkout.Status)
+	assert.Equal(t, 4, checkout.Version)
+}
+
+func TestCheckoutApplyMarksOverdue(t *testing.T) {
+	id := uuid.New()
+	checkout := &Checkout{ID: id, Status: "active", Version: 1}
+
+	require.NoError(t, checkout.Apply(checkoutEvent(t, id, "ItemOverdue", 2, ItemOverdueEvent{CheckoutID: id, DaysCharged: 1})))
+
+	assert.Equal(t, "overdue", checkout.Status)
+	assert.Equal(t, 2, checkout.Version)
+}
+
+func TestCheckoutApplyRejectsUnknownEvent(t *testing.T) {
+	checkout := &Checkout{}
+	err := checkout.Apply(eventstore.Event{EventType: "ItemTeleported", Version: 1})
+	assert.Error(t, err)
+}
+
+func TestCheckoutApplyRecordsCompensation(t *testing.T) {
+	id, memberID, itemID := uuid.New(), uuid.New(), uuid.New()
+
+	compensated := &Checkout{}
+	require.NoError(t, compensated.Apply(checkoutEvent(t, id, "CheckoutCompensated", 1,
+		CheckoutCompensatedEvent{CheckoutID: id, MemberID: memberID, ItemID: itemID, Reason: "membership unavailable"})))
+	assert.Equal(t, id, compensated.ID)
+	assert.Equal(t, itemID, compensated.ItemID)
+	assert.Equal(t, "compensated", compensated.Status)
+
+	failed := &Checkout{}
+	require.NoError(t, failed.Apply(checkoutEvent(t, id, "CompensationFailed", 1,
+		CompensationFailedEvent{CheckoutID: id, MemberID: memberID, ItemID: itemID, Reason: "membership unavailable", Error: "catalog unavailable"})))
+	assert.Equal(t, memberID, failed.MemberID)
+	assert.Equal(t, "compensation_failed", failed.Status)
+	assert.Equal(t, 1, failed.Version)
+}
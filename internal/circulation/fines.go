@@ -0,0 +1,163 @@
+// internal/circulation/fines.go
+package circulation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"libranexus/internal/database"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+)
+
+const day = 24 * time.Hour
+
+type overdueCheckout struct {
+	id           uuid.UUID
+	memberID     uuid.UUID
+	itemID       uuid.UUID
+	dueDate      time.Time
+	lastFineDate *time.Time
+	version      int
+}
+
+// AccrueFines marks past-due checkouts as overdue and charges members for every
+// day not yet fined. Each checkout records the date it has been fined through,
+// so running the job several times on the same day charges only once.
+// It returns the number of checkouts that were fined. What is overdue, and
+// which day it is, follow the service's clock rather than the database's.
+func (s *service) AccrueFines(ctx context.Context) (int, error) {
+	ctx = eventstore.WithActorID(ctx, eventstore.SystemActorID)
+	now := s.now()
+	today := now.UTC().Truncate(day)
+	checkouts, err := s.listUnfinedOverdueCheckouts(ctx, now, today)
+	if err != nil {
+		return 0, err
+	}
+
+	fined := 0
+	for _, c := range checkouts {
+		if err := s.accrueFine(ctx, c, now, today); err != nil {
+			log.Printf("Failed to accrue fine for checkout %s: %v", c.id, err)
+			continue
+		}
+		fined++
+	}
+
+	return fined, nil
+}
+
+// fineDate formats a day for the DATE column last_fine_date. Passing the
+// date itself, rather than a timestamp, keeps the database's time zone from
+// shifting it.
+func fineDate(day time.Time) string {
+	return day.Format("2006-01-02")
+}
+
+func (s *service) listUnfinedOverdueCheckouts(ctx context.Context, now, today time.Time) ([]overdueCheckout, error) {
+	query := `
+		SELECT id, member_id, item_id, due_date, last_fine_date, version
+		FROM checkouts
+		WHERE status IN ('active', 'overdue')
+		AND due_date < $1
+		AND (last_fine_date IS NULL OR last_fine_date < $2::date)
+		ORDER BY due_date ASC
+	`
+	rows, err := s.db.QueryContext(ctx, query, now, fineDate(today))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query overdue checkouts: %w", err)
+	}
+	defer rows.Close()
+
+	var checkouts []overdueCheckout
+	for rows.Next() {
+		var c overdueCheckout
+		if err := rows.Scan(&c.id, &c.memberID, &c.itemID, &c.dueDate, &c.lastFineDate, &c.version); err != nil {
+			return nil, fmt.Errorf("failed to scan checkout: %w", err)
+		}
+		checkouts = append(checkouts, c)
+	}
+
+	return checkouts, rows.Err()
+}
+
+func (s *service) accrueFine(ctx context.Context, c overdueCheckout, now, today time.Time) error {
+	ctx = eventstore.WithCausationID(ctx, c.id.String())
+
+	finedThrough := c.dueDate.UTC().Truncate(day)
+	if c.lastFineDate != nil {
+		finedThrough = c.lastFineDate.UTC().Truncate(day)
+	}
+
+	days := int(today.Sub(finedThrough) / day)
+	if days <= 0 {
+		return nil
+	}
+	amount := float64(days) * s.finePerDay
+
+	// The reference is stable for a given checkout and day, so a retry after a
+	// partial failure is recognised by the membership service and not re-charged.
+	if amount > 0 {
+		reference := fmt.Sprintf("overdue:%s:%s", c.id, fineDate(today))
+		reason := fmt.Sprintf("Overdue fine: %d day(s) for item %s", days, c.itemID)
+		if _, err := s.membershipClient.ChargeFine(ctx, c.memberID, amount, reason, reference); err != nil {
+			return fmt.Errorf("failed to charge fine: %w", err)
+		}
+	}
+
+	eventData := ItemOverdueEvent{
+		CheckoutID:   c.id,
+		MemberID:     c.memberID,
+		ItemID:       c.itemID,
+		DueDate:      c.dueDate,
+		DaysCharged:  days,
+		FineAmount:   amount,
+		FinedThrough: today,
+	}
+	jsonData, err := json.Marshal(eventData)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	event := eventstore.Event{
+		AggregateID:   c.id,
+		AggregateType: "checkout",
+		EventType:     "ItemOverdue",
+		EventData:     jsonData,
+		Version:       c.version + 1,
+	}
+
+	err = database.InTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.eventStore.AppendEventsTx(ctx, tx, c.id, "checkout", c.version, []eventstore.Event{event}); err != nil {
+			return fmt.Errorf("failed to append event: %w", err)
+		}
+		query := `
+			UPDATE checkouts
+			SET status = 'overdue', last_fine_date = $1::date, version = version + 1, updated_at = $2
+			WHERE id = $3 AND version = $4
+		`
+		if _, err := tx.ExecContext(ctx, query, fineDate(today), now, c.id, c.version); err != nil {
+			return fmt.Errorf("failed to update read model: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// Fines accrue daily, so a long-overdue checkout gathers events steadily.
+	s.snapshotIfDue(ctx, c.id, c.version, c.version+1)
+
+	s.notify(ctx, Notification{
+		Kind:       NotifyOverdue,
+		MemberID:   c.memberID,
+		ItemID:     c.itemID,
+		CheckoutID: c.id,
+		Deadline:   c.dueDate,
+		FineAmount: amount,
+	})
+	return nil
+}
@@ -0,0 +1,50 @@
+package circulation
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"libranexus/internal/clients/clientmocks"
+	"libranexus/internal/membership"
+)
+
+func TestAccrueFinesFollowsClock(t *testing.T) {
+	// Late in the evening, in a time zone behind UTC, it is already the
+	// next day by the fines calendar.
+	now := time.Date(2025, 3, 10, 22, 30, 0, 0, time.FixedZone("EST", -5*60*60))
+	member := membership.Member{ID: uuid.New(), Status: "active"}
+	checkoutID, itemID := uuid.New(), uuid.New()
+	dueDate := time.Date(2025, 3, 8, 12, 0, 0, 0, time.UTC)
+
+	db, script := openScriptedDB(t,
+		scriptRule{match: "FROM checkouts", rows: [][]driver.Value{{
+			checkoutID.String(), member.ID.String(), itemID.String(), dueDate, nil, int64(3),
+		}}},
+		scriptRule{match: "INSERT INTO events", rows: [][]driver.Value{{int64(4)}}},
+		scriptRule{match: "FROM events", rows: [][]driver.Value{{int64(3)}}},
+		scriptRule{match: "UPDATE checkouts"},
+	)
+	members := clientmocks.NewMembership(member)
+	svc := NewService(eventstore.NewEventStore(db), db, clientmocks.NewCatalog(), members,
+		WithFinePerDay(0.25), WithClock(func() time.Time { return now }))
+
+	fined, err := svc.AccrueFines(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, fined)
+
+	assert.Equal(t, []driver.Value{now, "2025-03-11"}, script.argsOf("FROM checkouts"), "overdue is judged by the service's clock")
+	update := script.argsOf("UPDATE checkouts")
+	require.Len(t, update, 4)
+	assert.Equal(t, "2025-03-11", update[0])
+	assert.Equal(t, now, update[1])
+
+	charged, _ := members.Member(member.ID)
+	assert.Equal(t, 0.75, charged.FineBalance, "three days from the due date")
+}
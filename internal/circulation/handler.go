@@ -3,22 +3,87 @@ package circulation
 
 import (
 	"encoding/json"
+	"errors"
+	"libranexus/internal/catalog"
+	"libranexus/internal/clients"
+	"libranexus/internal/export"
+	"libranexus/internal/httperr"
+	"libranexus/internal/logging"
+	"libranexus/internal/membership"
+	"libranexus/internal/validate"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
 )
 
+// memberIDHeader is set by the API gateway to the authenticated member's ID.
+const memberIDHeader = "X-Member-ID"
+
+// memberRoleHeader carries the authenticated member's role, set by the gateway.
+const memberRoleHeader = "X-Member-Role"
+
+// HandlerConfig controls how the handler derives the acting member.
+type HandlerConfig struct {
+	// AllowBodyMemberID honours a member_id from the request body when the
+	// gateway header is absent. Intended for local testing only.
+	AllowBodyMemberID bool
+	// Idempotency, when set, makes checkout and return honour Idempotency-Key.
+	Idempotency IdempotencyStore
+}
+
+// errorRules maps circulation's errors to HTTP responses.
+var errorRules = httperr.Rules{
+	{Err: ErrCheckoutNotFound, Status: http.StatusNotFound, Code: "checkout_not_found"},
+	{Err: ErrInvalidFilter, Status: http.StatusBadRequest, Code: "invalid_filter"},
+	{Err: ErrItemUnavailable, Status: http.StatusConflict, Code: "item_unavailable"},
+	{Err: ErrItemNotCheckoutable, Status: http.StatusConflict, Code: "item_not_checkoutable"},
+	{Err: ErrMemberNotEligible, Status: http.StatusConflict, Code: "member_not_eligible"},
+	{Err: ErrMembershipExpired, Status: http.StatusConflict, Code: "membership_expired"},
+	{Err: ErrItemAvailable, Status: http.StatusConflict, Code: "item_available"},
+	{Err: ErrDuplicateHold, Status: http.StatusConflict, Code: "duplicate_hold"},
+	{Err: ErrCheckoutLimitReached, Status: http.StatusConflict, Code: "checkout_limit_reached"},
+	{Err: ErrRenewalLimitReached, Status: http.StatusConflict, Code: "renewal_limit_reached"},
+	{Err: ErrItemOnHold, Status: http.StatusConflict, Code: "item_on_hold"},
+	{Err: ErrFinesOutstanding, Status: http.StatusConflict, Code: "fines_outstanding"},
+	{Err: ErrNotCheckoutHolder, Status: http.StatusForbidden, Code: "forbidden"},
+	{Err: ErrIdempotencyInProgress, Status: http.StatusConflict, Code: "idempotency_in_progress"},
+	{Err: ErrIdempotencyKeyReused, Status: http.StatusUnprocessableEntity, Code: "idempotency_key_reused"},
+	{Err: catalog.ErrVersionConflict, Status: http.StatusConflict, Code: "version_conflict"},
+	{Err: clients.ErrServiceUnavailable, Status: http.StatusServiceUnavailable, Code: "service_unavailable"},
+}
+
 type Handler struct {
 	service Service
+	config  HandlerConfig
+}
+
+func NewHandler(service Service, config HandlerConfig) *Handler {
+	return &Handler{service: service, config: config}
 }
 
-func NewHandler(service Service) *Handler {
-	return &Handler{service: service}
+// memberID resolves the acting member from the gateway-injected header,
+// falling back to the body value only when the handler is configured to.
+func (h *Handler) memberID(r *http.Request, bodyMemberID uuid.UUID) (uuid.UUID, error) {
+	if header := r.Header.Get(memberIDHeader); header != "" {
+		id, err := uuid.Parse(header)
+		if err != nil {
+			return uuid.Nil, errors.New("invalid " + memberIDHeader + " header")
+		}
+		return id, nil
+	}
+
+	if h.config.AllowBodyMemberID && bodyMemberID != uuid.Nil {
+		return bodyMemberID, nil
+	}
+
+	return uuid.Nil, errors.New("missing " + memberIDHeader + " header")
 }
 
 func (h *Handler) HandleCheckout(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		httperr.MethodNotAllowed(w)
 		return
 	}
 
@@ -27,24 +92,37 @@ func (h *Handler) HandleCheckout(w http.ResponseWriter, r *http.Request) {
 		ItemID   uuid.UUID `json:"item_id"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	body, ok := httperr.ReadJSON(w, r, &req)
+	if !ok {
+		return
+	}
+	var invalid validate.Fields
+	invalid.RequiredID("item_id", req.ItemID)
+	if invalid.Reject(w) {
 		return
 	}
 
-	checkout, err := h.service.CheckoutItem(r.Context(), req.MemberID, req.ItemID)
+	memberID, err := h.memberID(r, req.MemberID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		httperr.BadRequest(w, err.Error())
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(checkout)
+	h.idempotent(w, r, "checkout", memberID, body, func(w http.ResponseWriter) {
+		checkout, err := h.service.CheckoutItem(r.Context(), memberID, req.ItemID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(checkout)
+	})
 }
 
 func (h *Handler) HandleReturn(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		httperr.MethodNotAllowed(w)
 		return
 	}
 
@@ -53,15 +131,322 @@ func (h *Handler) HandleReturn(w http.ResponseWriter, r *http.Request) {
 		ItemID   uuid.UUID `json:"item_id"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	body, ok := httperr.ReadJSON(w, r, &req)
+	if !ok {
+		return
+	}
+	var invalid validate.Fields
+	invalid.RequiredID("item_id", req.ItemID)
+	if invalid.Reject(w) {
+		return
+	}
+
+	memberID, err := h.memberID(r, req.MemberID)
+	if err != nil {
+		httperr.BadRequest(w, err.Error())
+		return
+	}
+
+	h.idempotent(w, r, "return", memberID, body, func(w http.ResponseWriter) {
+		if err := h.service.ReturnItem(r.Context(), memberID, req.ItemID); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// HandleReturnCheckout serves POST /return/{checkoutID}, returning a specific
+// checkout. Members may return only their own checkouts; administrators may
+// return anyone's, e.g. when scanning a receipt at the desk.
+func (h *Handler) HandleReturnCheckout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httperr.MethodNotAllowed(w)
+		return
+	}
+
+	idStr := strings.TrimPrefix(r.URL.Path, "/return/")
+	checkoutID, err := uuid.Parse(idStr)
+	if err != nil {
+		httperr.BadRequest(w, "invalid checkout ID")
+		return
+	}
+
+	memberID, err := h.memberID(r, uuid.Nil)
+	if err != nil {
+		httperr.BadRequest(w, err.Error())
+		return
+	}
+
+	caller := Caller{MemberID: memberID, Role: r.Header.Get(memberRoleHeader)}
+	h.idempotent(w, r, "return-checkout", memberID, []byte(checkoutID.String()), func(w http.ResponseWriter) {
+		if err := h.service.ReturnCheckout(r.Context(), caller, checkoutID); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// HandleRenew serves POST /renew, extending a checkout by another loan period.
+// Members may renew only their own checkouts; administrators may renew
+// anyone's.
+func (h *Handler) HandleRenew(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httperr.MethodNotAllowed(w)
+		return
+	}
+
+	var req struct {
+		CheckoutID uuid.UUID `json:"checkout_id"`
+	}
+
+	body, ok := httperr.ReadJSON(w, r, &req)
+	if !ok {
+		return
+	}
+	var invalid validate.Fields
+	invalid.RequiredID("checkout_id", req.CheckoutID)
+	if invalid.Reject(w) {
 		return
 	}
 
-	if err := h.service.ReturnItem(r.Context(), req.MemberID, req.ItemID); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	memberID, err := h.memberID(r, uuid.Nil)
+	if err != nil {
+		httperr.BadRequest(w, err.Error())
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	caller := Caller{MemberID: memberID, Role: r.Header.Get(memberRoleHeader)}
+	h.idempotent(w, r, "renew", memberID, body, func(w http.ResponseWriter) {
+		checkout, err := h.service.RenewCheckout(r.Context(), caller, req.CheckoutID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		json.NewEncoder(w).Encode(checkout)
+	})
+}
+
+// writeError responds to a service error. A checkout limit error also
+// carries the member's count and limit, so the UI can say how many items must
+// be returned first.
+func writeError(w http.ResponseWriter, err error) {
+	var limit *CheckoutLimitError
+	if errors.As(err, &limit) {
+		httperr.JSON(w, http.StatusConflict, struct {
+			httperr.Response
+			*CheckoutLimitError
+		}{httperr.Response{Error: err.Error(), Code: "checkout_limit_reached"}, limit})
+		return
+	}
+	errorRules.Write(w, err)
+}
+
+// HandleCheckouts serves GET /checkouts, the acting member's checkout
+// history. Supports active_only, limit and offset query parameters.
+func (h *Handler) HandleCheckouts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httperr.MethodNotAllowed(w)
+		return
+	}
+
+	q := r.URL.Query()
+	var queryMemberID uuid.UUID
+	if v := q.Get("member_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			httperr.BadRequest(w, "invalid member_id")
+			return
+		}
+		queryMemberID = id
+	}
+
+	memberID, err := h.memberID(r, queryMemberID)
+	if err != nil {
+		httperr.BadRequest(w, err.Error())
+		return
+	}
+	if queryMemberID != uuid.Nil && queryMemberID != memberID {
+		httperr.Error(w, http.StatusForbidden, "forbidden", "cannot list another member's checkouts")
+		return
+	}
+
+	var filter CheckoutFilter
+	if v := q.Get("active_only"); v != "" {
+		if filter.ActiveOnly, err = strconv.ParseBool(v); err != nil {
+			httperr.BadRequest(w, "invalid active_only")
+			return
+		}
+	}
+	if filter.Limit, err = intParam(q.Get("limit")); err != nil {
+		httperr.BadRequest(w, "invalid limit")
+		return
+	}
+	if filter.Offset, err = intParam(q.Get("offset")); err != nil {
+		httperr.BadRequest(w, "invalid offset")
+		return
+	}
+
+	checkouts, err := h.service.ListCheckouts(r.Context(), memberID, filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(checkouts)
+}
+
+// HandleCheckoutDetails serves GET /checkouts/{id}, one checkout with its
+// item's title and author and its member's name. Members see only their own
+// checkouts; administrators see anyone's.
+func (h *Handler) HandleCheckoutDetails(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httperr.MethodNotAllowed(w)
+		return
+	}
+
+	checkoutID, err := uuid.Parse(strings.TrimPrefix(r.URL.Path, "/checkouts/"))
+	if err != nil {
+		httperr.BadRequest(w, "invalid checkout ID")
+		return
+	}
+	memberID, err := h.memberID(r, uuid.Nil)
+	if err != nil {
+		httperr.BadRequest(w, err.Error())
+		return
+	}
+
+	view, err := h.service.GetCheckoutDetails(r.Context(), checkoutID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if view.MemberID != memberID && r.Header.Get(memberRoleHeader) != membership.RoleAdmin {
+		httperr.Error(w, http.StatusForbidden, "forbidden", "cannot view another member's checkout")
+		return
+	}
+
+	json.NewEncoder(w).Encode(view)
+}
+
+// HandleExportCheckouts serves GET /checkouts/export, every member's loan
+// records as a CSV or JSON download, streamed as they are read. A since
+// parameter limits it to checkouts changed at or after that time. The
+// gateway only admits administrators.
+func (h *Handler) HandleExportCheckouts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httperr.MethodNotAllowed(w)
+		return
+	}
+
+	q := r.URL.Query()
+	format, err := export.ParseFormat(q.Get("format"))
+	if err != nil {
+		httperr.BadRequest(w, err.Error())
+		return
+	}
+	since, err := export.ParseSince(q.Get("since"))
+	if err != nil {
+		httperr.BadRequest(w, "invalid since")
+		return
+	}
+
+	out := export.NewWriter(w, format, "checkouts", checkoutColumns)
+	err = h.service.ExportCheckouts(r.Context(), since, func(checkout *Checkout) error {
+		return out.Write(checkoutRow{checkout})
+	})
+	if err == nil {
+		err = out.Close()
+	}
+	if err != nil {
+		if !out.Started() {
+			writeError(w, err)
+			return
+		}
+		logging.FromContext(r.Context()).Error("checkout export failed", "err", err)
+		out.Abort()
+	}
+}
+
+func (h *Handler) HandleHolds(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.handleListHolds(w, r)
+	case http.MethodPost:
+		h.handlePlaceHold(w, r)
+	default:
+		httperr.MethodNotAllowed(w)
+	}
+}
+
+func (h *Handler) handleListHolds(w http.ResponseWriter, r *http.Request) {
+	var queryMemberID uuid.UUID
+	if v := r.URL.Query().Get("member_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			httperr.BadRequest(w, "invalid member_id")
+			return
+		}
+		queryMemberID = id
+	}
+
+	memberID, err := h.memberID(r, queryMemberID)
+	if err != nil {
+		httperr.BadRequest(w, err.Error())
+		return
+	}
+	if queryMemberID != uuid.Nil && queryMemberID != memberID {
+		httperr.Error(w, http.StatusForbidden, "forbidden", "cannot list another member's holds")
+		return
+	}
+
+	holds, err := h.service.ListHolds(r.Context(), memberID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(holds)
+}
+
+func (h *Handler) handlePlaceHold(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		MemberID uuid.UUID `json:"member_id"`
+		ItemID   uuid.UUID `json:"item_id"`
+	}
+
+	if !httperr.DecodeJSON(w, r, &req) {
+		return
+	}
+	var invalid validate.Fields
+	invalid.RequiredID("item_id", req.ItemID)
+	if invalid.Reject(w) {
+		return
+	}
+
+	memberID, err := h.memberID(r, req.MemberID)
+	if err != nil {
+		httperr.BadRequest(w, err.Error())
+		return
+	}
+
+	hold, err := h.service.PlaceHold(r.Context(), memberID, req.ItemID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(hold)
+}
+
+func intParam(v string) (int, error) {
+	if v == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(v)
 }
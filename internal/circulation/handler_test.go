@@ -0,0 +1,505 @@
+package circulation
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"libranexus/internal/clients"
+	"libranexus/internal/httperr"
+)
+
+type fakeService struct {
+	Service
+	memberID uuid.UUID
+}
+
+func (f *fakeService) CheckoutItem(ctx context.Context, memberID, itemID uuid.UUID) (*Checkout, error) {
+	f.memberID = memberID
+	return &Checkout{ID: uuid.New(), MemberID: memberID, ItemID: itemID}, nil
+}
+
+func TestHandleCheckoutMemberIdentity(t *testing.T) {
+	headerMember := uuid.New()
+	bodyMember := uuid.New()
+
+	tests := []struct {
+		name       string
+		config     HandlerConfig
+		header     string
+		wantStatus int
+		wantMember uuid.UUID
+	}{
+		{"header wins over body", HandlerConfig{}, headerMember.String(), http.StatusCreated, headerMember},
+		{"missing header rejected", HandlerConfig{}, "", http.StatusBadRequest, uuid.Nil},
+		{"malformed header rejected", HandlerConfig{}, "not-a-uuid", http.StatusBadRequest, uuid.Nil},
+		{"body fallback when allowed", HandlerConfig{AllowBodyMemberID: true}, "", http.StatusCreated, bodyMember},
+		{"header still wins when fallback allowed", HandlerConfig{AllowBodyMemberID: true}, headerMember.String(), http.StatusCreated, headerMember},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &fakeService{}
+			h := NewHandler(svc, tt.config)
+
+			body, _ := json.Marshal(map[string]string{
+				"member_id": bodyMember.String(),
+				"item_id":   uuid.NewString(),
+			})
+			req := httptest.NewRequest(http.MethodPost, "/checkout", bytes.NewReader(body))
+			if tt.header != "" {
+				req.Header.Set(memberIDHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			h.HandleCheckout(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantMember, svc.memberID)
+		})
+	}
+}
+
+type memoryIdempotencyStore struct {
+	responses map[IdempotencyScope]*StoredResponse
+	hashes    map[IdempotencyScope]string
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{
+		responses: make(map[IdempotencyScope]*StoredResponse),
+		hashes:    make(map[IdempotencyScope]string),
+	}
+}
+
+func (m *memoryIdempotencyStore) Begin(ctx context.Context, scope IdempotencyScope, requestHash string) (*StoredResponse, error) {
+	hash, ok := m.hashes[scope]
+	if !ok {
+		m.hashes[scope] = requestHash
+		return nil, nil
+	}
+	if hash != requestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if m.responses[scope] == nil {
+		return nil, ErrIdempotencyInProgress
+	}
+	return m.responses[scope], nil
+}
+
+func (m *memoryIdempotencyStore) Complete(ctx context.Context, scope IdempotencyScope, resp StoredResponse) error {
+	m.responses[scope] = &resp
+	return nil
+}
+
+func (m *memoryIdempotencyStore) Abandon(ctx context.Context, scope IdempotencyScope) error {
+	delete(m.hashes, scope)
+	return nil
+}
+
+func (m *memoryIdempotencyStore) PurgeExpired(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+type countingService struct {
+	Service
+	checkouts int
+}
+
+func (c *countingService) CheckoutItem(ctx context.Context, memberID, itemID uuid.UUID) (*Checkout, error) {
+	c.checkouts++
+	return &Checkout{ID: uuid.New(), MemberID: memberID, ItemID: itemID}, nil
+}
+
+func TestHandleCheckoutIdempotencyKey(t *testing.T) {
+	svc := &countingService{}
+	h := NewHandler(svc, HandlerConfig{Idempotency: newMemoryIdempotencyStore()})
+	memberID := uuid.New()
+	itemID := uuid.New()
+
+	send := func(member uuid.UUID, key string, item uuid.UUID) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]string{"item_id": item.String()})
+		req := httptest.NewRequest(http.MethodPost, "/checkout", bytes.NewReader(body))
+		req.Header.Set(memberIDHeader, member.String())
+		req.Header.Set(idempotencyKeyHeader, key)
+		rec := httptest.NewRecorder()
+		h.HandleCheckout(rec, req)
+		return rec
+	}
+
+	first := send(memberID, "key-1", itemID)
+	assert.Equal(t, http.StatusCreated, first.Code)
+
+	replay := send(memberID, "key-1", itemID)
+	assert.Equal(t, http.StatusCreated, replay.Code)
+	assert.Equal(t, first.Body.String(), replay.Body.String())
+	assert.Equal(t, "true", replay.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, 1, svc.checkouts, "replayed key must not check out again")
+
+	reused := send(memberID, "key-1", uuid.New())
+	assert.Equal(t, http.StatusUnprocessableEntity, reused.Code)
+
+	otherMember := send(uuid.New(), "key-1", itemID)
+	assert.Equal(t, http.StatusCreated, otherMember.Code)
+	assert.Equal(t, 2, svc.checkouts, "keys are scoped per member")
+}
+
+func TestHandleCheckoutReplaysResponseHeaders(t *testing.T) {
+	h := NewHandler(failingService{err: ErrItemUnavailable}, HandlerConfig{Idempotency: newMemoryIdempotencyStore()})
+	body, _ := json.Marshal(map[string]string{"item_id": uuid.NewString()})
+	memberID := uuid.NewString()
+
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/checkout", bytes.NewReader(body))
+		req.Header.Set(memberIDHeader, memberID)
+		req.Header.Set(idempotencyKeyHeader, "key-1")
+		rec := httptest.NewRecorder()
+		h.HandleCheckout(rec, req)
+		return rec
+	}
+
+	first := send()
+	replay := send()
+	assert.Equal(t, http.StatusConflict, replay.Code)
+	assert.Equal(t, "true", replay.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, "application/json", replay.Header().Get("Content-Type"))
+	assert.Equal(t, first.Header().Get("X-Content-Type-Options"), replay.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, first.Body.String(), replay.Body.String())
+}
+
+type returningService struct {
+	Service
+	holder   uuid.UUID
+	returned []uuid.UUID
+}
+
+func (r *returningService) ReturnCheckout(ctx context.Context, caller Caller, checkoutID uuid.UUID) error {
+	for _, id := range r.returned {
+		if id == checkoutID {
+			return ErrCheckoutNotFound
+		}
+	}
+	if !caller.mayActFor(r.holder) {
+		return ErrNotCheckoutHolder
+	}
+	r.returned = append(r.returned, checkoutID)
+	return nil
+}
+
+func TestHandleReturnCheckout(t *testing.T) {
+	svc := &returningService{holder: uuid.New()}
+	h := NewHandler(svc, HandlerConfig{})
+	checkoutID, staffReturned := uuid.New(), uuid.New()
+
+	send := func(path string, member uuid.UUID, role string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set(memberIDHeader, member.String())
+		if role != "" {
+			req.Header.Set(memberRoleHeader, role)
+		}
+		rec := httptest.NewRecorder()
+		h.HandleReturnCheckout(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusForbidden, send("/return/"+checkoutID.String(), uuid.New(), "").Code, "another member")
+	assert.Empty(t, svc.returned)
+
+	assert.Equal(t, http.StatusOK, send("/return/"+checkoutID.String(), svc.holder, "").Code)
+	assert.Equal(t, http.StatusOK, send("/return/"+staffReturned.String(), uuid.New(), "admin").Code, "administrator")
+	assert.Equal(t, []uuid.UUID{checkoutID, staffReturned}, svc.returned)
+
+	assert.Equal(t, http.StatusNotFound, send("/return/"+checkoutID.String(), svc.holder, "").Code, "already returned")
+	assert.Equal(t, http.StatusBadRequest, send("/return/not-a-uuid", svc.holder, "").Code)
+}
+
+func TestHandlersRequireIDs(t *testing.T) {
+	h := NewHandler(&fakeService{}, HandlerConfig{})
+
+	tests := []struct {
+		name   string
+		handle http.HandlerFunc
+		path   string
+		body   string
+		field  string
+	}{
+		{"checkout without item_id", h.HandleCheckout, "/checkout", `{}`, "item_id"},
+		{"checkout with nil item_id", h.HandleCheckout, "/checkout", `{"item_id":"` + uuid.Nil.String() + `"}`, "item_id"},
+		{"return without item_id", h.HandleReturn, "/return", `{"member_id":"` + uuid.NewString() + `"}`, "item_id"},
+		{"renew without checkout_id", h.HandleRenew, "/renew", `{}`, "checkout_id"},
+		{"hold without item_id", h.HandleHolds, "/holds", `{}`, "item_id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set(memberIDHeader, uuid.NewString())
+			rec := httptest.NewRecorder()
+			tt.handle(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			var resp struct {
+				Code   string            `json:"code"`
+				Fields map[string]string `json:"fields"`
+			}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, "invalid_fields", resp.Code)
+			assert.Equal(t, map[string]string{tt.field: "is required"}, resp.Fields)
+		})
+	}
+}
+
+type limitedService struct {
+	Service
+}
+
+func (limitedService) CheckoutItem(ctx context.Context, memberID, itemID uuid.UUID) (*Checkout, error) {
+	return nil, &CheckoutLimitError{Count: 3, Limit: 3}
+}
+
+func TestHandleCheckoutLimitReached(t *testing.T) {
+	h := NewHandler(limitedService{}, HandlerConfig{})
+
+	body, _ := json.Marshal(map[string]string{"item_id": uuid.NewString()})
+	req := httptest.NewRequest(http.MethodPost, "/checkout", bytes.NewReader(body))
+	req.Header.Set(memberIDHeader, uuid.NewString())
+	rec := httptest.NewRecorder()
+
+	h.HandleCheckout(rec, req)
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	var resp struct {
+		Error string `json:"error"`
+		Code  string `json:"code"`
+		Count int    `json:"count"`
+		Limit int    `json:"limit"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "checkout_limit_reached", resp.Code)
+	assert.Equal(t, 3, resp.Count)
+	assert.Equal(t, 3, resp.Limit)
+	assert.Contains(t, resp.Error, "3 of 3")
+}
+
+type failingService struct {
+	Service
+	err error
+}
+
+func (f failingService) CheckoutItem(ctx context.Context, memberID, itemID uuid.UUID) (*Checkout, error) {
+	return nil, f.err
+}
+
+func TestHandleCheckoutErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		want       httperr.Response
+	}{
+		{"unavailable", ErrItemUnavailable, http.StatusConflict, httperr.Response{Error: "item is not available", Code: "item_unavailable"}},
+		{"catalog down", fmt.Errorf("failed to get item: %w", clients.ErrServiceUnavailable), http.StatusServiceUnavailable,
+			httperr.Response{Error: "failed to get item: service unavailable: circuit breaker open", Code: "service_unavailable"}},
+		{"internal details stay internal", errors.New("pq: password authentication failed"), http.StatusInternalServerError,
+			httperr.Response{Error: "internal server error", Code: "internal"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(failingService{err: tt.err}, HandlerConfig{})
+			body, _ := json.Marshal(map[string]string{"item_id": uuid.NewString()})
+			req := httptest.NewRequest(http.MethodPost, "/checkout", bytes.NewReader(body))
+			req.Header.Set(memberIDHeader, uuid.NewString())
+			rec := httptest.NewRecorder()
+
+			h.HandleCheckout(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			var got httperr.Response
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+type historyService struct {
+	Service
+	memberID uuid.UUID
+	filter   CheckoutFilter
+}
+
+func (h *historyService) ListCheckouts(ctx context.Context, memberID uuid.UUID, filter CheckoutFilter) ([]*Checkout, error) {
+	h.memberID, h.filter = memberID, filter
+	return []*Checkout{{ID: uuid.New(), MemberID: memberID, ItemTitle: "Dune"}}, nil
+}
+
+func TestHandleCheckouts(t *testing.T) {
+	memberID := uuid.New()
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantFilter CheckoutFilter
+	}{
+		{"defaults", "", http.StatusOK, CheckoutFilter{}},
+		{"own member_id", "?member_id=" + memberID.String(), http.StatusOK, CheckoutFilter{}},
+		{"active only page", "?active_only=true&limit=5&offset=10", http.StatusOK, CheckoutFilter{ActiveOnly: true, Limit: 5, Offset: 10}},
+		{"another member", "?member_id=" + uuid.NewString(), http.StatusForbidden, CheckoutFilter{}},
+		{"bad active_only", "?active_only=maybe", http.StatusBadRequest, CheckoutFilter{}},
+		{"bad limit", "?limit=ten", http.StatusBadRequest, CheckoutFilter{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &historyService{}
+			h := NewHandler(svc, HandlerConfig{})
+
+			req := httptest.NewRequest(http.MethodGet, "/checkouts"+tt.query, nil)
+			req.Header.Set(memberIDHeader, memberID.String())
+			rec := httptest.NewRecorder()
+
+			h.HandleCheckouts(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, memberID, svc.memberID)
+				assert.Equal(t, tt.wantFilter, svc.filter)
+				assert.Contains(t, rec.Body.String(), `"item_title":"Dune"`)
+			}
+		})
+	}
+}
+
+type exportService struct {
+	Service
+	since time.Time
+	err   error
+}
+
+func (e *exportService) ExportCheckouts(ctx context.Context, since time.Time, fn func(*Checkout) error) error {
+	e.since = since
+	if e.err != nil {
+		return e.err
+	}
+	due := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	for _, title := range []string{"Dune", `The "Hobbit", Illustrated`} {
+		if err := fn(&Checkout{ID: uuid.New(), MemberID: uuid.New(), ItemID: uuid.New(), ItemTitle: title, DueDate: due, Status: "active", Version: 1}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestHandleExportCheckouts(t *testing.T) {
+	svc := &exportService{}
+	h := NewHandler(svc, HandlerConfig{})
+
+	req := httptest.NewRequest(http.MethodGet, "/checkouts/export?format=csv&since=2024-03-01T00:00:00Z", nil)
+	rec := httptest.NewRecorder()
+	h.HandleExportCheckouts(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `attachment; filename=checkouts.csv`, rec.Header().Get("Content-Disposition"))
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), svc.since)
+
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+	assert.Equal(t, checkoutColumns, records[0])
+	assert.Equal(t, `The "Hobbit", Illustrated`, records[2][3])
+	assert.Equal(t, "2024-03-15T00:00:00Z", records[2][5])
+	assert.Empty(t, records[2][6], "no return date")
+}
+
+func TestHandleExportCheckoutsErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		err        error
+		wantStatus int
+	}{
+		{"unknown format", "?format=xlsx", nil, http.StatusBadRequest},
+		{"bad since", "?since=yesterday", nil, http.StatusBadRequest},
+		{"fails before the first row", "", errors.New("pq: connection refused"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(&exportService{err: tt.err}, HandlerConfig{})
+			rec := httptest.NewRecorder()
+			h.HandleExportCheckouts(rec, httptest.NewRequest(http.MethodGet, "/checkouts/export"+tt.query, nil))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		})
+	}
+}
+
+type detailsService struct {
+	Service
+	view *CheckoutView
+}
+
+func (d *detailsService) GetCheckoutDetails(ctx context.Context, id uuid.UUID) (*CheckoutView, error) {
+	if d.view == nil || d.view.ID != id {
+		return nil, ErrCheckoutNotFound
+	}
+	return d.view, nil
+}
+
+func TestHandleCheckoutDetails(t *testing.T) {
+	owner := uuid.New()
+	view := &CheckoutView{
+		Checkout:   Checkout{ID: uuid.New(), MemberID: owner, ItemTitle: "Dune"},
+		ItemAuthor: "Frank Herbert",
+		MemberName: "Paul Atreides",
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		member     uuid.UUID
+		role       string
+		wantStatus int
+	}{
+		{"owner", "/checkouts/" + view.ID.String(), owner, "", http.StatusOK},
+		{"administrator", "/checkouts/" + view.ID.String(), uuid.New(), "admin", http.StatusOK},
+		{"another member", "/checkouts/" + view.ID.String(), uuid.New(), "", http.StatusForbidden},
+		{"unknown checkout", "/checkouts/" + uuid.NewString(), owner, "", http.StatusNotFound},
+		{"malformed id", "/checkouts/nope", owner, "", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(&detailsService{view: view}, HandlerConfig{})
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set(memberIDHeader, tt.member.String())
+			if tt.role != "" {
+				req.Header.Set(memberRoleHeader, tt.role)
+			}
+			rec := httptest.NewRecorder()
+
+			h.HandleCheckoutDetails(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus == http.StatusOK {
+				var got map[string]interface{}
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+				assert.Equal(t, "Dune", got["item_title"])
+				assert.Equal(t, "Frank Herbert", got["item_author"])
+				assert.Equal(t, "Paul Atreides", got["member_name"])
+			}
+		})
+	}
+}
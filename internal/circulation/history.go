@@ -0,0 +1,100 @@
+// internal/circulation/history.go
+package circulation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ListCheckouts returns a page of a member's checkouts, most recent first,
+// each carrying the item's title from the catalog read model.
+func (s *service) ListCheckouts(ctx context.Context, memberID uuid.UUID, filter CheckoutFilter) ([]*Checkout, error) {
+	if err := filter.normalize(); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT c.id, c.member_id, c.item_id, COALESCE(i.title, ''), c.checkout_date, c.due_date,
+		       c.return_date, c.renewal_count, c.status, c.version
+		FROM checkouts c
+		LEFT JOIN items i ON i.id = c.item_id
+		WHERE c.member_id = $1 AND (NOT $2 OR c.status IN ('active', 'overdue'))
+		ORDER BY c.checkout_date DESC, c.id
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := s.db.QueryContext(ctx, query, memberID, filter.ActiveOnly, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkouts: %w", err)
+	}
+	defer rows.Close()
+
+	checkouts := make([]*Checkout, 0)
+	for rows.Next() {
+		checkout := &Checkout{}
+		var returnDate sql.NullTime
+		err := rows.Scan(
+			&checkout.ID,
+			&checkout.MemberID,
+			&checkout.ItemID,
+			&checkout.ItemTitle,
+			&checkout.CheckoutDate,
+			&checkout.DueDate,
+			&returnDate,
+			&checkout.RenewalCount,
+			&checkout.Status,
+			&checkout.Version,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan checkout: %w", err)
+		}
+		if returnDate.Valid {
+			checkout.ReturnDate = returnDate.Time
+		}
+		checkouts = append(checkouts, checkout)
+	}
+
+	return checkouts, rows.Err()
+}
+
+// GetCheckoutDetails returns a checkout, open or not, with its item's title
+// and author and its member's name.
+func (s *service) GetCheckoutDetails(ctx context.Context, id uuid.UUID) (*CheckoutView, error) {
+	query := `
+		SELECT c.id, c.member_id, c.item_id, COALESCE(i.title, ''), COALESCE(i.author, ''), COALESCE(m.name, ''),
+		       c.checkout_date, c.due_date, c.return_date, c.renewal_count, c.status, c.version
+		FROM checkouts c
+		LEFT JOIN items i ON i.id = c.item_id
+		LEFT JOIN members m ON m.id = c.member_id
+		WHERE c.id = $1
+	`
+	view := &CheckoutView{}
+	var returnDate sql.NullTime
+	err := s.db.QueryRowContext(ctx, query, id).Scan(
+		&view.ID,
+		&view.MemberID,
+		&view.ItemID,
+		&view.ItemTitle,
+		&view.ItemAuthor,
+		&view.MemberName,
+		&view.CheckoutDate,
+		&view.DueDate,
+		&returnDate,
+		&view.RenewalCount,
+		&view.Status,
+		&view.Version,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCheckoutNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkout: %w", err)
+	}
+	if returnDate.Valid {
+		view.ReturnDate = returnDate.Time
+	}
+	return view, nil
+}
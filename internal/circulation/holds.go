@@ -0,0 +1,281 @@
+// internal/circulation/holds.go
+package circulation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"libranexus/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+	"github.com/lib/pq"
+)
+
+// PlaceHold queues a member for the next copy of an item that has none available.
+func (s *service) PlaceHold(ctx context.Context, memberID, itemID uuid.UUID) (*Hold, error) {
+	member, err := s.membershipClient.GetMember(ctx, memberID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get member: %w", err)
+	}
+	if member.Status != "active" {
+		return nil, fmt.Errorf("member is not eligible to place holds")
+	}
+
+	item, err := s.catalogClient.GetItem(ctx, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item: %w", err)
+	}
+	if err := checkoutable(item); err != nil {
+		return nil, err
+	}
+	if item.Available > 0 {
+		return nil, ErrItemAvailable
+	}
+
+	existing, err := s.getOpenHold(ctx, memberID, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing holds: %w", err)
+	}
+	if existing != nil {
+		return nil, ErrDuplicateHold
+	}
+
+	now := s.now()
+	hold := &Hold{
+		ID:        uuid.New(),
+		MemberID:  memberID,
+		ItemID:    itemID,
+		PlacedAt:  now,
+		ExpiresAt: now.Add(s.holdExpiry),
+		Status:    "pending",
+		Version:   1,
+	}
+
+	eventData := ItemHeldEvent{
+		HoldID:    hold.ID,
+		MemberID:  memberID,
+		ItemID:    itemID,
+		ExpiresAt: hold.ExpiresAt,
+	}
+	err = s.appendHoldEvent(ctx, hold.ID, 0, "ItemHeld", eventData, func(tx *sql.Tx) error {
+		query := `
+			INSERT INTO holds (id, member_id, item_id, placed_at, expires_at, status, version)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`
+		_, err := tx.ExecContext(ctx, query, hold.ID, hold.MemberID, hold.ItemID, hold.PlacedAt, hold.ExpiresAt, hold.Status, hold.Version)
+		return err
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return nil, ErrDuplicateHold
+		}
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// ListHolds returns a member's holds, most recent first.
+func (s *service) ListHolds(ctx context.Context, memberID uuid.UUID) ([]*Hold, error) {
+	query := `
+		SELECT id, member_id, item_id, placed_at, expires_at, fulfilled_at, status, version
+		FROM holds
+		WHERE member_id = $1
+		ORDER BY placed_at DESC
+	`
+	rows, err := s.db.QueryContext(ctx, query, memberID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list holds: %w", err)
+	}
+	defer rows.Close()
+
+	holds := make([]*Hold, 0)
+	for rows.Next() {
+		hold, err := scanHold(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan hold: %w", err)
+		}
+		holds = append(holds, hold)
+	}
+
+	return holds, rows.Err()
+}
+
+// nextPendingHold returns the earliest unexpired pending hold on an item,
+// expiring any lapsed holds it passes over. It returns nil if the queue is empty.
+func (s *service) nextPendingHold(ctx context.Context, itemID uuid.UUID) (*Hold, error) {
+	query := `
+		SELECT id, member_id, item_id, placed_at, expires_at, fulfilled_at, status, version
+		FROM holds
+		WHERE item_id = $1 AND status = 'pending'
+		ORDER BY placed_at ASC
+		LIMIT 1
+	`
+	for {
+		hold, err := scanHold(s.db.QueryRowContext(ctx, query, itemID))
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if hold.ExpiresAt.After(s.now()) {
+			return hold, nil
+		}
+		if err := s.expireHold(ctx, hold); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// fulfillHold marks a hold as ready for collection by its member, who has
+// the pickup window to collect it, and updates hold to match. The system
+// fulfills it, whoever's return freed the copy.
+func (s *service) fulfillHold(ctx context.Context, hold *Hold) error {
+	ctx = eventstore.WithActorID(ctx, eventstore.SystemActorID)
+	now := s.now()
+	eventData := HoldFulfilledEvent{
+		HoldID:      hold.ID,
+		MemberID:    hold.MemberID,
+		ItemID:      hold.ItemID,
+		FulfilledAt: now,
+		PickupBy:    now.Add(s.pickupWindow),
+	}
+	err := s.appendHoldEvent(ctx, hold.ID, hold.Version, "HoldFulfilled", eventData, func(tx *sql.Tx) error {
+		query := `
+			UPDATE holds
+			SET status = 'fulfilled', fulfilled_at = $1, expires_at = $2, version = version + 1
+			WHERE id = $3 AND version = $4
+		`
+		_, err := tx.ExecContext(ctx, query, now, eventData.PickupBy, hold.ID, hold.Version)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	hold.Status = "fulfilled"
+	hold.FulfilledAt = &now
+	hold.ExpiresAt = eventData.PickupBy
+	hold.Version++
+	return nil
+}
+
+// collectHold closes a fulfilled hold, in the transaction recording the
+// checkout its member took the copy on. It fails with
+// eventstore.ErrConcurrencyConflict if the hold changed since it was read.
+func (s *service) collectHold(ctx context.Context, tx *sql.Tx, hold *Hold, checkoutID uuid.UUID) error {
+	eventData := HoldCollectedEvent{
+		HoldID:     hold.ID,
+		CheckoutID: checkoutID,
+	}
+	return s.appendHoldEventTx(ctx, tx, hold.ID, hold.Version, "HoldCollected", eventData, setHoldStatus(ctx, hold, "collected"))
+}
+
+// expireHold closes a hold under its item's lock, so it takes turns with a
+// checkout collecting the same hold rather than interleaving with it.
+func (s *service) expireHold(ctx context.Context, hold *Hold) error {
+	return database.InTx(ctx, s.db, func(tx *sql.Tx) error {
+		return database.WithItemLock(ctx, tx, hold.ItemID, func() error {
+			return s.appendHoldEventTx(ctx, tx, hold.ID, hold.Version, "HoldExpired", HoldExpiredEvent{HoldID: hold.ID}, setHoldStatus(ctx, hold, "expired"))
+		})
+	})
+}
+
+// setHoldStatus returns a read-model update moving hold to status.
+func setHoldStatus(ctx context.Context, hold *Hold, status string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		query := `
+			UPDATE holds
+			SET status = $1, version = version + 1
+			WHERE id = $2 AND version = $3
+		`
+		_, err := tx.ExecContext(ctx, query, status, hold.ID, hold.Version)
+		return err
+	}
+}
+
+// getOpenHold returns the member's pending or fulfilled hold on an item, if any.
+func (s *service) getOpenHold(ctx context.Context, memberID, itemID uuid.UUID) (*Hold, error) {
+	return s.getHoldByStatus(ctx, memberID, itemID, "pending", "fulfilled")
+}
+
+// getFulfilledHold returns the hold under which a copy is set aside for the member, if any.
+func (s *service) getFulfilledHold(ctx context.Context, memberID, itemID uuid.UUID) (*Hold, error) {
+	return s.getHoldByStatus(ctx, memberID, itemID, "fulfilled")
+}
+
+func (s *service) getHoldByStatus(ctx context.Context, memberID, itemID uuid.UUID, statuses ...string) (*Hold, error) {
+	query := `
+		SELECT id, member_id, item_id, placed_at, expires_at, fulfilled_at, status, version
+		FROM holds
+		WHERE member_id = $1 AND item_id = $2 AND status = ANY($3)
+		LIMIT 1
+	`
+	hold, err := scanHold(s.db.QueryRowContext(ctx, query, memberID, itemID, pq.Array(statuses)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return hold, err
+}
+
+// appendHoldEvent appends an event to a hold and applies the matching
+// read-model change in the same transaction.
+func (s *service) appendHoldEvent(ctx context.Context, holdID uuid.UUID, expectedVersion int, eventType string, data interface{}, apply func(tx *sql.Tx) error) error {
+	return database.InTx(ctx, s.db, func(tx *sql.Tx) error {
+		return s.appendHoldEventTx(ctx, tx, holdID, expectedVersion, eventType, data, apply)
+	})
+}
+
+// appendHoldEventTx is appendHoldEvent within the caller's transaction.
+func (s *service) appendHoldEventTx(ctx context.Context, tx *sql.Tx, holdID uuid.UUID, expectedVersion int, eventType string, data interface{}, apply func(tx *sql.Tx) error) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	event := eventstore.Event{
+		AggregateID:   holdID,
+		AggregateType: "hold",
+		EventType:     eventType,
+		EventData:     jsonData,
+		Version:       expectedVersion + 1,
+	}
+	if err := s.eventStore.AppendEventsTx(ctx, tx, holdID, "hold", expectedVersion, []eventstore.Event{event}); err != nil {
+		return fmt.Errorf("failed to append event: %w", err)
+	}
+	if err := apply(tx); err != nil {
+		return fmt.Errorf("failed to update read model: %w", err)
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanHold(row rowScanner) (*Hold, error) {
+	hold := &Hold{}
+	var fulfilledAt sql.NullTime
+	err := row.Scan(
+		&hold.ID,
+		&hold.MemberID,
+		&hold.ItemID,
+		&hold.PlacedAt,
+		&hold.ExpiresAt,
+		&fulfilledAt,
+		&hold.Status,
+		&hold.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if fulfilledAt.Valid {
+		hold.FulfilledAt = &fulfilledAt.Time
+	}
+	return hold, nil
+}
@@ -0,0 +1,252 @@
+// internal/circulation/idempotency.go
+package circulation
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"libranexus/internal/httperr"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// idempotencyKeyHeader lets clients mark a request as safe to retry.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// defaultIdempotencyTTL is how long a stored response can be replayed.
+const defaultIdempotencyTTL = 24 * time.Hour
+
+// idempotencyLease is how long a request may hold its key without completing.
+// A key still held after that belongs to a request that crashed, and a retry
+// may claim it rather than be told it is in progress until the key expires.
+const idempotencyLease = time.Minute
+
+// replayedHeaders describe a stored body, so they are recorded and replayed
+// with it. The rest, such as trace IDs, belong to each response.
+var replayedHeaders = []string{"Content-Type", "X-Content-Type-Options"}
+
+var (
+	ErrIdempotencyInProgress = errors.New("a request with this idempotency key is still being processed")
+	ErrIdempotencyKeyReused  = errors.New("idempotency key was already used with a different request")
+)
+
+// IdempotencyScope identifies a key: the same key used on another endpoint or
+// by another member is a different key.
+type IdempotencyScope struct {
+	Endpoint string
+	MemberID uuid.UUID
+	Key      string
+}
+
+// StoredResponse is the response recorded for a completed request.
+type StoredResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// IdempotencyStore records the outcome of keyed requests so retries can be
+// answered with the original response.
+type IdempotencyStore interface {
+	// Begin claims the key for a new request. It returns the stored response
+	// if the key already completed, ErrIdempotencyInProgress if another request
+	// holds it, or ErrIdempotencyKeyReused if it was used for a different body.
+	Begin(ctx context.Context, scope IdempotencyScope, requestHash string) (*StoredResponse, error)
+	// Complete records the response for a claimed key.
+	Complete(ctx context.Context, scope IdempotencyScope, resp StoredResponse) error
+	// Abandon releases a claimed key so the request can be retried.
+	Abandon(ctx context.Context, scope IdempotencyScope) error
+	// PurgeExpired deletes keys whose TTL, or whose lease if they never
+	// completed, has passed.
+	PurgeExpired(ctx context.Context) (int64, error)
+}
+
+type sqlIdempotencyStore struct {
+	db  *sql.DB
+	ttl time.Duration
+}
+
+// NewIdempotencyStore creates a Postgres-backed store whose completed keys
+// live for ttl.
+func NewIdempotencyStore(db *sql.DB, ttl time.Duration) IdempotencyStore {
+	if ttl <= 0 {
+		ttl = defaultIdempotencyTTL
+	}
+	return &sqlIdempotencyStore{db: db, ttl: ttl}
+}
+
+func (s *sqlIdempotencyStore) Begin(ctx context.Context, scope IdempotencyScope, requestHash string) (*StoredResponse, error) {
+	// A claim lasts for the lease until Complete extends it to the TTL. An
+	// expired key, or a lapsed claim, is reclaimed as though it had never
+	// been used.
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO idempotency_keys (endpoint, member_id, key, request_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (endpoint, member_id, key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash,
+		    status_code = NULL,
+		    response_headers = NULL,
+		    response_body = NULL,
+		    created_at = NOW(),
+		    expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at < NOW()
+	`, scope.Endpoint, scope.MemberID, scope.Key, requestHash, time.Now().Add(idempotencyLease))
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 1 {
+		return nil, nil
+	}
+
+	var storedHash string
+	var statusCode sql.NullInt64
+	var headerJSON, body []byte
+	err = s.db.QueryRowContext(ctx, `
+		SELECT request_hash, status_code, response_headers, response_body
+		FROM idempotency_keys
+		WHERE endpoint = $1 AND member_id = $2 AND key = $3
+	`, scope.Endpoint, scope.MemberID, scope.Key).Scan(&storedHash, &statusCode, &headerJSON, &body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load idempotency key: %w", err)
+	}
+
+	if storedHash != requestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if !statusCode.Valid {
+		return nil, ErrIdempotencyInProgress
+	}
+	resp := &StoredResponse{StatusCode: int(statusCode.Int64), Body: body}
+	if len(headerJSON) > 0 {
+		if err := json.Unmarshal(headerJSON, &resp.Header); err != nil {
+			return nil, fmt.Errorf("failed to decode stored response headers: %w", err)
+		}
+	}
+	return resp, nil
+}
+
+// Complete records the response, unless the claim lapsed and the key was
+// reclaimed by a retry, which will record its own.
+func (s *sqlIdempotencyStore) Complete(ctx context.Context, scope IdempotencyScope, resp StoredResponse) error {
+	headerJSON, err := json.Marshal(resp.Header)
+	if err != nil {
+		return fmt.Errorf("failed to encode response headers: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx, `
+		UPDATE idempotency_keys
+		SET status_code = $1, response_headers = $2, response_body = $3, expires_at = $4
+		WHERE endpoint = $5 AND member_id = $6 AND key = $7 AND status_code IS NULL
+	`, resp.StatusCode, headerJSON, resp.Body, time.Now().Add(s.ttl), scope.Endpoint, scope.MemberID, scope.Key)
+	return err
+}
+
+func (s *sqlIdempotencyStore) Abandon(ctx context.Context, scope IdempotencyScope) error {
+	_, err := s.db.ExecContext(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE endpoint = $1 AND member_id = $2 AND key = $3 AND status_code IS NULL
+	`, scope.Endpoint, scope.MemberID, scope.Key)
+	return err
+}
+
+func (s *sqlIdempotencyStore) PurgeExpired(ctx context.Context) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at < NOW()`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge idempotency keys: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// hashRequest fingerprints a request body so a reused key can be detected.
+func hashRequest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// responseRecorder captures a response while writing it through.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// idempotent runs serve at most once per Idempotency-Key. A repeated key is
+// answered with the recorded response. Server errors are not recorded, so the
+// client may retry them with the same key.
+func (h *Handler) idempotent(w http.ResponseWriter, r *http.Request, endpoint string, memberID uuid.UUID, body []byte, serve func(http.ResponseWriter)) {
+	key := r.Header.Get(idempotencyKeyHeader)
+	if key == "" || h.config.Idempotency == nil {
+		serve(w)
+		return
+	}
+	if len(key) > 255 {
+		httperr.BadRequest(w, "idempotency key too long")
+		return
+	}
+
+	store := h.config.Idempotency
+	scope := IdempotencyScope{Endpoint: endpoint, MemberID: memberID, Key: key}
+	stored, err := store.Begin(r.Context(), scope, hashRequest(body))
+	if err != nil {
+		errorRules.Write(w, err)
+		return
+	}
+
+	if stored != nil {
+		for name, values := range stored.Header {
+			w.Header()[name] = values
+		}
+		w.Header().Set("Idempotent-Replayed", "true")
+		w.WriteHeader(stored.StatusCode)
+		w.Write(stored.Body)
+		return
+	}
+
+	rec := &responseRecorder{ResponseWriter: w}
+	serve(rec)
+
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	// The request is done; record the outcome even if the caller went away.
+	ctx := context.WithoutCancel(r.Context())
+	if rec.status >= http.StatusInternalServerError {
+		err = store.Abandon(ctx, scope)
+	} else {
+		err = store.Complete(ctx, scope, StoredResponse{StatusCode: rec.status, Header: storedHeader(rec.Header()), Body: rec.body.Bytes()})
+	}
+	if err != nil {
+		log.Printf("Failed to record idempotency key for %s: %v", endpoint, err)
+	}
+}
+
+// storedHeader picks the headers to record with a response out of header.
+func storedHeader(header http.Header) http.Header {
+	stored := make(http.Header)
+	for _, name := range replayedHeaders {
+		if values := header.Values(name); len(values) > 0 {
+			stored[name] = values
+		}
+	}
+	return stored
+}
@@ -5,70 +5,255 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"libranexus/internal/catalog"
 	"libranexus/internal/clients"
-	"github.com/jules-labs/go-eventstore"
+	"libranexus/internal/database"
+	"libranexus/internal/logging"
+	"libranexus/internal/membership"
+	"libranexus/internal/metrics"
 	"log"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+)
+
+const (
+	// defaultHoldExpiry is how long a pending hold waits for a copy before lapsing.
+	defaultHoldExpiry = 30 * 24 * time.Hour
+	// defaultPickupWindow is how long a copy set aside for a hold waits to be
+	// collected before it is offered to the next member in the queue.
+	defaultPickupWindow = 3 * 24 * time.Hour
+	// defaultFinePerDay is charged for each day a checkout is overdue.
+	defaultFinePerDay = 0.25
+	// DefaultSnapshotInterval is how many events a checkout may accumulate
+	// beyond its latest snapshot before a fresh one is saved.
+	DefaultSnapshotInterval = 50
 )
 
 // service implements the Service interface.
 type service struct {
-	eventStore      *eventstore.EventStore
-	db              *sql.DB
-	catalogClient   *clients.CatalogClient
-	membershipClient *clients.MembershipClient
+	eventStore       *eventstore.EventStore
+	db               *sql.DB
+	catalogClient    clients.CatalogAPI
+	membershipClient clients.MembershipAPI
+	holdExpiry       time.Duration
+	pickupWindow     time.Duration
+	notifier         Notifier
+	finePerDay       float64
+	maxRenewals      map[string]int
+	loanPolicy       LoanPolicy
+	calendar         Calendar
+	snapshotInterval int
+	now              func() time.Time
+}
+
+// Option configures optional circulation service behaviour.
+type Option func(*service)
+
+// WithClock overrides the service's time source, which sets due dates, hold
+// and pickup deadlines and fines.
+func WithClock(now func() time.Time) Option {
+	return func(s *service) {
+		s.now = now
+	}
+}
+
+// WithHoldExpiry sets how long a pending hold remains in the queue.
+func WithHoldExpiry(d time.Duration) Option {
+	return func(s *service) {
+		if d > 0 {
+			s.holdExpiry = d
+		}
+	}
+}
+
+// WithPickupWindow sets how long a member has to collect the copy set aside
+// for their hold.
+func WithPickupWindow(d time.Duration) Option {
+	return func(s *service) {
+		if d > 0 {
+			s.pickupWindow = d
+		}
+	}
+}
+
+// WithNotifier sets how members are told about ready holds and overdue
+// checkouts. By default they are not told.
+func WithNotifier(n Notifier) Option {
+	return func(s *service) {
+		if n != nil {
+			s.notifier = n
+		}
+	}
+}
+
+// WithFinePerDay sets the fine charged for each day an item is overdue.
+func WithFinePerDay(amount float64) Option {
+	return func(s *service) {
+		if amount >= 0 {
+			s.finePerDay = amount
+		}
+	}
+}
+
+// WithMaxRenewals sets how many times a checkout may be renewed, by
+// membership tier. Tiers missing from the map cannot renew.
+func WithMaxRenewals(limits map[string]int) Option {
+	return func(s *service) {
+		if limits != nil {
+			s.maxRenewals = limits
+		}
+	}
+}
+
+// WithLoanPolicy sets how long loans last.
+func WithLoanPolicy(p LoanPolicy) Option {
+	return func(s *service) {
+		s.loanPolicy = p
+	}
+}
+
+// WithCalendar sets the days the library is open. Due dates that would fall
+// on a closed day move to the next open one.
+func WithCalendar(c Calendar) Option {
+	return func(s *service) {
+		if c != nil {
+			s.calendar = c
+		}
+	}
+}
+
+// WithSnapshotInterval sets how many events a checkout may accumulate beyond
+// its latest snapshot before a fresh one is saved. Zero disables snapshotting.
+func WithSnapshotInterval(n int) Option {
+	return func(s *service) {
+		if n >= 0 {
+			s.snapshotInterval = n
+		}
+	}
 }
 
 // NewService creates a new circulation service instance.
-func NewService(es *eventstore.EventStore, db *sql.DB, catalogClient *clients.CatalogClient, membershipClient *clients.MembershipClient) Service {
-	return &service{
-		eventStore:      es,
-		db:              db,
-		catalogClient:   catalogClient,
+func NewService(es *eventstore.EventStore, db *sql.DB, catalogClient clients.CatalogAPI, membershipClient clients.MembershipAPI, opts ...Option) Service {
+	s := &service{
+		eventStore:       es,
+		db:               db,
+		catalogClient:    catalogClient,
 		membershipClient: membershipClient,
+		holdExpiry:       defaultHoldExpiry,
+		pickupWindow:     defaultPickupWindow,
+		notifier:         NopNotifier{},
+		finePerDay:       defaultFinePerDay,
+		maxRenewals:      defaultMaxRenewals,
+		loanPolicy:       DefaultLoanPolicy(),
+		calendar:         AlwaysOpen{},
+		snapshotInterval: DefaultSnapshotInterval,
+		now:              time.Now,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
+// checkouts counts checkout attempts. Rejections are checkouts refused for
+// business reasons, such as no copy being available; errors are failures.
+var checkouts = metrics.NewCounter("circulation_checkouts_total",
+	"Checkout attempts, by outcome (success, rejected or error).", "outcome")
+
 // CheckoutItem orchestrates the checkout saga.
 func (s *service) CheckoutItem(ctx context.Context, memberID, itemID uuid.UUID) (*Checkout, error) {
+	checkout, err := s.checkoutItem(ctx, memberID, itemID)
+	checkouts.Inc(checkoutOutcome(err))
+	return checkout, err
+}
+
+func checkoutOutcome(err error) string {
+	switch {
+	case err == nil:
+		return "success"
+	case errors.Is(err, ErrItemUnavailable), errors.Is(err, ErrItemNotCheckoutable), errors.Is(err, ErrMemberNotEligible), errors.Is(err, ErrMembershipExpired), errors.Is(err, ErrCheckoutLimitReached), errors.Is(err, catalog.ErrVersionConflict):
+		return "rejected"
+	default:
+		return "error"
+	}
+}
+
+func (s *service) checkoutItem(ctx context.Context, memberID, itemID uuid.UUID) (*Checkout, error) {
 	// Step 1: Validate the member
 	member, err := s.membershipClient.GetMember(ctx, memberID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get member: %w", err)
 	}
-	if member.Status != "active" || member.FineBalance > 0 {
-		return nil, fmt.Errorf("member is not eligible for checkout")
+	if err := checkEligibility(member, s.now()); err != nil {
+		return nil, err
+	}
+
+	// Items still out past their due date count against the limit too.
+	var count int
+	err = s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM checkouts WHERE member_id = $1 AND status IN ('active', 'overdue')
+	`, memberID).Scan(&count)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count checkouts: %w", err)
+	}
+	if count >= member.MaxCheckouts {
+		return nil, &CheckoutLimitError{Count: count, Limit: member.MaxCheckouts}
 	}
 
-	// Step 2: Check item availability
+	// A copy set aside for the member's fulfilled hold is already out of the
+	// available pool, so it is handed over without touching availability.
+	hold, err := s.getFulfilledHold(ctx, memberID, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check holds: %w", err)
+	}
+
+	// The item's category and the member's tier set the loan period.
 	item, err := s.catalogClient.GetItem(ctx, itemID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get item: %w", err)
 	}
-	if item.Available <= 0 {
-		return nil, fmt.Errorf("item is not available")
+	if err := checkoutable(item); err != nil {
+		return nil, err
 	}
 
-	// Step 3: Decrement item availability (with compensation)
-	err = s.catalogClient.UpdateItemCopies(ctx, itemID, item.TotalCopies, item.Available-1)
-	if err != nil {
-		return nil, fmt.Errorf("failed to update item copies: %w", err)
+	// Everything the saga writes, here and in the catalog, is caused by this
+	// checkout.
+	checkoutID := uuid.New()
+	ctx = eventstore.WithCausationID(ctx, checkoutID.String())
+
+	// Step 2: Record the attempt, so that whatever happens from here on a
+	// crash leaves it for the recovery worker to finish or undo.
+	saga := &checkoutSaga{id: checkoutID, memberID: memberID, itemID: itemID}
+	if hold != nil {
+		saga.holdID = &hold.ID
+	}
+	if err := s.startSaga(ctx, saga); err != nil {
+		return nil, err
 	}
 
-	// Compensation function for decrementing item availability
-	compensation := func() {
-		log.Printf("Compensating for failed checkout: rolling back item availability for item %s", itemID)
-		if err := s.catalogClient.UpdateItemCopies(ctx, itemID, item.TotalCopies, item.Available); err != nil {
-			log.Printf("Failed to compensate item availability: %v", err)
+	if hold == nil {
+		// Step 3: Atomically reserve a copy. The catalog checks availability
+		// and decrements it in one step, so concurrent checkouts cannot both
+		// take the last copy.
+		if err := s.catalogClient.ReserveCopy(ctx, itemID); err != nil {
+			if errors.Is(err, catalog.ErrNoCopiesAvailable) {
+				s.stepSaga(ctx, saga, sagaCompensated, sagaPending)
+				return nil, ErrItemUnavailable
+			}
+			// The copy may or may not have been taken, so the saga stays
+			// pending for recovery to check against the catalog's events.
+			return nil, fmt.Errorf("failed to reserve copy: %w", err)
 		}
 	}
+	s.stepSaga(ctx, saga, sagaItemReserved, sagaPending)
 
 	// Step 4: Create the checkout record
-	checkoutID := uuid.New()
-	dueDate := time.Now().AddDate(0, 0, 14) // 2 weeks
+	checkedOutAt := s.now()
+	dueDate := s.dueDate(checkedOutAt, member, item)
 
 	eventData := ItemCheckedOutEvent{
 		CheckoutID: checkoutID,
@@ -78,8 +263,9 @@ func (s *service) CheckoutItem(ctx context.Context, memberID, itemID uuid.UUID)
 	}
 	jsonData, err := json.Marshal(eventData)
 	if err != nil {
-		compensation()
-		return nil, fmt.Errorf("failed to marshal event data: %w", err)
+		err = fmt.Errorf("failed to marshal event data: %w", err)
+		s.compensateSaga(ctx, saga, err)
+		return nil, err
 	}
 
 	event := eventstore.Event{
@@ -90,62 +276,176 @@ func (s *service) CheckoutItem(ctx context.Context, memberID, itemID uuid.UUID)
 		Version:       1,
 	}
 
-	if err := s.eventStore.AppendEvents(ctx, checkoutID, "checkout", 0, []eventstore.Event{event}); err != nil {
-		compensation()
-		return nil, fmt.Errorf("failed to append event: %w", err)
-	}
-
-	// Step 5: Update the read model
 	checkout := &Checkout{
 		ID:           checkoutID,
 		MemberID:     memberID,
 		ItemID:       itemID,
-		CheckoutDate: time.Now(),
+		CheckoutDate: checkedOutAt,
 		DueDate:      dueDate,
 		Status:       "active",
 	}
 
-	if err := s.insertCheckoutIntoReadModel(ctx, checkout); err != nil {
-		compensation()
-		return nil, fmt.Errorf("failed to update read model: %w", err)
+	// Step 5: Record the event, the read model and the saga's progress
+	// together, so a checkout is never listed without its history or
+	// recorded without being listed, and recovery never undoes a checkout
+	// that happened. The item's lock makes the checkout take turns with
+	// anything else converting one of the item's holds, and a hold being
+	// collected is closed in the same transaction, so a hold released since
+	// it was read fails the checkout instead of handing over a copy passed
+	// to someone else.
+	err = database.InTx(ctx, s.db, func(tx *sql.Tx) error {
+		return database.WithItemLock(ctx, tx, itemID, func() error {
+			if err := s.eventStore.AppendEventsTx(ctx, tx, checkoutID, "checkout", 0, []eventstore.Event{event}); err != nil {
+				return fmt.Errorf("failed to append event: %w", err)
+			}
+			if err := insertCheckoutIntoReadModel(ctx, tx, checkout); err != nil {
+				return fmt.Errorf("failed to update read model: %w", err)
+			}
+			if hold != nil {
+				if err := s.collectHold(ctx, tx, hold, checkoutID); err != nil {
+					return fmt.Errorf("failed to collect hold: %w", err)
+				}
+			}
+			moved, err := advanceSaga(ctx, tx, saga, sagaEventAppended, nil, sagaPending, sagaItemReserved)
+			if err != nil {
+				return err
+			}
+			if !moved {
+				return errSagaTakenOver
+			}
+			return nil
+		})
+	})
+	if errors.Is(err, errSagaTakenOver) {
+		// Recovery gave up on this checkout and is undoing it.
+		return nil, err
+	}
+	if hold != nil && errors.Is(err, eventstore.ErrConcurrencyConflict) {
+		s.stepSaga(ctx, saga, sagaCompensated, sagaPending, sagaItemReserved)
+		return nil, ErrItemUnavailable
+	}
+	if err != nil {
+		s.compensateSaga(ctx, saga, err)
+		return nil, err
 	}
 
+	s.stepSaga(ctx, saga, sagaCompleted, sagaEventAppended)
 	return checkout, nil
 }
 
-func (s *service) insertCheckoutIntoReadModel(ctx context.Context, checkout *Checkout) error {
+// recordCompensation appends the outcome of a failed checkout's compensation
+// to the checkout that was never created: first, or after an earlier failed
+// attempt to compensate. Failing to record it is only logged; the reconciler
+// still finds the item's count.
+func (s *service) recordCompensation(ctx context.Context, checkoutID uuid.UUID, eventType string, data interface{}) {
+	jsonData, err := json.Marshal(data)
+	var version int
+	if err == nil {
+		version, err = s.eventStore.GetCurrentVersion(ctx, checkoutID)
+	}
+	if err == nil {
+		err = s.eventStore.AppendEvents(ctx, checkoutID, "checkout", version, []eventstore.Event{{
+			AggregateID:   checkoutID,
+			AggregateType: "checkout",
+			EventType:     eventType,
+			EventData:     jsonData,
+			Version:       version + 1,
+		}})
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to record compensation", "checkout_id", checkoutID, "event_type", eventType, "err", err)
+	}
+}
+
+// checkEligibility reports why a member may not borrow, or nil if they may.
+// Only active members whose membership has not run out and who have no fines
+// outstanding can check items out. Expiry is checked against the date, not
+// just the status, since memberships are only marked expired periodically.
+func checkEligibility(member *membership.Member, now time.Time) error {
+	if member.Status == "expired" || (!member.ExpiresAt.IsZero() && !member.ExpiresAt.After(now)) {
+		return ErrMembershipExpired
+	}
+	if member.Status != "active" {
+		return fmt.Errorf("%w: member is %s", ErrMemberNotEligible, member.Status)
+	}
+	if member.FineBalance > 0 {
+		return fmt.Errorf("%w: %.2f in fines outstanding", ErrMemberNotEligible, member.FineBalance)
+	}
+	return nil
+}
+
+// checkoutable reports why an item cannot be lent, or nil if it can. Only
+// active items are lent, and an item showing more copies available than it
+// has is refused until someone corrects the count.
+func checkoutable(item *catalog.Item) error {
+	if item.Status != "active" {
+		return fmt.Errorf("%w: item is %s", ErrItemNotCheckoutable, item.Status)
+	}
+	if item.Available > item.TotalCopies {
+		log.Printf("Integrity violation: item %s has %d copies available but only %d in total", item.ID, item.Available, item.TotalCopies)
+		return fmt.Errorf("%w: availability exceeds total copies", ErrItemNotCheckoutable)
+	}
+	return nil
+}
+
+func insertCheckoutIntoReadModel(ctx context.Context, tx *sql.Tx, checkout *Checkout) error {
 	query := `
 		INSERT INTO checkouts (id, member_id, item_id, checkout_date, due_date, status)
 		VALUES ($1, $2, $3, $4, $5, $6)
 	`
-	_, err := s.db.ExecContext(ctx, query, checkout.ID, checkout.MemberID, checkout.ItemID, checkout.CheckoutDate, checkout.DueDate, checkout.Status)
+	_, err := tx.ExecContext(ctx, query, checkout.ID, checkout.MemberID, checkout.ItemID, checkout.CheckoutDate, checkout.DueDate, checkout.Status)
 	return err
 }
 
-// ReturnItem handles returning an item.
+// ReturnItem returns the member's active checkout of the item.
 func (s *service) ReturnItem(ctx context.Context, memberID, itemID uuid.UUID) error {
-	// Step 1: Find the active checkout
 	checkout, err := s.getActiveCheckout(ctx, memberID, itemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrCheckoutNotFound
+	}
 	if err != nil {
 		return fmt.Errorf("failed to find active checkout: %w", err)
 	}
+	return s.ReturnCheckout(ctx, Caller{MemberID: memberID}, checkout.ID)
+}
 
-	// Step 2: Increment item availability
-	item, err := s.catalogClient.GetItem(ctx, itemID)
+// ReturnCheckout handles returning the item on a specific checkout. Only the
+// member holding the checkout or an administrator may return it.
+func (s *service) ReturnCheckout(ctx context.Context, caller Caller, checkoutID uuid.UUID) error {
+	// Step 1: Find the checkout
+	checkout, err := s.getOpenCheckout(ctx, checkoutID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrCheckoutNotFound
+	}
 	if err != nil {
-		return fmt.Errorf("failed to get item: %w", err)
+		return fmt.Errorf("failed to find checkout: %w", err)
 	}
-	err = s.catalogClient.UpdateItemCopies(ctx, itemID, item.TotalCopies, item.Available+1)
+	if !caller.mayActFor(checkout.MemberID) {
+		return ErrNotCheckoutHolder
+	}
+	ctx = eventstore.WithCausationID(ctx, checkout.ID.String())
+	itemID := checkout.ItemID
+
+	// Step 2: Hand the copy to the next hold in the queue, or make it available again
+	hold, err := s.nextPendingHold(ctx, itemID)
 	if err != nil {
-		return fmt.Errorf("failed to update item copies: %w", err)
+		return fmt.Errorf("failed to check holds: %w", err)
+	}
+
+	released := false
+	if hold == nil {
+		if err := s.releaseCopy(ctx, itemID); err != nil {
+			return err
+		}
+		released = true
 	}
 
 	// Step 3: Create the return event
 	eventData := ItemReturnedEvent{
 		CheckoutID: checkout.ID,
-		MemberID:   memberID,
+		MemberID:   checkout.MemberID,
 		ItemID:     itemID,
-		ReturnDate: time.Now(),
+		ReturnDate: s.now(),
 	}
 	jsonData, err := json.Marshal(eventData)
 	if err != nil {
@@ -160,30 +460,84 @@ func (s *service) ReturnItem(ctx context.Context, memberID, itemID uuid.UUID) er
 		Version:       checkout.Version + 1,
 	}
 
-	if err := s.eventStore.AppendEvents(ctx, checkout.ID, "checkout", checkout.Version, []eventstore.Event{event}); err != nil {
-		// If appending the event fails, we should compensate by decrementing the item availability
-		log.Printf("Failed to append return event, compensating item availability for item %s", itemID)
-		if err := s.catalogClient.UpdateItemCopies(ctx, itemID, item.TotalCopies, item.Available); err != nil {
-			log.Printf("Failed to compensate item availability: %v", err)
+	// Step 4: Record the event and update the read model together
+	err = database.InTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.eventStore.AppendEventsTx(ctx, tx, checkout.ID, "checkout", checkout.Version, []eventstore.Event{event}); err != nil {
+			return fmt.Errorf("failed to append event: %w", err)
+		}
+		query := `
+			UPDATE checkouts
+			SET status = 'returned', return_date = $1, version = version + 1, updated_at = NOW()
+			WHERE id = $2
+		`
+		if _, err := tx.ExecContext(ctx, query, eventData.ReturnDate, checkout.ID); err != nil {
+			return fmt.Errorf("failed to update read model: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		// If recording the return fails, we should compensate by decrementing the item availability
+		if released {
+			logging.FromContext(ctx).Warn("failed to record return, compensating item availability", "item_id", itemID)
+			if err := s.catalogClient.ReserveCopy(ctx, itemID); err != nil {
+				logging.FromContext(ctx).Error("failed to compensate item availability", "item_id", itemID, "err", err)
+			}
+		}
+		return err
+	}
+	s.snapshotIfDue(ctx, checkout.ID, checkout.Version, checkout.Version+1)
+
+	// Step 5: Fulfill the hold; if that fails, release the copy to the shelf instead
+	if hold != nil {
+		if err := s.fulfillHold(ctx, hold); err != nil {
+			logging.FromContext(ctx).Error("failed to fulfill hold, releasing copy", "hold_id", hold.ID, "item_id", itemID, "err", err)
+			return s.releaseCopy(ctx, itemID)
 		}
-		return fmt.Errorf("failed to append event: %w", err)
+		s.notifyHoldReady(ctx, hold)
 	}
 
-	// Step 4: Update the read model
+	return nil
+}
+
+// releaseCopy puts one copy of an item back into the available pool. The
+// catalog does so atomically, so it cannot conflict with checkouts of the
+// same item reserving copies meanwhile.
+func (s *service) releaseCopy(ctx context.Context, itemID uuid.UUID) error {
+	if err := s.catalogClient.ReleaseCopy(ctx, itemID); err != nil {
+		return fmt.Errorf("failed to release copy: %w", err)
+	}
+	return nil
+}
+
+// getOpenCheckout loads a checkout that has not been returned yet.
+func (s *service) getOpenCheckout(ctx context.Context, checkoutID uuid.UUID) (*Checkout, error) {
 	query := `
-		UPDATE checkouts
-		SET status = 'returned', return_date = $1, updated_at = NOW()
-		WHERE id = $2
+		SELECT id, member_id, item_id, checkout_date, due_date, renewal_count, status, version
+		FROM checkouts
+		WHERE id = $1 AND status IN ('active', 'overdue')
 	`
-	_, err = s.db.ExecContext(ctx, query, time.Now(), checkout.ID)
-	return err
+	checkout := &Checkout{}
+	err := s.db.QueryRowContext(ctx, query, checkoutID).Scan(
+		&checkout.ID,
+		&checkout.MemberID,
+		&checkout.ItemID,
+		&checkout.CheckoutDate,
+		&checkout.DueDate,
+		&checkout.RenewalCount,
+		&checkout.Status,
+		&checkout.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return checkout, nil
 }
 
 func (s *service) getActiveCheckout(ctx context.Context, memberID, itemID uuid.UUID) (*Checkout, error) {
 	query := `
 		SELECT id, version
 		FROM checkouts
-		WHERE member_id = $1 AND item_id = $2 AND status = 'active'
+		WHERE member_id = $1 AND item_id = $2 AND status IN ('active', 'overdue')
 	`
 	checkout := &Checkout{}
 	err := s.db.QueryRowContext(ctx, query, memberID, itemID).Scan(&checkout.ID, &checkout.Version)
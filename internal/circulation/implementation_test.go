@@ -0,0 +1,135 @@
+package circulation
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"libranexus/internal/catalog"
+	"libranexus/internal/clients"
+	"libranexus/internal/clients/clientmocks"
+	"libranexus/internal/membership"
+)
+
+func TestCheckoutable(t *testing.T) {
+	tests := []struct {
+		name    string
+		item    catalog.Item
+		wantErr bool
+	}{
+		{"active with copies", catalog.Item{Status: "active", TotalCopies: 3, Available: 1}, false},
+		{"active with none left", catalog.Item{Status: "active", TotalCopies: 3, Available: 0}, false},
+		{"retired", catalog.Item{Status: "retired", TotalCopies: 3, Available: 3}, true},
+		{"lost", catalog.Item{Status: "lost", TotalCopies: 1, Available: 1}, true},
+		{"more available than owned", catalog.Item{Status: "active", TotalCopies: 2, Available: 3}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkoutable(&tt.item)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrItemNotCheckoutable)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckoutRejectsMemberWithFines(t *testing.T) {
+	id := uuid.New()
+	// The member comes over HTTP, so the fine must survive the JSON round trip.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(membership.Member{ID: id, Status: "active", FineBalance: 2.5, MaxCheckouts: 5, ExpiresAt: time.Now().AddDate(1, 0, 0)})
+	}))
+	defer server.Close()
+
+	s := &service{membershipClient: clients.NewMembershipClient(server.URL), now: time.Now}
+	_, err := s.CheckoutItem(context.Background(), id, uuid.New())
+
+	assert.ErrorIs(t, err, ErrMemberNotEligible)
+}
+
+func TestCheckEligibility(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	current := now.AddDate(0, 6, 0)
+
+	assert.NoError(t, checkEligibility(&membership.Member{Status: "active", ExpiresAt: current}, now))
+	assert.ErrorIs(t, checkEligibility(&membership.Member{Status: "suspended", ExpiresAt: current}, now), ErrMemberNotEligible)
+	assert.ErrorIs(t, checkEligibility(&membership.Member{Status: "active", ExpiresAt: current, FineBalance: 0.25}, now), ErrMemberNotEligible)
+	assert.ErrorIs(t, checkEligibility(&membership.Member{Status: "expired", ExpiresAt: now.AddDate(0, -1, 0)}, now), ErrMembershipExpired)
+	// Not yet marked expired, but past its date.
+	assert.ErrorIs(t, checkEligibility(&membership.Member{Status: "active", ExpiresAt: now.Add(-time.Minute)}, now), ErrMembershipExpired)
+}
+
+func TestCheckoutFollowsClock(t *testing.T) {
+	now := time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC)
+	db, _ := openScriptedDB(t, checkoutRules(
+		scriptRule{match: "INSERT INTO events", rows: [][]driver.Value{{int64(1)}}},
+		scriptRule{match: "FROM events", rows: [][]driver.Value{{int64(0)}}},
+		scriptRule{match: "INSERT INTO checkouts"},
+	)...)
+	item := catalog.Item{ID: uuid.New(), Status: "active", TotalCopies: 1, Available: 1, Version: 1}
+	member := membership.Member{ID: uuid.New(), Status: "active", MaxCheckouts: 5, ExpiresAt: now.Add(time.Hour)}
+	svc := NewService(eventstore.NewEventStore(db), db, clientmocks.NewCatalog(item), clientmocks.NewMembership(member),
+		WithClock(func() time.Time { return now }))
+
+	checkout, err := svc.CheckoutItem(context.Background(), member.ID, item.ID)
+	require.NoError(t, err)
+	assert.Equal(t, now, checkout.CheckoutDate)
+	assert.Equal(t, now.Add(defaultLoanPeriod), checkout.DueDate)
+
+	// An hour on, the membership has run out, though it is still marked active.
+	now = now.Add(time.Hour)
+	_, err = svc.CheckoutItem(context.Background(), member.ID, item.ID)
+	assert.ErrorIs(t, err, ErrMembershipExpired)
+}
+
+func TestReturnCheckoutRefusesAnotherMember(t *testing.T) {
+	now := time.Now()
+	item := catalog.Item{ID: uuid.New(), Status: "active", TotalCopies: 1, Version: 1}
+	holder, checkoutID := uuid.New(), uuid.New()
+	db, _ := openScriptedDB(t, scriptRule{match: "FROM checkouts", rows: [][]driver.Value{{
+		checkoutID.String(), holder.String(), item.ID.String(), now.Add(-24 * time.Hour), now.Add(24 * time.Hour), int64(0), "active", int64(1),
+	}}})
+	cat := clientmocks.NewCatalog(item)
+	svc := NewService(eventstore.NewEventStore(db), db, cat, clientmocks.NewMembership())
+
+	err := svc.ReturnCheckout(context.Background(), Caller{MemberID: uuid.New(), Role: membership.RoleMember}, checkoutID)
+
+	assert.ErrorIs(t, err, ErrNotCheckoutHolder)
+	assert.Empty(t, cat.Calls(), "a refused return should leave the copy checked out")
+}
+
+func TestReturnCheckoutReleasesCopyAtomically(t *testing.T) {
+	now := time.Now()
+	item := catalog.Item{ID: uuid.New(), Status: "active", TotalCopies: 2, Available: 0, Version: 7}
+	holder, checkoutID := uuid.New(), uuid.New()
+	db, _ := openScriptedDB(t,
+		scriptRule{match: "FROM checkouts", rows: [][]driver.Value{{
+			checkoutID.String(), holder.String(), item.ID.String(), now.Add(-24 * time.Hour), now.Add(24 * time.Hour), int64(0), "active", int64(1),
+		}}},
+		scriptRule{match: "FROM holds"},
+		scriptRule{match: "INSERT INTO events", rows: [][]driver.Value{{int64(1)}}},
+		scriptRule{match: "FROM events", rows: [][]driver.Value{{int64(1)}}},
+		scriptRule{match: "UPDATE checkouts"},
+	)
+	cat := clientmocks.NewCatalog(item)
+	svc := NewService(eventstore.NewEventStore(db), db, cat, clientmocks.NewMembership())
+
+	require.NoError(t, svc.ReturnCheckout(context.Background(), Caller{MemberID: holder}, checkoutID))
+
+	after, _ := cat.Item(item.ID)
+	assert.Equal(t, 1, after.Available)
+	assert.Equal(t, []string{"ReleaseCopy"}, cat.Calls(),
+		"the copy should go back without a versioned write that a concurrent reservation could conflict with")
+}
@@ -0,0 +1,200 @@
+// internal/circulation/notify.go
+package circulation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"libranexus/internal/logging"
+	"libranexus/internal/membership"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// defaultNotifyTimeout bounds a webhook notification.
+const defaultNotifyTimeout = 10 * time.Second
+
+// NotificationKind names what a notification tells the member.
+type NotificationKind string
+
+const (
+	// NotifyHoldReady tells a member a copy is set aside for their hold.
+	// Deadline is when it is released if they have not collected it.
+	NotifyHoldReady NotificationKind = "hold_ready"
+	// NotifyOverdue tells a member a checkout is overdue and has been fined.
+	// Deadline is the checkout's due date.
+	NotifyOverdue NotificationKind = "item_overdue"
+)
+
+// Notification is something a member should hear about their loans.
+type Notification struct {
+	Kind       NotificationKind `json:"kind"`
+	MemberID   uuid.UUID        `json:"member_id"`
+	ItemID     uuid.UUID        `json:"item_id"`
+	HoldID     uuid.UUID        `json:"hold_id,omitempty"`
+	CheckoutID uuid.UUID        `json:"checkout_id,omitempty"`
+	Deadline   time.Time        `json:"deadline"`
+	FineAmount float64          `json:"fine_amount,omitempty"`
+}
+
+// Message renders the notification for people.
+func (n Notification) Message() (subject, body string) {
+	deadline := n.Deadline.UTC().Format("Monday 2 January 2006, 15:04 MST")
+	switch n.Kind {
+	case NotifyHoldReady:
+		return "Your hold is ready for collection",
+			fmt.Sprintf("A copy of item %s is being held for you. Please collect it by %s, after which it is offered to the next member waiting.", n.ItemID, deadline)
+	case NotifyOverdue:
+		return "Your checkout is overdue",
+			fmt.Sprintf("Item %s was due back on %s. A fine of %.2f has been charged to your account; please return it as soon as you can.", n.ItemID, deadline, n.FineAmount)
+	}
+	return "Library notification", fmt.Sprintf("%s for item %s", n.Kind, n.ItemID)
+}
+
+// Notifier delivers notifications to members. The service calls it after
+// the change a notification describes has been recorded, and only logs its
+// errors, so a notifier that is down never fails a return or a fine.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// NopNotifier discards every notification. It is the default.
+type NopNotifier struct{}
+
+func (NopNotifier) Notify(ctx context.Context, n Notification) error {
+	return nil
+}
+
+// ConsoleNotifier logs every notification, for development.
+type ConsoleNotifier struct{}
+
+func (ConsoleNotifier) Notify(ctx context.Context, n Notification) error {
+	subject, body := n.Message()
+	logging.FromContext(ctx).Info("notification", "kind", n.Kind, "member_id", n.MemberID, "subject", subject, "body", body)
+	return nil
+}
+
+// WebhookNotifier POSTs each notification as JSON to a URL, leaving delivery
+// to members to whatever listens there.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a notifier posting to url.
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{url: url, client: &http.Client{Timeout: defaultNotifyTimeout}}
+}
+
+func (w *WebhookNotifier) Notify(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("notification webhook responded %s", resp.Status)
+	}
+	return nil
+}
+
+// MemberDirectory finds a member's contact details;
+// either of the clients package's membership clients satisfies it.
+type MemberDirectory interface {
+	GetMember(ctx context.Context, id uuid.UUID) (*membership.Member, error)
+}
+
+// EmailNotifier emails each notification to the member's address over SMTP.
+type EmailNotifier struct {
+	addr    string
+	from    string
+	auth    smtp.Auth
+	members MemberDirectory
+	send    func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewEmailNotifier creates a notifier sending through the SMTP server at
+// addr (host:port) from the given address, authenticating when username is
+// set.
+func NewEmailNotifier(addr, from, username, password string, members MemberDirectory) *EmailNotifier {
+	e := &EmailNotifier{addr: addr, from: from, members: members, send: smtp.SendMail}
+	if username != "" {
+		host, _, _ := strings.Cut(addr, ":")
+		e.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return e
+}
+
+func (e *EmailNotifier) Notify(ctx context.Context, n Notification) error {
+	member, err := e.members.GetMember(ctx, n.MemberID)
+	if err != nil {
+		return fmt.Errorf("failed to look up member: %w", err)
+	}
+	subject, body := n.Message()
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s\r\n",
+		e.from, member.Email, subject, body)
+	return e.send(e.addr, e.auth, e.from, []string{member.Email}, []byte(msg))
+}
+
+// NotifierFromEnv builds the notifier NOTIFIER names: none (the default),
+// console, webhook (posting to NOTIFY_WEBHOOK_URL) or email (through
+// SMTP_ADDR from SMTP_FROM, with optional SMTP_USERNAME and SMTP_PASSWORD,
+// finding addresses in members).
+func NotifierFromEnv(members MemberDirectory) (Notifier, error) {
+	switch kind := os.Getenv("NOTIFIER"); kind {
+	case "", "none":
+		return NopNotifier{}, nil
+	case "console":
+		return ConsoleNotifier{}, nil
+	case "webhook":
+		url := os.Getenv("NOTIFY_WEBHOOK_URL")
+		if url == "" {
+			return nil, errors.New("NOTIFY_WEBHOOK_URL is required for the webhook notifier")
+		}
+		return NewWebhookNotifier(url), nil
+	case "email":
+		addr, from := os.Getenv("SMTP_ADDR"), os.Getenv("SMTP_FROM")
+		if addr == "" || from == "" {
+			return nil, errors.New("SMTP_ADDR and SMTP_FROM are required for the email notifier")
+		}
+		return NewEmailNotifier(addr, from, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), members), nil
+	default:
+		return nil, fmt.Errorf("unknown NOTIFIER %q", kind)
+	}
+}
+
+// notify hands n to the notifier, logging rather than returning a failure:
+// by the time it is called, what n describes has already happened.
+func (s *service) notify(ctx context.Context, n Notification) {
+	if err := s.notifier.Notify(ctx, n); err != nil {
+		logging.FromContext(ctx).Warn("failed to send notification", "kind", n.Kind, "member_id", n.MemberID, "err", err)
+	}
+}
+
+// notifyHoldReady tells a hold's member that a copy is set aside for them.
+// It is called once per fulfilment, after the fulfilment is recorded.
+func (s *service) notifyHoldReady(ctx context.Context, hold *Hold) {
+	s.notify(ctx, Notification{
+		Kind:     NotifyHoldReady,
+		MemberID: hold.MemberID,
+		ItemID:   hold.ItemID,
+		HoldID:   hold.ID,
+		Deadline: hold.ExpiresAt,
+	})
+}
@@ -0,0 +1,145 @@
+package circulation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"libranexus/internal/membership"
+)
+
+type recordingNotifier struct {
+	sent []Notification
+	err  error
+}
+
+func (r *recordingNotifier) Notify(ctx context.Context, n Notification) error {
+	r.sent = append(r.sent, n)
+	return r.err
+}
+
+func TestNotifyHoldReadySendsOneNotification(t *testing.T) {
+	notifier := &recordingNotifier{}
+	s := &service{notifier: notifier}
+	pickupBy := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	hold := &Hold{ID: uuid.New(), MemberID: uuid.New(), ItemID: uuid.New(), ExpiresAt: pickupBy, Status: "fulfilled"}
+
+	s.notifyHoldReady(context.Background(), hold)
+
+	require.Len(t, notifier.sent, 1)
+	assert.Equal(t, Notification{Kind: NotifyHoldReady, MemberID: hold.MemberID, ItemID: hold.ItemID, HoldID: hold.ID, Deadline: pickupBy}, notifier.sent[0])
+}
+
+func TestNotifySwallowsNotifierErrors(t *testing.T) {
+	notifier := &recordingNotifier{err: errors.New("smtp: connection refused")}
+	s := &service{notifier: notifier}
+
+	assert.NotPanics(t, func() {
+		s.notify(context.Background(), Notification{Kind: NotifyOverdue, MemberID: uuid.New()})
+	})
+	assert.Len(t, notifier.sent, 1)
+}
+
+func TestNotificationMessage(t *testing.T) {
+	deadline := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+
+	subject, body := Notification{Kind: NotifyHoldReady, Deadline: deadline}.Message()
+	assert.Equal(t, "Your hold is ready for collection", subject)
+	assert.Contains(t, body, "Monday 4 March 2024")
+
+	subject, body = Notification{Kind: NotifyOverdue, Deadline: deadline, FineAmount: 0.75}.Message()
+	assert.Equal(t, "Your checkout is overdue", subject)
+	assert.Contains(t, body, "0.75")
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var got Notification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+	}))
+	defer server.Close()
+
+	n := Notification{Kind: NotifyHoldReady, MemberID: uuid.New(), ItemID: uuid.New(), Deadline: time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)}
+	require.NoError(t, NewWebhookNotifier(server.URL).Notify(context.Background(), n))
+	assert.Equal(t, n, got)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+	assert.Error(t, NewWebhookNotifier(failing.URL).Notify(context.Background(), n))
+}
+
+type directory map[uuid.UUID]*membership.Member
+
+func (d directory) GetMember(ctx context.Context, id uuid.UUID) (*membership.Member, error) {
+	if m, ok := d[id]; ok {
+		return m, nil
+	}
+	return nil, membership.ErrMemberNotFound
+}
+
+func TestEmailNotifierSendsToMember(t *testing.T) {
+	memberID := uuid.New()
+	e := NewEmailNotifier("smtp.example.com:587", "library@example.com", "", "", directory{memberID: {ID: memberID, Email: "reader@example.com"}})
+	var to []string
+	var msg string
+	e.send = func(addr string, a smtp.Auth, from string, rcpt []string, body []byte) error {
+		assert.Equal(t, "smtp.example.com:587", addr)
+		assert.Nil(t, a)
+		to, msg = rcpt, string(body)
+		return nil
+	}
+
+	require.NoError(t, e.Notify(context.Background(), Notification{Kind: NotifyHoldReady, MemberID: memberID}))
+	assert.Equal(t, []string{"reader@example.com"}, to)
+	assert.Contains(t, msg, "To: reader@example.com\r\n")
+	assert.Contains(t, msg, "Subject: Your hold is ready for collection\r\n")
+
+	assert.ErrorIs(t, e.Notify(context.Background(), Notification{Kind: NotifyHoldReady, MemberID: uuid.New()}), membership.ErrMemberNotFound)
+}
+
+func TestNotifierFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    Notifier
+		wantErr bool
+	}{
+		{"default", nil, NopNotifier{}, false},
+		{"console", map[string]string{"NOTIFIER": "console"}, ConsoleNotifier{}, false},
+		{"webhook", map[string]string{"NOTIFIER": "webhook", "NOTIFY_WEBHOOK_URL": "http://hooks:9000"}, nil, false},
+		{"webhook without url", map[string]string{"NOTIFIER": "webhook"}, nil, true},
+		{"email without server", map[string]string{"NOTIFIER": "email", "SMTP_FROM": "library@example.com"}, nil, true},
+		{"unknown", map[string]string{"NOTIFIER": "pigeon"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"NOTIFIER", "NOTIFY_WEBHOOK_URL", "SMTP_ADDR", "SMTP_FROM"} {
+				t.Setenv(key, tt.env[key])
+			}
+			got, err := NotifierFromEnv(directory{})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.want != nil {
+				assert.Equal(t, tt.want, got)
+			} else {
+				assert.IsType(t, &WebhookNotifier{}, got)
+			}
+		})
+	}
+}
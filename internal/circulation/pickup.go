@@ -0,0 +1,86 @@
+// internal/circulation/pickup.go
+package circulation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"libranexus/internal/logging"
+
+	"github.com/jules-labs/go-eventstore"
+)
+
+// pickupBatchSize bounds how many uncollected holds one pass releases.
+const pickupBatchSize = 100
+
+// ReleaseUncollectedHolds expires fulfilled holds whose pickup window has
+// passed and passes each copy set aside for them to the next member waiting,
+// who is notified, or back to the shelf. It returns how many holds it
+// released. A hold collected meanwhile is left alone, and one whose copy
+// cannot be passed on is logged for the reconciler to correct.
+func (s *service) ReleaseUncollectedHolds(ctx context.Context) (int, error) {
+	ctx = eventstore.WithActorID(ctx, eventstore.SystemActorID)
+	query := `
+		SELECT id, member_id, item_id, placed_at, expires_at, fulfilled_at, status, version
+		FROM holds
+		WHERE status = 'fulfilled' AND expires_at <= $1
+		ORDER BY expires_at
+		LIMIT $2
+	`
+	rows, err := s.db.QueryContext(ctx, query, s.now(), pickupBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find uncollected holds: %w", err)
+	}
+	var holds []*Hold
+	for rows.Next() {
+		hold, err := scanHold(rows)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan hold: %w", err)
+		}
+		holds = append(holds, hold)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to find uncollected holds: %w", err)
+	}
+
+	released := 0
+	for _, hold := range holds {
+		logger := logging.FromContext(ctx).With("hold_id", hold.ID, "item_id", hold.ItemID)
+		holdCtx := eventstore.WithCausationID(ctx, hold.ID.String())
+		err := s.expireHold(holdCtx, hold)
+		if errors.Is(err, eventstore.ErrConcurrencyConflict) {
+			logger.Info("skipped release of hold changed since it was read")
+			continue
+		}
+		if err != nil {
+			logger.Warn("failed to release uncollected hold", "err", err)
+			continue
+		}
+		released++
+		if err := s.passOnCopy(holdCtx, hold); err != nil {
+			logger.Error("failed to pass on copy from uncollected hold", "err", err)
+		}
+	}
+	return released, nil
+}
+
+// passOnCopy sets the copy released from an uncollected hold aside for the
+// next member in the item's queue, or returns it to the shelf if the queue
+// is empty or the next hold cannot be fulfilled.
+func (s *service) passOnCopy(ctx context.Context, released *Hold) error {
+	next, err := s.nextPendingHold(ctx, released.ItemID)
+	if err != nil {
+		return fmt.Errorf("failed to check holds: %w", err)
+	}
+	if next == nil {
+		return s.releaseCopy(ctx, released.ItemID)
+	}
+	if err := s.fulfillHold(ctx, next); err != nil {
+		logging.FromContext(ctx).Error("failed to fulfill hold, releasing copy", "hold_id", next.ID, "item_id", next.ItemID, "err", err)
+		return s.releaseCopy(ctx, released.ItemID)
+	}
+	s.notifyHoldReady(ctx, next)
+	return nil
+}
@@ -0,0 +1,58 @@
+// internal/circulation/policy.go
+package circulation
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// defaultLoanPeriod is how long a loan lasts when no policy entry applies.
+const defaultLoanPeriod = 14 * 24 * time.Hour
+
+// LoanPolicy decides how long a loan lasts. A period set for the item's
+// category wins over one set for the member's tier, so short-loan categories
+// such as reference stay short for everyone; otherwise the tier's period
+// applies, and failing that the default.
+type LoanPolicy struct {
+	Default    time.Duration
+	ByTier     map[string]time.Duration
+	ByCategory map[string]time.Duration
+}
+
+// DefaultLoanPolicy lends everything for two weeks.
+func DefaultLoanPolicy() LoanPolicy {
+	return LoanPolicy{Default: defaultLoanPeriod}
+}
+
+// LoanPeriod returns the loan period for a member tier and item category.
+func (p LoanPolicy) LoanPeriod(tier, category string) time.Duration {
+	if d, ok := p.ByCategory[category]; ok {
+		return d
+	}
+	if d, ok := p.ByTier[tier]; ok {
+		return d
+	}
+	if p.Default > 0 {
+		return p.Default
+	}
+	return defaultLoanPeriod
+}
+
+// ParseLoanPeriods parses a list of loan periods such as
+// "reference=72h,periodical=168h".
+func ParseLoanPeriods(s string) (map[string]time.Duration, error) {
+	periods := make(map[string]time.Duration)
+	for _, pair := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid loan period %q: want name=duration", pair)
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid loan period for %q: %q", key, value)
+		}
+		periods[key] = d
+	}
+	return periods, nil
+}
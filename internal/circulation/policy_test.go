@@ -0,0 +1,34 @@
+package circulation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoanPolicyLoanPeriod(t *testing.T) {
+	day := 24 * time.Hour
+	policy := LoanPolicy{
+		Default:    14 * day,
+		ByTier:     map[string]time.Duration{"premium": 28 * day},
+		ByCategory: map[string]time.Duration{"reference": 3 * day},
+	}
+
+	assert.Equal(t, 14*day, policy.LoanPeriod("basic", "general"))
+	assert.Equal(t, 28*day, policy.LoanPeriod("premium", "general"))
+	assert.Equal(t, 3*day, policy.LoanPeriod("premium", "reference"), "category wins over tier")
+	assert.Equal(t, 14*day, DefaultLoanPolicy().LoanPeriod("premium", "reference"))
+	assert.Equal(t, 14*day, LoanPolicy{}.LoanPeriod("basic", "general"), "zero policy keeps the two-week default")
+}
+
+func TestParseLoanPeriods(t *testing.T) {
+	periods, err := ParseLoanPeriods("reference=72h, periodical=168h")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]time.Duration{"reference": 72 * time.Hour, "periodical": 168 * time.Hour}, periods)
+
+	for _, bad := range []string{"", "reference", "reference=3d", "reference=0s", "=72h"} {
+		_, err := ParseLoanPeriods(bad)
+		assert.Error(t, err, bad)
+	}
+}
@@ -0,0 +1,94 @@
+// internal/circulation/reconcile.go
+package circulation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"libranexus/internal/catalog"
+	"libranexus/internal/logging"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+)
+
+// availabilityMismatch is an item whose available count disagrees with the
+// copies circulation knows to be out.
+type availabilityMismatch struct {
+	itemID      uuid.UUID
+	totalCopies int
+	available   int
+	expected    int
+	version     int
+}
+
+// ReconcileAvailability corrects items whose available count is not their
+// total copies less the copies out on open checkouts and those set aside for
+// fulfilled holds, as happens when a failed checkout's compensation could not
+// release its copy. Items changed within grace are left alone, since a
+// checkout or return may still be between its catalog and circulation
+// writes. Each correction goes through the catalog, conditional on the
+// version the mismatch was seen at, so it is recorded as an event and loses
+// to any concurrent change. It returns the number of items corrected.
+func (s *service) ReconcileAvailability(ctx context.Context, grace time.Duration) (int, error) {
+	ctx = eventstore.WithActorID(ctx, eventstore.SystemActorID)
+	mismatches, err := s.listAvailabilityMismatches(ctx, s.now().Add(-grace))
+	if err != nil {
+		return 0, err
+	}
+
+	corrected := 0
+	for _, m := range mismatches {
+		logger := logging.FromContext(ctx).With("item_id", m.itemID, "available", m.available, "expected", m.expected)
+		err := s.catalogClient.UpdateItemCopies(ctx, m.itemID, m.totalCopies, m.expected, m.version)
+		if errors.Is(err, catalog.ErrVersionConflict) {
+			// The item changed since the scan; the next run looks again.
+			logger.Info("skipped availability correction for changed item")
+			continue
+		}
+		if err != nil {
+			logger.Error("failed to correct availability", "err", err)
+			continue
+		}
+		logger.Warn("corrected item availability")
+		corrected++
+	}
+	return corrected, nil
+}
+
+func (s *service) listAvailabilityMismatches(ctx context.Context, settledBefore time.Time) ([]availabilityMismatch, error) {
+	query := `
+		SELECT i.id, i.total_copies, i.available, i.version,
+		       GREATEST(i.total_copies - COALESCE(c.open, 0) - COALESCE(h.held, 0), 0) AS expected
+		FROM items i
+		LEFT JOIN (
+			SELECT item_id, COUNT(*) AS open FROM checkouts
+			WHERE status IN ('active', 'overdue')
+			GROUP BY item_id
+		) c ON c.item_id = i.id
+		LEFT JOIN (
+			SELECT item_id, COUNT(*) AS held FROM holds
+			WHERE status = 'fulfilled'
+			GROUP BY item_id
+		) h ON h.item_id = i.id
+		WHERE i.updated_at < $1
+		AND i.available <> GREATEST(i.total_copies - COALESCE(c.open, 0) - COALESCE(h.held, 0), 0)
+		ORDER BY i.id
+	`
+	rows, err := s.db.QueryContext(ctx, query, settledBefore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find availability mismatches: %w", err)
+	}
+	defer rows.Close()
+
+	var mismatches []availabilityMismatch
+	for rows.Next() {
+		var m availabilityMismatch
+		if err := rows.Scan(&m.itemID, &m.totalCopies, &m.available, &m.version, &m.expected); err != nil {
+			return nil, fmt.Errorf("failed to scan availability mismatch: %w", err)
+		}
+		mismatches = append(mismatches, m)
+	}
+	return mismatches, rows.Err()
+}
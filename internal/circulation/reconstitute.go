@@ -0,0 +1,74 @@
+// internal/circulation/reconstitute.go
+package circulation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"libranexus/internal/logging"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+)
+
+// ReconstituteCheckout rebuilds a checkout's state from the event store
+// rather than the read model: it starts from the latest snapshot, if any, and
+// folds in the events recorded after it.
+func (s *service) ReconstituteCheckout(ctx context.Context, id uuid.UUID) (*Checkout, error) {
+	checkout := &Checkout{}
+
+	snapshot, err := s.eventStore.LoadSnapshot(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load snapshot: %w", err)
+	}
+	if snapshot != nil {
+		if err := json.Unmarshal(snapshot.State, checkout); err != nil {
+			return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+		}
+	}
+
+	events, err := s.eventStore.LoadEventsWithOptions(ctx, id, checkout.Version+1, 0, eventstore.LoadEventsOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load events: %w", err)
+	}
+	if snapshot == nil && len(events) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrCheckoutNotFound, id)
+	}
+
+	for _, event := range events {
+		if err := checkout.Apply(event); err != nil {
+			return nil, fmt.Errorf("failed to apply event %d: %w", event.Version, err)
+		}
+	}
+	return checkout, nil
+}
+
+// snapshotIfDue saves a fresh snapshot of the checkout when an append taking
+// it from version from to version to crossed a multiple of the snapshot
+// interval. Failures are only logged: the append has already committed.
+func (s *service) snapshotIfDue(ctx context.Context, id uuid.UUID, from, to int) {
+	if !eventstore.SnapshotDue(from, to, s.snapshotInterval) {
+		return
+	}
+	checkout, err := s.ReconstituteCheckout(ctx, id)
+	if err == nil {
+		err = s.saveCheckoutSnapshot(ctx, checkout)
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to save checkout snapshot", "checkout_id", id, "err", err)
+	}
+}
+
+func (s *service) saveCheckoutSnapshot(ctx context.Context, checkout *Checkout) error {
+	state, err := json.Marshal(checkout)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+
+	return s.eventStore.SaveSnapshot(ctx, eventstore.Snapshot{
+		AggregateID:   checkout.ID,
+		AggregateType: "checkout",
+		Version:       checkout.Version,
+		State:         state,
+	})
+}
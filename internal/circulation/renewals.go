@@ -0,0 +1,132 @@
+// internal/circulation/renewals.go
+package circulation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"libranexus/internal/database"
+	"libranexus/internal/membership"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+)
+
+// defaultMaxRenewals is how many times a checkout may be renewed, by tier.
+var defaultMaxRenewals = map[string]int{
+	"basic":     2,
+	"premium":   5,
+	"librarian": 10,
+}
+
+// RenewCheckout extends a checkout's due date by another loan period. Only the
+// member holding the checkout or an administrator may renew it.
+func (s *service) RenewCheckout(ctx context.Context, caller Caller, checkoutID uuid.UUID) (*Checkout, error) {
+	checkout, err := s.getOpenCheckout(ctx, checkoutID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCheckoutNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find checkout: %w", err)
+	}
+	if !caller.mayActFor(checkout.MemberID) {
+		return nil, ErrNotCheckoutHolder
+	}
+	ctx = eventstore.WithCausationID(ctx, checkout.ID.String())
+
+	member, err := s.membershipClient.GetMember(ctx, checkout.MemberID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get member: %w", err)
+	}
+	item, err := s.catalogClient.GetItem(ctx, checkout.ItemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item: %w", err)
+	}
+	hold, err := s.nextPendingHold(ctx, checkout.ItemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check holds: %w", err)
+	}
+
+	now := s.now()
+	if err := checkRenewal(checkout, member, s.maxRenewals[member.MembershipTier], hold != nil, now); err != nil {
+		return nil, err
+	}
+
+	renewed := *checkout
+	renewed.DueDate = NextOpenDay(s.calendar, renewalDueDate(checkout.DueDate, now, s.loanPolicy.LoanPeriod(member.MembershipTier, item.Category)))
+	renewed.RenewalCount++
+	renewed.Status = "active"
+	renewed.Version++
+
+	eventData := CheckoutRenewedEvent{
+		CheckoutID:   renewed.ID,
+		MemberID:     renewed.MemberID,
+		ItemID:       renewed.ItemID,
+		DueDate:      renewed.DueDate,
+		RenewalCount: renewed.RenewalCount,
+	}
+	jsonData, err := json.Marshal(eventData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	event := eventstore.Event{
+		AggregateID:   renewed.ID,
+		AggregateType: "checkout",
+		EventType:     "CheckoutRenewed",
+		EventData:     jsonData,
+		Version:       renewed.Version,
+	}
+
+	err = database.InTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.eventStore.AppendEventsTx(ctx, tx, renewed.ID, "checkout", checkout.Version, []eventstore.Event{event}); err != nil {
+			return fmt.Errorf("failed to append event: %w", err)
+		}
+		query := `
+			UPDATE checkouts
+			SET due_date = $1, renewal_count = $2, status = 'active', version = $3, updated_at = NOW()
+			WHERE id = $4
+		`
+		if _, err := tx.ExecContext(ctx, query, renewed.DueDate, renewed.RenewalCount, renewed.Version, renewed.ID); err != nil {
+			return fmt.Errorf("failed to update read model: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.snapshotIfDue(ctx, renewed.ID, checkout.Version, renewed.Version)
+
+	return &renewed, nil
+}
+
+// checkRenewal reports why a checkout may not be renewed, or nil if it may.
+// Overdue checkouts can only be renewed once the member's fines are paid.
+func checkRenewal(checkout *Checkout, member *membership.Member, maxRenewals int, itemHeld bool, now time.Time) error {
+	if member.Status != "active" {
+		return fmt.Errorf("member is not eligible to renew")
+	}
+	if checkout.RenewalCount >= maxRenewals {
+		return ErrRenewalLimitReached
+	}
+	if itemHeld {
+		return ErrItemOnHold
+	}
+	overdue := checkout.Status == "overdue" || checkout.DueDate.Before(now)
+	if overdue && member.FineBalance > 0 {
+		return ErrFinesOutstanding
+	}
+	return nil
+}
+
+// renewalDueDate extends a loan by one loan period. An overdue loan is
+// extended from now, so the renewal always buys a full period.
+func renewalDueDate(dueDate, now time.Time, period time.Duration) time.Time {
+	if dueDate.Before(now) {
+		return now.Add(period)
+	}
+	return dueDate.Add(period)
+}
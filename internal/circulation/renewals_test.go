@@ -0,0 +1,108 @@
+package circulation
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"libranexus/internal/catalog"
+	"libranexus/internal/clients/clientmocks"
+	"libranexus/internal/membership"
+)
+
+func TestCheckRenewal(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	onTime := &Checkout{DueDate: now.Add(24 * time.Hour), Status: "active"}
+	overdue := &Checkout{DueDate: now.Add(-24 * time.Hour), Status: "overdue"}
+	renewedTwice := &Checkout{DueDate: now.Add(24 * time.Hour), Status: "active", RenewalCount: 2}
+
+	paidUp := &membership.Member{Status: "active"}
+	owing := &membership.Member{Status: "active", FineBalance: 1.25}
+	suspended := &membership.Member{Status: "suspended"}
+
+	tests := []struct {
+		name     string
+		checkout *Checkout
+		member   *membership.Member
+		itemHeld bool
+		wantErr  error
+	}{
+		{"on time", onTime, paidUp, false, nil},
+		{"on time with fines from elsewhere", onTime, owing, false, nil},
+		{"at renewal limit", renewedTwice, paidUp, false, ErrRenewalLimitReached},
+		{"item held by another member", onTime, paidUp, true, ErrItemOnHold},
+		{"overdue and fines paid", overdue, paidUp, false, nil},
+		{"overdue with fines owing", overdue, owing, false, ErrFinesOutstanding},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkRenewal(tt.checkout, tt.member, 2, tt.itemHeld, now)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+
+	assert.Error(t, checkRenewal(onTime, suspended, 2, false, now), "suspended members cannot renew")
+}
+
+func TestRenewalDueDate(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	period := 14 * 24 * time.Hour
+
+	assert.Equal(t, now.Add(24*time.Hour+period), renewalDueDate(now.Add(24*time.Hour), now, period))
+	assert.Equal(t, now.Add(period), renewalDueDate(now.Add(-72*time.Hour), now, period), "overdue loans renew from today")
+}
+
+func TestRenewCheckoutRequiresHolderOrAdministrator(t *testing.T) {
+	now := time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC)
+	item := catalog.Item{ID: uuid.New(), Status: "active", TotalCopies: 1, Version: 1}
+	holder := membership.Member{ID: uuid.New(), Status: "active", MembershipTier: "basic"}
+	checkoutID := uuid.New()
+
+	tests := []struct {
+		name    string
+		caller  Caller
+		wantErr error
+	}{
+		{"holder", Caller{MemberID: holder.ID, Role: membership.RoleMember}, nil},
+		{"administrator", Caller{MemberID: uuid.New(), Role: membership.RoleAdmin}, nil},
+		{"another member", Caller{MemberID: uuid.New(), Role: membership.RoleMember}, ErrNotCheckoutHolder},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _ := openScriptedDB(t,
+				scriptRule{match: "FROM checkouts", rows: [][]driver.Value{{
+					checkoutID.String(), holder.ID.String(), item.ID.String(), now.Add(-7 * 24 * time.Hour), now.Add(7 * 24 * time.Hour), int64(0), "active", int64(1),
+				}}},
+				scriptRule{match: "FROM holds"},
+				scriptRule{match: "INSERT INTO events", rows: [][]driver.Value{{int64(2)}}},
+				scriptRule{match: "FROM events", rows: [][]driver.Value{{int64(1)}}},
+				scriptRule{match: "UPDATE checkouts"},
+			)
+			cat := clientmocks.NewCatalog(item)
+			svc := NewService(eventstore.NewEventStore(db), db, cat, clientmocks.NewMembership(holder),
+				WithClock(func() time.Time { return now }))
+
+			renewed, err := svc.RenewCheckout(context.Background(), tt.caller, checkoutID)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, cat.Calls(), "a refused renewal should not reach the catalog")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 1, renewed.RenewalCount)
+		})
+	}
+}
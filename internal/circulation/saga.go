@@ -0,0 +1,265 @@
+// internal/circulation/saga.go
+package circulation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"libranexus/internal/logging"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+	"github.com/lib/pq"
+)
+
+// sagaRecoveryBatchSize bounds how many stalled sagas one recovery pass takes.
+const sagaRecoveryBatchSize = 100
+
+// sagaState is how far a checkout saga has got. Each step is recorded before
+// the next begins, so a saga cut short can be picked up from its last one.
+type sagaState string
+
+const (
+	// sagaPending: the checkout was accepted; a copy may be being reserved.
+	sagaPending sagaState = "pending"
+	// sagaItemReserved: a copy is out of the pool for this checkout.
+	sagaItemReserved sagaState = "item_reserved"
+	// sagaEventAppended: the checkout is recorded. Set in the same
+	// transaction as the ItemCheckedOut event, so the two never disagree.
+	sagaEventAppended sagaState = "event_appended"
+	sagaCompleted     sagaState = "completed"
+	// sagaCompensating: the checkout failed and its copy is being put back.
+	sagaCompensating sagaState = "compensating"
+	sagaCompensated  sagaState = "compensated"
+)
+
+// errSagaTakenOver is returned when a saga's state changed under a step,
+// meaning the recovery worker has already taken it over.
+var errSagaTakenOver = errors.New("checkout saga was taken over by recovery")
+
+// checkoutSaga is the persisted progress of one checkout attempt.
+type checkoutSaga struct {
+	id       uuid.UUID // the checkout's ID
+	memberID uuid.UUID
+	itemID   uuid.UUID
+	// holdID is set when the copy comes from the member's fulfilled hold,
+	// in which case the saga reserves nothing and has nothing to release.
+	holdID *uuid.UUID
+	state  sagaState
+}
+
+type sagaExecer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// startSaga records a checkout attempt as pending.
+func (s *service) startSaga(ctx context.Context, saga *checkoutSaga) error {
+	query := `
+		INSERT INTO saga_instances (id, member_id, item_id, hold_id, state)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	if _, err := s.db.ExecContext(ctx, query, saga.id, saga.memberID, saga.itemID, saga.holdID, sagaPending); err != nil {
+		return fmt.Errorf("failed to start checkout saga: %w", err)
+	}
+	saga.state = sagaPending
+	return nil
+}
+
+// advanceSaga moves a saga to state through q, provided it is still in one
+// of the from states, recording cause, if any, as why. It reports whether
+// the saga moved.
+func advanceSaga(ctx context.Context, q sagaExecer, saga *checkoutSaga, state sagaState, cause error, from ...sagaState) (bool, error) {
+	var reason sql.NullString
+	if cause != nil {
+		reason = sql.NullString{String: cause.Error(), Valid: true}
+	}
+	states := make([]string, len(from))
+	for i, f := range from {
+		states[i] = string(f)
+	}
+	query := `
+		UPDATE saga_instances
+		SET state = $2, error = COALESCE($3, error), updated_at = NOW()
+		WHERE id = $1 AND state = ANY($4)
+	`
+	res, err := q.ExecContext(ctx, query, saga.id, state, reason, pq.Array(states))
+	if err != nil {
+		return false, fmt.Errorf("failed to move checkout saga to %s: %w", state, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	saga.state = state
+	return true, nil
+}
+
+// stepSaga advances a saga whose step has already happened. Failing to
+// record the step is only logged: recovery works out what happened from the
+// state before it.
+func (s *service) stepSaga(ctx context.Context, saga *checkoutSaga, state sagaState, from ...sagaState) {
+	if _, err := advanceSaga(ctx, s.db, saga, state, nil, from...); err != nil {
+		logging.FromContext(ctx).Error("failed to record checkout saga step", "checkout_id", saga.id, "state", state, "err", err)
+	}
+}
+
+// compensateSaga undoes a checkout that cannot complete. The copy it
+// reserved, if any, goes back to the available pool and the outcome is
+// recorded on the checkout's stream. A copy that cannot be released leaves
+// the saga compensating, for the recovery worker to try again.
+func (s *service) compensateSaga(ctx context.Context, saga *checkoutSaga, cause error) {
+	logger := logging.FromContext(ctx).With("checkout_id", saga.id, "item_id", saga.itemID)
+	retrying := saga.state == sagaCompensating
+	if !retrying {
+		moved, err := advanceSaga(ctx, s.db, saga, sagaCompensating, cause, sagaPending, sagaItemReserved)
+		if err != nil {
+			// Put the copy back regardless; recovery will find the saga
+			// where it was and find nothing left to release.
+			logger.Error("failed to record checkout saga compensation", "err", err)
+		} else if !moved {
+			logger.Info("checkout saga already taken over by recovery")
+			return
+		}
+	}
+
+	if saga.holdID == nil {
+		logger.Warn("compensating for failed checkout: releasing reserved copy", "err", cause)
+		if err := s.releaseCopy(ctx, saga.itemID); err != nil {
+			logger.Error("failed to compensate item availability", "err", err)
+			if !retrying {
+				s.recordCompensation(ctx, saga.id, "CompensationFailed", CompensationFailedEvent{
+					CheckoutID: saga.id,
+					MemberID:   saga.memberID,
+					ItemID:     saga.itemID,
+					Reason:     cause.Error(),
+					Error:      err.Error(),
+				})
+			}
+			return
+		}
+		s.recordCompensation(ctx, saga.id, "CheckoutCompensated", CheckoutCompensatedEvent{
+			CheckoutID: saga.id,
+			MemberID:   saga.memberID,
+			ItemID:     saga.itemID,
+			Reason:     cause.Error(),
+		})
+	}
+	s.stepSaga(ctx, saga, sagaCompensated, sagaCompensating, sagaPending, sagaItemReserved)
+}
+
+// RecoverSagas finishes or undoes checkout sagas that have made no progress
+// for longer than grace, as when the service stopped mid-checkout. A saga
+// whose checkout was recorded is completed; any other is compensated,
+// releasing the copy it reserved. Whether a pending saga got as far as
+// reserving one is read from the catalog's events, which carry the
+// checkout as their cause. Grace should comfortably exceed how long a
+// checkout takes, so sagas still in flight are left alone. It returns how
+// many sagas it finished.
+func (s *service) RecoverSagas(ctx context.Context, grace time.Duration) (int, error) {
+	ctx = eventstore.WithActorID(ctx, eventstore.SystemActorID)
+	query := `
+		SELECT id, member_id, item_id, hold_id, state, COALESCE(error, '')
+		FROM saga_instances
+		WHERE state NOT IN ('completed', 'compensated') AND updated_at < NOW() - $1::interval
+		ORDER BY updated_at
+		LIMIT $2
+	`
+	// updated_at is set by the database's clock, so the grace period is
+	// measured by it too; the service's clock may be skewed from it.
+	rows, err := s.db.QueryContext(ctx, query, sqlInterval(grace), sagaRecoveryBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find stalled checkout sagas: %w", err)
+	}
+	type stalledSaga struct {
+		checkoutSaga
+		reason string
+	}
+	var stalled []stalledSaga
+	for rows.Next() {
+		var saga stalledSaga
+		var holdID uuid.NullUUID
+		if err := rows.Scan(&saga.id, &saga.memberID, &saga.itemID, &holdID, &saga.state, &saga.reason); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan checkout saga: %w", err)
+		}
+		if holdID.Valid {
+			saga.holdID = &holdID.UUID
+		}
+		stalled = append(stalled, saga)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to find stalled checkout sagas: %w", err)
+	}
+
+	recovered := 0
+	for i := range stalled {
+		saga := &stalled[i].checkoutSaga
+		sagaCtx := eventstore.WithCausationID(ctx, saga.id.String())
+		logger := logging.FromContext(ctx).With("checkout_id", saga.id, "item_id", saga.itemID, "state", saga.state)
+
+		reserved := false
+		if saga.state == sagaPending && saga.holdID == nil {
+			if reserved, err = s.copyReservedFor(ctx, saga); err != nil {
+				logger.Warn("failed to check checkout saga's reservation", "err", err)
+				continue
+			}
+		}
+
+		switch recoveryStep(saga, reserved) {
+		case sagaCompleted:
+			s.stepSaga(sagaCtx, saga, sagaCompleted, sagaEventAppended)
+		case sagaCompensated:
+			s.stepSaga(sagaCtx, saga, sagaCompensated, sagaPending)
+		case sagaCompensating:
+			reason := stalled[i].reason
+			if reason == "" {
+				reason = "checkout interrupted"
+			}
+			s.compensateSaga(sagaCtx, saga, errors.New(reason))
+		}
+		if saga.state == sagaCompleted || saga.state == sagaCompensated {
+			logger.Warn("recovered checkout saga", "outcome", saga.state)
+			recovered++
+		}
+	}
+	return recovered, nil
+}
+
+// recoveryStep decides where a stalled saga goes: on to completion if its
+// checkout was recorded, straight to compensated if it never took a copy,
+// and otherwise through compensation to put its copy back.
+func recoveryStep(saga *checkoutSaga, copyReserved bool) sagaState {
+	switch saga.state {
+	case sagaEventAppended:
+		return sagaCompleted
+	case sagaPending:
+		if saga.holdID != nil || !copyReserved {
+			return sagaCompensated
+		}
+	}
+	return sagaCompensating
+}
+
+// copyReservedFor reports whether the catalog recorded reserving a copy for
+// the saga's checkout. The event may since have been archived, so both
+// tables are searched, as the event store does when loading a stream.
+func (s *service) copyReservedFor(ctx context.Context, saga *checkoutSaga) (bool, error) {
+	var reserved bool
+	err := s.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM events
+			WHERE aggregate_id = $1 AND event_type = 'ItemCopyReserved' AND metadata->>$2 = $3
+			UNION ALL
+			SELECT 1 FROM events_archive
+			WHERE aggregate_id = $1 AND event_type = 'ItemCopyReserved' AND metadata->>$2 = $3
+		)
+	`, saga.itemID, eventstore.MetadataCausationID, saga.id.String()).Scan(&reserved)
+	return reserved, err
+}
+
+// sqlInterval renders d as a Postgres interval.
+func sqlInterval(d time.Duration) string {
+	return fmt.Sprintf("%d microseconds", d.Microseconds())
+}
@@ -0,0 +1,63 @@
+package circulation
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoveryStep(t *testing.T) {
+	holdID := uuid.New()
+	tests := []struct {
+		name         string
+		state        sagaState
+		fromHold     bool
+		copyReserved bool
+		want         sagaState
+	}{
+		{"checkout recorded", sagaEventAppended, false, false, sagaCompleted},
+		{"checkout recorded from a hold", sagaEventAppended, true, false, sagaCompleted},
+		{"stopped before reserving", sagaPending, false, false, sagaCompensated},
+		{"stopped after reserving, before recording it", sagaPending, false, true, sagaCompensating},
+		{"stopped before using a hold", sagaPending, true, false, sagaCompensated},
+		{"copy reserved", sagaItemReserved, false, true, sagaCompensating},
+		{"copy from a hold", sagaItemReserved, true, false, sagaCompensating},
+		{"compensation unfinished", sagaCompensating, false, true, sagaCompensating},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saga := &checkoutSaga{state: tt.state}
+			if tt.fromHold {
+				saga.holdID = &holdID
+			}
+			assert.Equal(t, tt.want, recoveryStep(saga, tt.copyReserved))
+		})
+	}
+}
+
+func TestRecoverSagasMeasuresGraceByDatabaseClock(t *testing.T) {
+	db, script := openScriptedDB(t, scriptRule{match: "FROM saga_instances"})
+	// A service clock a year out must not move the cutoff.
+	s := &service{db: db, now: func() time.Time { return time.Now().AddDate(1, 0, 0) }}
+
+	recovered, err := s.RecoverSagas(context.Background(), 5*time.Minute)
+	require.NoError(t, err)
+	assert.Zero(t, recovered)
+	assert.Equal(t, "300000000 microseconds", script.argsOf("FROM saga_instances")[0])
+}
+
+func TestCopyReservedForReadsArchivedEvents(t *testing.T) {
+	// Only the archive answers, so the reservation is found there or not at all.
+	db, _ := openScriptedDB(t, scriptRule{match: "FROM events_archive", rows: [][]driver.Value{{true}}})
+	s := &service{db: db}
+
+	reserved, err := s.copyReservedFor(context.Background(), &checkoutSaga{id: uuid.New(), itemID: uuid.New()})
+	require.NoError(t, err)
+	assert.True(t, reserved)
+}
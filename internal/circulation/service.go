@@ -3,6 +3,7 @@ package circulation
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -10,5 +11,17 @@ import (
 // Service defines the interface for the circulation service.
 type Service interface {
 	CheckoutItem(ctx context.Context, memberID, itemID uuid.UUID) (*Checkout, error)
+	RecoverSagas(ctx context.Context, grace time.Duration) (int, error)
 	ReturnItem(ctx context.Context, memberID, itemID uuid.UUID) error
+	ReturnCheckout(ctx context.Context, caller Caller, checkoutID uuid.UUID) error
+	RenewCheckout(ctx context.Context, caller Caller, checkoutID uuid.UUID) (*Checkout, error)
+	ReconstituteCheckout(ctx context.Context, id uuid.UUID) (*Checkout, error)
+	ListCheckouts(ctx context.Context, memberID uuid.UUID, filter CheckoutFilter) ([]*Checkout, error)
+	GetCheckoutDetails(ctx context.Context, id uuid.UUID) (*CheckoutView, error)
+	ExportCheckouts(ctx context.Context, since time.Time, fn func(*Checkout) error) error
+	PlaceHold(ctx context.Context, memberID, itemID uuid.UUID) (*Hold, error)
+	ListHolds(ctx context.Context, memberID uuid.UUID) ([]*Hold, error)
+	ReleaseUncollectedHolds(ctx context.Context) (int, error)
+	AccrueFines(ctx context.Context) (int, error)
+	ReconcileAvailability(ctx context.Context, grace time.Duration) (int, error)
 }
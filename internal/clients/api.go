@@ -0,0 +1,72 @@
+// internal/clients/api.go
+package clients
+
+import (
+	"context"
+	"fmt"
+	"libranexus/internal/catalog"
+	"libranexus/internal/membership"
+	"os"
+
+	"github.com/google/uuid"
+)
+
+// CatalogAPI is what other services call on the catalog service. Both the
+// JSON client, CatalogClient, and the gRPC one, GRPCCatalogClient, provide
+// it.
+type CatalogAPI interface {
+	Ping(ctx context.Context) error
+	GetItem(ctx context.Context, id uuid.UUID) (*catalog.Item, error)
+	UpdateItemCopies(ctx context.Context, id uuid.UUID, newTotal, newAvailable, expectedVersion int) error
+	ReserveCopy(ctx context.Context, id uuid.UUID) error
+	ReleaseCopy(ctx context.Context, id uuid.UUID) error
+}
+
+// MembershipAPI is what other services call on the membership service.
+// Both the JSON client, MembershipClient, and the gRPC one,
+// GRPCMembershipClient, provide it.
+type MembershipAPI interface {
+	Ping(ctx context.Context) error
+	GetMember(ctx context.Context, id uuid.UUID) (*membership.Member, error)
+	GetMemberByEmail(ctx context.Context, email string) (*membership.Member, error)
+	ChargeFine(ctx context.Context, id uuid.UUID, amount float64, reason, reference string) (*membership.Member, error)
+}
+
+// CatalogClientFromEnv returns the catalog client CLIENT_TRANSPORT selects:
+// the JSON API at baseURL unless it is "grpc", in which case reads go to the
+// gRPC API at CATALOG_GRPC_ADDR.
+func CatalogClientFromEnv(baseURL string, opts ...ClientOption) (CatalogAPI, error) {
+	grpcAddr, err := grpcAddrFromEnv("CATALOG_GRPC_ADDR")
+	if err != nil || grpcAddr == "" {
+		return NewCatalogClient(baseURL, opts...), err
+	}
+	return NewGRPCCatalogClient(grpcAddr, baseURL, opts...)
+}
+
+// MembershipClientFromEnv returns the membership client CLIENT_TRANSPORT
+// selects: the JSON API at baseURL unless it is "grpc", in which case reads
+// go to the gRPC API at MEMBERSHIP_GRPC_ADDR.
+func MembershipClientFromEnv(baseURL string, opts ...ClientOption) (MembershipAPI, error) {
+	grpcAddr, err := grpcAddrFromEnv("MEMBERSHIP_GRPC_ADDR")
+	if err != nil || grpcAddr == "" {
+		return NewMembershipClient(baseURL, opts...), err
+	}
+	return NewGRPCMembershipClient(grpcAddr, baseURL, opts...)
+}
+
+// grpcAddrFromEnv returns the gRPC address in addrVar when CLIENT_TRANSPORT
+// selects gRPC, or "" for the JSON API.
+func grpcAddrFromEnv(addrVar string) (string, error) {
+	switch transport := os.Getenv("CLIENT_TRANSPORT"); transport {
+	case "", "http":
+		return "", nil
+	case "grpc":
+		addr := os.Getenv(addrVar)
+		if addr == "" {
+			return "", fmt.Errorf("CLIENT_TRANSPORT=grpc needs %s", addrVar)
+		}
+		return addr, nil
+	default:
+		return "", fmt.Errorf("unknown CLIENT_TRANSPORT %q", transport)
+	}
+}
@@ -0,0 +1,100 @@
+// internal/clients/breaker.go
+package clients
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrServiceUnavailable is returned without contacting the downstream service
+// while its circuit breaker is open.
+var ErrServiceUnavailable = errors.New("service unavailable: circuit breaker open")
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// circuitBreaker trips open after a run of consecutive failures and rejects
+// calls until the cooldown elapses. It then lets a single probe through
+// (half-open): success closes the breaker, failure re-opens it.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// allow reports whether a call may proceed, returning ErrServiceUnavailable
+// while the breaker is open or a half-open probe is already in flight.
+func (b *circuitBreaker) allow() error {
+	if b.threshold <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return ErrServiceUnavailable
+		}
+		b.state = breakerHalfOpen
+		return nil
+	case breakerHalfOpen:
+		return ErrServiceUnavailable
+	default:
+		return nil
+	}
+}
+
+// record updates the breaker with the outcome of a call that allow admitted.
+func (b *circuitBreaker) record(success bool) {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if success {
+		b.state = breakerClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.state = breakerOpen
+		b.openedAt = b.now()
+		b.failures = 0
+	}
+}
+
+// release abandons an admitted call without judging the service. A half-open
+// probe that never completed re-opens the breaker so the next call probes again.
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == breakerHalfOpen {
+		b.state = breakerOpen
+	}
+}
@@ -0,0 +1,121 @@
+package clients
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := newCircuitBreaker(3, time.Minute)
+	b.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, b.allow())
+		b.record(false)
+	}
+	require.NoError(t, b.allow(), "breaker should stay closed below the threshold")
+	b.record(false)
+
+	assert.ErrorIs(t, b.allow(), ErrServiceUnavailable, "breaker should open at the threshold")
+
+	now = now.Add(time.Minute)
+	require.NoError(t, b.allow(), "breaker should admit a probe after the cooldown")
+	assert.ErrorIs(t, b.allow(), ErrServiceUnavailable, "only one probe at a time")
+	b.record(false)
+	assert.ErrorIs(t, b.allow(), ErrServiceUnavailable, "failed probe should re-open the breaker")
+
+	now = now.Add(time.Minute)
+	require.NoError(t, b.allow())
+	b.record(true)
+	assert.NoError(t, b.allow(), "successful probe should close the breaker")
+}
+
+func TestCircuitBreakerSuccessResetsFailures(t *testing.T) {
+	b := newCircuitBreaker(2, time.Minute)
+
+	b.record(false)
+	b.record(true)
+	b.record(false)
+	assert.NoError(t, b.allow())
+}
+
+func TestCatalogClientOpensBreakerOnServerErrors(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := NewCatalogClient(server.URL, WithRetries(0), WithFailureThreshold(2), WithCooldown(time.Hour))
+	for i := 0; i < 2; i++ {
+		_, err := client.GetItem(context.Background(), uuid.New())
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrServiceUnavailable)
+	}
+
+	_, err := client.GetItem(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, ErrServiceUnavailable)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "open breaker should not reach the server")
+}
+
+func TestCatalogClientTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client := NewCatalogClient(server.URL, WithRetries(0), WithTimeout(20*time.Millisecond))
+	start := time.Now()
+	_, err := client.GetItem(context.Background(), uuid.New())
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestCatalogClientCallDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	t.Run("applies when the caller sets no deadline", func(t *testing.T) {
+		client := NewCatalogClient(server.URL, WithTimeout(0), WithCallDeadline(50*time.Millisecond))
+		start := time.Now()
+		_, err := client.GetItem(context.Background(), uuid.New())
+		require.Error(t, err)
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+	})
+
+	t.Run("counts against the breaker", func(t *testing.T) {
+		client := NewCatalogClient(server.URL, WithRetries(0), WithTimeout(0), WithCallDeadline(20*time.Millisecond), WithFailureThreshold(1))
+		_, err := client.GetItem(context.Background(), uuid.New())
+		require.Error(t, err)
+		_, err = client.GetItem(context.Background(), uuid.New())
+		assert.ErrorIs(t, err, ErrServiceUnavailable)
+	})
+
+	t.Run("defers to the caller's deadline", func(t *testing.T) {
+		client := NewCatalogClient(server.URL, WithTimeout(0), WithCallDeadline(20*time.Millisecond))
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err := client.GetItem(ctx, uuid.New())
+		require.Error(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+	})
+}
@@ -2,9 +2,9 @@
 package clients
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"libranexus/internal/catalog"
 	"net/http"
@@ -13,44 +13,50 @@ import (
 )
 
 type CatalogClient struct {
-	baseURL string
+	baseURL   string
+	transport *transport
 }
 
-func NewCatalogClient(baseURL string) *CatalogClient {
-	return &CatalogClient{baseURL: baseURL}
+func NewCatalogClient(baseURL string, opts ...ClientOption) *CatalogClient {
+	return &CatalogClient{baseURL: baseURL, transport: newTransport(opts...)}
 }
 
-func (c *CatalogClient) GetItem(ctx context.Context, id uuid.UUID) (*catalog.Item, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/items/%s", c.baseURL, id), nil)
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+// Ping reports whether the catalog service is reachable.
+func (c *CatalogClient) Ping(ctx context.Context) error {
+	return c.transport.ping(ctx, c.baseURL)
+}
 
+// GetItem fetches an item, including retired ones: the circulation saga must
+// see an item's real status to refuse it, and still settle loans of items
+// retired while out.
+func (c *CatalogClient) GetItem(ctx context.Context, id uuid.UUID) (*catalog.Item, error) {
 	var item catalog.Item
-	if err := json.NewDecoder(resp.Body).Decode(&item); err != nil {
+	err := c.transport.doWithRetry(ctx, http.MethodGet, fmt.Sprintf("%s/items/%s?include_retired=true", c.baseURL, id), nil, func(resp *http.Response) error {
+		if resp.StatusCode != http.StatusOK {
+			return newStatusError(resp)
+		}
+		return json.NewDecoder(resp.Body).Decode(&item)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return &item, nil
 }
 
-func (c *CatalogClient) UpdateItemCopies(ctx context.Context, id uuid.UUID, newTotal, newAvailable int) error {
+// UpdateItemCopies sets an item's copy counts. With a positive expectedVersion
+// the update only applies to that version of the item, which makes it safe to
+// retry: a repeat of an update that already landed is recognised rather than
+// applied twice. Without one the update is sent exactly once.
+func (c *CatalogClient) UpdateItemCopies(ctx context.Context, id uuid.UUID, newTotal, newAvailable, expectedVersion int) error {
 	updateReq := struct {
-		TotalCopies int `json:"total_copies"`
-		Available   int `json:"available"`
+		TotalCopies     int `json:"total_copies"`
+		Available       int `json:"available"`
+		ExpectedVersion int `json:"expected_version,omitempty"`
 	}{
-		TotalCopies: newTotal,
-		Available:   newAvailable,
+		TotalCopies:     newTotal,
+		Available:       newAvailable,
+		ExpectedVersion: expectedVersion,
 	}
 
 	body, err := json.Marshal(updateReq)
@@ -58,31 +64,58 @@ func (c *CatalogClient) UpdateItemCopies(ctx context.Context, id uuid.UUID, newT
 		return err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, fmt.Sprintf("%s/items/%s", c.baseURL, id), bytes.NewBuffer(body))
-	if err != nil {
-		return err
+	url := fmt.Sprintf("%s/items/%s", c.baseURL, id)
+	if expectedVersion <= 0 {
+		return c.transport.do(ctx, http.MethodPatch, url, body, expectStatus(http.StatusOK))
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return err
+	err = c.transport.doWithRetry(ctx, http.MethodPatch, url, body, expectStatus(http.StatusOK))
+	var se *statusError
+	if errors.As(err, &se) && se.code == http.StatusConflict {
+		// An earlier attempt may have landed before its response was lost.
+		item, getErr := c.GetItem(ctx, id)
+		if getErr == nil && item.Version == expectedVersion+1 && item.TotalCopies == newTotal && item.Available == newAvailable {
+			return nil
+		}
+		return fmt.Errorf("%w: %v", catalog.ErrVersionConflict, err)
 	}
-	defer resp.Body.Close()
+	return err
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+// ReserveCopy takes one copy of an item out of the available pool, returning
+// catalog.ErrNoCopiesAvailable if none are left. Other conflicts, such as
+// the catalog running out of retries against concurrent writers, are
+// returned as they are. The reservation is not idempotent, so it is never
+// retried.
+func (c *CatalogClient) ReserveCopy(ctx context.Context, id uuid.UUID) error {
+	err := c.transport.do(ctx, http.MethodPost, fmt.Sprintf("%s/items/%s/reserve-copy", c.baseURL, id), nil, expectStatus(http.StatusNoContent))
+	if isConflict(err, "no_copies_available") {
+		return fmt.Errorf("%w: %v", catalog.ErrNoCopiesAvailable, err)
 	}
+	return err
+}
 
-	return nil
+// ReleaseCopy puts one copy of an item back into the available pool,
+// returning catalog.ErrAllCopiesAvailable if none is out. Like ReserveCopy
+// it passes other conflicts through and is never retried.
+func (c *CatalogClient) ReleaseCopy(ctx context.Context, id uuid.UUID) error {
+	err := c.transport.do(ctx, http.MethodPost, fmt.Sprintf("%s/items/%s/release-copy", c.baseURL, id), nil, expectStatus(http.StatusNoContent))
+	var se *statusError
+	switch {
+	case isConflict(err, "all_copies_available"):
+		return fmt.Errorf("%w: %v", catalog.ErrAllCopiesAvailable, err)
+	case errors.As(err, &se) && se.code == http.StatusNotFound:
+		return fmt.Errorf("%w: %v", catalog.ErrItemNotFound, err)
+	}
+	return err
 }
 
-func (c *CatalogClient) Search(ctx context.Context, query string) ([]*catalog.Item, error) {
+func (c *CatalogClient) Search(ctx context.Context, params catalog.SearchParams) (*catalog.SearchResult, error) {
 	// This is a placeholder and will not be used by the circulation service
 	return nil, nil
 }
 
-func (c *CatalogClient) AddItem(ctx context.Context, isbn, title, author string, totalCopies int) (*catalog.Item, error) {
+func (c *CatalogClient) AddItem(ctx context.Context, isbn, title, author, category string, totalCopies int) (*catalog.Item, error) {
 	// This is a placeholder and will not be used by the circulation service
 	return nil, nil
 }
@@ -0,0 +1,216 @@
+// internal/clients/clientmocks/clientmocks.go
+
+// Package clientmocks provides in-memory stand-ins for the catalog and
+// membership clients, so code calling those services can be tested without
+// running them.
+package clientmocks
+
+import (
+	"context"
+	"fmt"
+	"libranexus/internal/catalog"
+	"libranexus/internal/clients"
+	"libranexus/internal/membership"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+var (
+	_ clients.CatalogAPI    = (*Catalog)(nil)
+	_ clients.MembershipAPI = (*Membership)(nil)
+)
+
+// Catalog is a clients.CatalogAPI over items held in memory. It follows the
+// catalog's rules for the calls it answers: reservations fail once no copies
+// are left, releases once every copy is back, and versioned updates fail on
+// a version mismatch. The Err fields
+// make the matching calls fail instead.
+type Catalog struct {
+	GetItemErr          error
+	ReserveCopyErr      error
+	ReleaseCopyErr      error
+	UpdateItemCopiesErr error
+
+	mu    sync.Mutex
+	items map[uuid.UUID]catalog.Item
+	calls []string
+}
+
+// NewCatalog returns a catalog holding items.
+func NewCatalog(items ...catalog.Item) *Catalog {
+	c := &Catalog{items: make(map[uuid.UUID]catalog.Item)}
+	for _, item := range items {
+		c.items[item.ID] = item
+	}
+	return c
+}
+
+// Item returns the item as the catalog now holds it.
+func (c *Catalog) Item(id uuid.UUID) (catalog.Item, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	item, ok := c.items[id]
+	return item, ok
+}
+
+// Calls returns the names of the methods called so far, in order.
+func (c *Catalog) Calls() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.calls...)
+}
+
+func (c *Catalog) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (c *Catalog) GetItem(ctx context.Context, id uuid.UUID) (*catalog.Item, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, "GetItem")
+	if c.GetItemErr != nil {
+		return nil, c.GetItemErr
+	}
+	item, ok := c.items[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", catalog.ErrItemNotFound, id)
+	}
+	return &item, nil
+}
+
+func (c *Catalog) UpdateItemCopies(ctx context.Context, id uuid.UUID, newTotal, newAvailable, expectedVersion int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, "UpdateItemCopies")
+	if c.UpdateItemCopiesErr != nil {
+		return c.UpdateItemCopiesErr
+	}
+	item, ok := c.items[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", catalog.ErrItemNotFound, id)
+	}
+	if expectedVersion > 0 && item.Version != expectedVersion {
+		return catalog.ErrVersionConflict
+	}
+	item.TotalCopies, item.Available = newTotal, newAvailable
+	item.Version++
+	c.items[id] = item
+	return nil
+}
+
+func (c *Catalog) ReserveCopy(ctx context.Context, id uuid.UUID) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, "ReserveCopy")
+	if c.ReserveCopyErr != nil {
+		return c.ReserveCopyErr
+	}
+	item, ok := c.items[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", catalog.ErrItemNotFound, id)
+	}
+	if item.Available <= 0 {
+		return catalog.ErrNoCopiesAvailable
+	}
+	item.Available--
+	item.Version++
+	c.items[id] = item
+	return nil
+}
+
+func (c *Catalog) ReleaseCopy(ctx context.Context, id uuid.UUID) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, "ReleaseCopy")
+	if c.ReleaseCopyErr != nil {
+		return c.ReleaseCopyErr
+	}
+	item, ok := c.items[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", catalog.ErrItemNotFound, id)
+	}
+	if item.Available >= item.TotalCopies {
+		return catalog.ErrAllCopiesAvailable
+	}
+	item.Available++
+	item.Version++
+	c.items[id] = item
+	return nil
+}
+
+// Membership is a clients.MembershipAPI over members held in memory. Fines
+// charged are added to the member's balance. The Err fields make the
+// matching calls fail instead.
+type Membership struct {
+	GetMemberErr  error
+	ChargeFineErr error
+
+	mu      sync.Mutex
+	members map[uuid.UUID]membership.Member
+}
+
+// NewMembership returns a membership service holding members.
+func NewMembership(members ...membership.Member) *Membership {
+	m := &Membership{members: make(map[uuid.UUID]membership.Member)}
+	for _, member := range members {
+		m.members[member.ID] = member
+	}
+	return m
+}
+
+// Member returns the member as the service now holds them.
+func (m *Membership) Member(id uuid.UUID) (membership.Member, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	member, ok := m.members[id]
+	return member, ok
+}
+
+func (m *Membership) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (m *Membership) GetMember(ctx context.Context, id uuid.UUID) (*membership.Member, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.GetMemberErr != nil {
+		return nil, m.GetMemberErr
+	}
+	member, ok := m.members[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", membership.ErrMemberNotFound, id)
+	}
+	return &member, nil
+}
+
+func (m *Membership) GetMemberByEmail(ctx context.Context, email string) (*membership.Member, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.GetMemberErr != nil {
+		return nil, m.GetMemberErr
+	}
+	for _, member := range m.members {
+		if strings.EqualFold(member.Email, email) {
+			return &member, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", membership.ErrMemberNotFound, email)
+}
+
+func (m *Membership) ChargeFine(ctx context.Context, id uuid.UUID, amount float64, reason, reference string) (*membership.Member, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.ChargeFineErr != nil {
+		return nil, m.ChargeFineErr
+	}
+	member, ok := m.members[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", membership.ErrMemberNotFound, id)
+	}
+	member.FineBalance += amount
+	member.Version++
+	m.members[id] = member
+	return &member, nil
+}
@@ -0,0 +1,205 @@
+// internal/clients/grpc.go
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"libranexus/internal/catalog"
+	"libranexus/internal/membership"
+	"libranexus/internal/rpc"
+	"libranexus/internal/rpc/catalogpb"
+	"libranexus/internal/rpc/membershippb"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCCatalogClient reads items over the catalog's gRPC API. Writes, and
+// pings, still go through the JSON API at the client's base URL. Both share
+// one circuit breaker, as they reach the same service.
+type GRPCCatalogClient struct {
+	*CatalogClient
+	rpc catalogpb.CatalogClient
+}
+
+// NewGRPCCatalogClient returns a client reading from the catalog's gRPC API
+// at addr and writing through its JSON API at baseURL.
+func NewGRPCCatalogClient(addr, baseURL string, opts ...ClientOption) (*GRPCCatalogClient, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	return newGRPCCatalogClient(conn, baseURL, opts...), nil
+}
+
+func newGRPCCatalogClient(conn grpc.ClientConnInterface, baseURL string, opts ...ClientOption) *GRPCCatalogClient {
+	return &GRPCCatalogClient{CatalogClient: NewCatalogClient(baseURL, opts...), rpc: catalogpb.NewCatalogClient(conn)}
+}
+
+// GetItem fetches an item, including retired ones, as CatalogClient.GetItem
+// does.
+func (c *GRPCCatalogClient) GetItem(ctx context.Context, id uuid.UUID) (*catalog.Item, error) {
+	var item *catalogpb.Item
+	err := c.transport.callWithRetry(ctx, func(ctx context.Context) error {
+		var err error
+		item, err = c.rpc.GetItem(ctx, &catalogpb.GetItemRequest{Id: id.String(), IncludeRetired: true})
+		return err
+	})
+	if status.Code(err) == codes.NotFound {
+		return nil, fmt.Errorf("%w: %v", catalog.ErrItemNotFound, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return itemFromProto(item)
+}
+
+// GRPCMembershipClient reads members over membership's gRPC API. Writes,
+// and pings, still go through the JSON API at the client's base URL.
+type GRPCMembershipClient struct {
+	*MembershipClient
+	rpc membershippb.MembershipClient
+}
+
+// NewGRPCMembershipClient returns a client reading from membership's gRPC
+// API at addr and writing through its JSON API at baseURL.
+func NewGRPCMembershipClient(addr, baseURL string, opts ...ClientOption) (*GRPCMembershipClient, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	return newGRPCMembershipClient(conn, baseURL, opts...), nil
+}
+
+func newGRPCMembershipClient(conn grpc.ClientConnInterface, baseURL string, opts ...ClientOption) *GRPCMembershipClient {
+	return &GRPCMembershipClient{MembershipClient: NewMembershipClient(baseURL, opts...), rpc: membershippb.NewMembershipClient(conn)}
+}
+
+func (c *GRPCMembershipClient) GetMember(ctx context.Context, id uuid.UUID) (*membership.Member, error) {
+	return c.getMember(ctx, func(ctx context.Context) (*membershippb.Member, error) {
+		return c.rpc.GetMember(ctx, &membershippb.GetMemberRequest{Id: id.String()})
+	})
+}
+
+// GetMemberByEmail looks a member up by email address, ignoring case.
+func (c *GRPCMembershipClient) GetMemberByEmail(ctx context.Context, email string) (*membership.Member, error) {
+	return c.getMember(ctx, func(ctx context.Context) (*membershippb.Member, error) {
+		return c.rpc.GetMemberByEmail(ctx, &membershippb.GetMemberByEmailRequest{Email: email})
+	})
+}
+
+func (c *GRPCMembershipClient) getMember(ctx context.Context, get func(context.Context) (*membershippb.Member, error)) (*membership.Member, error) {
+	var member *membershippb.Member
+	err := c.transport.callWithRetry(ctx, func(ctx context.Context) error {
+		var err error
+		member, err = get(ctx)
+		return err
+	})
+	if status.Code(err) == codes.NotFound {
+		return nil, fmt.Errorf("%w: %v", membership.ErrMemberNotFound, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return memberFromProto(member)
+}
+
+// call makes one gRPC call through the breaker, bounded by the client's
+// timeouts as do bounds a request, and presenting the service token as do
+// does. Codes that say the service is unwell count as breaker failures; any
+// other answer means it is healthy.
+func (t *transport) call(ctx context.Context, invoke func(context.Context) error) error {
+	if err := t.breaker.allow(); err != nil {
+		return err
+	}
+	if t.serviceToken != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, rpc.ServiceTokenMetadata, t.serviceToken, rpc.ServiceNameMetadata, t.serviceName)
+	}
+
+	ctx, cancelCall := t.withCallDeadline(ctx)
+	defer cancelCall()
+	parent := ctx
+	if t.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+		defer cancel()
+	}
+
+	err := invoke(ctx)
+	if err != nil && parent.Err() != nil && context.Cause(parent) != errCallDeadline {
+		t.breaker.release()
+		return err
+	}
+	t.breaker.record(!retryableCode(err))
+	return err
+}
+
+// callWithRetry makes an idempotent gRPC call, retrying as doWithRetry does.
+func (t *transport) callWithRetry(ctx context.Context, invoke func(context.Context) error) error {
+	return t.retry(ctx, retryableCode, func(ctx context.Context) error {
+		return t.call(ctx, invoke)
+	})
+}
+
+// retryableCode reports whether a failed gRPC call can safely be made again,
+// on the same terms as retryable: the service failing or being unreachable
+// is, anything it answered deliberately is not.
+func retryableCode(err error) bool {
+	if errors.Is(err, ErrServiceUnavailable) {
+		return false
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Internal, codes.Unknown, codes.DeadlineExceeded, codes.DataLoss:
+		return true
+	}
+	return false
+}
+
+func itemFromProto(p *catalogpb.Item) (*catalog.Item, error) {
+	id, err := uuid.Parse(p.GetId())
+	if err != nil {
+		return nil, fmt.Errorf("invalid item ID %q: %w", p.GetId(), err)
+	}
+	return &catalog.Item{
+		ID:            id,
+		ISBN:          p.GetIsbn(),
+		Title:         p.GetTitle(),
+		Author:        p.GetAuthor(),
+		Publisher:     p.GetPublisher(),
+		PublishedYear: int(p.GetPublishedYear()),
+		Category:      p.GetCategory(),
+		TotalCopies:   int(p.GetTotalCopies()),
+		Available:     int(p.GetAvailable()),
+		Status:        p.GetStatus(),
+		Version:       int(p.GetVersion()),
+		CreatedAt:     p.GetCreatedAt().AsTime(),
+		UpdatedAt:     p.GetUpdatedAt().AsTime(),
+	}, nil
+}
+
+func memberFromProto(p *membershippb.Member) (*membership.Member, error) {
+	id, err := uuid.Parse(p.GetId())
+	if err != nil {
+		return nil, fmt.Errorf("invalid member ID %q: %w", p.GetId(), err)
+	}
+	return &membership.Member{
+		ID:               id,
+		Email:            p.GetEmail(),
+		Name:             p.GetName(),
+		MembershipTier:   p.GetMembershipTier(),
+		Role:             p.GetRole(),
+		Status:           p.GetStatus(),
+		SuspensionReason: p.GetSuspensionReason(),
+		FineBalance:      p.GetFineBalance(),
+		MaxCheckouts:     int(p.GetMaxCheckouts()),
+		ExpiresAt:        p.GetExpiresAt().AsTime(),
+		CreatedAt:        p.GetCreatedAt().AsTime(),
+		UpdatedAt:        p.GetUpdatedAt().AsTime(),
+		Version:          int(p.GetVersion()),
+	}, nil
+}
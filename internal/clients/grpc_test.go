@@ -0,0 +1,204 @@
+package clients
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"libranexus/internal/catalog"
+	"libranexus/internal/membership"
+	"libranexus/internal/rpc"
+)
+
+// testServiceToken is the token the test servers require of callers.
+const testServiceToken = "test-service-token"
+
+// asService identifies a test client's calls as circulation's.
+var asService = WithServiceToken("circulation", testServiceToken)
+
+// fakeCatalog serves GetItem from a fixed set of items.
+type fakeCatalog struct {
+	catalog.Service
+	items map[uuid.UUID]*catalog.Item
+}
+
+func (f *fakeCatalog) GetItem(ctx context.Context, id uuid.UUID, includeRetired bool) (*catalog.Item, error) {
+	item, ok := f.items[id]
+	if !ok || (item.Status == "retired" && !includeRetired) {
+		return nil, catalog.ErrItemNotFound
+	}
+	return item, nil
+}
+
+// fakeMembership serves member reads from a fixed set of members.
+type fakeMembership struct {
+	membership.Service
+	members map[uuid.UUID]*membership.Member
+}
+
+func (f *fakeMembership) GetMember(ctx context.Context, id uuid.UUID) (*membership.Member, error) {
+	if member, ok := f.members[id]; ok {
+		return member, nil
+	}
+	return nil, membership.ErrMemberNotFound
+}
+
+func (f *fakeMembership) GetMemberByEmail(ctx context.Context, email string) (*membership.Member, error) {
+	for _, member := range f.members {
+		if member.Email == email {
+			return member, nil
+		}
+	}
+	return nil, membership.ErrMemberNotFound
+}
+
+// dialBufconn serves the APIs register adds in memory, to callers presenting
+// testServiceToken, and returns a connection to them.
+func dialBufconn(t testing.TB, register func(grpc.ServiceRegistrar)) *grpc.ClientConn {
+	ln := bufconn.Listen(1 << 20)
+	s := rpc.NewServer(testServiceToken)
+	register(s)
+	go s.Serve(ln)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func testItem() *catalog.Item {
+	created := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	return &catalog.Item{
+		ID: uuid.New(), ISBN: "9780141439518", Title: "Pride and Prejudice", Author: "Jane Austen",
+		Publisher: "Penguin", PublishedYear: 2003, Category: "fiction", TotalCopies: 3, Available: 2,
+		Status: "retired", Version: 4, CreatedAt: created, UpdatedAt: created.Add(time.Hour),
+	}
+}
+
+func TestGRPCCatalogClientGetItem(t *testing.T) {
+	item := testItem()
+	svc := &fakeCatalog{items: map[uuid.UUID]*catalog.Item{item.ID: item}}
+	conn := dialBufconn(t, func(s grpc.ServiceRegistrar) { catalog.RegisterGRPC(s, svc) })
+	client := newGRPCCatalogClient(conn, "http://catalog.invalid", asService)
+
+	got, err := client.GetItem(context.Background(), item.ID)
+	require.NoError(t, err)
+	assert.Equal(t, item, got, "retired items should be fetched, with every field intact")
+
+	_, err = client.GetItem(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, catalog.ErrItemNotFound)
+}
+
+func TestGRPCMembershipClientGetMember(t *testing.T) {
+	joined := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	member := &membership.Member{
+		ID: uuid.New(), Email: "ada@example.com", Name: "Ada Lovelace", MembershipTier: "premium",
+		Role: membership.RoleMember, Status: "suspended", SuspensionReason: "unpaid fines", FineBalance: 12.5,
+		MaxCheckouts: 10, ExpiresAt: joined.AddDate(1, 0, 0), CreatedAt: joined, UpdatedAt: joined, Version: 3,
+	}
+	svc := &fakeMembership{members: map[uuid.UUID]*membership.Member{member.ID: member}}
+	conn := dialBufconn(t, func(s grpc.ServiceRegistrar) { membership.RegisterGRPC(s, svc) })
+	client := newGRPCMembershipClient(conn, "http://membership.invalid", asService)
+
+	got, err := client.GetMember(context.Background(), member.ID)
+	require.NoError(t, err)
+	assert.Equal(t, member, got)
+
+	got, err = client.GetMemberByEmail(context.Background(), "ada@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, member.ID, got.ID)
+
+	_, err = client.GetMember(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, membership.ErrMemberNotFound)
+}
+
+func TestGRPCServerRequiresServiceToken(t *testing.T) {
+	member := &membership.Member{ID: uuid.New(), Email: "ada@example.com"}
+	svc := &fakeMembership{members: map[uuid.UUID]*membership.Member{member.ID: member}}
+	conn := dialBufconn(t, func(s grpc.ServiceRegistrar) { membership.RegisterGRPC(s, svc) })
+
+	for name, opts := range map[string][]ClientOption{
+		"no token":    nil,
+		"wrong token": {WithServiceToken("circulation", "guess")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			client := newGRPCMembershipClient(conn, "http://membership.invalid", opts...)
+			_, err := client.GetMember(context.Background(), member.ID)
+			assert.Equal(t, codes.Unauthenticated, status.Code(err))
+			_, err = client.GetMemberByEmail(context.Background(), member.Email)
+			assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		})
+	}
+}
+
+func TestGRPCClientRetriesUnavailableService(t *testing.T) {
+	ln := bufconn.Listen(1 << 20)
+	ln.Close()
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client := newGRPCCatalogClient(conn, "http://catalog.invalid", WithRetries(1), WithFailureThreshold(2))
+	_, err = client.GetItem(context.Background(), uuid.New())
+	assert.ErrorContains(t, err, "giving up after 2 attempts")
+
+	_, err = client.GetItem(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, ErrServiceUnavailable, "two failed attempts should open the breaker")
+}
+
+// BenchmarkGetItem compares fetching an item over the catalog's JSON API
+// and over its gRPC API, both across loopback TCP.
+func BenchmarkGetItem(b *testing.B) {
+	item := testItem()
+	svc := &fakeCatalog{items: map[uuid.UUID]*catalog.Item{item.ID: item}}
+	ctx := context.Background()
+
+	b.Run("http", func(b *testing.B) {
+		handler := catalog.NewHandler(svc)
+		server := httptest.NewServer(http.HandlerFunc(handler.HandleItem))
+		defer server.Close()
+		client := NewCatalogClient(server.URL)
+
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := client.GetItem(ctx, item.ID); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("grpc", func(b *testing.B) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(b, err)
+		s := rpc.NewServer(testServiceToken)
+		catalog.RegisterGRPC(s, svc)
+		go s.Serve(ln)
+		defer s.Stop()
+		client, err := NewGRPCCatalogClient(ln.Addr().String(), "http://catalog.invalid", asService)
+		require.NoError(b, err)
+
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := client.GetItem(ctx, item.ID); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -7,48 +7,87 @@ import (
 	"fmt"
 	"libranexus/internal/membership"
 	"net/http"
+	"net/url"
 
 	"github.com/google/uuid"
 )
 
 type MembershipClient struct {
-	baseURL string
+	baseURL   string
+	transport *transport
 }
 
-func NewMembershipClient(baseURL string) *MembershipClient {
-	return &MembershipClient{baseURL: baseURL}
+func NewMembershipClient(baseURL string, opts ...ClientOption) *MembershipClient {
+	return &MembershipClient{baseURL: baseURL, transport: newTransport(opts...)}
+}
+
+// Ping reports whether the membership service is reachable.
+func (c *MembershipClient) Ping(ctx context.Context) error {
+	return c.transport.ping(ctx, c.baseURL)
 }
 
 func (c *MembershipClient) GetMember(ctx context.Context, id uuid.UUID) (*membership.Member, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/members/%s", c.baseURL, id), nil)
+	var member membership.Member
+	err := c.transport.doWithRetry(ctx, http.MethodGet, fmt.Sprintf("%s/members/%s", c.baseURL, id), nil, decodeMember(&member))
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	return &member, nil
+}
+
+// GetMemberByEmail looks a member up by email address, ignoring case.
+func (c *MembershipClient) GetMemberByEmail(ctx context.Context, email string) (*membership.Member, error) {
+	var member membership.Member
+	endpoint := fmt.Sprintf("%s/members?email=%s", c.baseURL, url.QueryEscape(email))
+	err := c.transport.doWithRetry(ctx, http.MethodGet, endpoint, nil, decodeMember(&member))
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	return &member, nil
+}
+
+func (c *MembershipClient) ChargeFine(ctx context.Context, id uuid.UUID, amount float64, reason, reference string) (*membership.Member, error) {
+	chargeReq := struct {
+		Amount    float64 `json:"amount"`
+		Reason    string  `json:"reason"`
+		Reference string  `json:"reference"`
+	}{
+		Amount:    amount,
+		Reason:    reason,
+		Reference: reference,
+	}
+
+	body, err := json.Marshal(chargeReq)
+	if err != nil {
+		return nil, err
 	}
 
 	var member membership.Member
-	if err := json.NewDecoder(resp.Body).Decode(&member); err != nil {
+	err = c.transport.do(ctx, http.MethodPost, fmt.Sprintf("%s/members/%s/fines", c.baseURL, id), body, decodeMember(&member))
+	if err != nil {
 		return nil, err
 	}
 
 	return &member, nil
 }
 
+func decodeMember(member *membership.Member) func(*http.Response) error {
+	return func(resp *http.Response) error {
+		if resp.StatusCode != http.StatusOK {
+			return newStatusError(resp)
+		}
+		return json.NewDecoder(resp.Body).Decode(member)
+	}
+}
+
 func (c *MembershipClient) RegisterMember(ctx context.Context, email, name, password string) (*membership.Member, error) {
 	// This is a placeholder and will not be used by the circulation service
 	return nil, nil
 }
 
-func (c *MembershipClient) Authenticate(ctx context.Context, email, password string) (*membership.Member, error) {
+func (c *MembershipClient) Authenticate(ctx context.Context, email, password, mfaCode string) (*membership.Member, error) {
 	// This is a placeholder and will not be used by the circulation service
 	return nil, nil
 }
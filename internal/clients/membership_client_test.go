@@ -0,0 +1,54 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"libranexus/internal/membership"
+)
+
+func TestGetMemberByEmailEscapesAddress(t *testing.T) {
+	id := uuid.New()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/members", r.URL.Path)
+		if r.URL.Query().Get("email") != "ada+lib@example.com" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(membership.Member{ID: id, Email: "ada+lib@example.com"})
+	}))
+	defer server.Close()
+
+	client := NewMembershipClient(server.URL)
+	member, err := client.GetMemberByEmail(context.Background(), "ada+lib@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, id, member.ID)
+
+	_, err = client.GetMemberByEmail(context.Background(), "grace@example.com")
+	assert.Error(t, err)
+}
+
+func TestClientPresentsServiceToken(t *testing.T) {
+	var token, name string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, name = r.Header.Get(membership.ServiceTokenHeader), r.Header.Get(membership.ServiceNameHeader)
+		json.NewEncoder(w).Encode(membership.Member{})
+	}))
+	defer server.Close()
+
+	_, err := NewMembershipClient(server.URL).GetMember(context.Background(), uuid.New())
+	require.NoError(t, err)
+	assert.Empty(t, token, "no token is sent unless configured")
+
+	_, err = NewMembershipClient(server.URL, WithServiceToken("circulation", "s3cret")).GetMember(context.Background(), uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", token)
+	assert.Equal(t, "circulation", name)
+}
@@ -0,0 +1,136 @@
+// internal/clients/retry.go
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"libranexus/internal/httperr"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultMaxRetries = 2
+	retryBaseDelay    = 50 * time.Millisecond
+	retryMaxDelay     = 1 * time.Second
+)
+
+// WithRetries sets how many times a failed idempotent request is retried.
+// Zero disables retries.
+func WithRetries(n int) ClientOption {
+	return func(t *transport) {
+		t.maxRetries = n
+	}
+}
+
+// statusError reports an unexpected HTTP status from a downstream service,
+// with the error code its response body gave, if any.
+type statusError struct {
+	code      int
+	errorCode string
+}
+
+// maxErrorBodyBytes bounds how much of an error response is read for its
+// code.
+const maxErrorBodyBytes = 4 << 10
+
+// newStatusError reports resp's status, reading the error code from its
+// body when it is an httperr.Response.
+func newStatusError(resp *http.Response) *statusError {
+	var body httperr.Response
+	json.NewDecoder(io.LimitReader(resp.Body, maxErrorBodyBytes)).Decode(&body)
+	return &statusError{code: resp.StatusCode, errorCode: body.Code}
+}
+
+func (e *statusError) Error() string {
+	if e.errorCode != "" {
+		return fmt.Sprintf("unexpected status code: %d (%s)", e.code, e.errorCode)
+	}
+	return fmt.Sprintf("unexpected status code: %d", e.code)
+}
+
+// isConflict reports whether err is a 409 with the given error code.
+func isConflict(err error, errorCode string) bool {
+	var se *statusError
+	return errors.As(err, &se) && se.code == http.StatusConflict && se.errorCode == errorCode
+}
+
+// retryable reports whether a failed request can safely be sent again:
+// transport failures and 5xx responses are, anything the service answered
+// deliberately is not.
+func retryable(err error) bool {
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.code >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// doWithRetry sends an idempotent request, retrying retryable failures with
+// exponential backoff and jitter. It stops early once the caller's context is
+// done or its deadline would pass before the next attempt.
+func (t *transport) doWithRetry(ctx context.Context, method, url string, body []byte, handle func(*http.Response) error) error {
+	return t.retry(ctx, retryable, func(ctx context.Context) error {
+		return t.do(ctx, method, url, body, handle)
+	})
+}
+
+// retry makes an idempotent attempt until it succeeds, fails in a way
+// canRetry refuses, or runs out of retries or time, backing off between
+// attempts.
+func (t *transport) retry(ctx context.Context, canRetry func(error) bool, attempt func(context.Context) error) error {
+	ctx, cancel := t.withCallDeadline(ctx)
+	defer cancel()
+
+	n := 1
+	for ; ; n++ {
+		err := attempt(ctx)
+		if err == nil {
+			return nil
+		}
+		if !canRetry(err) || n > t.maxRetries || ctx.Err() != nil {
+			return wrapAttempts(n, err)
+		}
+
+		delay := backoff(n)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return wrapAttempts(n, err)
+		}
+		if sleepWithContext(ctx, delay) != nil {
+			return wrapAttempts(n, err)
+		}
+	}
+}
+
+func wrapAttempts(attempts int, err error) error {
+	if attempts == 1 {
+		return err
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", attempts, err)
+}
+
+// backoff returns an exponentially growing delay with full jitter.
+func backoff(attempt int) time.Duration {
+	delay := retryBaseDelay << (attempt - 1)
+	if delay <= 0 || delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(delay)) + 1)
+}
+
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,164 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"libranexus/internal/catalog"
+	"libranexus/internal/httperr"
+)
+
+func TestGetItemRetriesServerErrors(t *testing.T) {
+	id := uuid.New()
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(catalog.Item{ID: id, Available: 2})
+	}))
+	defer server.Close()
+
+	client := NewCatalogClient(server.URL, WithRetries(2))
+	item, err := client.GetItem(context.Background(), id)
+	require.NoError(t, err)
+	assert.Equal(t, id, item.ID)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestGetItemGivesUpWithAttemptCount(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewCatalogClient(server.URL, WithRetries(2), WithFailureThreshold(0))
+	_, err := client.GetItem(context.Background(), uuid.New())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "giving up after 3 attempts")
+}
+
+func TestGetItemDoesNotRetryClientErrors(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewCatalogClient(server.URL, WithRetries(2))
+	_, err := client.GetItem(context.Background(), uuid.New())
+	require.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestRetryStopsAtContextDeadline(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := NewCatalogClient(server.URL, WithRetries(100), WithFailureThreshold(0))
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GetItem(ctx, uuid.New())
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Less(t, atomic.LoadInt32(&calls), int32(100))
+}
+
+func TestUpdateItemCopiesRetryDoesNotDoubleApply(t *testing.T) {
+	id := uuid.New()
+	item := catalog.Item{ID: id, TotalCopies: 3, Available: 3, Version: 4}
+	var patches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode(item)
+		case http.MethodPatch:
+			var req struct {
+				TotalCopies     int `json:"total_copies"`
+				Available       int `json:"available"`
+				ExpectedVersion int `json:"expected_version"`
+			}
+			body, _ := io.ReadAll(r.Body)
+			require.NoError(t, json.Unmarshal(body, &req))
+			if req.ExpectedVersion != item.Version {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			item.TotalCopies, item.Available, item.Version = req.TotalCopies, req.Available, item.Version+1
+			if atomic.AddInt32(&patches, 1) == 1 {
+				// Apply the update but fail the response, as if it were lost.
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	client := NewCatalogClient(server.URL, WithRetries(2))
+	require.NoError(t, client.UpdateItemCopies(context.Background(), id, 3, 2, 4))
+	assert.Equal(t, 2, item.Available)
+	assert.Equal(t, 5, item.Version)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&patches))
+}
+
+func TestUpdateItemCopiesReportsRealConflicts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode(catalog.Item{TotalCopies: 3, Available: 0, Version: 9})
+		case http.MethodPatch:
+			w.WriteHeader(http.StatusConflict)
+		}
+	}))
+	defer server.Close()
+
+	client := NewCatalogClient(server.URL)
+	err := client.UpdateItemCopies(context.Background(), uuid.New(), 3, 2, 4)
+	assert.ErrorIs(t, err, catalog.ErrVersionConflict)
+}
+
+func TestReserveCopyMapsConflict(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		assert.Equal(t, http.MethodPost, r.Method)
+		httperr.Error(w, http.StatusConflict, "no_copies_available", "no copies of the item are available")
+	}))
+	defer server.Close()
+
+	client := NewCatalogClient(server.URL, WithRetries(2))
+	err := client.ReserveCopy(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, catalog.ErrNoCopiesAvailable)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestReserveCopyPassesOtherConflictsThrough(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httperr.Error(w, http.StatusConflict, "concurrency_conflict", "concurrency conflict")
+	}))
+	defer server.Close()
+
+	err := NewCatalogClient(server.URL).ReserveCopy(context.Background(), uuid.New())
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, catalog.ErrNoCopiesAvailable, "a copy may be free; the reservation lost a race")
+	assert.ErrorContains(t, err, "concurrency_conflict")
+}
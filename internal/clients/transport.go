@@ -0,0 +1,243 @@
+// internal/clients/transport.go
+package clients
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"libranexus/internal/membership"
+	"libranexus/internal/server"
+	"libranexus/internal/tracing"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultTimeout          = 5 * time.Second
+	defaultCallDeadline     = 15 * time.Second
+	defaultFailureThreshold = 5
+	defaultCooldown         = 30 * time.Second
+)
+
+// ClientOption configures an inter-service HTTP client.
+type ClientOption func(*transport)
+
+// WithTimeout bounds each request to the downstream service.
+func WithTimeout(d time.Duration) ClientOption {
+	return func(t *transport) {
+		t.timeout = d
+	}
+}
+
+// WithCallDeadline bounds a whole call, retries and backoff included, when
+// the caller's context has no deadline of its own, so a hung downstream
+// service fails the call instead of blocking it. Zero leaves such calls
+// bounded only per request.
+func WithCallDeadline(d time.Duration) ClientOption {
+	return func(t *transport) {
+		t.callDeadline = d
+	}
+}
+
+// WithFailureThreshold sets how many consecutive failures open the circuit
+// breaker. Zero disables the breaker.
+func WithFailureThreshold(n int) ClientOption {
+	return func(t *transport) {
+		t.breaker.threshold = n
+	}
+}
+
+// WithCooldown sets how long the breaker stays open before probing again.
+func WithCooldown(d time.Duration) ClientOption {
+	return func(t *transport) {
+		t.breaker.cooldown = d
+	}
+}
+
+// WithHTTPClient replaces the underlying HTTP client.
+func WithHTTPClient(c *http.Client) ClientOption {
+	return func(t *transport) {
+		t.httpClient = c
+	}
+}
+
+// WithServiceToken identifies every request as coming from the named service,
+// by presenting the token shared between the services.
+func WithServiceToken(service, token string) ClientOption {
+	return func(t *transport) {
+		t.serviceName = service
+		t.serviceToken = token
+	}
+}
+
+// errCallDeadline is the cause of a call cut short by the client's own
+// deadline rather than by its caller.
+var errCallDeadline = errors.New("downstream call deadline exceeded")
+
+// transport sends requests to one downstream service through a circuit
+// breaker, applying a per-request timeout.
+type transport struct {
+	httpClient   *http.Client
+	timeout      time.Duration
+	callDeadline time.Duration
+	maxRetries   int
+	breaker      *circuitBreaker
+
+	serviceName  string
+	serviceToken string
+}
+
+func newTransport(opts ...ClientOption) *transport {
+	t := &transport{
+		httpClient:   &http.Client{Transport: tracing.Transport(nil)},
+		timeout:      defaultTimeout,
+		callDeadline: defaultCallDeadline,
+		maxRetries:   defaultMaxRetries,
+		breaker:      newCircuitBreaker(defaultFailureThreshold, defaultCooldown),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// do sends a request and hands the response to handle before the request's
+// timeout is released. Transport errors and 5xx responses count as breaker
+// failures; any other response means the service is healthy.
+func (t *transport) do(ctx context.Context, method, url string, body []byte, handle func(*http.Response) error) error {
+	if err := t.breaker.allow(); err != nil {
+		return err
+	}
+
+	ctx, cancelCall := t.withCallDeadline(ctx)
+	defer cancelCall()
+	parent := ctx
+	if t.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+		defer cancel()
+	}
+
+	req, err := newRequest(ctx, method, url, body)
+	if err != nil {
+		t.breaker.release()
+		return err
+	}
+	if t.serviceToken != "" {
+		req.Header.Set(membership.ServiceTokenHeader, t.serviceToken)
+		req.Header.Set(membership.ServiceNameHeader, t.serviceName)
+	}
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		// A caller giving up says nothing about the downstream service's
+		// health, though running out the client's own deadline does.
+		if parent.Err() != nil && context.Cause(parent) != errCallDeadline {
+			t.breaker.release()
+		} else {
+			t.breaker.record(false)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	t.breaker.record(resp.StatusCode < http.StatusInternalServerError)
+	return handle(resp)
+}
+
+// withCallDeadline applies the client's call deadline to ctx, unless ctx
+// already has a deadline.
+func (t *transport) withCallDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || t.callDeadline <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeoutCause(ctx, t.callDeadline, errCallDeadline)
+}
+
+// newRequest builds a request carrying the event metadata and trace context on
+// ctx, so events the downstream service appends, and its spans, are traced
+// back to this one.
+func newRequest(ctx context.Context, method, url string, body []byte) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	server.SetMetadataHeaders(ctx, req.Header)
+	tracing.Inject(ctx, req.Header)
+	return req, nil
+}
+
+// expectStatus is a response handler that only checks the status code.
+func expectStatus(want int) func(*http.Response) error {
+	return func(resp *http.Response) error {
+		if resp.StatusCode != want {
+			return newStatusError(resp)
+		}
+		return nil
+	}
+}
+
+// ping checks that the service at baseURL is up by calling its liveness
+// endpoint once, without retries.
+func (t *transport) ping(ctx context.Context, baseURL string) error {
+	return t.do(ctx, http.MethodGet, baseURL+"/healthz", nil, expectStatus(http.StatusOK))
+}
+
+// ClientOptionsFromEnv reads client settings from CLIENT_TIMEOUT,
+// CLIENT_CALL_DEADLINE, CLIENT_MAX_RETRIES, CLIENT_FAILURE_THRESHOLD and
+// CLIENT_BREAKER_COOLDOWN.
+// Unset variables keep the defaults. When SERVICE_TOKEN is set, requests
+// present it as coming from service.
+func ClientOptionsFromEnv(service string) ([]ClientOption, error) {
+	var opts []ClientOption
+	if v := os.Getenv("SERVICE_TOKEN"); v != "" {
+		opts = append(opts, WithServiceToken(service, v))
+	}
+	if v := os.Getenv("CLIENT_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CLIENT_TIMEOUT: %w", err)
+		}
+		opts = append(opts, WithTimeout(d))
+	}
+	if v := os.Getenv("CLIENT_CALL_DEADLINE"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CLIENT_CALL_DEADLINE: %w", err)
+		}
+		opts = append(opts, WithCallDeadline(d))
+	}
+	if v := os.Getenv("CLIENT_MAX_RETRIES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CLIENT_MAX_RETRIES: %w", err)
+		}
+		opts = append(opts, WithRetries(n))
+	}
+	if v := os.Getenv("CLIENT_FAILURE_THRESHOLD"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CLIENT_FAILURE_THRESHOLD: %w", err)
+		}
+		opts = append(opts, WithFailureThreshold(n))
+	}
+	if v := os.Getenv("CLIENT_BREAKER_COOLDOWN"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CLIENT_BREAKER_COOLDOWN: %w", err)
+		}
+		opts = append(opts, WithCooldown(d))
+	}
+	return opts, nil
+}
@@ -0,0 +1,71 @@
+// internal/config/catalog.go
+package config
+
+import (
+	"libranexus/internal/catalog"
+	"time"
+)
+
+// Catalog is the catalog service's configuration.
+type Catalog struct {
+	Server
+	Database Database
+	// ImportBatchSize is how many bulk-import rows share a transaction
+	// (IMPORT_BATCH_SIZE). Zero keeps the service default.
+	ImportBatchSize int
+	// MinCopies is the fewest copies a new item may have (MIN_ITEM_COPIES).
+	MinCopies int
+	// SnapshotInterval is how many events an item may accumulate before it
+	// is snapshotted (SNAPSHOT_INTERVAL). Zero disables snapshotting.
+	SnapshotInterval int
+	// SearchCacheTTL is how long database search results are reused
+	// (SEARCH_CACHE_TTL). Zero disables the cache.
+	SearchCacheTTL time.Duration
+	// ItemCacheTTL, when set, turns on the item cache (ITEM_CACHE_TTL), which
+	// holds up to ItemCacheSize items (ITEM_CACHE_SIZE).
+	ItemCacheTTL  time.Duration
+	ItemCacheSize int
+	// SnapshotHistory, when set, keeps that many snapshots per item for
+	// auditing (SNAPSHOT_HISTORY). Otherwise each item keeps only its latest.
+	SnapshotHistory int
+	// Search chooses where searches run.
+	Search Search
+}
+
+// Search holds the search backend settings.
+type Search struct {
+	// Backend is "postgres", the default, or "meilisearch" (SEARCH_BACKEND).
+	Backend string
+	// MeilisearchURL, MeilisearchAPIKey and MeilisearchIndex locate the
+	// Meilisearch index (MEILISEARCH_URL, MEILISEARCH_API_KEY and
+	// MEILISEARCH_INDEX).
+	MeilisearchURL    string
+	MeilisearchAPIKey string
+	MeilisearchIndex  string
+}
+
+// LoadCatalog reads the catalog service's configuration from the
+// environment.
+func LoadCatalog() (Catalog, error) {
+	e := newEnv()
+	c := Catalog{
+		Server:           loadServer(e, "8081", true),
+		Database:         loadDatabase(e),
+		ImportBatchSize:  e.int("IMPORT_BATCH_SIZE", 0, 1),
+		MinCopies:        e.int("MIN_ITEM_COPIES", catalog.DefaultMinCopies, 0),
+		SnapshotInterval: e.int("SNAPSHOT_INTERVAL", catalog.DefaultSnapshotInterval, 0),
+		SearchCacheTTL:   e.duration("SEARCH_CACHE_TTL", catalog.DefaultSearchCacheTTL, 0),
+		ItemCacheTTL:     e.duration("ITEM_CACHE_TTL", 0, 0),
+		ItemCacheSize:    e.int("ITEM_CACHE_SIZE", catalog.DefaultItemCacheSize, 1),
+		SnapshotHistory:  e.int("SNAPSHOT_HISTORY", 0, 1),
+		Search: Search{
+			Backend: e.oneOf("SEARCH_BACKEND", "postgres", "postgres", "meilisearch"),
+		},
+	}
+	if c.Search.Backend == "meilisearch" {
+		c.Search.MeilisearchURL = e.url("MEILISEARCH_URL", "http://localhost:7700")
+		c.Search.MeilisearchAPIKey = e.string("MEILISEARCH_API_KEY", "")
+		c.Search.MeilisearchIndex = e.string("MEILISEARCH_INDEX", "items")
+	}
+	return c, e.err()
+}
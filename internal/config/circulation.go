@@ -0,0 +1,78 @@
+// internal/config/circulation.go
+package config
+
+import (
+	"libranexus/internal/circulation"
+	"libranexus/internal/clients"
+	"time"
+)
+
+// Circulation is the circulation service's configuration.
+type Circulation struct {
+	Server
+	Database Database
+	// CatalogURL and MembershipURL locate the services circulation calls
+	// (CATALOG_SERVICE_URL and MEMBERSHIP_SERVICE_URL).
+	CatalogURL    string
+	MembershipURL string
+	// Clients configures the clients for those services (see
+	// clients.ClientOptionsFromEnv).
+	Clients []clients.ClientOption
+	// HoldExpiry and PickupWindow bound how long holds wait (HOLD_EXPIRY and
+	// PICKUP_WINDOW). Zero keeps the service defaults.
+	HoldExpiry   time.Duration
+	PickupWindow time.Duration
+	// LoanPolicy sets how long loans last (LOAN_PERIOD, LOAN_PERIODS_BY_TIER
+	// and LOAN_PERIODS_BY_CATEGORY).
+	LoanPolicy circulation.LoanPolicy
+	// Calendar holds the days the library is open (see
+	// circulation.CalendarFromEnv).
+	Calendar circulation.Calendar
+	// SnapshotInterval is how many events a checkout may accumulate before
+	// it is snapshotted (SNAPSHOT_INTERVAL). Zero disables snapshotting.
+	SnapshotInterval int
+	// AllowBodyMemberID trusts member IDs in request bodies
+	// (ALLOW_BODY_MEMBER_ID), for deployments without the gateway.
+	AllowBodyMemberID bool
+	// IdempotencyTTL is how long idempotency keys are kept
+	// (IDEMPOTENCY_TTL). Zero keeps the default.
+	IdempotencyTTL time.Duration
+	// SagaRecoveryGrace is how long a checkout may stall before it is
+	// recovered (SAGA_RECOVERY_GRACE).
+	SagaRecoveryGrace time.Duration
+}
+
+// LoadCirculation reads the circulation service's configuration from the
+// environment.
+func LoadCirculation() (Circulation, error) {
+	e := newEnv()
+	c := Circulation{
+		Server:            loadServer(e, "8082", false),
+		Database:          loadDatabase(e),
+		CatalogURL:        e.url("CATALOG_SERVICE_URL", "http://localhost:8081"),
+		MembershipURL:     e.url("MEMBERSHIP_SERVICE_URL", "http://localhost:8083"),
+		HoldExpiry:        e.duration("HOLD_EXPIRY", 0, 0),
+		PickupWindow:      e.duration("PICKUP_WINDOW", 0, 0),
+		LoanPolicy:        circulation.DefaultLoanPolicy(),
+		SnapshotInterval:  e.int("SNAPSHOT_INTERVAL", circulation.DefaultSnapshotInterval, 0),
+		AllowBodyMemberID: e.bool("ALLOW_BODY_MEMBER_ID", false),
+		IdempotencyTTL:    e.duration("IDEMPOTENCY_TTL", 0, 0),
+		SagaRecoveryGrace: e.duration("SAGA_RECOVERY_GRACE", 5*time.Minute, time.Second),
+	}
+	c.LoanPolicy.Default = e.duration("LOAN_PERIOD", c.LoanPolicy.Default, time.Second)
+	e.parse("LOAN_PERIODS_BY_TIER", func(v string) (err error) {
+		c.LoanPolicy.ByTier, err = circulation.ParseLoanPeriods(v)
+		return err
+	})
+	e.parse("LOAN_PERIODS_BY_CATEGORY", func(v string) (err error) {
+		c.LoanPolicy.ByCategory, err = circulation.ParseLoanPeriods(v)
+		return err
+	})
+
+	var err error
+	c.Clients, err = clients.ClientOptionsFromEnv("circulation")
+	e.check("service clients", err)
+	c.Calendar, err = circulation.CalendarFromEnv()
+	e.check("library calendar", err)
+	return c, e.err()
+}
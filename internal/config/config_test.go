@@ -0,0 +1,122 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"libranexus/internal/catalog"
+	"libranexus/internal/server"
+)
+
+func TestLoadCatalogDefaults(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://localhost/libranexus")
+
+	cfg, err := LoadCatalog()
+	require.NoError(t, err)
+	assert.Equal(t, ":8081", cfg.Addr())
+	assert.Equal(t, server.DefaultDrainTimeout, cfg.DrainTimeout)
+	assert.Equal(t, "postgres://localhost/libranexus", cfg.Database.URL)
+	assert.True(t, cfg.Database.Migrate, "migrations run at startup unless turned off")
+	assert.Equal(t, catalog.DefaultMinCopies, cfg.MinCopies)
+	assert.Equal(t, catalog.DefaultSnapshotInterval, cfg.SnapshotInterval)
+	assert.Equal(t, "postgres", cfg.Search.Backend)
+}
+
+func TestLoadCatalogMeilisearch(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://localhost/libranexus")
+	t.Setenv("SEARCH_BACKEND", "meilisearch")
+	t.Setenv("MEILISEARCH_INDEX", "books")
+
+	cfg, err := LoadCatalog()
+	require.NoError(t, err)
+	assert.Equal(t, Search{Backend: "meilisearch", MeilisearchURL: "http://localhost:7700", MeilisearchIndex: "books"}, cfg.Search)
+}
+
+func TestLoadReportsAllProblems(t *testing.T) {
+	t.Setenv("DATABASE_URL", "")
+	t.Setenv("PORT", "http")
+	t.Setenv("SNAPSHOT_INTERVAL", "often")
+	t.Setenv("SEARCH_CACHE_TTL", "-1s")
+
+	_, err := LoadCatalog()
+	var cfgErr *Error
+	require.ErrorAs(t, err, &cfgErr)
+	assert.Len(t, cfgErr.Problems, 4)
+	for _, name := range []string{"PORT", "DATABASE_URL", "SNAPSHOT_INTERVAL", "SEARCH_CACHE_TTL"} {
+		assert.Contains(t, err.Error(), name)
+	}
+}
+
+func TestLoadCirculation(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://localhost/libranexus")
+	t.Setenv("LOAN_PERIOD", "168h")
+	t.Setenv("LOAN_PERIODS_BY_CATEGORY", "reference=72h")
+	t.Setenv("ALLOW_BODY_MEMBER_ID", "true")
+
+	cfg, err := LoadCirculation()
+	require.NoError(t, err)
+	assert.Equal(t, 168*time.Hour, cfg.LoanPolicy.Default)
+	assert.Equal(t, map[string]time.Duration{"reference": 72 * time.Hour}, cfg.LoanPolicy.ByCategory)
+	assert.True(t, cfg.AllowBodyMemberID)
+	assert.Equal(t, 5*time.Minute, cfg.SagaRecoveryGrace)
+
+	t.Setenv("LOAN_PERIODS_BY_TIER", "basic")
+	t.Setenv("MEMBERSHIP_SERVICE_URL", "membership:8083")
+	_, err = LoadCirculation()
+	var cfgErr *Error
+	require.ErrorAs(t, err, &cfgErr)
+	assert.Len(t, cfgErr.Problems, 2)
+}
+
+func TestLoadMembership(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://localhost/libranexus")
+	t.Setenv("GRPC_PORT", "9083")
+	t.Setenv("SERVICE_TOKEN", "secret")
+	t.Setenv("JWT_SIGNING_KEY", "jwt-secret")
+	t.Setenv("LOGIN_RATE_LIMIT", "10/1m")
+	t.Setenv("CHECKOUT_LIMITS", "basic=3")
+
+	cfg, err := LoadMembership()
+	require.NoError(t, err)
+	assert.Equal(t, "9083", cfg.GRPCPort)
+	assert.Equal(t, "secret", cfg.ServiceToken)
+	assert.Equal(t, 10, cfg.LoginLimit.Requests)
+	assert.Equal(t, map[string]int{"basic": 3}, cfg.CheckoutLimits)
+	assert.Nil(t, cfg.Argon2)
+	assert.NotNil(t, cfg.Tokens)
+
+	t.Setenv("TIER_CHANGE_INTERVAL", "0s")
+	_, err = LoadMembership()
+	assert.ErrorContains(t, err, "TIER_CHANGE_INTERVAL")
+
+	t.Setenv("TIER_CHANGE_INTERVAL", "1m")
+	t.Setenv("SERVICE_TOKEN", "")
+	_, err = LoadMembership()
+	assert.ErrorContains(t, err, "SERVICE_TOKEN", "serving gRPC needs the service token")
+}
+
+func TestLoadGateway(t *testing.T) {
+	t.Setenv("CATALOG_SERVICE_URL", "http://catalog:8081")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://library.example.org")
+	t.Setenv("JWT_SIGNING_KEY", "jwt-secret")
+
+	cfg, err := LoadGateway()
+	require.NoError(t, err)
+	assert.Equal(t, ":8080", cfg.Addr())
+	assert.Equal(t, "http://catalog:8081", cfg.CatalogURL)
+	assert.Equal(t, []string{"https://library.example.org"}, cfg.AllowedOrigins)
+	assert.NotNil(t, cfg.Tokens)
+
+	// A bad token setting is reported with the rest, not after them.
+	t.Setenv("GATEWAY_RATE_BURST", "none")
+	t.Setenv("JWT_TTL", "a while")
+	_, err = LoadGateway()
+	var cfgErr *Error
+	require.ErrorAs(t, err, &cfgErr)
+	assert.Len(t, cfgErr.Problems, 2)
+	assert.ErrorContains(t, err, "GATEWAY_RATE_BURST")
+	assert.ErrorContains(t, err, "JWT_TTL")
+}
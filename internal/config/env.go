@@ -0,0 +1,184 @@
+// internal/config/env.go
+
+// Package config loads each service's settings from the environment at
+// startup. Every setting is parsed and checked before the service starts,
+// and all the problems found are reported together, so a misconfigured
+// deployment fails at once with everything that needs fixing.
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Error lists everything wrong with a service's configuration.
+type Error struct {
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return "invalid configuration:\n\t" + strings.Join(e.Problems, "\n\t")
+}
+
+// env reads settings from environment variables, recording every invalid
+// one instead of stopping at the first. Each reader returns def when its
+// variable is unset or empty, and also when the value is invalid, so
+// loading can carry on and find the rest.
+type env struct {
+	lookup   func(string) (string, bool)
+	problems []string
+}
+
+func newEnv() *env {
+	return &env{lookup: os.LookupEnv}
+}
+
+// get returns name's value, and whether it is set to anything but blanks.
+func (e *env) get(name string) (string, bool) {
+	v, _ := e.lookup(name)
+	v = strings.TrimSpace(v)
+	return v, v != ""
+}
+
+// fail records a problem with name.
+func (e *env) fail(name, format string, args ...interface{}) {
+	e.problems = append(e.problems, name+": "+fmt.Sprintf(format, args...))
+}
+
+// check records err, from reading the settings what describes, if any.
+func (e *env) check(what string, err error) {
+	if err != nil {
+		e.problems = append(e.problems, what+": "+err.Error())
+	}
+}
+
+// err returns an *Error listing the problems recorded, or nil.
+func (e *env) err() error {
+	if len(e.problems) == 0 {
+		return nil
+	}
+	return &Error{Problems: e.problems}
+}
+
+func (e *env) string(name, def string) string {
+	if v, ok := e.get(name); ok {
+		return v
+	}
+	return def
+}
+
+// required returns name's value, recording a problem if it is unset.
+func (e *env) required(name string) string {
+	v, ok := e.get(name)
+	if !ok {
+		e.fail(name, "is required")
+	}
+	return v
+}
+
+// int returns name's value, which must be an integer of at least min.
+func (e *env) int(name string, def, min int) int {
+	v, ok := e.get(name)
+	if !ok {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		e.fail(name, "%q is not an integer", v)
+		return def
+	}
+	if n < min {
+		e.fail(name, "must be at least %d, got %d", min, n)
+		return def
+	}
+	return n
+}
+
+// duration returns name's value, which must be a duration such as "90s" of
+// at least min.
+func (e *env) duration(name string, def, min time.Duration) time.Duration {
+	v, ok := e.get(name)
+	if !ok {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		e.fail(name, "%q is not a duration", v)
+		return def
+	}
+	if d < min {
+		e.fail(name, "must be at least %s, got %s", min, d)
+		return def
+	}
+	return d
+}
+
+func (e *env) bool(name string, def bool) bool {
+	v, ok := e.get(name)
+	if !ok {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		e.fail(name, "%q is not a boolean", v)
+		return def
+	}
+	return b
+}
+
+// port returns name's value, which must be a TCP port number. An empty def
+// makes the port optional.
+func (e *env) port(name, def string) string {
+	v, ok := e.get(name)
+	if !ok {
+		return def
+	}
+	if n, err := strconv.Atoi(v); err != nil || n < 1 || n > 65535 {
+		e.fail(name, "%q is not a port number", v)
+		return def
+	}
+	return v
+}
+
+// url returns name's value, which must be an absolute http or https URL.
+func (e *env) url(name, def string) string {
+	v, ok := e.get(name)
+	if !ok {
+		return def
+	}
+	u, err := url.Parse(v)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		e.fail(name, "%q is not an http or https URL", v)
+		return def
+	}
+	return v
+}
+
+// oneOf returns name's value, which must be one of allowed.
+func (e *env) oneOf(name, def string, allowed ...string) string {
+	v, ok := e.get(name)
+	if !ok {
+		return def
+	}
+	for _, a := range allowed {
+		if v == a {
+			return v
+		}
+	}
+	e.fail(name, "%q is not one of %s", v, strings.Join(allowed, ", "))
+	return def
+}
+
+// parse passes name's value, if it is set, to parse, recording the error
+// it returns.
+func (e *env) parse(name string, parse func(string) error) {
+	if v, ok := e.get(name); ok {
+		if err := parse(v); err != nil {
+			e.fail(name, "%v", err)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fakeEnv(vars map[string]string) *env {
+	return &env{lookup: func(name string) (string, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}}
+}
+
+func TestEnvReaders(t *testing.T) {
+	e := fakeEnv(map[string]string{
+		"NAME":     " catalog ",
+		"COUNT":    "7",
+		"TIMEOUT":  "90s",
+		"ENABLED":  "true",
+		"PORT":     "8081",
+		"URL":      "https://example.org",
+		"BACKEND":  "meilisearch",
+		"PERIODS":  "a=1h",
+		"BLANK":    "  ",
+		"REQUIRED": "set",
+	})
+
+	assert.Equal(t, "catalog", e.string("NAME", "x"))
+	assert.Equal(t, "x", e.string("BLANK", "x"), "blank values are unset")
+	assert.Equal(t, "set", e.required("REQUIRED"))
+	assert.Equal(t, 7, e.int("COUNT", 1, 0))
+	assert.Equal(t, 3, e.int("MISSING", 3, 0))
+	assert.Equal(t, 90*time.Second, e.duration("TIMEOUT", time.Second, 0))
+	assert.True(t, e.bool("ENABLED", false))
+	assert.Equal(t, "8081", e.port("PORT", "80"))
+	assert.Equal(t, "https://example.org", e.url("URL", ""))
+	assert.Equal(t, "meilisearch", e.oneOf("BACKEND", "postgres", "postgres", "meilisearch"))
+	var parsed string
+	e.parse("PERIODS", func(v string) error {
+		parsed = v
+		return nil
+	})
+	assert.Equal(t, "a=1h", parsed)
+	assert.NoError(t, e.err())
+}
+
+func TestEnvCollectsEveryProblem(t *testing.T) {
+	e := fakeEnv(map[string]string{
+		"COUNT":   "seven",
+		"SMALL":   "0",
+		"TIMEOUT": "soon",
+		"ENABLED": "maybe",
+		"PORT":    "70000",
+		"URL":     "localhost:7700",
+		"BACKEND": "elastic",
+	})
+
+	assert.Equal(t, 1, e.int("COUNT", 1, 0), "invalid values fall back to the default")
+	e.int("SMALL", 1, 1)
+	e.duration("TIMEOUT", time.Second, 0)
+	e.bool("ENABLED", false)
+	e.port("PORT", "80")
+	e.url("URL", "")
+	e.oneOf("BACKEND", "postgres", "postgres", "meilisearch")
+	e.required("DATABASE_URL")
+
+	err := e.err()
+	var cfgErr *Error
+	assert.ErrorAs(t, err, &cfgErr)
+	assert.Equal(t, []string{
+		`COUNT: "seven" is not an integer`,
+		`SMALL: must be at least 1, got 0`,
+		`TIMEOUT: "soon" is not a duration`,
+		`ENABLED: "maybe" is not a boolean`,
+		`PORT: "70000" is not a port number`,
+		`URL: "localhost:7700" is not an http or https URL`,
+		`BACKEND: "elastic" is not one of postgres, meilisearch`,
+		`DATABASE_URL: is required`,
+	}, cfgErr.Problems)
+	assert.Contains(t, err.Error(), "invalid configuration:\n\tCOUNT:")
+}
@@ -0,0 +1,51 @@
+// internal/config/gateway.go
+package config
+
+import (
+	"libranexus/internal/gateway"
+	"libranexus/internal/membership"
+)
+
+// Gateway is the API gateway's configuration.
+type Gateway struct {
+	Server
+	// CatalogURL, CirculationURL, MembershipURL and PublisherURL locate the
+	// services the gateway routes to (CATALOG_SERVICE_URL,
+	// CIRCULATION_SERVICE_URL, MEMBERSHIP_SERVICE_URL and
+	// PUBLISHER_SERVICE_URL).
+	CatalogURL     string
+	CirculationURL string
+	MembershipURL  string
+	PublisherURL   string
+	// Proxy bounds the requests forwarded to them (see
+	// gateway.ProxyConfigFromEnv).
+	Proxy gateway.ProxyConfig
+	// ClientLimits bounds each client IP (see gateway.ClientLimitsFromEnv).
+	ClientLimits gateway.ClientLimits
+	// AllowedOrigins may call the API from a browser (CORS_ALLOWED_ORIGINS).
+	AllowedOrigins []string
+	// Tokens checks the tokens membership issues (see
+	// membership.NewTokenServiceFromEnv).
+	Tokens *membership.TokenService
+}
+
+// LoadGateway reads the API gateway's configuration from the environment.
+func LoadGateway() (Gateway, error) {
+	e := newEnv()
+	g := Gateway{
+		Server:         loadServer(e, "8080", false),
+		CatalogURL:     e.url("CATALOG_SERVICE_URL", "http://localhost:8081"),
+		CirculationURL: e.url("CIRCULATION_SERVICE_URL", "http://localhost:8082"),
+		MembershipURL:  e.url("MEMBERSHIP_SERVICE_URL", "http://localhost:8083"),
+		PublisherURL:   e.url("PUBLISHER_SERVICE_URL", "http://localhost:8084"),
+		AllowedOrigins: gateway.AllowedOriginsFromEnv(),
+	}
+	var err error
+	g.Proxy, err = gateway.ProxyConfigFromEnv()
+	e.check("proxy", err)
+	g.ClientLimits, err = gateway.ClientLimitsFromEnv()
+	e.check("rate limits", err)
+	g.Tokens, err = membership.NewTokenServiceFromEnv()
+	e.check("tokens", err)
+	return g, e.err()
+}
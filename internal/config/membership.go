@@ -0,0 +1,94 @@
+// internal/config/membership.go
+package config
+
+import (
+	"libranexus/internal/membership"
+	"time"
+)
+
+// Membership is the membership service's configuration.
+type Membership struct {
+	Server
+	Database Database
+	// LockoutThreshold failed logins in a row lock an account for
+	// LockoutDuration (LOCKOUT_THRESHOLD and LOCKOUT_DURATION).
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+	// CheckoutLimits caps checkouts by tier (CHECKOUT_LIMITS). Nil keeps the
+	// service defaults.
+	CheckoutLimits map[string]int
+	// PasswordPolicy sets how strong passwords must be (PASSWORD_MIN_LENGTH
+	// and PASSWORD_MIN_CLASSES).
+	PasswordPolicy membership.PasswordPolicy
+	// LoginLimit and RegisterLimit throttle logins and registrations
+	// (LOGIN_RATE_LIMIT and REGISTER_RATE_LIMIT).
+	LoginLimit    membership.RateLimit
+	RegisterLimit membership.RateLimit
+	// Argon2, when set, replaces the password hashing cost (ARGON2_PARAMS).
+	Argon2 *membership.Argon2Params
+	// ResetTokenTTL is how long a password reset token stays valid
+	// (PASSWORD_RESET_TTL).
+	ResetTokenTTL time.Duration
+	// MemberCacheTTL, when set, turns on the member cache (MEMBER_CACHE_TTL),
+	// which holds up to MemberCacheSize members (MEMBER_CACHE_SIZE).
+	MemberCacheTTL  time.Duration
+	MemberCacheSize int
+	// BootstrapAdminEmail appoints the library's first administrator once
+	// they have registered (BOOTSTRAP_ADMIN_EMAIL).
+	BootstrapAdminEmail string
+	// TierChangeInterval and ExpiryInterval are how often scheduled tier
+	// changes and lapsed memberships are applied (TIER_CHANGE_INTERVAL and
+	// MEMBERSHIP_EXPIRY_INTERVAL).
+	TierChangeInterval time.Duration
+	ExpiryInterval     time.Duration
+	// Tokens issues and checks member tokens (see
+	// membership.NewTokenServiceFromEnv).
+	Tokens *membership.TokenService
+}
+
+// LoadMembership reads the membership service's configuration from the
+// environment.
+func LoadMembership() (Membership, error) {
+	e := newEnv()
+	m := Membership{
+		Server:              loadServer(e, "8083", true),
+		Database:            loadDatabase(e),
+		LockoutThreshold:    e.int("LOCKOUT_THRESHOLD", membership.DefaultLockoutThreshold, 1),
+		LockoutDuration:     e.duration("LOCKOUT_DURATION", membership.DefaultLockoutDuration, time.Second),
+		PasswordPolicy:      membership.DefaultPasswordPolicy(),
+		LoginLimit:          membership.DefaultRateLimit,
+		RegisterLimit:       membership.DefaultRateLimit,
+		ResetTokenTTL:       e.duration("PASSWORD_RESET_TTL", membership.DefaultResetTokenTTL, time.Minute),
+		MemberCacheTTL:      e.duration("MEMBER_CACHE_TTL", 0, 0),
+		MemberCacheSize:     e.int("MEMBER_CACHE_SIZE", membership.DefaultMemberCacheSize, 1),
+		BootstrapAdminEmail: e.string("BOOTSTRAP_ADMIN_EMAIL", ""),
+		TierChangeInterval:  e.duration("TIER_CHANGE_INTERVAL", time.Minute, time.Second),
+		ExpiryInterval:      e.duration("MEMBERSHIP_EXPIRY_INTERVAL", time.Hour, time.Second),
+	}
+	m.PasswordPolicy.MinLength = e.int("PASSWORD_MIN_LENGTH", m.PasswordPolicy.MinLength, 1)
+	m.PasswordPolicy.MinClasses = e.int("PASSWORD_MIN_CLASSES", m.PasswordPolicy.MinClasses, 0)
+	e.parse("CHECKOUT_LIMITS", func(v string) (err error) {
+		m.CheckoutLimits, err = membership.ParseTierLimits(v)
+		return err
+	})
+	e.parse("LOGIN_RATE_LIMIT", func(v string) (err error) {
+		m.LoginLimit, err = membership.ParseRateLimit(v)
+		return err
+	})
+	e.parse("REGISTER_RATE_LIMIT", func(v string) (err error) {
+		m.RegisterLimit, err = membership.ParseRateLimit(v)
+		return err
+	})
+	e.parse("ARGON2_PARAMS", func(v string) error {
+		params, err := membership.ParseArgon2Params(v)
+		if err != nil {
+			return err
+		}
+		m.Argon2 = &params
+		return nil
+	})
+	var err error
+	m.Tokens, err = membership.NewTokenServiceFromEnv()
+	e.check("tokens", err)
+	return m, e.err()
+}
@@ -0,0 +1,69 @@
+// internal/config/server.go
+package config
+
+import (
+	"libranexus/internal/database"
+	"libranexus/internal/server"
+	"time"
+)
+
+// Server holds the settings every HTTP service shares.
+type Server struct {
+	// Port is the HTTP port (PORT).
+	Port string
+	// GRPCPort, when set, also serves reads over gRPC (GRPC_PORT), to
+	// services presenting ServiceToken.
+	GRPCPort string
+	// ServiceToken identifies other LibraNexus services calling in
+	// (SERVICE_TOKEN). Without it no caller is taken for a service, so
+	// circulation cannot look members up or charge fines, and gRPC cannot be
+	// served at all.
+	ServiceToken string
+	// DrainTimeout bounds how long shutdown waits for requests in flight
+	// (SHUTDOWN_TIMEOUT).
+	DrainTimeout time.Duration
+}
+
+// Addr is the address to serve HTTP on.
+func (s Server) Addr() string {
+	return ":" + s.Port
+}
+
+func loadServer(e *env, defaultPort string, grpc bool) Server {
+	s := Server{
+		Port:         e.port("PORT", defaultPort),
+		DrainTimeout: e.duration("SHUTDOWN_TIMEOUT", server.DefaultDrainTimeout, 0),
+	}
+	if grpc {
+		s.GRPCPort = e.port("GRPC_PORT", "")
+		s.ServiceToken = e.string("SERVICE_TOKEN", "")
+		if s.GRPCPort != "" && s.ServiceToken == "" {
+			e.fail("SERVICE_TOKEN", "must be set to serve gRPC on GRPC_PORT")
+		}
+	}
+	return s
+}
+
+// Database holds a service's database settings.
+type Database struct {
+	// URL is the Postgres connection string (DATABASE_URL).
+	URL string
+	// Pool bounds the connection pool (DB_MAX_OPEN_CONNS, DB_MAX_IDLE_CONNS,
+	// DB_CONN_MAX_LIFETIME and DB_CONN_MAX_IDLE_TIME).
+	Pool database.PoolConfig
+	// Migrate applies any pending schema migrations at startup
+	// (MIGRATE_ON_STARTUP). Deployments that run cmd/migrate themselves
+	// turn it off.
+	Migrate bool
+}
+
+func loadDatabase(e *env) Database {
+	db := Database{
+		URL:     e.required("DATABASE_URL"),
+		Migrate: e.bool("MIGRATE_ON_STARTUP", true),
+	}
+	var err error
+	db.Pool, err = database.PoolConfigFromEnv()
+	e.check("database pool", err)
+	return db
+}
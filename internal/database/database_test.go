@@ -0,0 +1,57 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolConfigFromEnv(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		cfg, err := PoolConfigFromEnv()
+		require.NoError(t, err)
+		assert.Equal(t, DefaultPoolConfig(), cfg)
+	})
+
+	t.Run("overrides", func(t *testing.T) {
+		t.Setenv("DB_MAX_OPEN_CONNS", "50")
+		t.Setenv("DB_MAX_IDLE_CONNS", "5")
+		t.Setenv("DB_CONN_MAX_LIFETIME", "1h")
+		t.Setenv("DB_CONN_MAX_IDLE_TIME", "30s")
+
+		cfg, err := PoolConfigFromEnv()
+		require.NoError(t, err)
+		assert.Equal(t, PoolConfig{
+			MaxOpenConns:    50,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: time.Hour,
+			ConnMaxIdleTime: 30 * time.Second,
+		}, cfg)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Setenv("DB_MAX_OPEN_CONNS", "lots")
+
+		_, err := PoolConfigFromEnv()
+		assert.ErrorContains(t, err, "DB_MAX_OPEN_CONNS")
+	})
+}
+
+func TestOpenAppliesPoolLimits(t *testing.T) {
+	t.Setenv("DB_MAX_OPEN_CONNS", "7")
+
+	db, err := Open()
+	require.NoError(t, err)
+	defer db.Close()
+
+	assert.Equal(t, 7, db.Stats().MaxOpenConnections)
+}
+
+func TestItemLockKey(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	assert.Equal(t, itemLockKey(a), itemLockKey(a), "an item always takes the same lock")
+	assert.NotEqual(t, itemLockKey(a), itemLockKey(b))
+}
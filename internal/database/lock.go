@@ -0,0 +1,35 @@
+// internal/database/lock.go
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// itemLockNamespace sets the item locks' keys apart from any other advisory
+// locks sharing the database.
+const itemLockNamespace uint64 = 0x6c6e_6974_656d_0000 // "lnitem"
+
+// WithItemLock runs fn holding a transaction-scoped advisory lock on itemID,
+// so concurrent transactions working on the same item take turns instead of
+// interleaving and failing each other's version checks. The lock is held
+// until tx commits or rolls back; fn should do everything that must not
+// interleave through tx.
+func WithItemLock(ctx context.Context, tx *sql.Tx, itemID uuid.UUID, fn func() error) error {
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, itemLockKey(itemID)); err != nil {
+		return fmt.Errorf("failed to lock item: %w", err)
+	}
+	return fn()
+}
+
+// itemLockKey folds an item's UUID into the 64-bit key advisory locks take.
+// Two items sharing a key only ever wait for one another needlessly.
+func itemLockKey(itemID uuid.UUID) int64 {
+	hi := binary.BigEndian.Uint64(itemID[:8])
+	lo := binary.BigEndian.Uint64(itemID[8:])
+	return int64(hi ^ lo ^ itemLockNamespace)
+}
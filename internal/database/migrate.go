@@ -0,0 +1,222 @@
+// internal/database/migrate.go
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"libranexus/db/migrations"
+)
+
+// migrationLockKey is the advisory lock held while migrating, so services
+// starting together apply each migration once between them.
+const migrationLockKey int64 = 0x6c6e_6d69_6772_6174 // "lnmigrat"
+
+// migrationFile matches a migration's file name, such as
+// "0002_holds.up.sql".
+var migrationFile = regexp.MustCompile(`^(\d+)_(\w+)\.up\.sql$`)
+
+// Migration is one numbered change to the schema.
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// AppliedMigration records when a migration was applied.
+type AppliedMigration struct {
+	Version   int
+	Name      string
+	AppliedAt time.Time
+}
+
+// Migrations returns the migrations embedded from db/migrations, in order.
+func Migrations() ([]Migration, error) {
+	return LoadMigrations(migrations.FS)
+}
+
+// MigrateUp applies the embedded migrations not yet applied to db.
+func MigrateUp(ctx context.Context, db *sql.DB) ([]Migration, error) {
+	list, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	return Migrate(ctx, db, list)
+}
+
+// LoadMigrations reads the NNNN_name.up.sql files at the root of fsys, in
+// version order. Other files are ignored; two files with one version are an
+// error.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+	var list []Migration
+	seen := make(map[int]string)
+	for _, entry := range entries {
+		m := migrationFile.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || m == nil {
+			continue
+		}
+		version, err := strconv.Atoi(m[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", other, entry.Name(), version)
+		}
+		seen[version] = entry.Name()
+		body, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+		list = append(list, Migration{Version: version, Name: m[2], SQL: string(body)})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list, nil
+}
+
+// Migrate applies, in order, each of list not yet recorded in the
+// schema_migrations table, and returns those it applied. Each migration runs
+// in its own transaction together with its record, so a failed one leaves
+// the schema as the previous one left it.
+func Migrate(ctx context.Context, db *sql.DB, list []Migration) ([]Migration, error) {
+	var applied []Migration
+	err := withMigrationLock(ctx, db, func(conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range list {
+			if done[m.Version] {
+				continue
+			}
+			if err := apply(ctx, conn, m, true); err != nil {
+				return err
+			}
+			applied = append(applied, m)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Baseline records each of list up to and including version as applied
+// without running it, for a database whose schema was built by other means,
+// such as by Postgres running the files at initialization.
+func Baseline(ctx context.Context, db *sql.DB, list []Migration, version int) error {
+	return withMigrationLock(ctx, db, func(conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range list {
+			if m.Version > version || done[m.Version] {
+				continue
+			}
+			if err := apply(ctx, conn, m, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// MigrationHistory returns the migrations recorded as applied, oldest first.
+func MigrationHistory(ctx context.Context, db *sql.DB) ([]AppliedMigration, error) {
+	if err := ensureMigrationsTable(ctx, db); err != nil {
+		return nil, err
+	}
+	rows, err := db.QueryContext(ctx, `SELECT version, name, applied_at FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration history: %w", err)
+	}
+	defer rows.Close()
+	var history []AppliedMigration
+	for rows.Next() {
+		var a AppliedMigration
+		if err := rows.Scan(&a.Version, &a.Name, &a.AppliedAt); err != nil {
+			return nil, fmt.Errorf("failed to read migration history: %w", err)
+		}
+		history = append(history, a)
+	}
+	return history, rows.Err()
+}
+
+// withMigrationLock runs fn on one connection holding the migration lock.
+func withMigrationLock(ctx context.Context, db *sql.DB, fn func(*sql.Conn) error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		return fmt.Errorf("failed to take migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+
+	if err := ensureMigrationsTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func ensureMigrationsTable(ctx context.Context, db execer) error {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INT PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return nil
+}
+
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int]bool, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration history: %w", err)
+	}
+	defer rows.Close()
+	done := make(map[int]bool)
+	for rows.Next() {
+		var v int
+		if err := rows.Scan(&v); err != nil {
+			return nil, fmt.Errorf("failed to read migration history: %w", err)
+		}
+		done[v] = true
+	}
+	return done, rows.Err()
+}
+
+// apply records m as applied, first running it if run is set.
+func apply(ctx context.Context, conn *sql.Conn, m Migration, run bool) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %04d_%s: %w", m.Version, m.Name, err)
+	}
+	defer tx.Rollback()
+
+	if run {
+		if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+			return fmt.Errorf("migration %04d_%s failed: %w", m.Version, m.Name, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, m.Version, m.Name); err != nil {
+		return fmt.Errorf("failed to record migration %04d_%s: %w", m.Version, m.Name, err)
+	}
+	return tx.Commit()
+}
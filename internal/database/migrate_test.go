@@ -0,0 +1,48 @@
+package database
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0010_events_index.up.sql": {Data: []byte("CREATE INDEX a ON events (id);")},
+		"0002_holds.up.sql":        {Data: []byte("CREATE TABLE holds ();")},
+		"0001_initial.up.sql":      {Data: []byte("CREATE TABLE items ();")},
+		"seed.sql":                 {Data: []byte("INSERT INTO items DEFAULT VALUES;")},
+		"migrations.go":            {Data: []byte("package migrations")},
+	}
+
+	list, err := LoadMigrations(fsys)
+	require.NoError(t, err)
+	assert.Equal(t, []Migration{
+		{Version: 1, Name: "initial", SQL: "CREATE TABLE items ();"},
+		{Version: 2, Name: "holds", SQL: "CREATE TABLE holds ();"},
+		{Version: 10, Name: "events_index", SQL: "CREATE INDEX a ON events (id);"},
+	}, list)
+}
+
+func TestLoadMigrationsRejectsDuplicateVersions(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0003_fines.up.sql":   {Data: []byte("SELECT 1;")},
+		"0003_reports.up.sql": {Data: []byte("SELECT 1;")},
+		"0001_initial.up.sql": {Data: []byte("SELECT 1;")},
+	}
+
+	_, err := LoadMigrations(fsys)
+	assert.ErrorContains(t, err, "share version 3")
+}
+
+func TestEmbeddedMigrationsAreNumberedInOrder(t *testing.T) {
+	list, err := Migrations()
+	require.NoError(t, err)
+	require.NotEmpty(t, list)
+	for i, m := range list {
+		assert.Equal(t, i+1, m.Version, "migration %s leaves a gap", m.Name)
+		assert.NotEmpty(t, m.SQL)
+	}
+}
@@ -0,0 +1,154 @@
+// internal/export/export.go
+
+// Package export streams query results to HTTP clients as CSV or JSON,
+// one row at a time, so an export's size is bounded by the client rather
+// than by the service's memory.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"time"
+)
+
+// Format is an export's encoding.
+type Format string
+
+const (
+	CSV  Format = "csv"
+	JSON Format = "json"
+)
+
+// ErrUnknownFormat is returned by ParseFormat for formats other than csv and
+// json.
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// ParseFormat reads a format query parameter, defaulting to CSV.
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case "", CSV:
+		return CSV, nil
+	case JSON:
+		return JSON, nil
+	}
+	return "", fmt.Errorf("%w %q", ErrUnknownFormat, s)
+}
+
+// ParseSince reads a since query parameter, an RFC 3339 time. Empty means
+// the zero time, which exports everything.
+func ParseSince(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// Row is one exported record. JSON exports encode the row itself; CSV
+// exports write its CSVRecord, whose fields must line up with the columns
+// the Writer was created with.
+type Row interface {
+	CSVRecord() []string
+}
+
+// Writer streams rows to a response. Nothing is written until the first row
+// or Close, so a query that fails before producing anything can still be
+// answered with an error status.
+type Writer struct {
+	w        http.ResponseWriter
+	format   Format
+	filename string
+	columns  []string
+
+	started bool
+	csv     *csv.Writer
+	json    *json.Encoder
+	rows    int
+}
+
+// NewWriter creates a writer offering the export as a download named
+// filename, with the extension for format added.
+func NewWriter(w http.ResponseWriter, format Format, filename string, columns []string) *Writer {
+	return &Writer{w: w, format: format, filename: filename, columns: columns}
+}
+
+// Started reports whether the response has begun, after which errors can
+// no longer change its status.
+func (x *Writer) Started() bool {
+	return x.started
+}
+
+func (x *Writer) start() error {
+	x.started = true
+	contentType := "text/csv; charset=utf-8"
+	if x.format == JSON {
+		contentType = "application/json"
+	}
+	x.w.Header().Set("Content-Type", contentType)
+	x.w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": x.filename + "." + string(x.format),
+	}))
+	x.w.WriteHeader(http.StatusOK)
+
+	if x.format == JSON {
+		x.json = json.NewEncoder(x.w)
+		_, err := x.w.Write([]byte("["))
+		return err
+	}
+	x.csv = csv.NewWriter(x.w)
+	return x.csv.Write(x.columns)
+}
+
+// Write adds one row to the export. The CSV writer buffers a few kilobytes
+// before passing them on, so memory stays flat however many rows follow.
+func (x *Writer) Write(row Row) error {
+	if !x.started {
+		if err := x.start(); err != nil {
+			return err
+		}
+	}
+	x.rows++
+	if x.format == JSON {
+		if x.rows > 1 {
+			if _, err := x.w.Write([]byte(",")); err != nil {
+				return err
+			}
+		}
+		return x.json.Encode(row)
+	}
+	return x.csv.Write(row.CSVRecord())
+}
+
+// Close finishes the export, writing just the header or an empty array if
+// there were no rows.
+func (x *Writer) Close() error {
+	if !x.started {
+		if err := x.start(); err != nil {
+			return err
+		}
+	}
+	if x.format == JSON {
+		_, err := x.w.Write([]byte("]\n"))
+		return err
+	}
+	x.csv.Flush()
+	return x.csv.Error()
+}
+
+// Abort ends a response that failed after it started. Closing the
+// connection without finishing the body tells the client the export is
+// incomplete, where a well-formed but truncated file would not.
+func (x *Writer) Abort() {
+	panic(http.ErrAbortHandler)
+}
+
+// Time formats a time for a CSV field, leaving the zero time empty.
+func Time(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,81 @@
+package export
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type book struct {
+	Title string `json:"title"`
+	Year  int    `json:"year"`
+}
+
+func (b book) CSVRecord() []string {
+	return []string{b.Title, strconv.Itoa(b.Year)}
+}
+
+var columns = []string{"title", "year"}
+
+func TestParseFormat(t *testing.T) {
+	for in, want := range map[string]Format{"": CSV, "csv": CSV, "json": JSON} {
+		got, err := ParseFormat(in)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	_, err := ParseFormat("xlsx")
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestWriterStreamsEscapedCSV(t *testing.T) {
+	rec := httptest.NewRecorder()
+	out := NewWriter(rec, CSV, "catalog", columns)
+	assert.False(t, out.Started())
+
+	require.NoError(t, out.Write(book{"Pride and Prejudice", 1813}))
+	require.NoError(t, out.Write(book{"Crime, \"Punishment\"\nand more", 1866}))
+	require.NoError(t, out.Close())
+
+	assert.True(t, out.Started())
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "attachment; filename=catalog.csv", rec.Header().Get("Content-Disposition"))
+	assert.Equal(t, "title,year\nPride and Prejudice,1813\n\"Crime, \"\"Punishment\"\"\nand more\",1866\n", rec.Body.String())
+}
+
+func TestWriterStreamsJSONArray(t *testing.T) {
+	rec := httptest.NewRecorder()
+	out := NewWriter(rec, JSON, "catalog", columns)
+	require.NoError(t, out.Write(book{"Emma", 1815}))
+	require.NoError(t, out.Write(book{"Persuasion", 1817}))
+	require.NoError(t, out.Close())
+
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "attachment; filename=catalog.json", rec.Header().Get("Content-Disposition"))
+	var got []book
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Equal(t, []book{{"Emma", 1815}, {"Persuasion", 1817}}, got)
+}
+
+func TestWriterCloseWithoutRows(t *testing.T) {
+	rec := httptest.NewRecorder()
+	require.NoError(t, NewWriter(rec, CSV, "catalog", columns).Close())
+	assert.Equal(t, "title,year\n", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	require.NoError(t, NewWriter(rec, JSON, "catalog", columns).Close())
+	var got []book
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Empty(t, got)
+}
+
+func TestTime(t *testing.T) {
+	assert.Empty(t, Time(time.Time{}))
+	assert.Equal(t, "2024-03-01T12:00:00Z", Time(time.Date(2024, 3, 1, 13, 0, 0, 0, time.FixedZone("CET", 3600))))
+}
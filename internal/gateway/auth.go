@@ -0,0 +1,105 @@
+// internal/gateway/auth.go
+package gateway
+
+import (
+	"net/http"
+	"strings"
+
+	"libranexus/internal/membership"
+)
+
+// MemberIDHeader carries the authenticated member ID to downstream services.
+const MemberIDHeader = "X-Member-ID"
+
+// MemberRoleHeader carries the authenticated member's role claim, if any.
+const MemberRoleHeader = "X-Member-Role"
+
+// TokenValidator verifies bearer tokens presented to the gateway.
+type TokenValidator interface {
+	ValidateToken(tokenString string) (*membership.Claims, error)
+}
+
+// Authenticate returns middleware that requires a valid bearer token on every
+// request except those whose path is in publicPaths. Any client-supplied
+// X-Member-ID and X-Member-Role headers are discarded and replaced with the
+// verified member ID and role, and service credentials are discarded too:
+// only services calling each other directly may present them.
+func Authenticate(validator TokenValidator, publicPaths ...string) func(http.Handler) http.Handler {
+	public := make(map[string]bool, len(publicPaths))
+	for _, p := range publicPaths {
+		public[p] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Header.Del(MemberIDHeader)
+			r.Header.Del(MemberRoleHeader)
+			r.Header.Del(membership.ServiceTokenHeader)
+			r.Header.Del(membership.ServiceNameHeader)
+
+			if public[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token, ok := bearerToken(r)
+			if !ok {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="libranexus"`)
+				http.Error(w, "missing bearer token", http.StatusUnauthorized)
+				return
+			}
+
+			claims, err := validator.ValidateToken(token)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="libranexus", error="invalid_token"`)
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+
+			r.Header.Set(MemberIDHeader, claims.MemberID.String())
+			if claims.Role != "" {
+				r.Header.Set(MemberRoleHeader, claims.Role)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireRole returns middleware that only admits requests from members
+// holding role. It must run inside Authenticate, which sets the role header
+// from the verified token and discards any the client sent.
+func RequireRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get(MemberRoleHeader) != role {
+				http.Error(w, "insufficient role", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ReadOnlyPublic sends reads (GET, HEAD and OPTIONS) to public and every
+// other request to protected, so a resource can be browsed freely while
+// changes to it are guarded.
+func ReadOnlyPublic(public, protected http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			public.ServeHTTP(w, r)
+		default:
+			protected.ServeHTTP(w, r)
+		}
+	})
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
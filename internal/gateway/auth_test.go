@@ -0,0 +1,146 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"libranexus/internal/membership"
+)
+
+func TestAuthenticate(t *testing.T) {
+	tokens, err := membership.NewHS256TokenService([]byte("test-secret"), time.Minute)
+	require.NoError(t, err)
+
+	memberID := uuid.New()
+	token, _, err := tokens.IssueToken(&membership.Member{ID: memberID})
+	require.NoError(t, err)
+
+	var seen string
+	backend := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r.Header.Get(MemberIDHeader)
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := Authenticate(tokens, "/api/v1/members/login")(backend)
+
+	tests := []struct {
+		name       string
+		path       string
+		auth       string
+		spoofed    string
+		wantStatus int
+		wantMember string
+	}{
+		{"missing token", "/api/v1/circulation/checkout", "", "", http.StatusUnauthorized, ""},
+		{"invalid token", "/api/v1/circulation/checkout", "Bearer garbage", "", http.StatusUnauthorized, ""},
+		{"valid token", "/api/v1/circulation/checkout", "Bearer " + token, "", http.StatusOK, memberID.String()},
+		{"spoofed header replaced", "/api/v1/circulation/checkout", "Bearer " + token, uuid.NewString(), http.StatusOK, memberID.String()},
+		{"public route", "/api/v1/members/login", "", "", http.StatusOK, ""},
+		{"public route strips spoofed header", "/api/v1/members/login", "", uuid.NewString(), http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen = ""
+			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			if tt.spoofed != "" {
+				req.Header.Set(MemberIDHeader, tt.spoofed)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantMember, seen)
+		})
+	}
+}
+
+func TestAuthenticateForwardsRoleClaim(t *testing.T) {
+	tokens, err := membership.NewHS256TokenService([]byte("test-secret"), time.Minute)
+	require.NoError(t, err)
+
+	admin, _, err := tokens.IssueToken(&membership.Member{ID: uuid.New(), Role: membership.RoleAdmin})
+	require.NoError(t, err)
+	member, _, err := tokens.IssueToken(&membership.Member{ID: uuid.New()})
+	require.NoError(t, err)
+
+	var seen string
+	handler := Authenticate(tokens)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r.Header.Get(MemberRoleHeader)
+	}))
+
+	serve := func(token string) string {
+		seen = ""
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/members/x/suspend", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set(MemberRoleHeader, membership.RoleAdmin)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		return seen
+	}
+
+	assert.Equal(t, membership.RoleAdmin, serve(admin))
+	assert.Empty(t, serve(member), "a spoofed role header must not survive")
+}
+
+func TestRequireRoleGuardsCatalogWrites(t *testing.T) {
+	tokens, err := membership.NewHS256TokenService([]byte("test-secret"), time.Minute)
+	require.NoError(t, err)
+	admin, _, err := tokens.IssueToken(&membership.Member{ID: uuid.New(), Role: membership.RoleAdmin})
+	require.NoError(t, err)
+	member, _, err := tokens.IssueToken(&membership.Member{ID: uuid.New(), Role: membership.RoleMember})
+	require.NoError(t, err)
+
+	backend := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := ReadOnlyPublic(backend, Authenticate(tokens)(RequireRole(membership.RoleAdmin)(backend)))
+
+	tests := []struct {
+		name       string
+		method     string
+		token      string
+		wantStatus int
+	}{
+		{"anonymous read", http.MethodGet, "", http.StatusOK},
+		{"anonymous write", http.MethodPost, "", http.StatusUnauthorized},
+		{"member write", http.MethodPost, member, http.StatusForbidden},
+		{"admin write", http.MethodPatch, admin, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/v1/catalog/items", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestAuthenticateStripsServiceCredentials(t *testing.T) {
+	tokens, err := membership.NewHS256TokenService([]byte("test-secret"), time.Minute)
+	require.NoError(t, err)
+
+	var seen http.Header
+	handler := Authenticate(tokens, "/api/v1/members/login")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r.Header.Clone()
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/members/login", nil)
+	req.Header.Set(membership.ServiceTokenHeader, "s3cret")
+	req.Header.Set(membership.ServiceNameHeader, "circulation")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Empty(t, seen.Get(membership.ServiceTokenHeader))
+	assert.Empty(t, seen.Get(membership.ServiceNameHeader))
+}
@@ -0,0 +1,89 @@
+// internal/gateway/cors.go
+package gateway
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"libranexus/internal/httperr"
+)
+
+const (
+	// corsMaxAge is how long a browser may cache a preflight response.
+	corsMaxAge = 10 * time.Minute
+	// corsAllowedMethods are the methods the services serve.
+	corsAllowedMethods = "GET, HEAD, POST, PUT, PATCH, DELETE"
+	// corsAllowedHeaders are the request headers a front-end may send.
+	corsAllowedHeaders = "Authorization, Content-Type, Idempotency-Key, X-Request-ID, X-Correlation-ID"
+	// corsExposedHeaders are the response headers a front-end may read,
+	// beyond those every browser exposes.
+	corsExposedHeaders = "Retry-After, Idempotent-Replayed, Content-Disposition, X-Request-ID, X-Correlation-ID"
+)
+
+// AllowedOriginsFromEnv reads the origins allowed to call the gateway from a
+// browser from CORS_ALLOWED_ORIGINS, a comma separated list such as
+// "https://library.example.org". It returns none when the variable is unset,
+// so only clients that are not browsers can use the API.
+func AllowedOriginsFromEnv() []string {
+	var origins []string
+	for _, origin := range strings.Split(os.Getenv("CORS_ALLOWED_ORIGINS"), ",") {
+		if origin = normalizeOrigin(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+// CORS returns middleware that lets browser front-ends served from
+// allowedOrigins call the API. It answers their preflight requests itself
+// and refuses, with 403, any request a browser sends from another origin.
+// Requests with no Origin header, from clients that are not browsers, pass
+// through untouched.
+func CORS(allowedOrigins []string) func(http.Handler) http.Handler {
+	allowed := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		allowed[normalizeOrigin(origin)] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if preflight {
+				h.Add("Vary", "Access-Control-Request-Method")
+				h.Add("Vary", "Access-Control-Request-Headers")
+			}
+			if !allowed[normalizeOrigin(origin)] {
+				httperr.Error(w, http.StatusForbidden, "origin_not_allowed", "origin not allowed")
+				return
+			}
+
+			h.Set("Access-Control-Allow-Origin", origin)
+			if preflight {
+				h.Set("Access-Control-Allow-Methods", corsAllowedMethods)
+				h.Set("Access-Control-Allow-Headers", corsAllowedHeaders)
+				h.Set("Access-Control-Max-Age", strconv.Itoa(int(corsMaxAge.Seconds())))
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			h.Set("Access-Control-Expose-Headers", corsExposedHeaders)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// normalizeOrigin lower-cases an origin and drops any trailing slash, since
+// origins compare case-insensitively and are sometimes configured with one.
+func normalizeOrigin(origin string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(origin)), "/")
+}
@@ -0,0 +1,83 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORS(t *testing.T) {
+	var reached bool
+	handler := CORS([]string{"https://Library.example.org/"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	send := func(method, origin, requestMethod string) *httptest.ResponseRecorder {
+		reached = false
+		req := httptest.NewRequest(method, "/api/v1/circulation/checkout", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if requestMethod != "" {
+			req.Header.Set("Access-Control-Request-Method", requestMethod)
+			req.Header.Set("Access-Control-Request-Headers", "authorization, idempotency-key")
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("preflight from an allowed origin", func(t *testing.T) {
+		rec := send(http.MethodOptions, "https://library.example.org", http.MethodPost)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.False(t, reached, "preflights are answered at the gateway")
+		assert.Equal(t, "https://library.example.org", rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Contains(t, rec.Header().Get("Access-Control-Allow-Methods"), http.MethodPost)
+		assert.Contains(t, rec.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+		assert.Contains(t, rec.Header().Get("Access-Control-Allow-Headers"), "Idempotency-Key")
+		assert.Equal(t, "600", rec.Header().Get("Access-Control-Max-Age"))
+		assert.Contains(t, rec.Header().Values("Vary"), "Origin")
+	})
+
+	t.Run("request from an allowed origin", func(t *testing.T) {
+		rec := send(http.MethodPost, "https://library.example.org", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, reached)
+		assert.Equal(t, "https://library.example.org", rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Contains(t, rec.Header().Get("Access-Control-Expose-Headers"), "Retry-After")
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Methods"))
+	})
+
+	for _, method := range []string{http.MethodOptions, http.MethodPost} {
+		t.Run(method+" from another origin", func(t *testing.T) {
+			requestMethod := ""
+			if method == http.MethodOptions {
+				requestMethod = http.MethodPost
+			}
+			rec := send(method, "https://evil.example.com", requestMethod)
+			assert.Equal(t, http.StatusForbidden, rec.Code)
+			assert.False(t, reached)
+			assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+			assert.Equal(t, "origin_not_allowed", decodeError(t, rec).Code)
+		})
+	}
+
+	t.Run("request without an origin", func(t *testing.T) {
+		rec := send(http.MethodPost, "", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, reached)
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, rec.Header().Values("Vary"))
+	})
+}
+
+func TestAllowedOriginsFromEnv(t *testing.T) {
+	t.Setenv("CORS_ALLOWED_ORIGINS", "")
+	assert.Empty(t, AllowedOriginsFromEnv(), "no origin is allowed by default")
+
+	t.Setenv("CORS_ALLOWED_ORIGINS", " https://library.example.org/, ,http://localhost:5173")
+	assert.Equal(t, []string{"https://library.example.org", "http://localhost:5173"}, AllowedOriginsFromEnv())
+}
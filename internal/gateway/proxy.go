@@ -0,0 +1,156 @@
+// internal/gateway/proxy.go
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"libranexus/internal/httperr"
+	"libranexus/internal/logging"
+	"libranexus/internal/tracing"
+)
+
+// ProxyConfig bounds how long the gateway waits on a backend and how much
+// it forwards to one.
+type ProxyConfig struct {
+	// DialTimeout bounds connecting to a backend.
+	DialTimeout time.Duration
+	// ResponseHeaderTimeout bounds how long a backend may take to start
+	// responding once it has the whole request.
+	ResponseHeaderTimeout time.Duration
+	// RequestTimeout bounds a whole request, from arriving at the gateway to
+	// the backend finishing its response. Zero means no limit.
+	RequestTimeout time.Duration
+	// LongRequestTimeout replaces RequestTimeout and ResponseHeaderTimeout
+	// for the routes that import or export the whole catalog or loan record.
+	LongRequestTimeout time.Duration
+	// MaxBodyBytes bounds a request body. Backends apply tighter limits of
+	// their own; this one stops the largest bodies at the edge.
+	MaxBodyBytes int64
+}
+
+// DefaultProxyConfig returns the limits the gateway applies unless
+// configured otherwise. The body limit matches the largest a backend
+// accepts, a catalog bulk import.
+func DefaultProxyConfig() ProxyConfig {
+	return ProxyConfig{
+		DialTimeout:           5 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+		RequestTimeout:        60 * time.Second,
+		LongRequestTimeout:    15 * time.Minute,
+		MaxBodyBytes:          64 << 20,
+	}
+}
+
+// ProxyConfigFromEnv reads the proxy limits from GATEWAY_DIAL_TIMEOUT,
+// GATEWAY_RESPONSE_HEADER_TIMEOUT, GATEWAY_REQUEST_TIMEOUT,
+// GATEWAY_LONG_REQUEST_TIMEOUT and GATEWAY_MAX_BODY_BYTES, falling back to
+// DefaultProxyConfig for any that are unset.
+func ProxyConfigFromEnv() (ProxyConfig, error) {
+	cfg := DefaultProxyConfig()
+	for _, d := range []struct {
+		name string
+		dst  *time.Duration
+	}{
+		{"GATEWAY_DIAL_TIMEOUT", &cfg.DialTimeout},
+		{"GATEWAY_RESPONSE_HEADER_TIMEOUT", &cfg.ResponseHeaderTimeout},
+		{"GATEWAY_REQUEST_TIMEOUT", &cfg.RequestTimeout},
+		{"GATEWAY_LONG_REQUEST_TIMEOUT", &cfg.LongRequestTimeout},
+	} {
+		v := os.Getenv(d.name)
+		if v == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(v)
+		if err != nil || parsed < 0 {
+			return ProxyConfig{}, fmt.Errorf("invalid %s: %q", d.name, v)
+		}
+		*d.dst = parsed
+	}
+	if v := os.Getenv("GATEWAY_MAX_BODY_BYTES"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			return ProxyConfig{}, fmt.Errorf("invalid GATEWAY_MAX_BODY_BYTES: %q", v)
+		}
+		cfg.MaxBodyBytes = n
+	}
+	return cfg, nil
+}
+
+// Long returns the config for routes that may legitimately run for minutes,
+// such as bulk imports and exports.
+func (c ProxyConfig) Long() ProxyConfig {
+	c.RequestTimeout = c.LongRequestTimeout
+	c.ResponseHeaderTimeout = c.LongRequestTimeout
+	return c
+}
+
+// NewProxy returns a handler forwarding requests to target within cfg's
+// limits. A body over the limit is refused with 413, and a backend that
+// cannot be reached or fails to answer in time gets a JSON 502 or 504.
+func NewProxy(target *url.URL, cfg ProxyConfig) http.Handler {
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.Transport = tracing.Transport(&http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   cfg.DialTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+		ResponseHeaderTimeout: cfg.ResponseHeaderTimeout,
+	})
+	proxy.ErrorHandler = proxyError
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > cfg.MaxBodyBytes {
+			bodyTooLarge(w, cfg.MaxBodyBytes)
+			return
+		}
+		if r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, cfg.MaxBodyBytes)
+		}
+		if cfg.RequestTimeout > 0 {
+			ctx, cancel := context.WithTimeout(r.Context(), cfg.RequestTimeout)
+			defer cancel()
+			r = r.WithContext(ctx)
+		}
+		proxy.ServeHTTP(w, r)
+	})
+}
+
+// proxyError answers a request the backend did not, in place of the
+// reverse proxy's plain-text 502.
+func proxyError(w http.ResponseWriter, r *http.Request, err error) {
+	var tooLarge *http.MaxBytesError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &tooLarge):
+		bodyTooLarge(w, tooLarge.Limit)
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		logging.FromContext(r.Context()).Warn("backend timed out", "path", r.URL.Path, "err", err)
+		httperr.Error(w, http.StatusGatewayTimeout, "gateway_timeout", "the service did not respond in time")
+	case errors.Is(err, context.Canceled):
+		// The client went away; nobody is left to read a response.
+		w.WriteHeader(http.StatusBadGateway)
+	default:
+		logging.FromContext(r.Context()).Warn("backend unavailable", "path", r.URL.Path, "err", err)
+		httperr.Error(w, http.StatusBadGateway, "bad_gateway", "the service is unavailable")
+	}
+}
+
+func bodyTooLarge(w http.ResponseWriter, limit int64) {
+	httperr.Error(w, http.StatusRequestEntityTooLarge, "body_too_large", fmt.Sprintf("request body exceeds %d bytes", limit))
+}
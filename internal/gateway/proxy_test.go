@@ -0,0 +1,137 @@
+package gateway
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"libranexus/internal/httperr"
+)
+
+func proxyTo(t *testing.T, backend http.Handler, cfg ProxyConfig) http.Handler {
+	t.Helper()
+	srv := httptest.NewServer(backend)
+	t.Cleanup(srv.Close)
+	target, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	return NewProxy(target, cfg)
+}
+
+func decodeError(t *testing.T, rec *httptest.ResponseRecorder) httperr.Response {
+	t.Helper()
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var resp httperr.Response
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return resp
+}
+
+func TestProxyForwards(t *testing.T) {
+	proxy := proxyTo(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(append([]byte(r.URL.Path+" "), body...))
+	}), DefaultProxyConfig())
+
+	rec := httptest.NewRecorder()
+	proxy.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/checkout", strings.NewReader("hello")))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "/checkout hello", rec.Body.String())
+}
+
+func TestProxyTimesOut(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+
+	for name, cfg := range map[string]ProxyConfig{
+		"request deadline":        {RequestTimeout: 50 * time.Millisecond, MaxBodyBytes: 1 << 10},
+		"response header timeout": {ResponseHeaderTimeout: 50 * time.Millisecond, MaxBodyBytes: 1 << 10},
+	} {
+		t.Run(name, func(t *testing.T) {
+			proxy := proxyTo(t, slow, cfg)
+			rec := httptest.NewRecorder()
+			proxy.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items", nil))
+
+			assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+			assert.Equal(t, "gateway_timeout", decodeError(t, rec).Code)
+		})
+	}
+}
+
+func TestProxyBackendDown(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	target, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	srv.Close()
+
+	rec := httptest.NewRecorder()
+	NewProxy(target, DefaultProxyConfig()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items", nil))
+
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+	assert.Equal(t, "bad_gateway", decodeError(t, rec).Code)
+}
+
+func TestProxyLimitsBodySize(t *testing.T) {
+	var reached atomic.Bool
+	proxy := proxyTo(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached.Store(true)
+		io.Copy(io.Discard, r.Body)
+	}), ProxyConfig{MaxBodyBytes: 8})
+
+	t.Run("declared length", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		proxy.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/items", strings.NewReader("far too long")))
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		assert.Equal(t, "body_too_large", decodeError(t, rec).Code)
+		assert.False(t, reached.Load(), "an oversized body should not be forwarded")
+	})
+
+	t.Run("chunked", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader("far too long"))
+		req.ContentLength = -1
+		rec := httptest.NewRecorder()
+		proxy.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		assert.Equal(t, "body_too_large", decodeError(t, rec).Code)
+	})
+
+	t.Run("within limit", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		proxy.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/items", strings.NewReader("short")))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func TestProxyConfigFromEnv(t *testing.T) {
+	t.Setenv("GATEWAY_REQUEST_TIMEOUT", "5s")
+	t.Setenv("GATEWAY_MAX_BODY_BYTES", "1024")
+	cfg, err := ProxyConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, cfg.RequestTimeout)
+	assert.Equal(t, int64(1024), cfg.MaxBodyBytes)
+	assert.Equal(t, DefaultProxyConfig().DialTimeout, cfg.DialTimeout)
+
+	long := cfg.Long()
+	assert.Equal(t, cfg.LongRequestTimeout, long.RequestTimeout)
+	assert.Equal(t, cfg.LongRequestTimeout, long.ResponseHeaderTimeout)
+
+	t.Setenv("GATEWAY_DIAL_TIMEOUT", "soon")
+	_, err = ProxyConfigFromEnv()
+	assert.Error(t, err)
+}
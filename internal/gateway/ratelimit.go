@@ -0,0 +1,258 @@
+// internal/gateway/ratelimit.go
+package gateway
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"libranexus/internal/httperr"
+	"libranexus/internal/membership"
+)
+
+// ClientLimits bounds how hard a single client IP can use the gateway. The
+// services keep their own, tighter budgets for logins and registrations;
+// these protect every route, including the catalog.
+type ClientLimits struct {
+	// Rate refills each client's token bucket at Rate.Requests per Rate.Per.
+	Rate membership.RateLimit
+	// Burst is how many requests a client may make at once after being idle.
+	Burst int
+	// MaxConcurrent caps a client's requests in flight. Zero means no cap.
+	MaxConcurrent int
+	// Allowlist holds the networks, such as internal monitoring, that no
+	// limit applies to.
+	Allowlist []*net.IPNet
+	// TrustForwardedFor takes the client IP from the last X-Forwarded-For
+	// entry, for a gateway behind a load balancer that appends it. Otherwise
+	// the connection's remote address is used.
+	TrustForwardedFor bool
+}
+
+// DefaultClientLimits returns the limits applied unless configured
+// otherwise: ten requests a second with bursts of 100, and at most 32 in
+// flight.
+func DefaultClientLimits() ClientLimits {
+	return ClientLimits{
+		Rate:          membership.RateLimit{Requests: 600, Per: time.Minute},
+		Burst:         100,
+		MaxConcurrent: 32,
+	}
+}
+
+// ClientLimitsFromEnv reads the limits from GATEWAY_RATE_LIMIT (requests per
+// period, such as "600/1m"), GATEWAY_RATE_BURST,
+// GATEWAY_MAX_CONCURRENT_PER_IP, GATEWAY_RATE_LIMIT_ALLOWLIST (a comma
+// separated list of IPs and CIDR blocks) and GATEWAY_TRUST_FORWARDED_FOR,
+// falling back to DefaultClientLimits for any that are unset.
+func ClientLimitsFromEnv() (ClientLimits, error) {
+	limits := DefaultClientLimits()
+	if v := os.Getenv("GATEWAY_RATE_LIMIT"); v != "" {
+		l, err := membership.ParseRateLimit(v)
+		if err != nil {
+			return ClientLimits{}, fmt.Errorf("invalid GATEWAY_RATE_LIMIT: %w", err)
+		}
+		limits.Rate = l
+	}
+	if v := os.Getenv("GATEWAY_RATE_BURST"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return ClientLimits{}, fmt.Errorf("invalid GATEWAY_RATE_BURST: %q", v)
+		}
+		limits.Burst = n
+	}
+	if v := os.Getenv("GATEWAY_MAX_CONCURRENT_PER_IP"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return ClientLimits{}, fmt.Errorf("invalid GATEWAY_MAX_CONCURRENT_PER_IP: %q", v)
+		}
+		limits.MaxConcurrent = n
+	}
+	if v := os.Getenv("GATEWAY_RATE_LIMIT_ALLOWLIST"); v != "" {
+		allowlist, err := ParseAllowlist(v)
+		if err != nil {
+			return ClientLimits{}, fmt.Errorf("invalid GATEWAY_RATE_LIMIT_ALLOWLIST: %w", err)
+		}
+		limits.Allowlist = allowlist
+	}
+	if v := os.Getenv("GATEWAY_TRUST_FORWARDED_FOR"); v != "" {
+		trust, err := strconv.ParseBool(v)
+		if err != nil {
+			return ClientLimits{}, fmt.Errorf("invalid GATEWAY_TRUST_FORWARDED_FOR: %q", v)
+		}
+		limits.TrustForwardedFor = trust
+	}
+	return limits, nil
+}
+
+// ParseAllowlist parses a comma separated list of IP addresses and CIDR
+// blocks. A bare address allows only itself.
+func ParseAllowlist(s string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("%q is not an IP address or CIDR block", entry)
+			}
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			entry = fmt.Sprintf("%s/%d", entry, bits)
+		}
+		_, n, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an IP address or CIDR block", entry)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// LimitClients returns middleware that holds each client IP to limits,
+// answering 429 with Retry-After once a client has used up its budget or
+// has too many requests in flight.
+func LimitClients(limits ClientLimits) func(http.Handler) http.Handler {
+	l := newClientLimiter(limits)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := requestIP(r, limits.TrustForwardedFor)
+			if l.allowlisted(ip) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			release, retryAfter, ok := l.acquire(ip.String())
+			if !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				httperr.Error(w, http.StatusTooManyRequests, "rate_limited", "too many requests, retry later")
+				return
+			}
+			defer release()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientLimiter gives every client IP its own token bucket and count of
+// requests in flight. Clients idle long enough for their bucket to refill,
+// with nothing in flight, are indistinguishable from new ones and are
+// evicted.
+type clientLimiter struct {
+	limit         rate.Limit
+	burst         int
+	maxConcurrent int
+	allowlist     []*net.IPNet
+	idle          time.Duration
+	now           func() time.Time
+
+	mu        sync.Mutex
+	clients   map[string]*client
+	lastSweep time.Time
+}
+
+type client struct {
+	limiter  *rate.Limiter
+	inFlight int
+	lastSeen time.Time
+}
+
+func newClientLimiter(limits ClientLimits) *clientLimiter {
+	burst := limits.Burst
+	if burst <= 0 {
+		burst = limits.Rate.Requests
+	}
+	every := limits.Rate.Per / time.Duration(limits.Rate.Requests)
+	return &clientLimiter{
+		limit:         rate.Every(every),
+		burst:         burst,
+		maxConcurrent: limits.MaxConcurrent,
+		allowlist:     limits.Allowlist,
+		idle:          every * time.Duration(burst),
+		now:           time.Now,
+		clients:       make(map[string]*client),
+	}
+}
+
+func (l *clientLimiter) allowlisted(ip net.IP) bool {
+	for _, n := range l.allowlist {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// acquire admits a request from key, returning a func to call once it has
+// been served, or reports how long the client should wait before retrying.
+func (l *clientLimiter) acquire(key string) (release func(), retryAfter time.Duration, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= l.idle {
+		l.sweep(now)
+	}
+
+	c, found := l.clients[key]
+	if !found {
+		c = &client{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.clients[key] = c
+	}
+	c.lastSeen = now
+
+	if l.maxConcurrent > 0 && c.inFlight >= l.maxConcurrent {
+		return nil, time.Second, false
+	}
+	r := c.limiter.ReserveN(now, 1)
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		return nil, delay, false
+	}
+
+	c.inFlight++
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		c.inFlight--
+		c.lastSeen = l.now()
+	}, 0, true
+}
+
+func (l *clientLimiter) sweep(now time.Time) {
+	for key, c := range l.clients {
+		if c.inFlight == 0 && now.Sub(c.lastSeen) >= l.idle {
+			delete(l.clients, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// requestIP returns the address of the client a request came from, or nil
+// if it cannot be parsed, in which case every such request shares a budget.
+func requestIP(r *http.Request, trustForwardedFor bool) net.IP {
+	if trustForwardedFor {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			parts := strings.Split(fwd, ",")
+			return net.ParseIP(strings.TrimSpace(parts[len(parts)-1]))
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return net.ParseIP(host)
+}
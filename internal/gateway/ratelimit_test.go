@@ -0,0 +1,142 @@
+package gateway
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"libranexus/internal/membership"
+)
+
+func TestClientLimiterBudgetsEachClient(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := newClientLimiter(ClientLimits{Rate: membership.RateLimit{Requests: 60, Per: time.Minute}, Burst: 2})
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		release, _, ok := l.acquire("203.0.113.7")
+		require.True(t, ok, "request %d is within the burst", i)
+		release()
+	}
+	_, retryAfter, ok := l.acquire("203.0.113.7")
+	assert.False(t, ok)
+	assert.Equal(t, time.Second, retryAfter)
+
+	_, _, ok = l.acquire("198.51.100.2")
+	assert.True(t, ok, "another client has its own budget")
+
+	now = now.Add(time.Second)
+	_, _, ok = l.acquire("203.0.113.7")
+	assert.True(t, ok, "a token is refilled after Per/Requests")
+}
+
+func TestClientLimiterCapsConcurrentRequests(t *testing.T) {
+	l := newClientLimiter(ClientLimits{Rate: membership.RateLimit{Requests: 100, Per: time.Second}, MaxConcurrent: 2})
+
+	first, _, ok := l.acquire("203.0.113.7")
+	require.True(t, ok)
+	_, _, ok = l.acquire("203.0.113.7")
+	require.True(t, ok)
+	_, _, ok = l.acquire("203.0.113.7")
+	assert.False(t, ok, "a third request in flight is over the cap")
+
+	first()
+	_, _, ok = l.acquire("203.0.113.7")
+	assert.True(t, ok, "finishing a request frees a slot")
+}
+
+func TestClientLimiterEvictsIdleClients(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := newClientLimiter(ClientLimits{Rate: membership.RateLimit{Requests: 5, Per: time.Minute}})
+	l.now = func() time.Time { return now }
+
+	busy, _, _ := l.acquire("a")
+	for _, key := range []string{"b", "c"} {
+		release, _, _ := l.acquire(key)
+		release()
+	}
+	assert.Len(t, l.clients, 3)
+
+	now = now.Add(time.Minute)
+	l.acquire("d")
+	assert.Len(t, l.clients, 2, "idle clients are evicted, ones with requests in flight are kept")
+	busy()
+}
+
+func TestLimitClients(t *testing.T) {
+	allowlist, err := ParseAllowlist("10.0.0.0/8, 192.0.2.1")
+	require.NoError(t, err)
+	limits := ClientLimits{Rate: membership.RateLimit{Requests: 1, Per: time.Minute}, Allowlist: allowlist}
+	handler := LimitClients(limits)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	send := func(remoteAddr, forwardedFor string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/catalog/items", nil)
+		req.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, send("203.0.113.7:5000", "").Code)
+	rec := send("203.0.113.7:5001", "")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+	assert.Equal(t, "rate_limited", decodeError(t, rec).Code)
+
+	assert.Equal(t, http.StatusTooManyRequests, send("203.0.113.7:5002", "198.51.100.9").Code,
+		"X-Forwarded-For is ignored unless trusted")
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, send("10.1.2.3:5000", "").Code, "allowlisted networks are not limited")
+		assert.Equal(t, http.StatusOK, send("192.0.2.1:5000", "").Code)
+	}
+}
+
+func TestRequestIP(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.5:41234"
+	r.Header.Set("X-Forwarded-For", "1.2.3.4, 203.0.113.7")
+
+	assert.Equal(t, net.ParseIP("10.0.0.5"), requestIP(r, false))
+	assert.Equal(t, net.ParseIP("203.0.113.7"), requestIP(r, true), "only the entry the load balancer appended is trusted")
+}
+
+func TestParseAllowlist(t *testing.T) {
+	nets, err := ParseAllowlist("10.0.0.0/8,2001:db8::1, ")
+	require.NoError(t, err)
+	require.Len(t, nets, 2)
+	assert.True(t, nets[0].Contains(net.ParseIP("10.200.0.1")))
+	assert.True(t, nets[1].Contains(net.ParseIP("2001:db8::1")))
+	assert.False(t, nets[1].Contains(net.ParseIP("2001:db8::2")))
+
+	for _, s := range []string{"localhost", "10.0.0.0/33"} {
+		_, err := ParseAllowlist(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestClientLimitsFromEnv(t *testing.T) {
+	t.Setenv("GATEWAY_RATE_LIMIT", "30/1s")
+	t.Setenv("GATEWAY_MAX_CONCURRENT_PER_IP", "0")
+	t.Setenv("GATEWAY_RATE_LIMIT_ALLOWLIST", "127.0.0.1")
+	limits, err := ClientLimitsFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, membership.RateLimit{Requests: 30, Per: time.Second}, limits.Rate)
+	assert.Equal(t, DefaultClientLimits().Burst, limits.Burst)
+	assert.Equal(t, 0, limits.MaxConcurrent)
+	assert.Len(t, limits.Allowlist, 1)
+
+	t.Setenv("GATEWAY_RATE_BURST", "-1")
+	_, err = ClientLimitsFromEnv()
+	assert.Error(t, err)
+}
@@ -0,0 +1,116 @@
+// internal/httperr/decode.go
+package httperr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// MaxBodyBytes bounds the JSON request bodies DecodeJSON and ReadJSON read.
+const MaxBodyBytes = 1 << 20
+
+var (
+	// errEmptyBody reports a request with no body where one is required.
+	errEmptyBody = errors.New("request body is empty")
+	// errTrailingData reports a body holding more than one JSON value.
+	errTrailingData = errors.New("request body must hold a single JSON value")
+)
+
+// DecodeJSON decodes r's body, a single JSON value of at most MaxBodyBytes,
+// into v, refusing fields v does not have. If the body does not decode it
+// responds 400, or 413 for a body over the limit, saying what was wrong, and
+// returns false.
+func DecodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	_, ok := ReadJSON(w, r, v)
+	return ok
+}
+
+// DecodeOptionalJSON is DecodeJSON for a body that may be left out, in
+// which case v is left as it is.
+func DecodeOptionalJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	body, ok := readBody(w, r)
+	if !ok {
+		return false
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return true
+	}
+	return unmarshal(w, body, v)
+}
+
+// ReadJSON is DecodeJSON for handlers that also need the raw body, as to
+// fingerprint a request. It returns the body read.
+func ReadJSON(w http.ResponseWriter, r *http.Request, v interface{}) ([]byte, bool) {
+	body, ok := readBody(w, r)
+	if !ok {
+		return nil, false
+	}
+	return body, unmarshal(w, body, v)
+}
+
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxBodyBytes))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		Error(w, http.StatusRequestEntityTooLarge, "body_too_large", fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit))
+		return nil, false
+	}
+	if err != nil {
+		BadRequest(w, "failed to read request body: "+err.Error())
+		return nil, false
+	}
+	return body, true
+}
+
+func unmarshal(w http.ResponseWriter, body []byte, v interface{}) bool {
+	if err := StrictUnmarshal(body, v); err != nil {
+		BadRequest(w, DescribeDecodeError(err))
+		return false
+	}
+	return true
+}
+
+// StrictUnmarshal decodes data, which must hold exactly one JSON value, into
+// v, refusing fields v does not have.
+func StrictUnmarshal(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		if err == io.EOF {
+			return errEmptyBody
+		}
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errTrailingData
+	}
+	return nil
+}
+
+// DescribeDecodeError says what was wrong with a JSON body that failed to
+// decode, naming the offending field where there is one.
+func DescribeDecodeError(err error) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, errEmptyBody), errors.Is(err, errTrailingData):
+		return err.Error()
+	case errors.As(err, &syntaxErr):
+		return fmt.Sprintf("malformed JSON at byte %d", syntaxErr.Offset)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return "malformed JSON: body ends unexpectedly"
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return fmt.Sprintf("invalid value for field %q: expected %s", typeErr.Field, typeErr.Type)
+	case errors.As(err, &typeErr):
+		return fmt.Sprintf("invalid JSON value: expected %s", typeErr.Type)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json reports unknown fields with no error type of their own.
+		return "unknown field " + strings.TrimPrefix(err.Error(), "json: unknown field ")
+	}
+	return "invalid request body: " + err.Error()
+}
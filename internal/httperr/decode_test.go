@@ -0,0 +1,65 @@
+package httperr
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type widgetRequest struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		want       Response
+	}{
+		{"valid", `{"name":"gear","count":2}`, http.StatusOK, Response{}},
+		{"unknown field", `{"name":"gear","colour":"red"}`, http.StatusBadRequest, Response{`unknown field "colour"`, "invalid_request"}},
+		{"wrong type", `{"name":"gear","count":"two"}`, http.StatusBadRequest, Response{`invalid value for field "count": expected int`, "invalid_request"}},
+		{"malformed", `{"name":}`, http.StatusBadRequest, Response{"malformed JSON at byte 9", "invalid_request"}},
+		{"truncated", `{"name":"gear"`, http.StatusBadRequest, Response{"malformed JSON: body ends unexpectedly", "invalid_request"}},
+		{"trailing value", `{"name":"gear"} {}`, http.StatusBadRequest, Response{"request body must hold a single JSON value", "invalid_request"}},
+		{"empty", ``, http.StatusBadRequest, Response{"request body is empty", "invalid_request"}},
+		{"too large", `{"name":"` + strings.Repeat("x", MaxBodyBytes) + `"}`, http.StatusRequestEntityTooLarge, Response{"request body exceeds 1048576 bytes", "body_too_large"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/widgets", strings.NewReader(tt.body))
+			var v widgetRequest
+			ok := DecodeJSON(rec, req, &v)
+
+			assert.Equal(t, tt.wantStatus == http.StatusOK, ok)
+			if ok {
+				assert.Equal(t, widgetRequest{Name: "gear", Count: 2}, v)
+				return
+			}
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			var got Response
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDecodeOptionalJSONAllowsEmptyBody(t *testing.T) {
+	v := widgetRequest{Count: 1}
+	rec := httptest.NewRecorder()
+	require.True(t, DecodeOptionalJSON(rec, httptest.NewRequest(http.MethodPost, "/widgets", strings.NewReader("  \n")), &v))
+	assert.Equal(t, widgetRequest{Count: 1}, v, "an empty body should leave v as it was")
+
+	rec = httptest.NewRecorder()
+	assert.False(t, DecodeOptionalJSON(rec, httptest.NewRequest(http.MethodPost, "/widgets", strings.NewReader(`{"size":3}`)), &v))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
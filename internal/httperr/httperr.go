@@ -0,0 +1,81 @@
+// internal/httperr/httperr.go
+package httperr
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/jules-labs/go-eventstore"
+)
+
+// Response is the JSON body of every error response. Code is a stable,
+// machine-readable name for the failure; Error is for people.
+type Response struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+// Rule maps errors matching Err, as reported by errors.Is, to a response.
+type Rule struct {
+	Err    error
+	Status int
+	Code   string
+}
+
+// Rules translates a service's errors into HTTP responses.
+type Rules []Rule
+
+// Common holds the rules every service shares. They apply after a service's
+// own rules.
+var Common = Rules{
+	{Err: eventstore.ErrConcurrencyConflict, Status: http.StatusConflict, Code: "concurrency_conflict"},
+	{Err: eventstore.ErrSerializationFailure, Status: http.StatusServiceUnavailable, Code: "serialization_failure"},
+}
+
+// Write responds with the first rule err matches, then the first Common rule.
+// Errors no rule matches are logged and answered with a bare 500, so internal
+// details never reach the client.
+func (rs Rules) Write(w http.ResponseWriter, err error) {
+	for _, rules := range []Rules{rs, Common} {
+		for _, rule := range rules {
+			if errors.Is(err, rule.Err) {
+				Error(w, rule.Status, rule.Code, err.Error())
+				return
+			}
+		}
+	}
+	log.Printf("Internal error: %v", err)
+	Error(w, http.StatusInternalServerError, "internal", "internal server error")
+}
+
+// Error responds with status and a Response body.
+func Error(w http.ResponseWriter, status int, code, message string) {
+	JSON(w, status, Response{Error: message, Code: code})
+}
+
+// BadRequest responds 400 for a request the handler could not make sense of.
+func BadRequest(w http.ResponseWriter, message string) {
+	Error(w, http.StatusBadRequest, "invalid_request", message)
+}
+
+// MethodNotAllowed responds 405.
+func MethodNotAllowed(w http.ResponseWriter) {
+	Error(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+}
+
+// NotFound responds 404 for a path no handler serves.
+func NotFound(w http.ResponseWriter) {
+	Error(w, http.StatusNotFound, "not_found", "not found")
+}
+
+// JSON responds with status and v encoded as JSON. Error responses that carry
+// more than a Response, such as per-field validation messages, embed
+// Response in their own body type and write it with JSON.
+func JSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,45 @@
+package httperr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jules-labs/go-eventstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errWidgetMissing = errors.New("widget not found")
+
+func TestRulesWrite(t *testing.T) {
+	rules := Rules{{errWidgetMissing, http.StatusNotFound, "widget_not_found"}}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		want       Response
+	}{
+		{"own rule", fmt.Errorf("lookup: %w", errWidgetMissing), http.StatusNotFound, Response{"lookup: widget not found", "widget_not_found"}},
+		{"common rule", fmt.Errorf("append: %w", eventstore.ErrConcurrencyConflict), http.StatusConflict, Response{"append: concurrency conflict: version mismatch", "concurrency_conflict"}},
+		{"serialization failure", eventstore.ErrSerializationFailure, http.StatusServiceUnavailable, Response{eventstore.ErrSerializationFailure.Error(), "serialization_failure"}},
+		{"unmatched", errors.New("pq: connection refused"), http.StatusInternalServerError, Response{"internal server error", "internal"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			rules.Write(rec, tt.err)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			var got Response
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
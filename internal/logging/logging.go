@@ -0,0 +1,58 @@
+// internal/logging/logging.go
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/jules-labs/go-eventstore"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// New returns a JSON logger for the named service, writing to stdout at the
+// level in LOG_LEVEL (debug, info, warn or error; info by default).
+func New(service string) *slog.Logger {
+	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: levelFromEnv()})
+	return slog.New(handler).With("service", service)
+}
+
+// Setup installs New(service) as the default logger. Output from the
+// standard log package is routed through it too, as info-level records.
+func Setup(service string) *slog.Logger {
+	logger := New(service)
+	slog.SetDefault(logger)
+	return logger
+}
+
+// FromContext returns the default logger annotated with the request,
+// correlation and trace IDs carried on ctx, so lines logged while serving a
+// request can be matched to its access log line, its trace and the events it
+// wrote.
+func FromContext(ctx context.Context) *slog.Logger {
+	logger := slog.Default()
+	if id := eventstore.RequestIDFromContext(ctx); id != "" {
+		logger = logger.With("request_id", id)
+	}
+	if id := eventstore.CorrelationIDFromContext(ctx); id != "" {
+		logger = logger.With("correlation_id", id)
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		logger = logger.With("trace_id", sc.TraceID().String())
+	}
+	return logger
+}
+
+func levelFromEnv() slog.Level {
+	switch strings.ToLower(os.Getenv("LOG_LEVEL")) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
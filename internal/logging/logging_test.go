@@ -0,0 +1,41 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/jules-labs/go-eventstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromContext(t *testing.T) {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	defer slog.SetDefault(prev)
+
+	ctx := eventstore.WithRequestID(context.Background(), "req-1")
+	ctx = eventstore.WithCorrelationID(ctx, "corr-1")
+	FromContext(ctx).Info("hello")
+
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "req-1", line["request_id"])
+	assert.Equal(t, "corr-1", line["correlation_id"])
+}
+
+func TestLevelFromEnv(t *testing.T) {
+	for value, want := range map[string]slog.Level{
+		"":      slog.LevelInfo,
+		"debug": slog.LevelDebug,
+		"WARN":  slog.LevelWarn,
+		"error": slog.LevelError,
+	} {
+		t.Setenv("LOG_LEVEL", value)
+		assert.Equal(t, want, levelFromEnv(), value)
+	}
+}
@@ -2,52 +2,200 @@
 package membership
 
 import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+var (
+	ErrInvalidCredentials     = errors.New("authentication failed: invalid credentials")
+	ErrAccountLocked          = errors.New("authentication failed: account locked")
+	ErrInvalidPaymentAmount   = errors.New("payment amount must be positive")
+	ErrOverpayment            = errors.New("payment exceeds outstanding fine balance")
+	ErrBalanceChanged         = errors.New("fine balance changed concurrently; retry the payment")
+	ErrMFARequired            = errors.New("authentication failed: MFA code required")
+	ErrInvalidMFACode         = errors.New("authentication failed: invalid MFA code")
+	ErrMFAAlreadyEnabled      = errors.New("MFA is already enabled")
+	ErrMFANotEnabled          = errors.New("MFA is not enabled")
+	ErrUnknownTier            = errors.New("unknown membership tier")
+	ErrEmailAlreadyRegistered = errors.New("a member with this email is already registered")
+	ErrInvalidRegistration    = errors.New("invalid registration")
+	ErrInvalidResetToken      = errors.New("password reset token is invalid or expired")
+	ErrRateLimited            = errors.New("rate limit exceeded")
+	ErrMemberNotFound         = errors.New("member not found")
+	ErrInvalidEmail           = errors.New("invalid email address")
+	ErrInvalidRenewalTerm     = errors.New("renewal term must be positive")
+	ErrMemberSuspended        = errors.New("member is already suspended")
+	ErrMemberNotSuspended     = errors.New("member is not suspended")
+	ErrUnknownRole            = errors.New("unknown role")
+	ErrInvalidEventRange      = errors.New("invalid event version range")
+)
+
+// ValidationError reports which registration fields were rejected and why.
+// It matches ErrInvalidRegistration.
+type ValidationError struct {
+	Fields map[string]string `json:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	names := make([]string, 0, len(e.Fields))
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	problems := make([]string, len(names))
+	for i, name := range names {
+		problems[i] = name + ": " + e.Fields[name]
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidRegistration, strings.Join(problems, "; "))
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidRegistration
+}
+
 // Member represents a library member.
 type Member struct {
-	ID             uuid.UUID `json:"id"`
-	Email          string    `json:"email"`
-	Name           string    `json:"name"`
-	MembershipTier string    `json:"membership_tier"`
-	Status         string    `json:"status"`
-	FineBalance    float64   `json:"fine_balance"`
-	MaxCheckouts   int       `json:"max_checkouts"`
-	ExpiresAt      time.Time `json:"expires_at"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
-	Version        int       `json:"version"`
+	ID               uuid.UUID `json:"id"`
+	Email            string    `json:"email"`
+	Name             string    `json:"name"`
+	MembershipTier   string    `json:"membership_tier"`
+	Role             string    `json:"role"`
+	Status           string    `json:"status"`
+	SuspensionReason string    `json:"suspension_reason,omitempty"`
+	FineBalance      float64   `json:"fine_balance"`
+	MaxCheckouts     int       `json:"max_checkouts"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	Version          int       `json:"version"`
+}
+
+// TierChange is one entry in a member's tier history: the tier they moved
+// to, the checkout limit it came with, and when.
+type TierChange struct {
+	Tier         string    `json:"tier"`
+	MaxCheckouts int       `json:"max_checkouts"`
+	ChangedAt    time.Time `json:"changed_at"`
+	Version      int       `json:"version"`
+}
+
+// ScheduledTierChange is a tier change waiting for its effective time.
+type ScheduledTierChange struct {
+	NewTier     string    `json:"new_tier"`
+	EffectiveAt time.Time `json:"effective_at"`
+}
+
+// TierHistory is every tier a member has held, oldest first, and the change
+// scheduled next, if any.
+type TierHistory struct {
+	Changes   []TierChange         `json:"changes"`
+	Scheduled *ScheduledTierChange `json:"scheduled,omitempty"`
 }
 
 // Credential represents a member's login credentials.
 type Credential struct {
-	MemberID      uuid.UUID `json:"member_id"`
-	PasswordHash  string    `json:"-"`
-	Salt          string    `json:"-"`
-	MFAEnabled    bool      `json:"mfa_enabled"`
-	MFASecret     string    `json:"-"`
-	FailedAttempts int      `json:"-"`
-	LockedUntil   time.Time `json:"-"`
+	MemberID       uuid.UUID `json:"member_id"`
+	PasswordHash   string    `json:"-"`
+	Salt           string    `json:"-"` // only set for hashes older than the PHC format
+	MFAEnabled     bool      `json:"mfa_enabled"`
+	MFASecret      string    `json:"-"`
+	FailedAttempts int       `json:"-"`
+	LockedUntil    time.Time `json:"-"`
 }
 
 // Event represents a domain event related to a member.
 type Event struct {
-	Type string      `json:"type"`
-	Data interface{} `json:"data"`
+	Type      string    `json:"type"`
+	Version   int       `json:"version"`
+	Timestamp time.Time `json:"timestamp"`
+	// Actor is the member who caused the event, eventstore.SystemActorID
+	// for one the system wrote on its own behalf, or empty for events
+	// recorded before actors were.
+	Actor string      `json:"actor,omitempty"`
+	Data  interface{} `json:"data"`
 }
 
 // MemberRegisteredEvent is published when a new member registers.
 type MemberRegisteredEvent struct {
-	ID    uuid.UUID `json:"id"`
-	Email string    `json:"email"`
-	Name  string    `json:"name"`
+	ID           uuid.UUID `json:"id"`
+	Email        string    `json:"email"`
+	Name         string    `json:"name"`
+	MaxCheckouts int       `json:"max_checkouts,omitempty"`
 }
 
 // MemberTierChangedEvent is published when a member's tier is changed.
 type MemberTierChangedEvent struct {
-	ID      uuid.UUID `json:"id"`
-	NewTier string    `json:"new_tier"`
+	ID           uuid.UUID `json:"id"`
+	PreviousTier string    `json:"previous_tier,omitempty"`
+	NewTier      string    `json:"new_tier"`
+	MaxCheckouts int       `json:"max_checkouts,omitempty"`
+}
+
+// MembershipRenewedEvent is published when a membership is extended. Status
+// is the member's status afterwards: an expired membership becomes active.
+type MembershipRenewedEvent struct {
+	ID        uuid.UUID `json:"id"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Status    string    `json:"status"`
+}
+
+// MemberRoleChangedEvent is published when a member is given a new role.
+type MemberRoleChangedEvent struct {
+	ID   uuid.UUID `json:"id"`
+	Role string    `json:"role"`
+}
+
+// MemberSuspendedEvent is published when staff suspend a member.
+type MemberSuspendedEvent struct {
+	ID     uuid.UUID `json:"id"`
+	Reason string    `json:"reason"`
+}
+
+// MemberReactivatedEvent is published when a suspension is lifted. Status is
+// the member's status afterwards: active, or expired if the membership ran
+// out in the meantime.
+type MemberReactivatedEvent struct {
+	ID     uuid.UUID `json:"id"`
+	Status string    `json:"status"`
+}
+
+// MembershipExpiredEvent is published when a membership runs out.
+type MembershipExpiredEvent struct {
+	ID        uuid.UUID `json:"id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// FineChargedEvent is published when a fine is added to a member's balance.
+type FineChargedEvent struct {
+	ID        uuid.UUID `json:"id"`
+	Amount    float64   `json:"amount"`
+	Reason    string    `json:"reason"`
+	Reference string    `json:"reference,omitempty"`
+}
+
+// FinePaidEvent is published when a member pays down their fine balance.
+type FinePaidEvent struct {
+	ID         uuid.UUID `json:"id"`
+	Amount     float64   `json:"amount"`
+	NewBalance float64   `json:"new_balance"`
+}
+
+// MFAEnabledEvent is published when a member enrolls a TOTP second factor.
+type MFAEnabledEvent struct {
+	ID uuid.UUID `json:"id"`
+}
+
+// MFADisabledEvent is published when a member removes their second factor.
+type MFADisabledEvent struct {
+	ID uuid.UUID `json:"id"`
+}
+
+// PasswordChangedEvent is published when a member's password is replaced.
+type PasswordChangedEvent struct {
+	ID uuid.UUID `json:"id"`
 }
@@ -0,0 +1,38 @@
+// internal/membership/email.go
+package membership
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+)
+
+// NormalizeEmail is the form emails are stored and looked up in, so that
+// addresses differing only in case belong to one member.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// ValidateEmail checks that email is a bare RFC 5322 address, such as
+// ada@example.com, with a dotted domain. Display names ("Ada <ada@…>") and
+// comments are rejected: the address is stored exactly as given.
+func ValidateEmail(email string) error {
+	if email == "" {
+		return errors.New("is required")
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Name != "" || addr.Address != email {
+		return errors.New("is not a valid email address")
+	}
+	_, domain, _ := strings.Cut(addr.Address, "@")
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return errors.New("must include a domain such as example.com")
+	}
+	for _, label := range labels {
+		if label == "" {
+			return errors.New("is not a valid email address")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+// internal/membership/events.go
+package membership
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+)
+
+// GetMemberEvents returns a member's events from fromVersion to toVersion
+// inclusive, oldest first, for auditing. A zero fromVersion starts at the
+// first event and a zero toVersion runs to the latest. A range past the
+// member's latest event is empty. No member event carries a credential, so
+// the events are returned as recorded.
+func (s *service) GetMemberEvents(ctx context.Context, id uuid.UUID, fromVersion, toVersion int) ([]Event, error) {
+	if fromVersion < 0 || toVersion < 0 || (toVersion > 0 && toVersion < fromVersion) {
+		return nil, fmt.Errorf("%w: from %d to %d", ErrInvalidEventRange, fromVersion, toVersion)
+	}
+
+	// The read model, unlike the event store, knows what kind of aggregate
+	// the ID names.
+	if _, err := s.GetMember(ctx, id); err != nil {
+		return nil, err
+	}
+
+	events, err := s.eventStore.LoadEvents(ctx, id, max(fromVersion, 1), toVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load member events: %w", err)
+	}
+	return auditEvents(events), nil
+}
+
+// auditEvents presents stored events as the audit log shows them.
+func auditEvents(events []eventstore.Event) []Event {
+	out := make([]Event, len(events))
+	for i, event := range events {
+		out[i] = Event{
+			Type:      event.EventType,
+			Version:   event.Version,
+			Timestamp: event.CreatedAt,
+			Actor:     event.ActorID(),
+			Data:      event.EventData,
+		}
+	}
+	return out
+}
@@ -0,0 +1,156 @@
+// internal/membership/expiry.go
+package membership
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"libranexus/internal/database"
+	"libranexus/internal/logging"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+)
+
+// defaultMembershipTerm is how long a renewal extends a membership for when
+// no term is given.
+const defaultMembershipTerm = 365 * 24 * time.Hour
+
+// expiryBatchSize bounds how many memberships one expiry pass closes.
+const expiryBatchSize = 100
+
+// RenewMembership extends a membership by term. The term runs from the
+// current expiry date, or from now if that has already passed, and a member
+// whose membership had expired becomes active again. Suspended members stay
+// suspended.
+func (s *service) RenewMembership(ctx context.Context, id uuid.UUID, term time.Duration) (*Member, error) {
+	if term <= 0 {
+		return nil, ErrInvalidRenewalTerm
+	}
+
+	member, err := s.readMember(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	from := member.ExpiresAt
+	if from.Before(now) {
+		from = now
+	}
+	renewed := *member
+	renewed.ExpiresAt = from.Add(term)
+	if renewed.Status == "expired" {
+		renewed.Status = "active"
+	}
+	renewed.Version++
+
+	jsonData, err := json.Marshal(MembershipRenewedEvent{ID: id, ExpiresAt: renewed.ExpiresAt, Status: renewed.Status})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event data: %w", err)
+	}
+	event := eventstore.Event{
+		AggregateID:   id,
+		AggregateType: "member",
+		EventType:     "MembershipRenewed",
+		EventData:     jsonData,
+		Version:       renewed.Version,
+	}
+
+	err = database.InTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.appendMemberEventsTx(ctx, tx, id, member.Version, []eventstore.Event{event}); err != nil {
+			return fmt.Errorf("failed to append event: %w", err)
+		}
+		query := `
+			UPDATE members
+			SET expires_at = $1, status = $2, version = $3, updated_at = NOW()
+			WHERE id = $4
+		`
+		if _, err := tx.ExecContext(ctx, query, renewed.ExpiresAt, renewed.Status, renewed.Version, id); err != nil {
+			return fmt.Errorf("failed to update read model: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &renewed, nil
+}
+
+// ExpireMemberships marks active members whose membership has run out as
+// expired and returns how many it marked. A member whose record changes
+// concurrently is logged and left for the next pass.
+func (s *service) ExpireMemberships(ctx context.Context) (int, error) {
+	ctx = eventstore.WithActorID(ctx, eventstore.SystemActorID)
+	query := `
+		SELECT id, version, expires_at
+		FROM members
+		WHERE status = 'active' AND expires_at <= $1
+		ORDER BY expires_at
+		LIMIT $2
+	`
+	rows, err := s.db.QueryContext(ctx, query, s.now(), expiryBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find expired memberships: %w", err)
+	}
+	var due []*Member
+	for rows.Next() {
+		member := &Member{}
+		if err := rows.Scan(&member.ID, &member.Version, &member.ExpiresAt); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan member: %w", err)
+		}
+		due = append(due, member)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to find expired memberships: %w", err)
+	}
+
+	expired := 0
+	for _, member := range due {
+		if err := s.expireMembership(ctx, member); err != nil {
+			logging.FromContext(ctx).Warn("failed to expire membership", "member_id", member.ID, "err", err)
+			continue
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+func (s *service) expireMembership(ctx context.Context, member *Member) error {
+	jsonData, err := json.Marshal(MembershipExpiredEvent{ID: member.ID, ExpiresAt: member.ExpiresAt})
+	if err != nil {
+		return fmt.Errorf("failed to marshal event data: %w", err)
+	}
+	event := eventstore.Event{
+		AggregateID:   member.ID,
+		AggregateType: "member",
+		EventType:     "MembershipExpired",
+		EventData:     jsonData,
+		Version:       member.Version + 1,
+	}
+
+	return database.InTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.appendMemberEventsTx(ctx, tx, member.ID, member.Version, []eventstore.Event{event}); err != nil {
+			return fmt.Errorf("failed to append event: %w", err)
+		}
+		// The status check keeps a renewal that landed after the scan from
+		// being undone.
+		res, err := tx.ExecContext(ctx, `
+			UPDATE members
+			SET status = 'expired', version = $1, updated_at = NOW()
+			WHERE id = $2 AND status = 'active' AND expires_at <= $3
+		`, member.Version+1, member.ID, s.now())
+		if err != nil {
+			return fmt.Errorf("failed to update read model: %w", err)
+		}
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			return errors.New("membership changed before it could be expired")
+		}
+		return nil
+	})
+}
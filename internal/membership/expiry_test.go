@@ -0,0 +1,74 @@
+package membership
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenewMembershipRejectsNonPositiveTerm(t *testing.T) {
+	s := &service{now: time.Now}
+	_, err := s.RenewMembership(context.Background(), uuid.New(), 0)
+	assert.ErrorIs(t, err, ErrInvalidRenewalTerm)
+}
+
+type renewingService struct {
+	Service
+	terms []time.Duration
+}
+
+func (r *renewingService) RenewMembership(ctx context.Context, id uuid.UUID, term time.Duration) (*Member, error) {
+	if term <= 0 {
+		return nil, ErrInvalidRenewalTerm
+	}
+	r.terms = append(r.terms, term)
+	return &Member{ID: id, Status: "active"}, nil
+}
+
+func TestHandleRenewMembership(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantTerm   time.Duration
+	}{
+		{"default term", "", http.StatusOK, defaultMembershipTerm},
+		{"explicit term", `{"term":"720h"}`, http.StatusOK, 720 * time.Hour},
+		{"unparseable term", `{"term":"a year"}`, http.StatusBadRequest, 0},
+		{"negative term", `{"term":"-1h"}`, http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &renewingService{}
+			h := NewHandler(svc, nil)
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/members/"+uuid.NewString()+"/renew", bytes.NewBufferString(tt.body))
+			req.Header.Set(memberRoleHeader, RoleAdmin)
+			h.HandleMember(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus == http.StatusOK {
+				require.Equal(t, []time.Duration{tt.wantTerm}, svc.terms)
+				var member Member
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &member))
+				assert.Equal(t, "active", member.Status)
+			}
+		})
+	}
+}
+
+func TestHandleRenewMembershipRequiresAdmin(t *testing.T) {
+	h := NewHandler(&renewingService{}, nil)
+	rec := httptest.NewRecorder()
+	h.HandleMember(rec, httptest.NewRequest(http.MethodPost, "/members/"+uuid.NewString()+"/renew", nil))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
@@ -0,0 +1,67 @@
+// internal/membership/grpc.go
+package membership
+
+import (
+	"context"
+	"errors"
+	"libranexus/internal/rpc"
+	"libranexus/internal/rpc/membershippb"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// RegisterGRPC serves svc's member reads on s, for services that call
+// membership over gRPC instead of its JSON API.
+func RegisterGRPC(s grpc.ServiceRegistrar, svc Service) {
+	membershippb.RegisterMembershipServer(s, &grpcServer{service: svc})
+}
+
+type grpcServer struct {
+	membershippb.UnimplementedMembershipServer
+	service Service
+}
+
+func (g *grpcServer) GetMember(ctx context.Context, req *membershippb.GetMemberRequest) (*membershippb.Member, error) {
+	id, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid member ID")
+	}
+	member, err := g.service.GetMember(ctx, id)
+	if err != nil {
+		return nil, rpc.Error(err, ErrMemberNotFound)
+	}
+	return memberToProto(member), nil
+}
+
+func (g *grpcServer) GetMemberByEmail(ctx context.Context, req *membershippb.GetMemberByEmailRequest) (*membershippb.Member, error) {
+	member, err := g.service.GetMemberByEmail(ctx, req.GetEmail())
+	if errors.Is(err, ErrInvalidEmail) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, rpc.Error(err, ErrMemberNotFound)
+	}
+	return memberToProto(member), nil
+}
+
+func memberToProto(m *Member) *membershippb.Member {
+	return &membershippb.Member{
+		Id:               m.ID.String(),
+		Email:            m.Email,
+		Name:             m.Name,
+		MembershipTier:   m.MembershipTier,
+		Role:             m.Role,
+		Status:           m.Status,
+		SuspensionReason: m.SuspensionReason,
+		FineBalance:      m.FineBalance,
+		MaxCheckouts:     int32(m.MaxCheckouts),
+		ExpiresAt:        timestamppb.New(m.ExpiresAt),
+		CreatedAt:        timestamppb.New(m.CreatedAt),
+		UpdatedAt:        timestamppb.New(m.UpdatedAt),
+		Version:          int32(m.Version),
+	}
+}
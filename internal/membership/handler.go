@@ -3,69 +3,228 @@ package membership
 
 import (
 	"encoding/json"
+	"errors"
+	"libranexus/internal/httperr"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// memberIDHeader carries the authenticated member's ID, set by the gateway.
+const memberIDHeader = "X-Member-ID"
+
+// memberRoleHeader carries the authenticated member's role, set by the gateway.
+const memberRoleHeader = "X-Member-Role"
+
+// errorRules maps membership errors to HTTP responses.
+var errorRules = httperr.Rules{
+	{Err: ErrMemberNotFound, Status: http.StatusNotFound, Code: "member_not_found"},
+	{Err: ErrInvalidEventRange, Status: http.StatusBadRequest, Code: "invalid_event_range"},
+	{Err: ErrInvalidEmail, Status: http.StatusBadRequest, Code: "invalid_email"},
+	{Err: ErrInvalidCredentials, Status: http.StatusUnauthorized, Code: "invalid_credentials"},
+	{Err: ErrMFARequired, Status: http.StatusUnauthorized, Code: "mfa_required"},
+	{Err: ErrInvalidMFACode, Status: http.StatusUnauthorized, Code: "invalid_mfa_code"},
+	{Err: ErrAccountLocked, Status: http.StatusLocked, Code: "account_locked"},
+	{Err: ErrEmailAlreadyRegistered, Status: http.StatusConflict, Code: "email_already_registered"},
+	{Err: ErrInvalidResetToken, Status: http.StatusBadRequest, Code: "invalid_reset_token"},
+	{Err: ErrInvalidPaymentAmount, Status: http.StatusBadRequest, Code: "invalid_payment_amount"},
+	{Err: ErrOverpayment, Status: http.StatusBadRequest, Code: "overpayment"},
+	{Err: ErrBalanceChanged, Status: http.StatusConflict, Code: "balance_changed"},
+	{Err: ErrMFAAlreadyEnabled, Status: http.StatusConflict, Code: "mfa_already_enabled"},
+	{Err: ErrMFANotEnabled, Status: http.StatusConflict, Code: "mfa_not_enabled"},
+	{Err: ErrUnknownTier, Status: http.StatusBadRequest, Code: "unknown_tier"},
+	{Err: ErrInvalidRenewalTerm, Status: http.StatusBadRequest, Code: "invalid_renewal_term"},
+	{Err: ErrMemberSuspended, Status: http.StatusConflict, Code: "member_suspended"},
+	{Err: ErrMemberNotSuspended, Status: http.StatusConflict, Code: "member_not_suspended"},
+	{Err: ErrUnknownRole, Status: http.StatusBadRequest, Code: "unknown_role"},
+}
+
 type Handler struct {
 	service Service
+	tokens  *TokenService
 }
 
-func NewHandler(service Service) *Handler {
-	return &Handler{service: service}
+func NewHandler(service Service, tokens *TokenService) *Handler {
+	return &Handler{service: service, tokens: tokens}
 }
 
 func (h *Handler) HandleMembers(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
+	case http.MethodGet:
+		h.handleGetMemberByEmail(w, r)
 	case http.MethodPost:
 		h.handleRegisterMember(w, r)
 	default:
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		httperr.MethodNotAllowed(w)
 	}
 }
 
 func (h *Handler) HandleMember(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/members/")
+	idStr, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/members/"), "/")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		http.Error(w, "invalid member ID", http.StatusBadRequest)
+		httperr.BadRequest(w, "invalid member ID")
 		return
 	}
 
-	switch r.Method {
-	case http.MethodGet:
-		h.handleGetMember(w, r, id)
+	switch action {
+	case "":
+		switch r.Method {
+		case http.MethodGet:
+			h.handleGetMember(w, r, id)
+		default:
+			httperr.MethodNotAllowed(w)
+		}
+	case "tier-history":
+		if r.Method != http.MethodGet {
+			httperr.MethodNotAllowed(w)
+			return
+		}
+		h.handleGetTierHistory(w, r, id)
+	case "events":
+		if r.Method != http.MethodGet {
+			httperr.MethodNotAllowed(w)
+			return
+		}
+		if r.Header.Get(memberRoleHeader) != RoleAdmin {
+			httperr.Error(w, http.StatusForbidden, "forbidden", "only administrators may audit member events")
+			return
+		}
+		h.handleGetMemberEvents(w, r, id)
+	case "renew", "suspend", "reactivate", "role":
+		if r.Method != http.MethodPost {
+			httperr.MethodNotAllowed(w)
+			return
+		}
+		if r.Header.Get(memberRoleHeader) != RoleAdmin {
+			httperr.Error(w, http.StatusForbidden, "forbidden", "only administrators may manage memberships")
+			return
+		}
+		switch action {
+		case "renew":
+			h.handleRenewMembership(w, r, id)
+		case "suspend":
+			h.handleSuspendMember(w, r, id)
+		case "reactivate":
+			h.handleReactivateMember(w, r, id)
+		case "role":
+			h.handleSetMemberRole(w, r, id)
+		}
+	case "fines", "fines/payment":
+		if r.Method != http.MethodPost {
+			httperr.MethodNotAllowed(w)
+			return
+		}
+		if serviceCallerFromContext(r.Context()) == "" && r.Header.Get(memberRoleHeader) != RoleAdmin {
+			httperr.Error(w, http.StatusForbidden, "forbidden", "only administrators and services may manage fines")
+			return
+		}
+		if action == "fines" {
+			h.handleChargeFine(w, r, id)
+		} else {
+			h.handlePayFine(w, r, id)
+		}
+	case "mfa/enable", "mfa/disable":
+		if r.Method != http.MethodPost {
+			httperr.MethodNotAllowed(w)
+			return
+		}
+		if r.Header.Get(memberIDHeader) != id.String() {
+			httperr.Error(w, http.StatusForbidden, "forbidden", "members may only manage their own MFA settings")
+			return
+		}
+		if action == "mfa/enable" {
+			h.handleEnableMFA(w, r, id)
+		} else {
+			h.handleDisableMFA(w, r, id)
+		}
 	default:
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		httperr.NotFound(w)
 	}
 }
 
 func (h *Handler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		httperr.MethodNotAllowed(w)
 		return
 	}
 
 	var req struct {
 		Email    string `json:"email"`
 		Password string `json:"password"`
+		MFACode  string `json:"mfa_code"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if !httperr.DecodeJSON(w, r, &req) {
 		return
 	}
 
-	member, err := h.service.Authenticate(r.Context(), req.Email, req.Password)
+	member, err := h.service.Authenticate(r.Context(), req.Email, req.Password, req.MFACode)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		writeError(w, err)
 		return
 	}
 
-	// In a real application, you would return a JWT here.
-	json.NewEncoder(w).Encode(member)
+	token, expiresAt, err := h.tokens.IssueToken(member)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}{
+		Token:     token,
+		ExpiresAt: expiresAt,
+	})
+}
+
+// HandlePasswordResetRequest always answers 202 for a well-formed request,
+// whether or not the email belongs to a member.
+func (h *Handler) HandlePasswordResetRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httperr.MethodNotAllowed(w)
+		return
+	}
+
+	var req struct {
+		Email string `json:"email"`
+	}
+	if !httperr.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.service.RequestPasswordReset(r.Context(), req.Email); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *Handler) HandlePasswordResetConfirm(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httperr.MethodNotAllowed(w)
+		return
+	}
+
+	var req struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+	if !httperr.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	ctx := withClientIP(r.Context(), clientIP(r))
+	if err := h.service.ResetPassword(ctx, req.Token, req.Password); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
 }
 
 func (h *Handler) handleRegisterMember(w http.ResponseWriter, r *http.Request) {
@@ -75,14 +234,14 @@ func (h *Handler) handleRegisterMember(w http.ResponseWriter, r *http.Request) {
 		Password string `json:"password"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if !httperr.DecodeJSON(w, r, &req) {
 		return
 	}
 
-	member, err := h.service.RegisterMember(r.Context(), req.Email, req.Name, req.Password)
+	ctx := withClientIP(r.Context(), clientIP(r))
+	member, err := h.service.RegisterMember(ctx, req.Email, req.Name, req.Password)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -90,12 +249,271 @@ func (h *Handler) handleRegisterMember(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(member)
 }
 
+// mayViewMember reports whether the request may see member id's record: the
+// member themself, an administrator and other services may. Everyone else is
+// told there is no such member, so lookups cannot discover who is registered.
+func mayViewMember(r *http.Request, id uuid.UUID) bool {
+	return r.Header.Get(memberIDHeader) == id.String() ||
+		r.Header.Get(memberRoleHeader) == RoleAdmin ||
+		serviceCallerFromContext(r.Context()) != ""
+}
+
 func (h *Handler) handleGetMember(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	if !mayViewMember(r, id) {
+		writeError(w, ErrMemberNotFound)
+		return
+	}
+
 	member, err := h.service.GetMember(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(member)
+}
+
+func (h *Handler) handleGetMemberByEmail(w http.ResponseWriter, r *http.Request) {
+	email := r.URL.Query().Get("email")
+	if email == "" {
+		httperr.BadRequest(w, "missing email")
+		return
+	}
+
+	member, err := h.service.GetMemberByEmail(r.Context(), email)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if !mayViewMember(r, member.ID) {
+		writeError(w, ErrMemberNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(member)
+}
+
+func (h *Handler) handleGetTierHistory(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	if !mayViewMember(r, id) {
+		writeError(w, ErrMemberNotFound)
+		return
+	}
+
+	history, err := h.service.GetTierHistory(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(history)
+}
+
+// handleGetMemberEvents serves a member's event history for auditing,
+// limited by the optional from_version and to_version parameters.
+func (h *Handler) handleGetMemberEvents(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	q := r.URL.Query()
+	fromVersion, err := intParam(q.Get("from_version"))
+	if err != nil {
+		httperr.BadRequest(w, "invalid from_version")
+		return
+	}
+	toVersion, err := intParam(q.Get("to_version"))
+	if err != nil {
+		httperr.BadRequest(w, "invalid to_version")
+		return
+	}
+
+	events, err := h.service.GetMemberEvents(r.Context(), id, fromVersion, toVersion)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(struct {
+		Events []Event `json:"events"`
+	}{Events: events})
+}
+
+// handleRenewMembership extends a membership by the requested term, a Go
+// duration such as "8760h", or by a year if the body is empty.
+func (h *Handler) handleRenewMembership(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	var req struct {
+		Term string `json:"term"`
+	}
+	if !httperr.DecodeOptionalJSON(w, r, &req) {
+		return
+	}
+
+	term := defaultMembershipTerm
+	if req.Term != "" {
+		var err error
+		if term, err = time.ParseDuration(req.Term); err != nil {
+			httperr.BadRequest(w, "invalid term")
+			return
+		}
+	}
+
+	member, err := h.service.RenewMembership(r.Context(), id, term)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(member)
+}
+
+func (h *Handler) handleSuspendMember(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if !httperr.DecodeJSON(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		httperr.BadRequest(w, "missing reason")
+		return
+	}
+
+	member, err := h.service.SuspendMember(r.Context(), id, strings.TrimSpace(req.Reason))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(member)
+}
+
+func (h *Handler) handleReactivateMember(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	member, err := h.service.ReactivateMember(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(member)
+}
+
+func (h *Handler) handleSetMemberRole(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	var req struct {
+		Role string `json:"role"`
+	}
+	if !httperr.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	member, err := h.service.SetMemberRole(r.Context(), id, req.Role)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(member)
+}
+
+func (h *Handler) handleChargeFine(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	var req struct {
+		Amount    float64 `json:"amount"`
+		Reason    string  `json:"reason"`
+		Reference string  `json:"reference"`
+	}
+
+	if !httperr.DecodeJSON(w, r, &req) {
+		return
+	}
+	if req.Amount <= 0 {
+		httperr.BadRequest(w, "amount must be positive")
+		return
+	}
+
+	member, err := h.service.ChargeFine(r.Context(), id, req.Amount, req.Reason, req.Reference)
+	if err != nil {
+		writeError(w, err)
 		return
 	}
 
 	json.NewEncoder(w).Encode(member)
 }
+
+func (h *Handler) handlePayFine(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	var req struct {
+		Amount float64 `json:"amount"`
+	}
+
+	if !httperr.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	member, err := h.service.PayFine(r.Context(), id, req.Amount)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(struct {
+		MemberID    uuid.UUID `json:"member_id"`
+		FineBalance float64   `json:"fine_balance"`
+	}{
+		MemberID:    member.ID,
+		FineBalance: member.FineBalance,
+	})
+}
+
+func (h *Handler) handleEnableMFA(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	secret, otpauthURL, err := h.service.EnableMFA(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(struct {
+		Secret     string `json:"secret"`
+		OTPAuthURL string `json:"otpauth_url"`
+	}{
+		Secret:     secret,
+		OTPAuthURL: otpauthURL,
+	})
+}
+
+func (h *Handler) handleDisableMFA(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	var req struct {
+		Code string `json:"code"`
+	}
+
+	if !httperr.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.service.DisableMFA(r.Context(), id, req.Code); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeError responds to a service error. Validation errors list the reason
+// each field was rejected, and rate limit errors say when to try again.
+func writeError(w http.ResponseWriter, err error) {
+	var invalid *ValidationError
+	var limited *RateLimitError
+	switch {
+	case errors.As(err, &limited):
+		w.Header().Set("Retry-After", retryAfterSeconds(limited.RetryAfter))
+		httperr.Error(w, http.StatusTooManyRequests, "rate_limited", err.Error())
+	case errors.As(err, &invalid):
+		httperr.JSON(w, http.StatusBadRequest, struct {
+			httperr.Response
+			*ValidationError
+		}{httperr.Response{Error: ErrInvalidRegistration.Error(), Code: "invalid_fields"}, invalid})
+	default:
+		errorRules.Write(w, err)
+	}
+}
+
+// intParam parses an optional integer query parameter, treating empty as zero.
+func intParam(v string) (int, error) {
+	if v == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(v)
+}
@@ -0,0 +1,432 @@
+package membership
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type registeringService struct {
+	Service
+	emails map[string]bool
+}
+
+func (r *registeringService) RegisterMember(ctx context.Context, email, name, password string) (*Member, error) {
+	email = NormalizeEmail(email)
+	if err := (&service{passwordPolicy: DefaultPasswordPolicy()}).validateRegistration(email, name, password); err != nil {
+		return nil, err
+	}
+	if r.emails[email] {
+		return nil, fmt.Errorf("failed to update read model: %w", ErrEmailAlreadyRegistered)
+	}
+	r.emails[email] = true
+	return &Member{Email: email, Name: name}, nil
+}
+
+func TestHandleRegisterDuplicateEmail(t *testing.T) {
+	h := NewHandler(&registeringService{emails: map[string]bool{}}, nil)
+
+	register := func(email string) int {
+		body := fmt.Sprintf(`{"email":%q,"name":"Ada","password":"SecurePass123!"}`, email)
+		rec := httptest.NewRecorder()
+		h.HandleMembers(rec, httptest.NewRequest(http.MethodPost, "/register", bytes.NewBufferString(body)))
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusCreated, register("ada@example.com"))
+	assert.Equal(t, http.StatusConflict, register("ada@example.com"))
+	assert.Equal(t, http.StatusConflict, register("Ada@Example.com"))
+}
+
+func TestHandleRegisterInvalidFields(t *testing.T) {
+	h := NewHandler(&registeringService{emails: map[string]bool{}}, nil)
+
+	body := `{"email":"ada at example.com","name":"Ada","password":"password"}`
+	rec := httptest.NewRecorder()
+	h.HandleMembers(rec, httptest.NewRequest(http.MethodPost, "/register", bytes.NewBufferString(body)))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	var resp struct {
+		Error  string            `json:"error"`
+		Fields map[string]string `json:"fields"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, ErrInvalidRegistration.Error(), resp.Error)
+	assert.Contains(t, resp.Fields, "email")
+	assert.Contains(t, resp.Fields, "password")
+	assert.NotContains(t, resp.Fields, "name")
+}
+
+func TestHandleRegisterRefusesUnknownFields(t *testing.T) {
+	h := NewHandler(&registeringService{emails: map[string]bool{}}, nil)
+
+	body := `{"email":"ada@example.com","name":"Ada","password":"SecurePass123!","role":"admin"}`
+	rec := httptest.NewRecorder()
+	h.HandleMembers(rec, httptest.NewRequest(http.MethodPost, "/register", bytes.NewBufferString(body)))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `unknown field \"role\"`)
+}
+
+type resettingService struct {
+	Service
+	requested []string
+}
+
+func (r *resettingService) RequestPasswordReset(ctx context.Context, email string) error {
+	r.requested = append(r.requested, email)
+	return nil
+}
+
+func (r *resettingService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	if err := DefaultPasswordPolicy().Validate(newPassword); err != nil {
+		return &ValidationError{Fields: map[string]string{"password": err.Error()}}
+	}
+	if token != "good-token" {
+		return ErrInvalidResetToken
+	}
+	return nil
+}
+
+func TestHandlePasswordResetRequest(t *testing.T) {
+	svc := &resettingService{}
+	h := NewHandler(svc, nil)
+
+	for _, email := range []string{"ada@example.com", "nobody@example.com"} {
+		rec := httptest.NewRecorder()
+		body := fmt.Sprintf(`{"email":%q}`, email)
+		h.HandlePasswordResetRequest(rec, httptest.NewRequest(http.MethodPost, "/password-reset/request", bytes.NewBufferString(body)))
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Empty(t, rec.Body.String(), "the response must not reveal whether %s is a member", email)
+	}
+	assert.Equal(t, []string{"ada@example.com", "nobody@example.com"}, svc.requested)
+}
+
+func TestHandlePasswordResetConfirm(t *testing.T) {
+	tests := []struct {
+		name       string
+		token      string
+		password   string
+		wantStatus int
+	}{
+		{"valid token", "good-token", "NewSecurePass456!", http.StatusNoContent},
+		{"spent or unknown token", "used-token", "NewSecurePass456!", http.StatusBadRequest},
+		{"weak password", "good-token", "short", http.StatusBadRequest},
+	}
+
+	h := NewHandler(&resettingService{}, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := fmt.Sprintf(`{"token":%q,"password":%q}`, tt.token, tt.password)
+			rec := httptest.NewRecorder()
+			h.HandlePasswordResetConfirm(rec, httptest.NewRequest(http.MethodPost, "/password-reset/confirm", bytes.NewBufferString(body)))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+type throttledService struct {
+	Service
+}
+
+func (throttledService) Authenticate(ctx context.Context, email, password, mfaCode string) (*Member, error) {
+	return nil, &RateLimitError{RetryAfter: 1500 * time.Millisecond}
+}
+
+func (throttledService) RegisterMember(ctx context.Context, email, name, password string) (*Member, error) {
+	return nil, &RateLimitError{RetryAfter: 12 * time.Second}
+}
+
+func (throttledService) RequestPasswordReset(ctx context.Context, email string) error {
+	return &RateLimitError{RetryAfter: time.Minute}
+}
+
+func (throttledService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	return &RateLimitError{RetryAfter: time.Second}
+}
+
+func TestRateLimitedRequestsGet429(t *testing.T) {
+	h := NewHandler(throttledService{}, nil)
+
+	rec := httptest.NewRecorder()
+	h.HandleLogin(rec, httptest.NewRequest(http.MethodPost, "/login", bytes.NewBufferString(`{"email":"ada@example.com"}`)))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+
+	rec = httptest.NewRecorder()
+	h.HandleMembers(rec, httptest.NewRequest(http.MethodPost, "/register", bytes.NewBufferString(`{"email":"ada@example.com"}`)))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "12", rec.Header().Get("Retry-After"))
+
+	rec = httptest.NewRecorder()
+	h.HandlePasswordResetRequest(rec, httptest.NewRequest(http.MethodPost, "/password-reset/request", bytes.NewBufferString(`{"email":"ada@example.com"}`)))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+
+	rec = httptest.NewRecorder()
+	h.HandlePasswordResetConfirm(rec, httptest.NewRequest(http.MethodPost, "/password-reset/confirm", bytes.NewBufferString(`{"token":"t"}`)))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+}
+
+type failingLoginService struct {
+	Service
+	err error
+}
+
+func (f failingLoginService) Authenticate(ctx context.Context, email, password, mfaCode string) (*Member, error) {
+	return nil, f.err
+}
+
+func TestHandleLoginErrorStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"bad password", ErrInvalidCredentials, http.StatusUnauthorized},
+		{"missing MFA code", ErrMFARequired, http.StatusUnauthorized},
+		{"bad MFA code", ErrInvalidMFACode, http.StatusUnauthorized},
+		{"locked", ErrAccountLocked, http.StatusLocked},
+		{"database down", fmt.Errorf("authentication failed: %w", errors.New("connection refused")), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(failingLoginService{err: tt.err}, nil)
+			rec := httptest.NewRecorder()
+			h.HandleLogin(rec, httptest.NewRequest(http.MethodPost, "/login", bytes.NewBufferString(`{"email":"ada@example.com"}`)))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+type lookupService struct {
+	Service
+	members map[string]*Member
+}
+
+func (l lookupService) GetMemberByEmail(ctx context.Context, email string) (*Member, error) {
+	email = NormalizeEmail(email)
+	if err := ValidateEmail(email); err != nil {
+		return nil, fmt.Errorf("%w: email %v", ErrInvalidEmail, err)
+	}
+	if member, ok := l.members[email]; ok {
+		return member, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrMemberNotFound, email)
+}
+
+func TestHandleGetMemberByEmail(t *testing.T) {
+	ada := uuid.New()
+	h := NewHandler(lookupService{members: map[string]*Member{
+		"ada@example.com": {ID: ada, Email: "ada@example.com", Name: "Ada"},
+	}}, nil)
+
+	tests := []struct {
+		name       string
+		target     string
+		member     uuid.UUID
+		role       string
+		service    string
+		wantStatus int
+	}{
+		{"own record", "/members?email=ada@example.com", ada, "", "", http.StatusOK},
+		{"different case", "/members?email=Ada%40Example.com", ada, "", "", http.StatusOK},
+		{"administrator", "/members?email=ada@example.com", uuid.New(), RoleAdmin, "", http.StatusOK},
+		{"service", "/members?email=ada@example.com", uuid.Nil, "", "circulation", http.StatusOK},
+		{"another member", "/members?email=ada@example.com", uuid.New(), "", "", http.StatusNotFound},
+		{"anonymous", "/members?email=ada@example.com", uuid.Nil, "", "", http.StatusNotFound},
+		{"unknown", "/members?email=grace@example.com", uuid.New(), RoleAdmin, "", http.StatusNotFound},
+		{"malformed", "/members?email=ada+at+example.com", uuid.New(), RoleAdmin, "", http.StatusBadRequest},
+		{"missing", "/members", uuid.New(), RoleAdmin, "", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.HandleMembers(rec, viewerRequest(tt.target, tt.member, tt.role, tt.service))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus == http.StatusOK {
+				var member Member
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &member))
+				assert.Equal(t, "Ada", member.Name)
+			}
+		})
+	}
+}
+
+type memberService struct {
+	Service
+	member *Member
+}
+
+func (m memberService) GetMember(ctx context.Context, id uuid.UUID) (*Member, error) {
+	if m.member.ID != id {
+		return nil, ErrMemberNotFound
+	}
+	return m.member, nil
+}
+
+func TestHandleGetMemberHidesOthers(t *testing.T) {
+	ada := &Member{ID: uuid.New(), Name: "Ada"}
+	h := NewHandler(memberService{member: ada}, nil)
+
+	tests := []struct {
+		name       string
+		member     uuid.UUID
+		role       string
+		service    string
+		wantStatus int
+	}{
+		{"own record", ada.ID, "", "", http.StatusOK},
+		{"administrator", uuid.New(), RoleAdmin, "", http.StatusOK},
+		{"service", uuid.Nil, "", "circulation", http.StatusOK},
+		{"another member", uuid.New(), "", "", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.HandleMember(rec, viewerRequest("/members/"+ada.ID.String(), tt.member, tt.role, tt.service))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+// viewerRequest builds a GET for target as the gateway would forward it for
+// member with role, or as service would send it.
+func viewerRequest(target string, member uuid.UUID, role, service string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if member != uuid.Nil {
+		req.Header.Set(memberIDHeader, member.String())
+	}
+	if role != "" {
+		req.Header.Set(memberRoleHeader, role)
+	}
+	if service != "" {
+		req = req.WithContext(withServiceCaller(req.Context(), service))
+	}
+	return req
+}
+
+type auditService struct {
+	Service
+	from, to int
+}
+
+func (a *auditService) GetMemberEvents(ctx context.Context, id uuid.UUID, fromVersion, toVersion int) ([]Event, error) {
+	a.from, a.to = fromVersion, toVersion
+	return []Event{{Type: "MemberRegistered", Version: 1, Data: json.RawMessage(`{"id":"x"}`)}}, nil
+}
+
+func TestHandleGetMemberEvents(t *testing.T) {
+	id := uuid.New()
+	tests := []struct {
+		name       string
+		query      string
+		role       string
+		wantStatus int
+		wantFrom   int
+		wantTo     int
+	}{
+		{"whole history", "", RoleAdmin, http.StatusOK, 0, 0},
+		{"range", "?from_version=2&to_version=5", RoleAdmin, http.StatusOK, 2, 5},
+		{"not an admin", "", "", http.StatusForbidden, 0, 0},
+		{"malformed range", "?from_version=two", RoleAdmin, http.StatusBadRequest, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &auditService{}
+			h := NewHandler(svc, nil)
+			req := httptest.NewRequest(http.MethodGet, "/members/"+id.String()+"/events"+tt.query, nil)
+			if tt.role != "" {
+				req.Header.Set(memberRoleHeader, tt.role)
+			}
+			rec := httptest.NewRecorder()
+			h.HandleMember(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantFrom, svc.from)
+			assert.Equal(t, tt.wantTo, svc.to)
+			if tt.wantStatus == http.StatusOK {
+				var resp struct {
+					Events []struct {
+						Type    string          `json:"type"`
+						Version int             `json:"version"`
+						Data    json.RawMessage `json:"data"`
+					} `json:"events"`
+				}
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Len(t, resp.Events, 1)
+				assert.JSONEq(t, `{"id":"x"}`, string(resp.Events[0].Data))
+			}
+		})
+	}
+}
+
+type fineService struct {
+	Service
+	charged, paid bool
+}
+
+func (f *fineService) ChargeFine(ctx context.Context, id uuid.UUID, amount float64, reason, reference string) (*Member, error) {
+	f.charged = true
+	return &Member{ID: id, FineBalance: amount}, nil
+}
+
+func (f *fineService) PayFine(ctx context.Context, id uuid.UUID, amount float64) (*Member, error) {
+	f.paid = true
+	return &Member{ID: id}, nil
+}
+
+func TestHandleFinesRequireAdministratorOrService(t *testing.T) {
+	id := uuid.New()
+	tests := []struct {
+		name       string
+		action     string
+		role       string
+		service    string
+		wantStatus int
+	}{
+		{"charge by a service", "fines", "", "fines", http.StatusOK},
+		{"charge by an admin", "fines", RoleAdmin, "", http.StatusOK},
+		{"charge by a member", "fines", "", "", http.StatusForbidden},
+		{"payment by a service", "fines/payment", "", "circulation", http.StatusOK},
+		{"payment by an admin", "fines/payment", RoleAdmin, "", http.StatusOK},
+		{"payment by a member", "fines/payment", "", "", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &fineService{}
+			h := NewHandler(svc, nil)
+			body := `{"amount":1.5}`
+			if tt.action == "fines" {
+				body = `{"amount":1.5,"reason":"overdue","reference":"checkout-1"}`
+			}
+			req := httptest.NewRequest(http.MethodPost, "/members/"+id.String()+"/"+tt.action, bytes.NewBufferString(body))
+			if tt.role != "" {
+				req.Header.Set(memberRoleHeader, tt.role)
+			}
+			if tt.service != "" {
+				req = req.WithContext(withServiceCaller(req.Context(), tt.service))
+			}
+			rec := httptest.NewRecorder()
+			h.HandleMember(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantStatus == http.StatusOK, svc.charged || svc.paid)
+		})
+	}
+}
@@ -5,46 +5,103 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/jules-labs/go-eventstore"
+	"libranexus/internal/cache"
+	"libranexus/internal/database"
+	"log"
+	"math"
 	"time"
 
 	"github.com/google/uuid"
-	"golang.org/x/time/rate"
+	"github.com/lib/pq"
 )
 
 // service implements the Service interface.
 type service struct {
-	eventStore   *eventstore.EventStore
-	db           *sql.DB
-	rateLimiter  *rate.Limiter
+	eventStore      *eventstore.EventStore
+	db              *sql.DB
+	loginLimiter    *keyedLimiter
+	registerLimiter *keyedLimiter
+	lockout         lockoutPolicy
+	checkoutLimits  map[string]int
+	passwordPolicy  PasswordPolicy
+	argon2          Argon2Params
+	dummyHash       string
+	resetTokenTTL   time.Duration
+	sendResetToken  ResetTokenSender
+	now             func() time.Time
+	memberCache     *cache.LRU[uuid.UUID, Member]
+}
+
+// Option configures optional membership service behaviour.
+type Option func(*service)
+
+// WithLockoutPolicy locks an account for duration after threshold consecutive
+// failed logins. A threshold of zero disables lockout.
+func WithLockoutPolicy(threshold int, duration time.Duration) Option {
+	return func(s *service) {
+		s.lockout = lockoutPolicy{threshold: threshold, duration: duration}
+	}
+}
+
+// WithClock overrides the service's time source.
+func WithClock(now func() time.Time) Option {
+	return func(s *service) {
+		s.now = now
+	}
 }
 
 // NewService creates a new membership service instance.
-func NewService(es *eventstore.EventStore, db *sql.DB) Service {
-	return &service{
-		eventStore:   es,
-		db:           db,
-		rateLimiter:  rate.NewLimiter(rate.Every(1*time.Minute), 5), // 5 requests per minute
+func NewService(es *eventstore.EventStore, db *sql.DB, opts ...Option) Service {
+	s := &service{
+		eventStore:      es,
+		db:              db,
+		loginLimiter:    newKeyedLimiter(DefaultRateLimit),
+		registerLimiter: newKeyedLimiter(DefaultRateLimit),
+		lockout:         lockoutPolicy{threshold: DefaultLockoutThreshold, duration: DefaultLockoutDuration},
+		checkoutLimits:  defaultCheckoutLimits,
+		passwordPolicy:  DefaultPasswordPolicy(),
+		argon2:          DefaultArgon2Params(),
+		resetTokenTTL:   DefaultResetTokenTTL,
+		now:             time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	// Only a failing system random source can make this fail, and then
+	// nothing else in the service would work either.
+	s.dummyHash, _ = hashPassword("", s.argon2)
+	return s
 }
 
 // RegisterMember creates a new member.
 func (s *service) RegisterMember(ctx context.Context, email, name, password string) (*Member, error) {
-	if !s.rateLimiter.Allow() {
-		return nil, fmt.Errorf("rate limit exceeded")
+	if err := admit(ctx, s.registerLimiter, clientIPFromContext(ctx)); err != nil {
+		return nil, err
+	}
+	email = NormalizeEmail(email)
+	if err := s.validateRegistration(email, name, password); err != nil {
+		return nil, err
+	}
+
+	maxCheckouts, err := s.maxCheckouts(registrationTier)
+	if err != nil {
+		return nil, err
 	}
 
 	id := uuid.New()
-	passwordHash, salt, err := hashPassword(password)
+	passwordHash, err := hashPassword(password, s.argon2)
 	if err != nil {
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
 
 	eventData := MemberRegisteredEvent{
-		ID:    id,
-		Email: email,
-		Name:  name,
+		ID:           id,
+		Email:        email,
+		Name:         name,
+		MaxCheckouts: maxCheckouts,
 	}
 
 	jsonData, err := json.Marshal(eventData)
@@ -60,44 +117,48 @@ func (s *service) RegisterMember(ctx context.Context, email, name, password stri
 		Version:       1,
 	}
 
-	if err := s.eventStore.AppendEvents(ctx, id, "member", 0, []eventstore.Event{event}); err != nil {
-		return nil, fmt.Errorf("failed to append event: %w", err)
-	}
-
 	member := &Member{
 		ID:             id,
 		Email:          email,
 		Name:           name,
-		MembershipTier: "basic",
+		MembershipTier: registrationTier,
+		Role:           RoleMember,
 		Status:         "active",
-		ExpiresAt:      time.Now().AddDate(1, 0, 0),
+		MaxCheckouts:   maxCheckouts,
+		ExpiresAt:      s.now().AddDate(1, 0, 0),
 	}
 	credential := &Credential{
 		MemberID:     id,
 		PasswordHash: passwordHash,
-		Salt:         salt,
 	}
 
-	if err := s.insertMemberIntoReadModel(ctx, member, credential); err != nil {
-		return nil, fmt.Errorf("failed to update read model: %w", err)
+	err = database.InTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.appendMemberEventsTx(ctx, tx, id, 0, []eventstore.Event{event}); err != nil {
+			return fmt.Errorf("failed to append event: %w", err)
+		}
+		if err := insertMemberIntoReadModel(ctx, tx, member, credential); err != nil {
+			return fmt.Errorf("failed to update read model: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return member, nil
 }
 
-func (s *service) insertMemberIntoReadModel(ctx context.Context, member *Member, credential *Credential) error {
-	tx, err := s.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
+func insertMemberIntoReadModel(ctx context.Context, tx *sql.Tx, member *Member, credential *Credential) error {
 	memberQuery := `
-		INSERT INTO members (id, email, name, membership_tier, status, expires_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO members (id, email, name, membership_tier, status, max_checkouts, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
-	_, err = tx.ExecContext(ctx, memberQuery, member.ID, member.Email, member.Name, member.MembershipTier, member.Status, member.ExpiresAt)
+	_, err := tx.ExecContext(ctx, memberQuery, member.ID, member.Email, member.Name, member.MembershipTier, member.Status, member.MaxCheckouts, member.ExpiresAt)
 	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrEmailAlreadyRegistered
+		}
 		return err
 	}
 
@@ -106,20 +167,24 @@ func (s *service) insertMemberIntoReadModel(ctx context.Context, member *Member,
 		VALUES ($1, $2, $3)
 	`
 	_, err = tx.ExecContext(ctx, credQuery, credential.MemberID, credential.PasswordHash, credential.Salt)
-	if err != nil {
-		return err
-	}
-
-	return tx.Commit()
+	return err
 }
 
 // Authenticate verifies a member's credentials and returns the member if successful.
-func (s *service) Authenticate(ctx context.Context, email, password string) (*Member, error) {
-	if !s.rateLimiter.Allow() {
-		return nil, fmt.Errorf("rate limit exceeded")
+// Members with MFA enabled must also supply a currently valid TOTP code.
+func (s *service) Authenticate(ctx context.Context, email, password, mfaCode string) (*Member, error) {
+	email = NormalizeEmail(email)
+	if err := admit(ctx, s.loginLimiter, email); err != nil {
+		return nil, err
 	}
 
 	member, err := s.getMemberByEmail(ctx, email)
+	if err == sql.ErrNoRows {
+		// Hash anyway, so an unknown email takes as long to reject as a
+		// wrong password and response times don't reveal who is a member.
+		verifyPassword(password, "", s.dummyHash)
+		return nil, ErrInvalidCredentials
+	}
 	if err != nil {
 		return nil, fmt.Errorf("authentication failed: %w", err)
 	}
@@ -129,31 +194,127 @@ func (s *service) Authenticate(ctx context.Context, email, password string) (*Me
 		return nil, fmt.Errorf("authentication failed: %w", err)
 	}
 
+	// A locked account is rejected before the password is even checked.
+	now := s.now()
+	if s.lockout.isLocked(credential, now) {
+		return nil, ErrAccountLocked
+	}
+
 	ok, err := verifyPassword(password, credential.Salt, credential.PasswordHash)
 	if err != nil {
 		return nil, fmt.Errorf("authentication failed: %w", err)
 	}
 	if !ok {
-		return nil, fmt.Errorf("authentication failed: invalid credentials")
+		if err := s.recordFailedLogin(ctx, credential, now); err != nil {
+			return nil, fmt.Errorf("authentication failed: %w", err)
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	if credential.MFAEnabled {
+		if mfaCode == "" {
+			return nil, ErrMFARequired
+		}
+		if !validateMFACode(credential.MFASecret, mfaCode, now) {
+			if err := s.recordFailedLogin(ctx, credential, now); err != nil {
+				return nil, fmt.Errorf("authentication failed: %w", err)
+			}
+			return nil, ErrInvalidMFACode
+		}
+	}
+
+	if err := s.recordSuccessfulLogin(ctx, credential, now); err != nil {
+		return nil, fmt.Errorf("authentication failed: %w", err)
+	}
+	if needsRehash(credential.Salt, credential.PasswordHash, s.argon2) {
+		s.rehashPassword(ctx, credential, password)
 	}
 
 	return member, nil
 }
 
-func (s *service) getMemberByEmail(ctx context.Context, email string) (*Member, error) {
-	query := `
-		SELECT id, email, name, membership_tier, status, expires_at
-		FROM members
-		WHERE email = $1
-	`
+func (s *service) recordFailedLogin(ctx context.Context, credential *Credential, now time.Time) error {
+	attempts, lockedUntil := s.lockout.afterFailure(credential, now)
+
+	var locked interface{}
+	if !lockedUntil.IsZero() {
+		locked = lockedUntil
+	}
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE credentials
+		SET failed_attempts = $1, locked_until = $2, updated_at = NOW()
+		WHERE member_id = $3
+	`, attempts, locked, credential.MemberID)
+	return err
+}
+
+// rehashPassword upgrades a stored hash to the current Argon2 parameters.
+// The login has already succeeded, so a failure here is only logged and the
+// upgrade is retried at the next login.
+func (s *service) rehashPassword(ctx context.Context, credential *Credential, password string) {
+	passwordHash, err := hashPassword(password, s.argon2)
+	if err != nil {
+		log.Printf("Failed to re-hash password for member %s: %v", credential.MemberID, err)
+		return
+	}
+	// Matching on the old hash keeps a concurrent password reset from being
+	// overwritten with the password it replaced.
+	_, err = s.db.ExecContext(ctx, `
+		UPDATE credentials
+		SET password_hash = $1, salt = '', updated_at = NOW()
+		WHERE member_id = $2 AND password_hash = $3
+	`, passwordHash, credential.MemberID, credential.PasswordHash)
+	if err != nil {
+		log.Printf("Failed to store re-hashed password for member %s: %v", credential.MemberID, err)
+	}
+}
+
+func (s *service) recordSuccessfulLogin(ctx context.Context, credential *Credential, now time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if credential.FailedAttempts > 0 || !credential.LockedUntil.IsZero() {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE credentials
+			SET failed_attempts = 0, locked_until = NULL, updated_at = NOW()
+			WHERE member_id = $1
+		`, credential.MemberID)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE members SET last_login_at = $1 WHERE id = $2`, now, credential.MemberID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// memberColumns are the members columns scanMember reads, in order.
+const memberColumns = `id, email, name, membership_tier, role, status, COALESCE(suspension_reason, ''), fine_balance, max_checkouts, version, expires_at, created_at, updated_at`
+
+// scanMember reads a row selected with memberColumns.
+func scanMember(row *sql.Row) (*Member, error) {
 	member := &Member{}
-	err := s.db.QueryRowContext(ctx, query, email).Scan(
+	err := row.Scan(
 		&member.ID,
 		&member.Email,
 		&member.Name,
 		&member.MembershipTier,
+		&member.Role,
 		&member.Status,
+		&member.SuspensionReason,
+		&member.FineBalance,
+		&member.MaxCheckouts,
+		&member.Version,
 		&member.ExpiresAt,
+		&member.CreatedAt,
+		&member.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -161,44 +322,52 @@ func (s *service) getMemberByEmail(ctx context.Context, email string) (*Member,
 	return member, nil
 }
 
+func (s *service) getMemberByEmail(ctx context.Context, email string) (*Member, error) {
+	query := `SELECT ` + memberColumns + ` FROM members WHERE LOWER(email) = $1`
+	return scanMember(s.db.QueryRowContext(ctx, query, email))
+}
+
 func (s *service) getCredentialByMemberID(ctx context.Context, memberID uuid.UUID) (*Credential, error) {
 	query := `
-		SELECT member_id, password_hash, salt
+		SELECT member_id, password_hash, salt, mfa_enabled, mfa_secret, failed_attempts, locked_until
 		FROM credentials
 		WHERE member_id = $1
 	`
 	credential := &Credential{}
+	var mfaSecret sql.NullString
+	var lockedUntil sql.NullTime
 	err := s.db.QueryRowContext(ctx, query, memberID).Scan(
 		&credential.MemberID,
 		&credential.PasswordHash,
 		&credential.Salt,
+		&credential.MFAEnabled,
+		&mfaSecret,
+		&credential.FailedAttempts,
+		&lockedUntil,
 	)
 	if err != nil {
 		return nil, err
 	}
+	credential.MFASecret = mfaSecret.String
+	if lockedUntil.Valid {
+		credential.LockedUntil = lockedUntil.Time
+	}
 	return credential, nil
 }
 
 // GetMember retrieves a member by their ID.
 func (s *service) GetMember(ctx context.Context, id uuid.UUID) (*Member, error) {
-	query := `
-		SELECT id, email, name, membership_tier, status, version, expires_at
-		FROM members
-		WHERE id = $1
-	`
-	member := &Member{}
-	err := s.db.QueryRowContext(ctx, query, id).Scan(
-		&member.ID,
-		&member.Email,
-		&member.Name,
-		&member.MembershipTier,
-		&member.Status,
-		&member.Version,
-		&member.ExpiresAt,
-	)
+	return s.cachedMember(ctx, id)
+}
+
+// readMember reads a member from the read model. Writes read through it
+// rather than GetMember, so they never start from a cached member.
+func (s *service) readMember(ctx context.Context, id uuid.UUID) (*Member, error) {
+	query := `SELECT ` + memberColumns + ` FROM members WHERE id = $1`
+	member, err := scanMember(s.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("member with ID %s not found", id)
+			return nil, fmt.Errorf("%w: %s", ErrMemberNotFound, id)
 		}
 		return nil, fmt.Errorf("failed to get member from read model: %w", err)
 	}
@@ -206,16 +375,48 @@ func (s *service) GetMember(ctx context.Context, id uuid.UUID) (*Member, error)
 	return member, nil
 }
 
-// UpdateMemberTier updates a member's membership tier.
+// GetMemberByEmail retrieves a member by email address, ignoring case.
+func (s *service) GetMemberByEmail(ctx context.Context, email string) (*Member, error) {
+	email = NormalizeEmail(email)
+	if err := ValidateEmail(email); err != nil {
+		return nil, fmt.Errorf("%w: email %v", ErrInvalidEmail, err)
+	}
+
+	member, err := s.getMemberByEmail(ctx, email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: %s", ErrMemberNotFound, email)
+		}
+		return nil, fmt.Errorf("failed to get member from read model: %w", err)
+	}
+
+	return member, nil
+}
+
+// UpdateMemberTier updates a member's membership tier and the checkout limit
+// that comes with it.
 func (s *service) UpdateMemberTier(ctx context.Context, id uuid.UUID, newTier string) error {
-	member, err := s.GetMember(ctx, id)
+	return s.changeTier(ctx, id, newTier, nil)
+}
+
+// changeTier records a tier change and applies it to the read model. Any
+// also func runs in the same transaction.
+func (s *service) changeTier(ctx context.Context, id uuid.UUID, newTier string, also func(tx *sql.Tx) error) error {
+	maxCheckouts, err := s.maxCheckouts(newTier)
+	if err != nil {
+		return err
+	}
+
+	member, err := s.readMember(ctx, id)
 	if err != nil {
 		return err
 	}
 
 	eventData := MemberTierChangedEvent{
-		ID:      id,
-		NewTier: newTier,
+		ID:           id,
+		PreviousTier: member.MembershipTier,
+		NewTier:      newTier,
+		MaxCheckouts: maxCheckouts,
 	}
 
 	jsonData, err := json.Marshal(eventData)
@@ -231,16 +432,184 @@ func (s *service) UpdateMemberTier(ctx context.Context, id uuid.UUID, newTier st
 		Version:       member.Version + 1,
 	}
 
-	if err := s.eventStore.AppendEvents(ctx, id, "member", member.Version, []eventstore.Event{event}); err != nil {
-		return fmt.Errorf("failed to append event: %w", err)
+	return database.InTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.appendMemberEventsTx(ctx, tx, id, member.Version, []eventstore.Event{event}); err != nil {
+			return fmt.Errorf("failed to append event: %w", err)
+		}
+		query := `
+			UPDATE members
+			SET membership_tier = $1, max_checkouts = $2, version = $3, updated_at = NOW()
+			WHERE id = $4
+		`
+		if _, err := tx.ExecContext(ctx, query, newTier, maxCheckouts, member.Version+1, id); err != nil {
+			return fmt.Errorf("failed to update read model: %w", err)
+		}
+		if also != nil {
+			return also(tx)
+		}
+		return nil
+	})
+}
+
+// ChargeFine adds a fine to a member's balance. A non-empty reference makes the
+// charge idempotent: repeating a reference that was already applied is a no-op.
+func (s *service) ChargeFine(ctx context.Context, id uuid.UUID, amount float64, reason, reference string) (*Member, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("fine amount must be positive")
 	}
 
-	// Update read model
-	query := `
-		UPDATE members
-		SET membership_tier = $1, version = $2, updated_at = NOW()
-		WHERE id = $3
-	`
-	_, err = s.db.ExecContext(ctx, query, newTier, member.Version+1, id)
-	return err
+	member, err := s.readMember(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if reference != "" {
+		var exists bool
+		err := s.db.QueryRowContext(ctx, `
+			SELECT EXISTS (SELECT 1 FROM fine_transactions WHERE reference = $1)
+		`, reference).Scan(&exists)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check fine reference: %w", err)
+		}
+		if exists {
+			return member, nil
+		}
+	}
+
+	eventData := FineChargedEvent{
+		ID:        id,
+		Amount:    amount,
+		Reason:    reason,
+		Reference: reference,
+	}
+
+	jsonData, err := json.Marshal(eventData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	event := eventstore.Event{
+		AggregateID:   id,
+		AggregateType: "member",
+		EventType:     "FineCharged",
+		EventData:     jsonData,
+		Version:       member.Version + 1,
+	}
+
+	var ref interface{}
+	if reference != "" {
+		ref = reference
+	}
+	err = database.InTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.appendMemberEventsTx(ctx, tx, id, member.Version, []eventstore.Event{event}); err != nil {
+			return fmt.Errorf("failed to append event: %w", err)
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO fine_transactions (member_id, amount, type, reason, reference)
+			VALUES ($1, $2, 'fine', $3, $4)
+		`, id, amount, reason, ref)
+		if err != nil {
+			return fmt.Errorf("failed to record fine: %w", err)
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			UPDATE members
+			SET fine_balance = fine_balance + $1, version = $2, updated_at = NOW()
+			WHERE id = $3
+			RETURNING fine_balance
+		`, amount, member.Version+1, id).Scan(&member.FineBalance)
+		if err != nil {
+			return fmt.Errorf("failed to update fine balance: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	member.Version++
+	return member, nil
+}
+
+// PayFine pays down a member's fine balance. Payments are rejected if they are
+// not positive or exceed the balance. Two payments racing on the same member
+// are serialized by the event stream version, and the read-model update only
+// applies while the balance still covers the payment, so it can never go negative.
+func (s *service) PayFine(ctx context.Context, id uuid.UUID, amount float64) (*Member, error) {
+	amount = math.Round(amount*100) / 100
+	if amount <= 0 {
+		return nil, ErrInvalidPaymentAmount
+	}
+
+	member, err := s.readMember(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var balance float64
+	if err := s.db.QueryRowContext(ctx, `SELECT fine_balance FROM members WHERE id = $1`, id).Scan(&balance); err != nil {
+		return nil, fmt.Errorf("failed to get fine balance: %w", err)
+	}
+	if amount > balance {
+		return nil, ErrOverpayment
+	}
+
+	eventData := FinePaidEvent{
+		ID:         id,
+		Amount:     amount,
+		NewBalance: math.Round((balance-amount)*100) / 100,
+	}
+
+	jsonData, err := json.Marshal(eventData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	event := eventstore.Event{
+		AggregateID:   id,
+		AggregateType: "member",
+		EventType:     "FinePaid",
+		EventData:     jsonData,
+		Version:       member.Version + 1,
+	}
+
+	// The balance check in the update and the event share a transaction, so a
+	// payment that lost a race leaves no FinePaid event behind.
+	err = database.InTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.appendMemberEventsTx(ctx, tx, id, member.Version, []eventstore.Event{event}); err != nil {
+			if errors.Is(err, eventstore.ErrConcurrencyConflict) {
+				return ErrBalanceChanged
+			}
+			return fmt.Errorf("failed to append event: %w", err)
+		}
+
+		err := tx.QueryRowContext(ctx, `
+			UPDATE members
+			SET fine_balance = fine_balance - $1, version = $2, updated_at = NOW()
+			WHERE id = $3 AND version = $4 AND fine_balance >= $1
+			RETURNING fine_balance
+		`, amount, member.Version+1, id, member.Version).Scan(&member.FineBalance)
+		if err == sql.ErrNoRows {
+			return ErrBalanceChanged
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update fine balance: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO fine_transactions (member_id, amount, type, reason)
+			VALUES ($1, $2, 'payment', 'Fine payment')
+		`, id, amount)
+		if err != nil {
+			return fmt.Errorf("failed to record payment: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	member.Version++
+	return member, nil
 }
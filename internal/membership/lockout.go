@@ -0,0 +1,34 @@
+// internal/membership/lockout.go
+package membership
+
+import "time"
+
+const (
+	// DefaultLockoutThreshold is how many failed logins in a row lock an
+	// account unless configured otherwise.
+	DefaultLockoutThreshold = 5
+	// DefaultLockoutDuration is how long a locked account stays locked.
+	DefaultLockoutDuration = 15 * time.Minute
+)
+
+// lockoutPolicy decides when repeated failed logins lock an account.
+type lockoutPolicy struct {
+	threshold int
+	duration  time.Duration
+}
+
+// isLocked reports whether the credential is locked at the given time.
+func (p lockoutPolicy) isLocked(credential *Credential, now time.Time) bool {
+	return credential.LockedUntil.After(now)
+}
+
+// afterFailure returns the failed-attempt counter and lock expiry to store
+// after another bad password. Reaching the threshold locks the account and
+// restarts the count so the next window begins fresh once the lock lapses.
+func (p lockoutPolicy) afterFailure(credential *Credential, now time.Time) (int, time.Time) {
+	attempts := credential.FailedAttempts + 1
+	if p.threshold > 0 && attempts >= p.threshold {
+		return 0, now.Add(p.duration)
+	}
+	return attempts, credential.LockedUntil
+}
@@ -0,0 +1,30 @@
+package membership
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockoutPolicy(t *testing.T) {
+	policy := lockoutPolicy{threshold: 3, duration: 15 * time.Minute}
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cred := &Credential{}
+
+	for i := 1; i < 3; i++ {
+		attempts, lockedUntil := policy.afterFailure(cred, now)
+		assert.Equal(t, i, attempts)
+		assert.True(t, lockedUntil.IsZero())
+		cred.FailedAttempts, cred.LockedUntil = attempts, lockedUntil
+		assert.False(t, policy.isLocked(cred, now))
+	}
+
+	attempts, lockedUntil := policy.afterFailure(cred, now)
+	assert.Equal(t, 0, attempts)
+	assert.Equal(t, now.Add(15*time.Minute), lockedUntil)
+	cred.FailedAttempts, cred.LockedUntil = attempts, lockedUntil
+
+	assert.True(t, policy.isLocked(cred, now.Add(14*time.Minute)))
+	assert.False(t, policy.isLocked(cred, now.Add(15*time.Minute)))
+}
@@ -0,0 +1,64 @@
+// internal/membership/membercache.go
+package membership
+
+import (
+	"context"
+	"database/sql"
+	"libranexus/internal/cache"
+	"libranexus/internal/metrics"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+)
+
+// DefaultMemberCacheSize is how many members the member cache holds unless
+// configured otherwise.
+const DefaultMemberCacheSize = 10000
+
+// memberCacheRequests counts GetMember lookups in the member cache by
+// whether they were served from it.
+var memberCacheRequests = metrics.NewCounter("membership_member_cache_requests_total",
+	"Member reads looked up in the member cache, by result (hit or miss).",
+	"result")
+
+// WithMemberCache makes GetMember serve members from an in-memory cache of
+// up to size members, each kept for ttl. Writes through this service
+// invalidate the member they change; a change made through another replica
+// can go unseen for up to ttl. A size or ttl that is not positive leaves
+// the cache off.
+func WithMemberCache(size int, ttl time.Duration) Option {
+	return func(s *service) {
+		s.memberCache = cache.NewLRU[uuid.UUID, Member](size, ttl)
+	}
+}
+
+// cachedMember returns a member from the member cache, reading it from the
+// read model and caching it on a miss. Callers get their own copy.
+func (s *service) cachedMember(ctx context.Context, id uuid.UUID) (*Member, error) {
+	if s.memberCache == nil {
+		return s.readMember(ctx, id)
+	}
+	if member, ok := s.memberCache.Get(id); ok {
+		memberCacheRequests.Inc("hit")
+		return &member, nil
+	}
+	memberCacheRequests.Inc("miss")
+	member, err := s.readMember(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.memberCache.Put(id, *member, member.Version)
+	return member, nil
+}
+
+// appendMemberEventsTx appends events to a member in tx and invalidates the
+// member's cached state at the version they bring it to, before the commit,
+// so a read that sees the member as it was cannot be cached afterwards.
+func (s *service) appendMemberEventsTx(ctx context.Context, tx *sql.Tx, id uuid.UUID, expectedVersion int, events []eventstore.Event) error {
+	if err := s.eventStore.AppendEventsTx(ctx, tx, id, "member", expectedVersion, events); err != nil {
+		return err
+	}
+	s.memberCache.Invalidate(id, expectedVersion+len(events))
+	return nil
+}
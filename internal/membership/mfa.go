@@ -0,0 +1,138 @@
+// internal/membership/mfa.go
+package membership
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"libranexus/internal/database"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+const mfaIssuer = "LibraNexus"
+
+// mfaValidateOpts accepts 6-digit codes on a 30s step, tolerating one step of
+// clock skew in either direction.
+var mfaValidateOpts = totp.ValidateOpts{
+	Period:    30,
+	Skew:      1,
+	Digits:    otp.DigitsSix,
+	Algorithm: otp.AlgorithmSHA1,
+}
+
+// validateMFACode reports whether code is a currently valid TOTP for secret.
+func validateMFACode(secret, code string, now time.Time) bool {
+	ok, err := totp.ValidateCustom(code, secret, now, mfaValidateOpts)
+	return err == nil && ok
+}
+
+// EnableMFA generates and stores a new TOTP secret for the member, returning
+// the secret and an otpauth:// URL suitable for rendering as a QR code.
+func (s *service) EnableMFA(ctx context.Context, memberID uuid.UUID) (string, string, error) {
+	member, err := s.readMember(ctx, memberID)
+	if err != nil {
+		return "", "", err
+	}
+
+	credential, err := s.getCredentialByMemberID(ctx, memberID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get credentials: %w", err)
+	}
+	if credential.MFAEnabled {
+		return "", "", ErrMFAAlreadyEnabled
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      mfaIssuer,
+		AccountName: member.Email,
+		Period:      mfaValidateOpts.Period,
+		Digits:      mfaValidateOpts.Digits,
+		Algorithm:   mfaValidateOpts.Algorithm,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate MFA secret: %w", err)
+	}
+
+	if err := s.appendMFAEvent(ctx, member, "MFAEnabled", MFAEnabledEvent{ID: memberID}, true, key.Secret()); err != nil {
+		return "", "", err
+	}
+
+	return key.Secret(), key.URL(), nil
+}
+
+// DisableMFA turns off MFA for the member. It requires a currently valid code
+// so that a stolen session alone cannot strip the second factor.
+func (s *service) DisableMFA(ctx context.Context, memberID uuid.UUID, code string) error {
+	member, err := s.readMember(ctx, memberID)
+	if err != nil {
+		return err
+	}
+
+	credential, err := s.getCredentialByMemberID(ctx, memberID)
+	if err != nil {
+		return fmt.Errorf("failed to get credentials: %w", err)
+	}
+	if !credential.MFAEnabled {
+		return ErrMFANotEnabled
+	}
+	if !validateMFACode(credential.MFASecret, code, s.now()) {
+		return ErrInvalidMFACode
+	}
+
+	return s.appendMFAEvent(ctx, member, "MFADisabled", MFADisabledEvent{ID: memberID}, false, "")
+}
+
+// appendMFAEvent records an MFA change and applies it to the member's
+// credentials in the same transaction.
+func (s *service) appendMFAEvent(ctx context.Context, member *Member, eventType string, data interface{}, enabled bool, secret string) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	event := eventstore.Event{
+		AggregateID:   member.ID,
+		AggregateType: "member",
+		EventType:     eventType,
+		EventData:     jsonData,
+		Version:       member.Version + 1,
+	}
+
+	return database.InTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.appendMemberEventsTx(ctx, tx, member.ID, member.Version, []eventstore.Event{event}); err != nil {
+			return fmt.Errorf("failed to append event: %w", err)
+		}
+		if err := setMFA(ctx, tx, member, enabled, secret); err != nil {
+			return fmt.Errorf("failed to update read model: %w", err)
+		}
+		return nil
+	})
+}
+
+func setMFA(ctx context.Context, tx *sql.Tx, member *Member, enabled bool, secret string) error {
+	var mfaSecret interface{}
+	if secret != "" {
+		mfaSecret = secret
+	}
+	_, err := tx.ExecContext(ctx, `
+		UPDATE credentials
+		SET mfa_enabled = $1, mfa_secret = $2, updated_at = NOW()
+		WHERE member_id = $3
+	`, enabled, mfaSecret, member.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE members
+		SET version = $1, updated_at = NOW()
+		WHERE id = $2
+	`, member.Version+1, member.ID)
+	return err
+}
@@ -0,0 +1,40 @@
+package membership
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateMFACode(t *testing.T) {
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: mfaIssuer, AccountName: "test@example.com"})
+	require.NoError(t, err)
+	secret := key.Secret()
+
+	now := time.Date(2024, 1, 1, 12, 0, 15, 0, time.UTC)
+	code, err := totp.GenerateCodeCustom(secret, now, mfaValidateOpts)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		code string
+		at   time.Time
+		want bool
+	}{
+		{"current step", code, now, true},
+		{"one step behind", code, now.Add(30 * time.Second), true},
+		{"one step ahead", code, now.Add(-30 * time.Second), true},
+		{"two steps away", code, now.Add(90 * time.Second), false},
+		{"wrong code", "000000", now, code == "000000"},
+		{"empty code", "", now, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, validateMFACode(secret, tt.code, tt.at))
+		})
+	}
+}
@@ -3,39 +3,137 @@ package membership
 
 import (
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/base64"
 	"fmt"
+	"strings"
+
 	"golang.org/x/crypto/argon2"
 )
 
-// hashPassword generates a salted Argon2id hash of the password.
-func hashPassword(password string) (string, string, error) {
-	salt := make([]byte, 16)
-	if _, err := rand.Read(salt); err != nil {
-		return "", "", err
+// Argon2Params are the Argon2id cost parameters new password hashes use.
+// Each hash records the parameters it was made with, so they can be raised
+// without invalidating existing passwords.
+type Argon2Params struct {
+	Time       uint32 // passes over memory
+	Memory     uint32 // KiB
+	Threads    uint8
+	SaltLength uint32
+	KeyLength  uint32
+}
+
+// DefaultArgon2Params follows the second recommendation of RFC 9106.
+func DefaultArgon2Params() Argon2Params {
+	return Argon2Params{Time: 3, Memory: 64 * 1024, Threads: 4, SaltLength: 16, KeyLength: 32}
+}
+
+// legacyArgon2Params produced the bare base64 hashes stored, with a separate
+// salt, before hashes carried their own parameters.
+var legacyArgon2Params = Argon2Params{Time: 1, Memory: 64 * 1024, Threads: 4, SaltLength: 16, KeyLength: 32}
+
+// WithArgon2Params sets the cost of new password hashes. Members whose hash
+// was made at a different cost are re-hashed the next time they log in.
+func WithArgon2Params(params Argon2Params) Option {
+	return func(s *service) {
+		s.argon2 = params
 	}
+}
 
-	hash := argon2.IDKey([]byte(password), salt, 1, 64*1024, 4, 32)
+// ParseArgon2Params parses a cost list in PHC form, such as "m=65536,t=3,p=4".
+// Salt and key lengths keep their default values.
+func ParseArgon2Params(s string) (Argon2Params, error) {
+	params := DefaultArgon2Params()
+	if _, err := fmt.Sscanf(s, "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads); err != nil {
+		return Argon2Params{}, fmt.Errorf("invalid Argon2 parameters %q: want m=<KiB>,t=<passes>,p=<threads>", s)
+	}
+	if params.Memory == 0 || params.Time == 0 || params.Threads == 0 {
+		return Argon2Params{}, fmt.Errorf("invalid Argon2 parameters %q: must be positive", s)
+	}
+	return params, nil
+}
 
-	encodedHash := base64.StdEncoding.EncodeToString(hash)
-	encodedSalt := base64.StdEncoding.EncodeToString(salt)
+// hashPassword generates a salted Argon2id hash of the password, encoded in
+// PHC string format: $argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>.
+func hashPassword(password string, params Argon2Params) (string, error) {
+	salt := make([]byte, params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	hash := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, params.KeyLength)
 
-	return encodedHash, encodedSalt, nil
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, params.Memory, params.Time, params.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(hash),
+	), nil
 }
 
-// verifyPassword compares a password with a salted hash.
+// verifyPassword compares a password with a stored hash. The salt is only
+// used for legacy hashes; PHC-encoded hashes carry their own.
 func verifyPassword(password, salt, hash string) (bool, error) {
-	decodedSalt, err := base64.StdEncoding.DecodeString(salt)
+	params, decodedSalt, decodedHash, err := decodeHash(salt, hash)
 	if err != nil {
-		return false, fmt.Errorf("failed to decode salt: %w", err)
+		return false, err
 	}
 
-	decodedHash, err := base64.StdEncoding.DecodeString(hash)
-	if err != nil {
-		return false, fmt.Errorf("failed to decode hash: %w", err)
+	comparisonHash := argon2.IDKey([]byte(password), decodedSalt, params.Time, params.Memory, params.Threads, uint32(len(decodedHash)))
+
+	return subtle.ConstantTimeCompare(decodedHash, comparisonHash) == 1, nil
+}
+
+// needsRehash reports whether a stored hash was made with parameters other
+// than the current ones, including every legacy hash.
+func needsRehash(salt, hash string, current Argon2Params) bool {
+	if !isPHC(hash) {
+		return true
 	}
+	params, _, _, err := decodeHash(salt, hash)
+	return err != nil || params != current
+}
 
-	comparisonHash := argon2.IDKey([]byte(password), decodedSalt, 1, 64*1024, 4, 32)
+func isPHC(hash string) bool {
+	return strings.HasPrefix(hash, "$argon2id$")
+}
+
+// decodeHash splits a stored hash into its parameters, salt and key.
+func decodeHash(salt, hash string) (Argon2Params, []byte, []byte, error) {
+	if !isPHC(hash) {
+		decodedSalt, err := base64.StdEncoding.DecodeString(salt)
+		if err != nil {
+			return Argon2Params{}, nil, nil, fmt.Errorf("failed to decode salt: %w", err)
+		}
+		decodedHash, err := base64.StdEncoding.DecodeString(hash)
+		if err != nil {
+			return Argon2Params{}, nil, nil, fmt.Errorf("failed to decode hash: %w", err)
+		}
+		return legacyArgon2Params, decodedSalt, decodedHash, nil
+	}
 
-	return string(decodedHash) == string(comparisonHash), nil
+	// "$argon2id$v=19$m=...,t=...,p=...$salt$hash" splits into a leading
+	// empty field and five parts.
+	fields := strings.Split(hash, "$")
+	if len(fields) != 6 {
+		return Argon2Params{}, nil, nil, fmt.Errorf("malformed password hash")
+	}
+	var version int
+	if _, err := fmt.Sscanf(fields[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return Argon2Params{}, nil, nil, fmt.Errorf("unsupported Argon2 version %q", fields[2])
+	}
+	var params Argon2Params
+	if _, err := fmt.Sscanf(fields[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads); err != nil ||
+		params.Memory == 0 || params.Time == 0 || params.Threads == 0 {
+		return Argon2Params{}, nil, nil, fmt.Errorf("malformed Argon2 parameters %q", fields[3])
+	}
+	decodedSalt, err := base64.RawStdEncoding.DecodeString(fields[4])
+	if err != nil {
+		return Argon2Params{}, nil, nil, fmt.Errorf("failed to decode salt: %w", err)
+	}
+	decodedHash, err := base64.RawStdEncoding.DecodeString(fields[5])
+	if err != nil {
+		return Argon2Params{}, nil, nil, fmt.Errorf("failed to decode hash: %w", err)
+	}
+	params.SaltLength = uint32(len(decodedSalt))
+	params.KeyLength = uint32(len(decodedHash))
+	return params, decodedSalt, decodedHash, nil
 }
@@ -0,0 +1,96 @@
+package membership
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/argon2"
+)
+
+// cheapArgon2Params keeps the tests fast.
+var cheapArgon2Params = Argon2Params{Time: 1, Memory: 1024, Threads: 1, SaltLength: 16, KeyLength: 32}
+
+func TestHashPasswordRoundTrip(t *testing.T) {
+	hash, err := hashPassword("SecurePass123!", cheapArgon2Params)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"), hash)
+
+	ok, err := verifyPassword("SecurePass123!", "", hash)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = verifyPassword("WrongPass123!", "", hash)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	other, err := hashPassword("SecurePass123!", cheapArgon2Params)
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other, "each hash gets its own salt")
+}
+
+func TestVerifyLegacyPassword(t *testing.T) {
+	salt := []byte("0123456789abcdef")
+	p := legacyArgon2Params
+	key := argon2.IDKey([]byte("SecurePass123!"), salt, p.Time, p.Memory, p.Threads, p.KeyLength)
+	legacySalt := base64.StdEncoding.EncodeToString(salt)
+	legacyHash := base64.StdEncoding.EncodeToString(key)
+
+	ok, err := verifyPassword("SecurePass123!", legacySalt, legacyHash)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = verifyPassword("WrongPass123!", legacySalt, legacyHash)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestNeedsRehash(t *testing.T) {
+	current, err := hashPassword("SecurePass123!", cheapArgon2Params)
+	require.NoError(t, err)
+
+	assert.False(t, needsRehash("", current, cheapArgon2Params))
+
+	stronger := cheapArgon2Params
+	stronger.Time = 2
+	assert.True(t, needsRehash("", current, stronger), "raised cost")
+
+	assert.True(t, needsRehash("c2FsdA==", "aGFzaA==", cheapArgon2Params), "legacy hash")
+	assert.True(t, needsRehash("", "$argon2id$garbage", cheapArgon2Params), "unreadable hash")
+}
+
+func TestVerifyPasswordRejectsMalformedHashes(t *testing.T) {
+	for _, hash := range []string{
+		"$argon2id$v=19$m=1024,t=1,p=1$c2FsdA",
+		"$argon2id$v=16$m=1024,t=1,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=1024,t=1,p=0$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=1024,t=1,p=1$!!!$aGFzaA",
+	} {
+		_, err := verifyPassword("SecurePass123!", "", hash)
+		assert.Error(t, err, hash)
+	}
+}
+
+func TestParseArgon2Params(t *testing.T) {
+	params, err := ParseArgon2Params("m=131072,t=4,p=2")
+	require.NoError(t, err)
+	assert.Equal(t, Argon2Params{Time: 4, Memory: 131072, Threads: 2, SaltLength: 16, KeyLength: 32}, params)
+
+	for _, s := range []string{"", "t=3", "m=0,t=3,p=4", "m=65536,t=3,p=999"} {
+		_, err := ParseArgon2Params(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestDummyHashMatchesCurrentCost(t *testing.T) {
+	s := NewService(nil, nil, WithArgon2Params(cheapArgon2Params)).(*service)
+
+	// Rejecting an unknown email must cost the same Argon2 work as checking
+	// a real member's password.
+	assert.False(t, needsRehash("", s.dummyHash, cheapArgon2Params))
+	ok, err := verifyPassword("SecurePass123!", "", s.dummyHash)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
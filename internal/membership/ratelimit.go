@@ -0,0 +1,162 @@
+// internal/membership/ratelimit.go
+package membership
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// DefaultRateLimit allows a burst of five requests, refilled at five a minute.
+var DefaultRateLimit = RateLimit{Requests: 5, Per: time.Minute}
+
+// RateLimit allows a burst of Requests, refilled at Requests per Per.
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// ParseRateLimit parses a limit written as requests/period, such as "5/1m".
+func ParseRateLimit(s string) (RateLimit, error) {
+	requests, per, ok := strings.Cut(s, "/")
+	if !ok {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q: want requests/period", s)
+	}
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q: requests must be a positive integer", s)
+	}
+	d, err := time.ParseDuration(per)
+	if err != nil || d <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q: period must be a positive duration", s)
+	}
+	return RateLimit{Requests: n, Per: d}, nil
+}
+
+// WithRateLimits sets the per-email budget for logins and reset requests and
+// the per-client-IP budget for registrations and password resets.
+func WithRateLimits(login, register RateLimit) Option {
+	return func(s *service) {
+		s.loginLimiter = newKeyedLimiter(login)
+		s.registerLimiter = newKeyedLimiter(register)
+	}
+}
+
+// RateLimitError reports that a caller has used up their budget. It matches
+// ErrRateLimited.
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s: retry after %s", ErrRateLimited, e.RetryAfter.Round(time.Second))
+}
+
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// keyedLimiter gives every key, such as an email or IP address, its own token
+// bucket. Buckets that have been idle long enough to refill completely are
+// indistinguishable from new ones, so they are evicted.
+type keyedLimiter struct {
+	limit rate.Limit
+	burst int
+	idle  time.Duration
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+func newKeyedLimiter(l RateLimit) *keyedLimiter {
+	return &keyedLimiter{
+		limit:   rate.Every(l.Per / time.Duration(l.Requests)),
+		burst:   l.Requests,
+		idle:    l.Per,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// allow takes a token from key's bucket, or returns a *RateLimitError saying
+// when the next one will be available.
+func (k *keyedLimiter) allow(key string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := k.now()
+	if now.Sub(k.lastSweep) >= k.idle {
+		k.sweep(now)
+	}
+
+	b, ok := k.buckets[key]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(k.limit, k.burst)}
+		k.buckets[key] = b
+	}
+	b.lastSeen = now
+
+	r := b.limiter.ReserveN(now, 1)
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		return &RateLimitError{RetryAfter: delay}
+	}
+	return nil
+}
+
+func (k *keyedLimiter) sweep(now time.Time) {
+	for key, b := range k.buckets {
+		if now.Sub(b.lastSeen) >= k.idle {
+			delete(k.buckets, key)
+		}
+	}
+	k.lastSweep = now
+}
+
+type clientIPKey struct{}
+
+// withClientIP records the caller's IP address on ctx for rate limiting.
+func withClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+func clientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
+
+// clientIP returns the address of the client a request came from. Behind the
+// gateway that is the last X-Forwarded-For entry, which the gateway's proxy
+// appended itself; earlier entries are supplied by the client and untrusted.
+func clientIP(r *http.Request) string {
+	if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+		parts := strings.Split(fwd, ",")
+		return strings.TrimSpace(parts[len(parts)-1])
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// retryAfterSeconds formats a delay for the Retry-After header, which counts
+// whole seconds.
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
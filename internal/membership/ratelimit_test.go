@@ -0,0 +1,66 @@
+package membership
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyedLimiterBudgetsEachKey(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	k := newKeyedLimiter(RateLimit{Requests: 2, Per: time.Minute})
+	k.now = func() time.Time { return now }
+
+	require.NoError(t, k.allow("ada@example.com"))
+	require.NoError(t, k.allow("ada@example.com"))
+
+	err := k.allow("ada@example.com")
+	var limited *RateLimitError
+	require.ErrorAs(t, err, &limited)
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.Equal(t, 30*time.Second, limited.RetryAfter)
+
+	assert.NoError(t, k.allow("grace@example.com"), "another key has its own budget")
+
+	now = now.Add(30 * time.Second)
+	assert.NoError(t, k.allow("ada@example.com"), "a token is refilled after Per/Requests")
+}
+
+func TestKeyedLimiterEvictsIdleBuckets(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	k := newKeyedLimiter(RateLimit{Requests: 5, Per: time.Minute})
+	k.now = func() time.Time { return now }
+
+	for _, key := range []string{"a", "b", "c"} {
+		require.NoError(t, k.allow(key))
+	}
+	assert.Len(t, k.buckets, 3)
+
+	now = now.Add(time.Minute)
+	require.NoError(t, k.allow("d"))
+	assert.Len(t, k.buckets, 1)
+}
+
+func TestParseRateLimit(t *testing.T) {
+	l, err := ParseRateLimit("10/1h")
+	require.NoError(t, err)
+	assert.Equal(t, RateLimit{Requests: 10, Per: time.Hour}, l)
+
+	for _, s := range []string{"", "10", "0/1m", "x/1m", "10/forever", "10/-1m"} {
+		_, err := ParseRateLimit(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/register", nil)
+	r.RemoteAddr = "10.0.0.5:41234"
+	assert.Equal(t, "10.0.0.5", clientIP(r))
+
+	r.Header.Set("X-Forwarded-For", "1.2.3.4, 203.0.113.7")
+	assert.Equal(t, "203.0.113.7", clientIP(r), "only the entry the gateway appended is trusted")
+}
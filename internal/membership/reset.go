@@ -0,0 +1,176 @@
+// internal/membership/reset.go
+package membership
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"libranexus/internal/database"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+)
+
+// DefaultResetTokenTTL is how long a password reset token stays valid unless
+// configured otherwise.
+const DefaultResetTokenTTL = 1 * time.Hour
+
+// ResetTokenSender delivers a password reset token to the member, typically
+// as a link in an email.
+type ResetTokenSender func(ctx context.Context, member *Member, token string, expiresAt time.Time) error
+
+// WithResetTokenTTL sets how long a password reset token stays valid.
+func WithResetTokenTTL(ttl time.Duration) Option {
+	return func(s *service) {
+		s.resetTokenTTL = ttl
+	}
+}
+
+// WithResetTokenSender sets how reset tokens reach members. Without one,
+// requests are only logged and no token can be delivered.
+func WithResetTokenSender(send ResetTokenSender) Option {
+	return func(s *service) {
+		s.sendResetToken = send
+	}
+}
+
+// RequestPasswordReset issues a reset token for the member registered with
+// email and hands it to the ResetTokenSender. An unknown email is not an
+// error, so callers cannot use this to discover who is a member. Issuing a
+// token spends any the member was sent before.
+func (s *service) RequestPasswordReset(ctx context.Context, email string) error {
+	email = NormalizeEmail(email)
+	if err := admit(ctx, s.loginLimiter, email); err != nil {
+		return err
+	}
+
+	member, err := s.getMemberByEmail(ctx, email)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find member: %w", err)
+	}
+
+	token, err := newResetToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate reset token: %w", err)
+	}
+	expiresAt := s.now().Add(s.resetTokenTTL)
+
+	err = database.InTx(ctx, s.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE password_reset_tokens SET used_at = NOW()
+			WHERE member_id = $1 AND used_at IS NULL
+		`, member.ID); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO password_reset_tokens (token_hash, member_id, expires_at)
+			VALUES ($1, $2, $3)
+		`, hashResetToken(token), member.ID, expiresAt)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store reset token: %w", err)
+	}
+
+	if s.sendResetToken == nil {
+		log.Printf("Password reset requested for member %s, but no reset token sender is configured", member.ID)
+		return nil
+	}
+	if err := s.sendResetToken(ctx, member, token, expiresAt); err != nil {
+		return fmt.Errorf("failed to send reset token: %w", err)
+	}
+	return nil
+}
+
+// ResetPassword sets a new password for the member a reset token was issued
+// to. The token is spent in the same transaction that records the change, so
+// it works at most once even when submitted concurrently. A successful reset
+// also clears any login lockout.
+func (s *service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	if err := admit(ctx, s.registerLimiter, clientIPFromContext(ctx)); err != nil {
+		return err
+	}
+	if err := s.passwordPolicy.Validate(newPassword); err != nil {
+		return &ValidationError{Fields: map[string]string{"password": err.Error()}}
+	}
+
+	passwordHash, err := hashPassword(newPassword, s.argon2)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	return database.InTx(ctx, s.db, func(tx *sql.Tx) error {
+		var memberID uuid.UUID
+		err := tx.QueryRowContext(ctx, `
+			UPDATE password_reset_tokens SET used_at = NOW()
+			WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $2
+			RETURNING member_id
+		`, hashResetToken(token), s.now()).Scan(&memberID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidResetToken
+		}
+		if err != nil {
+			return fmt.Errorf("failed to consume reset token: %w", err)
+		}
+
+		var version int
+		if err := tx.QueryRowContext(ctx, `SELECT version FROM members WHERE id = $1`, memberID).Scan(&version); err != nil {
+			return fmt.Errorf("failed to get member: %w", err)
+		}
+
+		jsonData, err := json.Marshal(PasswordChangedEvent{ID: memberID})
+		if err != nil {
+			return fmt.Errorf("failed to marshal event data: %w", err)
+		}
+		event := eventstore.Event{
+			AggregateID:   memberID,
+			AggregateType: "member",
+			EventType:     "PasswordChanged",
+			EventData:     jsonData,
+			Version:       version + 1,
+		}
+		if err := s.appendMemberEventsTx(ctx, tx, memberID, version, []eventstore.Event{event}); err != nil {
+			return fmt.Errorf("failed to append event: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE credentials
+			SET password_hash = $1, salt = '', failed_attempts = 0, locked_until = NULL, updated_at = NOW()
+			WHERE member_id = $2
+		`, passwordHash, memberID); err != nil {
+			return fmt.Errorf("failed to update read model: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE members SET version = $1, updated_at = NOW() WHERE id = $2
+		`, version+1, memberID); err != nil {
+			return fmt.Errorf("failed to update read model: %w", err)
+		}
+		return nil
+	})
+}
+
+// newResetToken returns a random, URL-safe reset token.
+func newResetToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashResetToken is the form a reset token is stored and looked up in.
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,23 @@
+package membership
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResetTokens(t *testing.T) {
+	first, err := newResetToken()
+	require.NoError(t, err)
+	second, err := newResetToken()
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first, second)
+	assert.Len(t, first, 43, "32 random bytes, base64url without padding")
+
+	assert.Equal(t, hashResetToken(first), hashResetToken(first))
+	assert.NotEqual(t, hashResetToken(first), hashResetToken(second))
+	assert.Len(t, hashResetToken(first), 64, "matches the CHAR(64) column")
+	assert.NotContains(t, hashResetToken(first), first)
+}
@@ -0,0 +1,87 @@
+// internal/membership/roles.go
+package membership
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"libranexus/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+)
+
+// Roles a member can hold. Every member starts as RoleMember; RoleAdmin may
+// also manage the catalog and other members.
+const (
+	RoleMember = "member"
+	RoleAdmin  = "admin"
+)
+
+// validRole reports whether role is one members can be given.
+func validRole(role string) bool {
+	return role == RoleMember || role == RoleAdmin
+}
+
+// SetMemberRole gives a member a new role. It takes effect at the member's
+// next login, when their token is reissued.
+func (s *service) SetMemberRole(ctx context.Context, id uuid.UUID, role string) (*Member, error) {
+	if !validRole(role) {
+		return nil, ErrUnknownRole
+	}
+
+	member, err := s.readMember(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role == role {
+		return member, nil
+	}
+
+	changed := *member
+	changed.Role = role
+	changed.Version++
+
+	event, err := newMemberEvent(id, "MemberRoleChanged", changed.Version, MemberRoleChangedEvent{ID: id, Role: role})
+	if err != nil {
+		return nil, err
+	}
+	err = database.InTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.appendMemberEventsTx(ctx, tx, id, member.Version, []eventstore.Event{event}); err != nil {
+			return fmt.Errorf("failed to append event: %w", err)
+		}
+		query := `UPDATE members SET role = $1, version = $2, updated_at = NOW() WHERE id = $3`
+		if _, err := tx.ExecContext(ctx, query, role, changed.Version, id); err != nil {
+			return fmt.Errorf("failed to update read model: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &changed, nil
+}
+
+// BootstrapAdmin makes the member registered with email an administrator,
+// provided the library has none yet, so a fresh deployment can appoint its
+// first one. It reports whether the role was granted; once any administrator
+// exists it does nothing.
+func (s *service) BootstrapAdmin(ctx context.Context, email string) (bool, error) {
+	ctx = eventstore.WithActorID(ctx, eventstore.SystemActorID)
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM members WHERE role = $1)`, RoleAdmin).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check for administrators: %w", err)
+	}
+	if exists {
+		return false, nil
+	}
+
+	member, err := s.GetMemberByEmail(ctx, email)
+	if err != nil {
+		return false, err
+	}
+	if _, err := s.SetMemberRole(ctx, member.ID, RoleAdmin); err != nil {
+		return false, err
+	}
+	return true, nil
+}
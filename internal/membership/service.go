@@ -3,6 +3,7 @@ package membership
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -10,7 +11,24 @@ import (
 // Service defines the interface for the membership service.
 type Service interface {
 	RegisterMember(ctx context.Context, email, name, password string) (*Member, error)
-	Authenticate(ctx context.Context, email, password string) (*Member, error)
+	Authenticate(ctx context.Context, email, password, mfaCode string) (*Member, error)
 	GetMember(ctx context.Context, id uuid.UUID) (*Member, error)
+	GetMemberByEmail(ctx context.Context, email string) (*Member, error)
 	UpdateMemberTier(ctx context.Context, id uuid.UUID, newTier string) error
+	ScheduleTierChange(ctx context.Context, id uuid.UUID, newTier string, effective time.Time) error
+	ApplyDueTierChanges(ctx context.Context) (int, error)
+	GetTierHistory(ctx context.Context, id uuid.UUID) (*TierHistory, error)
+	GetMemberEvents(ctx context.Context, id uuid.UUID, fromVersion, toVersion int) ([]Event, error)
+	RenewMembership(ctx context.Context, id uuid.UUID, term time.Duration) (*Member, error)
+	ExpireMemberships(ctx context.Context) (int, error)
+	SuspendMember(ctx context.Context, id uuid.UUID, reason string) (*Member, error)
+	ReactivateMember(ctx context.Context, id uuid.UUID) (*Member, error)
+	SetMemberRole(ctx context.Context, id uuid.UUID, role string) (*Member, error)
+	BootstrapAdmin(ctx context.Context, email string) (bool, error)
+	ChargeFine(ctx context.Context, id uuid.UUID, amount float64, reason, reference string) (*Member, error)
+	PayFine(ctx context.Context, id uuid.UUID, amount float64) (*Member, error)
+	EnableMFA(ctx context.Context, memberID uuid.UUID) (secret string, otpauthURL string, err error)
+	DisableMFA(ctx context.Context, memberID uuid.UUID, code string) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
 }
@@ -0,0 +1,74 @@
+// internal/membership/servicecalls.go
+package membership
+
+import (
+	"context"
+	"crypto/subtle"
+	"libranexus/internal/logging"
+	"net/http"
+)
+
+// Headers by which another LibraNexus service identifies its requests. The
+// gateway strips both from client requests.
+const (
+	ServiceTokenHeader = "X-Service-Token"
+	ServiceNameHeader  = "X-Service-Name"
+)
+
+// unnamedService stands in for a caller that presented the token but no name.
+const unnamedService = "unnamed"
+
+type serviceCallerKey struct{}
+
+// withServiceCaller records on ctx that the request came from the named
+// service rather than a person.
+func withServiceCaller(ctx context.Context, service string) context.Context {
+	return context.WithValue(ctx, serviceCallerKey{}, service)
+}
+
+// serviceCallerFromContext returns the service a request came from, or "" if
+// it came from a person.
+func serviceCallerFromContext(ctx context.Context) string {
+	service, _ := ctx.Value(serviceCallerKey{}).(string)
+	return service
+}
+
+// IdentifyServices returns middleware that marks requests carrying token in
+// the X-Service-Token header as coming from the service named in
+// X-Service-Name, so the rate limits meant for people do not apply to them.
+// Each such request is logged. A wrong token is logged and the request is
+// treated as a person's. An empty token identifies no requests.
+func IdentifyServices(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		presented := r.Header.Get(ServiceTokenHeader)
+		if presented == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		logger := logging.FromContext(ctx).With("service", r.Header.Get(ServiceNameHeader), "method", r.Method, "path", r.URL.Path)
+		if token == "" || subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+			logger.Warn("rejected service token")
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		service := r.Header.Get(ServiceNameHeader)
+		if service == "" {
+			service = unnamedService
+		}
+		logger.Info("service request")
+		next.ServeHTTP(w, r.WithContext(withServiceCaller(ctx, service)))
+	})
+}
+
+// admit takes a token from key's bucket in l, unless the request on ctx came
+// from another service, whose calls never spend a person's budget.
+func admit(ctx context.Context, l *keyedLimiter, key string) error {
+	if service := serviceCallerFromContext(ctx); service != "" {
+		logging.FromContext(ctx).Info("service request exempt from rate limit", "service", service)
+		return nil
+	}
+	return l.allow(key)
+}
@@ -0,0 +1,56 @@
+package membership
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceCallsDoNotSpendRateLimits(t *testing.T) {
+	limiter := newKeyedLimiter(RateLimit{Requests: 1, Per: time.Minute})
+	handler := IdentifyServices("s3cret", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := admit(r.Context(), limiter, "10.0.0.1"); err != nil {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/members/x", nil)
+		if token != "" {
+			req.Header.Set(ServiceTokenHeader, token)
+			req.Header.Set(ServiceNameHeader, "circulation")
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	for range 3 {
+		assert.Equal(t, http.StatusOK, serve("s3cret"), "service calls are exempt")
+	}
+	assert.Equal(t, http.StatusOK, serve(""), "service calls leave a person's budget untouched")
+	assert.Equal(t, http.StatusTooManyRequests, serve(""))
+	assert.Equal(t, http.StatusTooManyRequests, serve("guess"), "a wrong token is treated as a person")
+}
+
+func TestIdentifyServicesWithoutTokenTrustsNoOne(t *testing.T) {
+	var service string
+	handler := IdentifyServices("", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		service = serviceCallerFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/members/x", nil)
+	req.Header.Set(ServiceTokenHeader, "")
+	req.Header.Set(ServiceNameHeader, "circulation")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Empty(t, service)
+
+	req.Header.Set(ServiceTokenHeader, "anything")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Empty(t, service)
+}
@@ -0,0 +1,60 @@
+package membership
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type suspendingService struct {
+	Service
+	reasons map[uuid.UUID]string
+}
+
+func (s *suspendingService) SuspendMember(ctx context.Context, id uuid.UUID, reason string) (*Member, error) {
+	if _, ok := s.reasons[id]; ok {
+		return nil, ErrMemberSuspended
+	}
+	s.reasons[id] = reason
+	return &Member{ID: id, Status: "suspended", SuspensionReason: reason}, nil
+}
+
+func (s *suspendingService) ReactivateMember(ctx context.Context, id uuid.UUID) (*Member, error) {
+	if _, ok := s.reasons[id]; !ok {
+		return nil, ErrMemberNotSuspended
+	}
+	delete(s.reasons, id)
+	return &Member{ID: id, Status: "active"}, nil
+}
+
+func TestHandleSuspendAndReactivate(t *testing.T) {
+	svc := &suspendingService{reasons: map[uuid.UUID]string{}}
+	h := NewHandler(svc, nil)
+	id := uuid.New()
+
+	post := func(action, role, body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/members/"+id.String()+"/"+action, bytes.NewBufferString(body))
+		if role != "" {
+			req.Header.Set(memberRoleHeader, role)
+		}
+		rec := httptest.NewRecorder()
+		h.HandleMember(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, post("suspend", "", `{"reason":"damaged items"}`))
+	assert.Equal(t, http.StatusForbidden, post("suspend", "member", `{"reason":"damaged items"}`))
+	assert.Equal(t, http.StatusBadRequest, post("suspend", RoleAdmin, `{"reason":"  "}`))
+	assert.Equal(t, http.StatusOK, post("suspend", RoleAdmin, `{"reason":"damaged items"}`))
+	assert.Equal(t, "damaged items", svc.reasons[id])
+	assert.Equal(t, http.StatusConflict, post("suspend", RoleAdmin, `{"reason":"again"}`))
+
+	assert.Equal(t, http.StatusForbidden, post("reactivate", "", ""))
+	assert.Equal(t, http.StatusOK, post("reactivate", RoleAdmin, ""))
+	assert.Equal(t, http.StatusConflict, post("reactivate", RoleAdmin, ""))
+}
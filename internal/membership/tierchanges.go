@@ -0,0 +1,170 @@
+// internal/membership/tierchanges.go
+package membership
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"libranexus/internal/logging"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+)
+
+// registrationTier is the tier every member starts on.
+const registrationTier = "basic"
+
+// tierChangeBatchSize bounds how many due tier changes one pass applies.
+const tierChangeBatchSize = 100
+
+// ScheduleTierChange moves a member to newTier at effective. An effective
+// time that has already passed changes the tier now. A member has at most
+// one scheduled change: scheduling another replaces it, and an immediate
+// change cancels it.
+func (s *service) ScheduleTierChange(ctx context.Context, id uuid.UUID, newTier string, effective time.Time) error {
+	if _, err := s.maxCheckouts(newTier); err != nil {
+		return err
+	}
+
+	if !effective.After(s.now()) {
+		return s.changeTier(ctx, id, newTier, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM scheduled_tier_changes WHERE member_id = $1`, id); err != nil {
+				return fmt.Errorf("failed to cancel scheduled tier change: %w", err)
+			}
+			return nil
+		})
+	}
+
+	if _, err := s.GetMember(ctx, id); err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO scheduled_tier_changes (member_id, new_tier, effective_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (member_id) DO UPDATE
+		SET new_tier = EXCLUDED.new_tier, effective_at = EXCLUDED.effective_at, scheduled_at = NOW()
+	`
+	if _, err := s.db.ExecContext(ctx, query, id, newTier, effective); err != nil {
+		return fmt.Errorf("failed to schedule tier change: %w", err)
+	}
+	return nil
+}
+
+// ApplyDueTierChanges applies the scheduled tier changes whose effective
+// time has come and returns how many it applied. A change that fails is
+// logged and left for the next pass, unless its tier is no longer known or
+// its member is gone, in which case it is dropped.
+func (s *service) ApplyDueTierChanges(ctx context.Context) (int, error) {
+	ctx = eventstore.WithActorID(ctx, eventstore.SystemActorID)
+	query := `
+		SELECT member_id, new_tier, effective_at
+		FROM scheduled_tier_changes
+		WHERE effective_at <= $1
+		ORDER BY effective_at
+		LIMIT $2
+	`
+	rows, err := s.db.QueryContext(ctx, query, s.now(), tierChangeBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find due tier changes: %w", err)
+	}
+	type dueChange struct {
+		memberID uuid.UUID
+		ScheduledTierChange
+	}
+	var due []dueChange
+	for rows.Next() {
+		var c dueChange
+		if err := rows.Scan(&c.memberID, &c.NewTier, &c.EffectiveAt); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan tier change: %w", err)
+		}
+		due = append(due, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to find due tier changes: %w", err)
+	}
+
+	applied := 0
+	for _, c := range due {
+		// The delete only matches the change as it was read, so one
+		// rescheduled in the meantime is kept for its new time.
+		unschedule := func(tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, `
+				DELETE FROM scheduled_tier_changes
+				WHERE member_id = $1 AND new_tier = $2 AND effective_at = $3
+			`, c.memberID, c.NewTier, c.EffectiveAt)
+			return err
+		}
+		logger := logging.FromContext(ctx).With("member_id", c.memberID, "new_tier", c.NewTier)
+		err := s.changeTier(ctx, c.memberID, c.NewTier, unschedule)
+		if errors.Is(err, ErrUnknownTier) || errors.Is(err, ErrMemberNotFound) {
+			logger.Error("dropped scheduled tier change", "err", err)
+			if _, err := s.db.ExecContext(ctx, `DELETE FROM scheduled_tier_changes WHERE member_id = $1`, c.memberID); err != nil {
+				logger.Error("failed to drop scheduled tier change", "err", err)
+			}
+			continue
+		}
+		if err != nil {
+			logger.Warn("failed to apply scheduled tier change", "err", err)
+			continue
+		}
+		applied++
+	}
+	return applied, nil
+}
+
+// GetTierHistory replays a member's events into the tiers they have held,
+// alongside any change still scheduled.
+func (s *service) GetTierHistory(ctx context.Context, id uuid.UUID) (*TierHistory, error) {
+	events, err := s.eventStore.LoadEventsWithOptions(ctx, id, 1, 0, eventstore.LoadEventsOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load member events: %w", err)
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrMemberNotFound, id)
+	}
+	changes, err := tierChanges(events)
+	if err != nil {
+		return nil, err
+	}
+	history := &TierHistory{Changes: changes}
+
+	scheduled := &ScheduledTierChange{}
+	err = s.db.QueryRowContext(ctx, `
+		SELECT new_tier, effective_at FROM scheduled_tier_changes WHERE member_id = $1
+	`, id).Scan(&scheduled.NewTier, &scheduled.EffectiveAt)
+	switch {
+	case err == nil:
+		history.Scheduled = scheduled
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, fmt.Errorf("failed to get scheduled tier change: %w", err)
+	}
+	return history, nil
+}
+
+// tierChanges folds a member's events into their tier history, starting
+// with the tier they registered on.
+func tierChanges(events []eventstore.Event) ([]TierChange, error) {
+	var changes []TierChange
+	for _, event := range events {
+		switch event.EventType {
+		case "MemberRegistered":
+			var e MemberRegisteredEvent
+			if err := json.Unmarshal(event.EventData, &e); err != nil {
+				return nil, fmt.Errorf("failed to decode %s: %w", event.EventType, err)
+			}
+			changes = append(changes, TierChange{Tier: registrationTier, MaxCheckouts: e.MaxCheckouts, ChangedAt: event.CreatedAt, Version: event.Version})
+		case "MemberTierChanged":
+			var e MemberTierChangedEvent
+			if err := json.Unmarshal(event.EventData, &e); err != nil {
+				return nil, fmt.Errorf("failed to decode %s: %w", event.EventType, err)
+			}
+			changes = append(changes, TierChange{Tier: e.NewTier, MaxCheckouts: e.MaxCheckouts, ChangedAt: event.CreatedAt, Version: event.Version})
+		}
+	}
+	return changes, nil
+}
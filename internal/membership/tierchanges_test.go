@@ -0,0 +1,102 @@
+package membership
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func memberEvent(t *testing.T, id uuid.UUID, eventType string, version int, data interface{}) eventstore.Event {
+	t.Helper()
+	jsonData, err := json.Marshal(data)
+	require.NoError(t, err)
+	return eventstore.Event{
+		AggregateID:   id,
+		AggregateType: "member",
+		EventType:     eventType,
+		EventData:     jsonData,
+		Version:       version,
+		CreatedAt:     time.Date(2024, time.Month(version), 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestTierChangesReplaysTierEvents(t *testing.T) {
+	id := uuid.New()
+	events := []eventstore.Event{
+		memberEvent(t, id, "MemberRegistered", 1, MemberRegisteredEvent{ID: id, Email: "ada@example.com", MaxCheckouts: 3}),
+		memberEvent(t, id, "FineCharged", 2, FineChargedEvent{ID: id, Amount: 1}),
+		memberEvent(t, id, "MemberTierChanged", 3, MemberTierChangedEvent{ID: id, PreviousTier: "basic", NewTier: "premium", MaxCheckouts: 10}),
+		memberEvent(t, id, "MemberTierChanged", 4, MemberTierChangedEvent{ID: id, PreviousTier: "premium", NewTier: "basic", MaxCheckouts: 3}),
+	}
+
+	changes, err := tierChanges(events)
+	require.NoError(t, err)
+
+	assert.Equal(t, []TierChange{
+		{Tier: "basic", MaxCheckouts: 3, ChangedAt: events[0].CreatedAt, Version: 1},
+		{Tier: "premium", MaxCheckouts: 10, ChangedAt: events[2].CreatedAt, Version: 3},
+		{Tier: "basic", MaxCheckouts: 3, ChangedAt: events[3].CreatedAt, Version: 4},
+	}, changes)
+}
+
+func TestScheduleTierChangeRejectsUnknownTier(t *testing.T) {
+	s := &service{checkoutLimits: defaultCheckoutLimits, now: time.Now}
+	err := s.ScheduleTierChange(context.Background(), uuid.New(), "platinum", time.Now().Add(time.Hour))
+	assert.ErrorIs(t, err, ErrUnknownTier)
+}
+
+type historyService struct {
+	Service
+	history *TierHistory
+}
+
+func (h historyService) GetTierHistory(ctx context.Context, id uuid.UUID) (*TierHistory, error) {
+	if h.history == nil {
+		return nil, ErrMemberNotFound
+	}
+	return h.history, nil
+}
+
+func TestHandleGetTierHistory(t *testing.T) {
+	effective := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := NewHandler(historyService{history: &TierHistory{
+		Changes:   []TierChange{{Tier: "premium", MaxCheckouts: 10, Version: 2}},
+		Scheduled: &ScheduledTierChange{NewTier: "basic", EffectiveAt: effective},
+	}}, nil)
+
+	memberID := uuid.New()
+	target := "/members/" + memberID.String() + "/tier-history"
+	rec := httptest.NewRecorder()
+	h.HandleMember(rec, viewerRequest(target, memberID, "", ""))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var history TierHistory
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &history))
+	assert.Equal(t, "premium", history.Changes[0].Tier)
+	assert.Equal(t, effective, history.Scheduled.EffectiveAt)
+
+	rec = httptest.NewRecorder()
+	NewHandler(historyService{}, nil).HandleMember(rec, viewerRequest(target, memberID, "", ""))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestHandleGetTierHistoryHidesOthers(t *testing.T) {
+	h := NewHandler(historyService{history: &TierHistory{}}, nil)
+	target := "/members/" + uuid.NewString() + "/tier-history"
+
+	rec := httptest.NewRecorder()
+	h.HandleMember(rec, viewerRequest(target, uuid.New(), "", ""))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.HandleMember(rec, viewerRequest(target, uuid.New(), RoleAdmin, ""))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
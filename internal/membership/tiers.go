@@ -0,0 +1,53 @@
+// internal/membership/tiers.go
+package membership
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// defaultCheckoutLimits is how many items a member may have checked out at
+// once, by membership tier.
+var defaultCheckoutLimits = map[string]int{
+	"basic":     3,
+	"premium":   10,
+	"librarian": 25,
+}
+
+// WithCheckoutLimits sets how many items a member may have checked out at
+// once, by tier. The map must list every tier members can be moved to.
+func WithCheckoutLimits(limits map[string]int) Option {
+	return func(s *service) {
+		if limits != nil {
+			s.checkoutLimits = limits
+		}
+	}
+}
+
+// maxCheckouts returns the checkout limit for a tier.
+func (s *service) maxCheckouts(tier string) (int, error) {
+	limit, ok := s.checkoutLimits[tier]
+	if !ok {
+		return 0, ErrUnknownTier
+	}
+	return limit, nil
+}
+
+// ParseTierLimits parses a per-tier limit list such as
+// "basic=3,premium=10,librarian=25".
+func ParseTierLimits(s string) (map[string]int, error) {
+	limits := make(map[string]int)
+	for _, pair := range strings.Split(s, ",") {
+		tier, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || tier == "" {
+			return nil, fmt.Errorf("invalid tier limit %q: want tier=limit", pair)
+		}
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("invalid limit for tier %q: %q", tier, value)
+		}
+		limits[tier] = limit
+	}
+	return limits, nil
+}
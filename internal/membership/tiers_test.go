@@ -0,0 +1,29 @@
+package membership
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxCheckoutsByTier(t *testing.T) {
+	s := NewService(nil, nil, WithCheckoutLimits(map[string]int{"basic": 2, "premium": 8})).(*service)
+
+	limit, err := s.maxCheckouts("premium")
+	assert.NoError(t, err)
+	assert.Equal(t, 8, limit)
+
+	_, err = s.maxCheckouts("librarian")
+	assert.ErrorIs(t, err, ErrUnknownTier)
+}
+
+func TestParseTierLimits(t *testing.T) {
+	limits, err := ParseTierLimits("basic=3, premium=10")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"basic": 3, "premium": 10}, limits)
+
+	for _, bad := range []string{"", "basic", "basic=many", "basic=-1", "=3"} {
+		_, err := ParseTierLimits(bad)
+		assert.Error(t, err, bad)
+	}
+}
@@ -0,0 +1,161 @@
+// internal/membership/token.go
+package membership
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+)
+
+const defaultTokenTTL = 1 * time.Hour
+
+// Claims are the JWT claims issued to an authenticated member.
+type Claims struct {
+	MemberID uuid.UUID `json:"member_id"`
+	Tier     string    `json:"tier"`
+	Role     string    `json:"role,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// TokenService issues and validates member access tokens.
+type TokenService struct {
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+	ttl       time.Duration
+	now       func() time.Time // stamps and checks expiry
+}
+
+// NewHS256TokenService creates a token service that signs with a shared secret.
+func NewHS256TokenService(secret []byte, ttl time.Duration) (*TokenService, error) {
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("signing key must not be empty")
+	}
+	return &TokenService{
+		method:    jwt.SigningMethodHS256,
+		signKey:   secret,
+		verifyKey: secret,
+		ttl:       ttl,
+		now:       time.Now,
+	}, nil
+}
+
+// NewRS256TokenService creates a token service that signs with an RSA key pair.
+// The private key may be nil for services that only need to validate tokens.
+func NewRS256TokenService(privateKey *rsa.PrivateKey, publicKey *rsa.PublicKey, ttl time.Duration) (*TokenService, error) {
+	if publicKey == nil {
+		if privateKey == nil {
+			return nil, fmt.Errorf("an RSA public or private key is required")
+		}
+		publicKey = &privateKey.PublicKey
+	}
+	ts := &TokenService{
+		method:    jwt.SigningMethodRS256,
+		verifyKey: publicKey,
+		ttl:       ttl,
+		now:       time.Now,
+	}
+	if privateKey != nil {
+		ts.signKey = privateKey
+	}
+	return ts, nil
+}
+
+// NewTokenServiceFromEnv builds a token service from the environment.
+//
+//	JWT_SIGNING_METHOD  HS256 (default) or RS256
+//	JWT_SIGNING_KEY     HMAC secret, or PEM-encoded RSA private key for RS256
+//	JWT_VERIFY_KEY      PEM-encoded RSA public key (RS256 only, optional if the private key is set)
+//	JWT_TTL             token lifetime as a Go duration (default 1h)
+func NewTokenServiceFromEnv() (*TokenService, error) {
+	ttl := defaultTokenTTL
+	if v := os.Getenv("JWT_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT_TTL: %w", err)
+		}
+		ttl = d
+	}
+
+	signingKey := os.Getenv("JWT_SIGNING_KEY")
+
+	switch method := os.Getenv("JWT_SIGNING_METHOD"); method {
+	case "", "HS256":
+		return NewHS256TokenService([]byte(signingKey), ttl)
+	case "RS256":
+		var privateKey *rsa.PrivateKey
+		var publicKey *rsa.PublicKey
+		var err error
+		if signingKey != "" {
+			privateKey, err = jwt.ParseRSAPrivateKeyFromPEM([]byte(signingKey))
+			if err != nil {
+				return nil, fmt.Errorf("invalid JWT_SIGNING_KEY: %w", err)
+			}
+		}
+		if verifyKey := os.Getenv("JWT_VERIFY_KEY"); verifyKey != "" {
+			publicKey, err = jwt.ParseRSAPublicKeyFromPEM([]byte(verifyKey))
+			if err != nil {
+				return nil, fmt.Errorf("invalid JWT_VERIFY_KEY: %w", err)
+			}
+		}
+		return NewRS256TokenService(privateKey, publicKey, ttl)
+	default:
+		return nil, fmt.Errorf("unsupported JWT_SIGNING_METHOD %q", method)
+	}
+}
+
+// IssueToken signs a token for the given member and returns it with its expiry.
+func (ts *TokenService) IssueToken(member *Member) (string, time.Time, error) {
+	if ts.signKey == nil {
+		return "", time.Time{}, fmt.Errorf("token service is not configured for signing")
+	}
+
+	now := ts.now()
+	expiresAt := now.Add(ts.ttl)
+	claims := Claims{
+		MemberID: member.ID,
+		Tier:     member.MembershipTier,
+		Role:     member.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   member.ID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	signed, err := jwt.NewWithClaims(ts.method, claims).SignedString(ts.signKey)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
+	}
+
+	return signed, expiresAt, nil
+}
+
+// ValidateToken verifies a token's signature and expiry and returns its claims.
+func (ts *TokenService) ValidateToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return ts.verifyKey, nil
+	}, jwt.WithValidMethods([]string{ts.method.Alg()}), jwt.WithExpirationRequired(), jwt.WithTimeFunc(ts.now))
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrTokenExpired
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if !token.Valid || claims.MemberID == uuid.Nil {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
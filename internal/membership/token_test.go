@@ -0,0 +1,114 @@
+package membership
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenRoundTrip(t *testing.T) {
+	ts, err := NewHS256TokenService([]byte("test-secret"), time.Minute)
+	require.NoError(t, err)
+
+	member := &Member{ID: uuid.New(), MembershipTier: "premium"}
+	token, expiresAt, err := ts.IssueToken(member)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), expiresAt, 5*time.Second)
+
+	claims, err := ts.ValidateToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, member.ID, claims.MemberID)
+	assert.Equal(t, "premium", claims.Tier)
+	assert.Empty(t, claims.Role)
+}
+
+func TestAdminTokensCarryRole(t *testing.T) {
+	ts, err := NewHS256TokenService([]byte("test-secret"), time.Minute)
+	require.NoError(t, err)
+
+	token, _, err := ts.IssueToken(&Member{ID: uuid.New(), MembershipTier: "basic", Role: RoleAdmin})
+	require.NoError(t, err)
+
+	claims, err := ts.ValidateToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, RoleAdmin, claims.Role)
+}
+
+func TestValidateTokenRejectsExpired(t *testing.T) {
+	ts, err := NewHS256TokenService([]byte("test-secret"), -time.Minute)
+	require.NoError(t, err)
+
+	token, _, err := ts.IssueToken(&Member{ID: uuid.New()})
+	require.NoError(t, err)
+
+	_, err = ts.ValidateToken(token)
+	assert.ErrorIs(t, err, ErrTokenExpired)
+}
+
+func TestTokenExpiryFollowsClock(t *testing.T) {
+	ts, err := NewHS256TokenService([]byte("test-secret"), time.Hour)
+	require.NoError(t, err)
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	ts.now = func() time.Time { return now }
+
+	token, expiresAt, err := ts.IssueToken(&Member{ID: uuid.New()})
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Hour), expiresAt)
+
+	now = now.Add(59 * time.Minute)
+	_, err = ts.ValidateToken(token)
+	assert.NoError(t, err)
+
+	now = now.Add(2 * time.Minute)
+	_, err = ts.ValidateToken(token)
+	assert.ErrorIs(t, err, ErrTokenExpired)
+}
+
+func TestValidateTokenRejectsTampered(t *testing.T) {
+	ts, err := NewHS256TokenService([]byte("test-secret"), time.Minute)
+	require.NoError(t, err)
+	other, err := NewHS256TokenService([]byte("other-secret"), time.Minute)
+	require.NoError(t, err)
+
+	token, _, err := other.IssueToken(&Member{ID: uuid.New()})
+	require.NoError(t, err)
+	_, err = ts.ValidateToken(token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	token, _, err = ts.IssueToken(&Member{ID: uuid.New()})
+	require.NoError(t, err)
+	parts := strings.Split(token, ".")
+	parts[1] = parts[1] + "x"
+	_, err = ts.ValidateToken(strings.Join(parts, "."))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = ts.ValidateToken("not-a-token")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestRS256VerifyOnly(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	signer, err := NewRS256TokenService(key, nil, time.Minute)
+	require.NoError(t, err)
+	verifier, err := NewRS256TokenService(nil, &key.PublicKey, time.Minute)
+	require.NoError(t, err)
+
+	member := &Member{ID: uuid.New(), MembershipTier: "basic"}
+	token, _, err := signer.IssueToken(member)
+	require.NoError(t, err)
+
+	claims, err := verifier.ValidateToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, member.ID, claims.MemberID)
+
+	_, _, err = verifier.IssueToken(member)
+	assert.Error(t, err)
+}
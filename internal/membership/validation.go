@@ -0,0 +1,93 @@
+// internal/membership/validation.go
+package membership
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	defaultPasswordMinLength  = 10
+	defaultPasswordMinClasses = 3
+)
+
+// PasswordPolicy decides which passwords members may choose. A password must
+// be at least MinLength characters and mix at least MinClasses of the four
+// character classes: lower case, upper case, digits and symbols.
+type PasswordPolicy struct {
+	MinLength  int
+	MinClasses int
+}
+
+// DefaultPasswordPolicy requires ten characters from at least three classes.
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{MinLength: defaultPasswordMinLength, MinClasses: defaultPasswordMinClasses}
+}
+
+// WithPasswordPolicy sets the rules new passwords must satisfy.
+func WithPasswordPolicy(policy PasswordPolicy) Option {
+	return func(s *service) {
+		s.passwordPolicy = policy
+	}
+}
+
+// Validate reports why the password breaks the policy, or nil if it does not.
+func (p PasswordPolicy) Validate(password string) error {
+	if n := len([]rune(password)); n < p.MinLength {
+		return fmt.Errorf("must be at least %d characters", p.MinLength)
+	}
+	if classes := characterClasses(password); classes < p.MinClasses {
+		return fmt.Errorf("must mix at least %d of lower case, upper case, digits and symbols", p.MinClasses)
+	}
+	return nil
+}
+
+// ValidatePassword checks a password against the default policy.
+func ValidatePassword(password string) error {
+	return DefaultPasswordPolicy().Validate(password)
+}
+
+// characterClasses counts how many of lower case, upper case, digits and
+// symbols appear in s.
+func characterClasses(s string) int {
+	var lower, upper, digit, symbol bool
+	for _, r := range s {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		case !unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	n := 0
+	for _, present := range []bool{lower, upper, digit, symbol} {
+		if present {
+			n++
+		}
+	}
+	return n
+}
+
+// validateRegistration collects every problem with a registration, so the
+// member can fix them all at once.
+func (s *service) validateRegistration(email, name, password string) error {
+	fields := make(map[string]string)
+	if err := ValidateEmail(email); err != nil {
+		fields["email"] = err.Error()
+	}
+	if strings.TrimSpace(name) == "" {
+		fields["name"] = "is required"
+	}
+	if err := s.passwordPolicy.Validate(password); err != nil {
+		fields["password"] = err.Error()
+	}
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
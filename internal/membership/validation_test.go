@@ -0,0 +1,82 @@
+package membership
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		valid bool
+	}{
+		{"ada@example.com", true},
+		{"ada.lovelace+books@mail.example.co.uk", true},
+		{"", false},
+		{"ada", false},
+		{"ada@", false},
+		{"@example.com", false},
+		{"ada@localhost", false},
+		{"ada@example..com", false},
+		{"ada@example.com.", false},
+		{"Ada <ada@example.com>", false},
+		{"ada@example.com (Ada)", false},
+		{"ada lovelace@example.com", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			err := ValidateEmail(tt.email)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   PasswordPolicy
+		password string
+		valid    bool
+	}{
+		{"strong", DefaultPasswordPolicy(), "SecurePass123!", true},
+		{"three classes", DefaultPasswordPolicy(), "securepass123!", true},
+		{"too short", DefaultPasswordPolicy(), "Sec123!", false},
+		{"two classes", DefaultPasswordPolicy(), "securepassword1", false},
+		{"spaces are not symbols", DefaultPasswordPolicy(), "secure pass 123", false},
+		{"length counts characters", PasswordPolicy{MinLength: 4}, "ñññ", false},
+		{"relaxed policy", PasswordPolicy{MinLength: 4, MinClasses: 1}, "abcd", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate(tt.password)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateRegistration(t *testing.T) {
+	s := &service{passwordPolicy: DefaultPasswordPolicy()}
+
+	assert.NoError(t, s.validateRegistration("ada@example.com", "Ada", "SecurePass123!"))
+
+	err := s.validateRegistration("not-an-email", " ", "short")
+	assert.ErrorIs(t, err, ErrInvalidRegistration)
+	var invalid *ValidationError
+	if assert.ErrorAs(t, err, &invalid) {
+		assert.Len(t, invalid.Fields, 3)
+		assert.Contains(t, invalid.Fields, "email")
+		assert.Contains(t, invalid.Fields, "name")
+		assert.Contains(t, invalid.Fields, "password")
+	}
+}
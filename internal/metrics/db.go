@@ -0,0 +1,26 @@
+// internal/metrics/db.go
+package metrics
+
+import "database/sql"
+
+// ObserveDB exports db's connection pool statistics. Waits show callers
+// queueing for a connection because the pool is at its limit.
+func ObserveDB(db *sql.DB) {
+	r := DefaultRegistry
+	r.NewGaugeFunc("db_max_open_connections", "Maximum number of open connections to the database.",
+		func() float64 { return float64(db.Stats().MaxOpenConnections) })
+	r.NewGaugeFunc("db_open_connections", "Established connections, both in use and idle.",
+		func() float64 { return float64(db.Stats().OpenConnections) })
+	r.NewGaugeFunc("db_in_use_connections", "Connections currently in use.",
+		func() float64 { return float64(db.Stats().InUse) })
+	r.NewGaugeFunc("db_idle_connections", "Idle connections.",
+		func() float64 { return float64(db.Stats().Idle) })
+	r.NewCounterFunc("db_wait_count_total", "Connections waited for because the pool was exhausted.",
+		func() float64 { return float64(db.Stats().WaitCount) })
+	r.NewCounterFunc("db_wait_duration_seconds_total", "Total time spent waiting for a connection.",
+		func() float64 { return db.Stats().WaitDuration.Seconds() })
+	r.NewCounterFunc("db_max_idle_closed_total", "Connections closed because of the idle pool limit.",
+		func() float64 { return float64(db.Stats().MaxIdleClosed) })
+	r.NewCounterFunc("db_max_lifetime_closed_total", "Connections closed because they reached their maximum lifetime.",
+		func() float64 { return float64(db.Stats().MaxLifetimeClosed) })
+}
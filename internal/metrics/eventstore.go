@@ -0,0 +1,35 @@
+// internal/metrics/eventstore.go
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jules-labs/go-eventstore"
+)
+
+var (
+	eventAppends = NewCounter("eventstore_appends_total",
+		"Event store appends, by aggregate type and outcome (ok, conflict, serialization_failure or error).",
+		"aggregate_type", "outcome")
+	eventAppendDuration = NewHistogram("eventstore_append_duration_seconds",
+		"Time taken to append events, by aggregate type.",
+		nil, "aggregate_type")
+)
+
+// ObserveEventAppend records one event store append. It matches
+// eventstore.AppendObserver, so services install it with
+// es.ObserveAppends(metrics.ObserveEventAppend).
+func ObserveEventAppend(aggregateType string, duration time.Duration, err error) {
+	outcome := "ok"
+	switch {
+	case errors.Is(err, eventstore.ErrConcurrencyConflict):
+		outcome = "conflict"
+	case errors.Is(err, eventstore.ErrSerializationFailure):
+		outcome = "serialization_failure"
+	case err != nil:
+		outcome = "error"
+	}
+	eventAppends.Inc(aggregateType, outcome)
+	eventAppendDuration.Observe(duration.Seconds(), aggregateType)
+}
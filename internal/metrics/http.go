@@ -0,0 +1,81 @@
+// internal/metrics/http.go
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	httpRequests = NewCounter("http_requests_total",
+		"HTTP requests handled, by service, method and status code.",
+		"service", "method", "code")
+	httpErrors = NewCounter("http_request_errors_total",
+		"HTTP requests that ended in a 5xx response, by service and method.",
+		"service", "method")
+	httpDuration = NewHistogram("http_request_duration_seconds",
+		"Time taken to handle HTTP requests, by service and method.",
+		nil, "service", "method")
+)
+
+// Instrument records request counts, errors and latency for every request
+// next handles. Scrapes and health probes are not counted, so a failing
+// readiness probe does not show up as an application error.
+func Instrument(service string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/metrics", "/healthz", "/readyz":
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		method := normalizeMethod(r.Method)
+		httpRequests.Inc(service, method, strconv.Itoa(rec.status))
+		if rec.status >= http.StatusInternalServerError {
+			httpErrors.Inc(service, method)
+		}
+		httpDuration.Observe(time.Since(start).Seconds(), service, method)
+	})
+}
+
+// statusRecorder captures the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.status = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// normalizeMethod keeps arbitrary client-supplied methods from creating
+// unbounded label values.
+func normalizeMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return method
+	default:
+		return "OTHER"
+	}
+}
@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, r *Registry) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	return rec.Body.String()
+}
+
+func TestCounterExposition(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounter("requests_total", "Requests handled.", "code")
+	c.Inc("200")
+	c.Inc("200")
+	c.Add(3, "500")
+	plain := r.NewCounter("events_total", "Events seen.")
+	plain.Inc()
+
+	assert.Equal(t, `# HELP requests_total Requests handled.
+# TYPE requests_total counter
+requests_total{code="200"} 2
+requests_total{code="500"} 3
+# HELP events_total Events seen.
+# TYPE events_total counter
+events_total 1
+`, scrape(t, r))
+}
+
+func TestHistogramExposition(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogram("latency_seconds", "Latency.", []float64{0.1, 1}, "op")
+	h.Observe(0.05, "read")
+	h.Observe(0.5, "read")
+	h.Observe(2, "read")
+
+	assert.Equal(t, `# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{op="read",le="0.1"} 1
+latency_seconds_bucket{op="read",le="1"} 2
+latency_seconds_bucket{op="read",le="+Inf"} 3
+latency_seconds_sum{op="read"} 2.55
+latency_seconds_count{op="read"} 3
+`, scrape(t, r))
+}
+
+func TestFuncMetricsReadAtScrape(t *testing.T) {
+	r := NewRegistry()
+	open := 3.0
+	r.NewGaugeFunc("open_connections", "Open connections.", func() float64 { return open })
+	r.NewCounterFunc("waits_total", "Waits.", func() float64 { return 12 })
+
+	assert.Contains(t, scrape(t, r), "# TYPE open_connections gauge\nopen_connections 3\n")
+	open = 5
+	out := scrape(t, r)
+	assert.Contains(t, out, "open_connections 5\n")
+	assert.Contains(t, out, "# TYPE waits_total counter\nwaits_total 12\n")
+}
+
+func TestLabelValuesAreEscaped(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("odd_total", "Odd labels.", "v").Inc("a\"b\\c\nd")
+
+	assert.Contains(t, scrape(t, r), `odd_total{v="a\"b\\c\nd"} 1`)
+}
+
+func TestMisusePanics(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounter("dup_total", "Duplicate.", "a")
+
+	assert.Panics(t, func() { r.NewCounter("dup_total", "Duplicate.") })
+	assert.Panics(t, func() { c.Inc() })
+	assert.Panics(t, func() { c.Add(-1, "x") })
+}
+
+func TestInstrumentRecordsStatus(t *testing.T) {
+	handler := Instrument("test-instrument", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+
+	for _, path := range []string{"/ok", "/ok", "/fail", "/metrics"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	out := scrape(t, DefaultRegistry)
+	assert.Contains(t, out, `http_requests_total{service="test-instrument",method="GET",code="200"} 2`)
+	assert.Contains(t, out, `http_requests_total{service="test-instrument",method="GET",code="500"} 1`)
+	assert.Contains(t, out, `http_request_errors_total{service="test-instrument",method="GET"} 1`)
+	assert.Contains(t, out, `http_request_duration_seconds_count{service="test-instrument",method="GET"} 3`)
+}
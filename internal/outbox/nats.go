@@ -0,0 +1,130 @@
+// internal/outbox/nats.go
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/jules-labs/go-eventstore"
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	defaultNATSTimeout       = 5 * time.Second
+	defaultNATSReconnectWait = 2 * time.Second
+)
+
+// NATSPublisher publishes events to a NATS server. Each publish is flushed
+// and only succeeds once the server has answered the flush, so a returned
+// nil means the server has the message. The client reconnects by itself
+// after losing the server, and publishes fail rather than queue while it
+// does: the relay already retries them, in order, from its checkpoint.
+type NATSPublisher struct {
+	url           string
+	timeout       time.Duration
+	reconnectWait time.Duration
+
+	mu   sync.Mutex
+	conn *nats.Conn
+}
+
+// NewNATSPublisher creates a publisher for a nats://[user:pass@]host[:port]
+// URL, or tls:// for a server that requires TLS. It does not connect until
+// the first publish.
+func NewNATSPublisher(rawURL string) (*NATSPublisher, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NATS URL: %w", err)
+	}
+	if u.Scheme != "nats" && u.Scheme != "tls" {
+		return nil, fmt.Errorf("unsupported NATS URL scheme %q", u.Scheme)
+	}
+	return &NATSPublisher{
+		url:           rawURL,
+		timeout:       defaultNATSTimeout,
+		reconnectWait: defaultNATSReconnectWait,
+	}, nil
+}
+
+// Publish sends the event, with its metadata, as JSON on Subject(event).
+func (p *NATSPublisher) Publish(ctx context.Context, event eventstore.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	conn, err := p.connection()
+	if err != nil {
+		return err
+	}
+	if err := conn.Publish(Subject(event), payload); err != nil {
+		return fmt.Errorf("nats publish: %w", err)
+	}
+	if err := p.flush(ctx, conn); err != nil {
+		return fmt.Errorf("nats publish: %w", err)
+	}
+	return nil
+}
+
+// Ping checks that the server is reachable.
+func (p *NATSPublisher) Ping(ctx context.Context) error {
+	conn, err := p.connection()
+	if err != nil {
+		return err
+	}
+	if err := p.flush(ctx, conn); err != nil {
+		return fmt.Errorf("nats ping: %w", err)
+	}
+	return nil
+}
+
+// Close drops the connection, if any.
+func (p *NATSPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn != nil {
+		p.conn.Close()
+		p.conn = nil
+	}
+	return nil
+}
+
+// connection returns the client, connecting it if this is the first use or
+// the last connection was closed for good.
+func (p *NATSPublisher) connection() (*nats.Conn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn != nil && !p.conn.IsClosed() {
+		return p.conn, nil
+	}
+	conn, err := nats.Connect(p.url,
+		nats.Name("libranexus-publisher"),
+		nats.Timeout(p.timeout),
+		nats.MaxReconnects(-1),
+		nats.ReconnectWait(p.reconnectWait),
+		nats.ReconnectBufSize(-1),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("nats connect: %w", err)
+	}
+	p.conn = conn
+	return conn, nil
+}
+
+// flush waits for the server to answer a PING sent after everything already
+// published, bounded by the publisher's timeout. An error the server sent,
+// such as an authorization failure, is reported in place of the flush's own.
+func (p *NATSPublisher) flush(ctx context.Context, conn *nats.Conn) error {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	if err := conn.FlushWithContext(ctx); err != nil {
+		if last := conn.LastError(); last != nil {
+			return last
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type natsMessage struct {
+	subject string
+	payload []byte
+}
+
+// fakeNATS speaks enough of the NATS protocol to accept publishes. The first
+// dropConns connections are closed after their first message, without
+// answering the PING that follows it.
+type fakeNATS struct {
+	ln        net.Listener
+	messages  chan natsMessage
+	connects  chan string
+	dropConns int
+	rejectAll bool
+}
+
+func startFakeNATS(t *testing.T, dropConns int, rejectAll bool) *fakeNATS {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	f := &fakeNATS{
+		ln:        ln,
+		messages:  make(chan natsMessage, 10),
+		connects:  make(chan string, 10),
+		dropConns: dropConns,
+		rejectAll: rejectAll,
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for n := 0; ; n++ {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn, n < f.dropConns)
+		}
+	}()
+	return f
+}
+
+func (f *fakeNATS) url() string {
+	return "nats://alice:secret@" + f.ln.Addr().String()
+}
+
+func (f *fakeNATS) serve(conn net.Conn, drop bool) {
+	defer conn.Close()
+	fmt.Fprint(conn, "INFO {\"server_id\":\"fake\",\"max_payload\":1048576}\r\n")
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb, args, _ := strings.Cut(line, " ")
+		switch verb {
+		case "CONNECT":
+			f.connects <- args
+			if f.rejectAll {
+				fmt.Fprint(conn, "-ERR 'Authorization Violation'\r\n")
+				return
+			}
+		case "PING":
+			fmt.Fprint(conn, "PONG\r\n")
+		case "PUB":
+			fields := strings.Fields(args)
+			size, _ := strconv.Atoi(fields[len(fields)-1])
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(r, payload); err != nil {
+				return
+			}
+			f.messages <- natsMessage{subject: fields[0], payload: payload[:size]}
+			if drop {
+				return
+			}
+		}
+	}
+}
+
+func testEvent() eventstore.Event {
+	return eventstore.Event{
+		ID:            42,
+		AggregateID:   uuid.New(),
+		AggregateType: "item",
+		EventType:     "ItemAdded",
+		EventData:     json.RawMessage(`{"title":"Dune"}`),
+		Metadata:      map[string]interface{}{eventstore.MetadataCorrelationID: "req-1"},
+		Version:       1,
+	}
+}
+
+func TestNATSPublisherPublishes(t *testing.T) {
+	server := startFakeNATS(t, 0, false)
+	p, err := NewNATSPublisher(server.url())
+	require.NoError(t, err)
+	defer p.Close()
+
+	event := testEvent()
+	require.NoError(t, p.Publish(context.Background(), event))
+
+	var connect map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(<-server.connects), &connect))
+	assert.Equal(t, "alice", connect["user"])
+	assert.Equal(t, "secret", connect["pass"])
+
+	msg := <-server.messages
+	assert.Equal(t, "libranexus.item.ItemAdded", msg.subject)
+	var got eventstore.Event
+	require.NoError(t, json.Unmarshal(msg.payload, &got))
+	assert.Equal(t, event.ID, got.ID)
+	assert.Equal(t, "req-1", got.CorrelationID())
+}
+
+func TestNATSPublisherReconnects(t *testing.T) {
+	server := startFakeNATS(t, 1, false)
+	p, err := NewNATSPublisher(server.url())
+	require.NoError(t, err)
+	p.reconnectWait = 10 * time.Millisecond
+	defer p.Close()
+
+	// The server takes the message but drops the connection before
+	// confirming it, so the publish fails and is retried. Publishes fail,
+	// rather than queue, until the client has reconnected.
+	assert.Error(t, p.Publish(context.Background(), testEvent()))
+	<-server.messages
+
+	require.Eventually(t, func() bool {
+		return p.Publish(context.Background(), testEvent()) == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	<-server.messages
+	assert.Len(t, server.connects, 2)
+}
+
+func TestNATSPublisherReportsServerErrors(t *testing.T) {
+	server := startFakeNATS(t, 0, true)
+	p, err := NewNATSPublisher(server.url())
+	require.NoError(t, err)
+
+	err = p.Ping(context.Background())
+	assert.ErrorIs(t, err, nats.ErrAuthorization)
+}
+
+func TestNewNATSPublisherRejectsOtherSchemes(t *testing.T) {
+	_, err := NewNATSPublisher("http://nats:4222")
+	assert.Error(t, err)
+}
@@ -0,0 +1,38 @@
+// internal/outbox/publisher.go
+package outbox
+
+import (
+	"context"
+	"libranexus/internal/metrics"
+
+	"github.com/jules-labs/go-eventstore"
+)
+
+// EventPublisher delivers events to a message broker.
+type EventPublisher interface {
+	Publish(ctx context.Context, event eventstore.Event) error
+}
+
+// eventsPublished counts publish attempts, so the success rate can be watched
+// through a broker outage.
+var eventsPublished = metrics.NewCounter("events_published_total",
+	"Events published to the message broker, by outcome (ok or error).", "outcome")
+
+// Subject is the subject an event is published on:
+// libranexus.<aggregate_type>.<event_type>.
+func Subject(event eventstore.Event) string {
+	return "libranexus." + event.AggregateType + "." + event.EventType
+}
+
+// Publish returns a Handler that relays events to p.
+func Publish(p EventPublisher) Handler {
+	return func(ctx context.Context, event eventstore.Event) error {
+		err := p.Publish(ctx, event)
+		if err != nil {
+			eventsPublished.Inc("error")
+			return err
+		}
+		eventsPublished.Inc("ok")
+		return nil
+	}
+}
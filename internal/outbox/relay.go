@@ -0,0 +1,182 @@
+// internal/outbox/relay.go
+
+// Package outbox delivers committed events to consumers outside the database.
+// Services append events in the same transaction as their read-model writes,
+// so the events table is itself the outbox: a Relay tails it in commit order
+// from a checkpoint and hands every event to a handler, at least once and in
+// order.
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/jules-labs/go-eventstore"
+)
+
+const (
+	defaultBatchSize    = 100
+	defaultPollInterval = time.Second
+)
+
+// Handler delivers one event. An error stops the relay at that event, which
+// is retried on the next pass, so handlers must tolerate redelivery.
+type Handler func(ctx context.Context, event eventstore.Event) error
+
+// EventSource is the stream a relay reads; *eventstore.EventStore satisfies
+// it. Reading in commit order rather than by event ID means an event whose
+// transaction commits late is still relayed, not skipped because a later ID
+// was checkpointed first.
+type EventSource interface {
+	StreamCommitted(ctx context.Context, after eventstore.Position, batchSize int) ([]eventstore.CommittedEvent, error)
+}
+
+// Checkpoints persists how far each relay has got.
+type Checkpoints interface {
+	Load(ctx context.Context, name string) (eventstore.Position, error)
+	Save(ctx context.Context, name string, last eventstore.Position) error
+}
+
+// Relay hands every event after its checkpoint to a handler.
+type Relay struct {
+	source       EventSource
+	checkpoints  Checkpoints
+	name         string
+	handler      Handler
+	batchSize    int
+	pollInterval time.Duration
+}
+
+// Option configures a Relay.
+type Option func(*Relay)
+
+// WithBatchSize sets how many events are read per query.
+func WithBatchSize(n int) Option {
+	return func(r *Relay) {
+		if n > 0 {
+			r.batchSize = n
+		}
+	}
+}
+
+// WithPollInterval sets how long Run waits between passes once caught up.
+func WithPollInterval(d time.Duration) Option {
+	return func(r *Relay) {
+		r.pollInterval = d
+	}
+}
+
+// NewRelay creates a relay that checkpoints its progress under name.
+func NewRelay(source EventSource, checkpoints Checkpoints, name string, handler Handler, opts ...Option) *Relay {
+	r := &Relay{
+		source:       source,
+		checkpoints:  checkpoints,
+		name:         name,
+		handler:      handler,
+		batchSize:    defaultBatchSize,
+		pollInterval: defaultPollInterval,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Drain hands every committed event after the checkpoint to the handler and
+// returns how many it delivered. It stops at the first handler error, having
+// checkpointed everything delivered before it.
+func (r *Relay) Drain(ctx context.Context) (int, error) {
+	pos, err := r.checkpoints.Load(ctx, r.name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+
+	delivered := 0
+	for {
+		events, err := r.source.StreamCommitted(ctx, pos, r.batchSize)
+		if err != nil {
+			return delivered, fmt.Errorf("failed to stream events: %w", err)
+		}
+
+		batchStart := pos
+		var handleErr error
+		for _, event := range events {
+			if handleErr = r.handler(ctx, event.Event); handleErr != nil {
+				handleErr = fmt.Errorf("failed to relay event %d (%s): %w", event.ID, event.EventType, handleErr)
+				break
+			}
+			pos = event.Position
+			delivered++
+		}
+
+		if pos != batchStart {
+			if err := r.checkpoints.Save(ctx, r.name, pos); err != nil {
+				return delivered, fmt.Errorf("failed to save checkpoint: %w", err)
+			}
+		}
+		if handleErr != nil {
+			return delivered, handleErr
+		}
+		// A short batch means the stream is exhausted, up to the oldest
+		// transaction still running.
+		if len(events) < r.batchSize {
+			return delivered, nil
+		}
+	}
+}
+
+// Run drains the outbox every poll interval until ctx is cancelled. Failed
+// passes are logged and retried on the next tick.
+func (r *Relay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Drain(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Outbox relay %s: %v", r.name, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// sqlCheckpoints keeps relay positions in the projection_checkpoints table,
+// alongside the projector's.
+type sqlCheckpoints struct {
+	db *sql.DB
+}
+
+// NewCheckpointStore returns Checkpoints backed by the database.
+func NewCheckpointStore(db *sql.DB) Checkpoints {
+	return &sqlCheckpoints{db: db}
+}
+
+func (c *sqlCheckpoints) Load(ctx context.Context, name string) (eventstore.Position, error) {
+	var pos eventstore.Position
+	err := c.db.QueryRowContext(ctx, `
+		SELECT last_transaction_id, last_event_id FROM projection_checkpoints WHERE name = $1
+	`, name).Scan(&pos.TransactionID, &pos.EventID)
+	if err == sql.ErrNoRows {
+		return eventstore.Position{}, nil
+	}
+	return pos, err
+}
+
+func (c *sqlCheckpoints) Save(ctx context.Context, name string, last eventstore.Position) error {
+	_, err := c.db.ExecContext(ctx, `
+		INSERT INTO projection_checkpoints (name, last_transaction_id, last_event_id, updated_at)
+		VALUES ($1, $2::xid8, $3, NOW())
+		ON CONFLICT (name) DO UPDATE
+		SET last_transaction_id = EXCLUDED.last_transaction_id,
+		    last_event_id = EXCLUDED.last_event_id,
+		    updated_at = EXCLUDED.updated_at
+	`, name, strconv.FormatUint(last.TransactionID, 10), last.EventID)
+	return err
+}
@@ -0,0 +1,136 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jules-labs/go-eventstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSource is a committed stream of events, each in its own transaction,
+// that hides every transaction from the first one still running on, as the
+// event store does.
+type fakeSource struct {
+	events  []eventstore.CommittedEvent // in commit order
+	running map[uint64]bool
+}
+
+func (f *fakeSource) StreamCommitted(ctx context.Context, after eventstore.Position, batchSize int) ([]eventstore.CommittedEvent, error) {
+	var batch []eventstore.CommittedEvent
+	for _, e := range f.events {
+		if f.running[e.Position.TransactionID] {
+			break
+		}
+		if positionAfter(e.Position, after) && len(batch) < batchSize {
+			batch = append(batch, e)
+		}
+	}
+	return batch, nil
+}
+
+func positionAfter(p, q eventstore.Position) bool {
+	if p.TransactionID != q.TransactionID {
+		return p.TransactionID > q.TransactionID
+	}
+	return p.EventID > q.EventID
+}
+
+type memoryCheckpoints map[string]eventstore.Position
+
+func (m memoryCheckpoints) Load(ctx context.Context, name string) (eventstore.Position, error) {
+	return m[name], nil
+}
+
+func (m memoryCheckpoints) Save(ctx context.Context, name string, last eventstore.Position) error {
+	m[name] = last
+	return nil
+}
+
+// events returns events committed in the order their IDs are given.
+func events(ids ...int64) []eventstore.CommittedEvent {
+	out := make([]eventstore.CommittedEvent, len(ids))
+	for i, id := range ids {
+		out[i] = eventstore.CommittedEvent{
+			Event:    eventstore.Event{ID: id, EventType: "ItemAdded"},
+			Position: eventstore.Position{TransactionID: uint64(100 + i), EventID: id},
+		}
+	}
+	return out
+}
+
+func newTestRelay(source EventSource, checkpoints Checkpoints, handler Handler) *Relay {
+	return NewRelay(source, checkpoints, "test", handler, WithBatchSize(2))
+}
+
+func TestRelayDeliversInOrderAndCheckpoints(t *testing.T) {
+	source := &fakeSource{events: events(1, 2, 3, 4, 5)}
+	checkpoints := memoryCheckpoints{}
+
+	var got []int64
+	r := newTestRelay(source, checkpoints, func(ctx context.Context, e eventstore.Event) error {
+		got = append(got, e.ID)
+		return nil
+	})
+
+	n, err := r.Drain(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 5, n)
+	assert.Equal(t, []int64{1, 2, 3, 4, 5}, got)
+	assert.Equal(t, int64(5), checkpoints["test"].EventID)
+
+	// Nothing new: a second pass delivers nothing.
+	n, err = r.Drain(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestRelayStopsAtFailureAndResumes(t *testing.T) {
+	source := &fakeSource{events: events(1, 2, 3)}
+	checkpoints := memoryCheckpoints{}
+
+	fail := true
+	var got []int64
+	r := newTestRelay(source, checkpoints, func(ctx context.Context, e eventstore.Event) error {
+		if e.ID == 2 && fail {
+			return errors.New("broker unreachable")
+		}
+		got = append(got, e.ID)
+		return nil
+	})
+
+	n, err := r.Drain(context.Background())
+	assert.ErrorContains(t, err, "broker unreachable")
+	assert.Equal(t, 1, n)
+	assert.Equal(t, int64(1), checkpoints["test"].EventID)
+
+	fail = false
+	n, err = r.Drain(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []int64{1, 2, 3}, got)
+}
+
+func TestRelayDeliversLateCommitsItWouldOtherwiseSkip(t *testing.T) {
+	// Event 2's transaction took its ID first but commits after event 3's.
+	source := &fakeSource{events: events(1, 3, 2, 4), running: map[uint64]bool{102: true}}
+	checkpoints := memoryCheckpoints{}
+
+	var got []int64
+	r := newTestRelay(source, checkpoints, func(ctx context.Context, e eventstore.Event) error {
+		got = append(got, e.ID)
+		return nil
+	})
+
+	_, err := r.Drain(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 3}, got)
+
+	delete(source.running, 102)
+	_, err = r.Drain(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 3, 2, 4}, got)
+	assert.Equal(t, eventstore.Position{TransactionID: 103, EventID: 4}, checkpoints["test"])
+}
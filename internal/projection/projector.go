@@ -0,0 +1,156 @@
+// internal/projection/projector.go
+package projection
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/jules-labs/go-eventstore"
+	"github.com/lib/pq"
+)
+
+const defaultBatchSize = 500
+
+// readModelTables are the read-model tables a projection rebuilds. Their
+// definitions are copied from the live tables in the public schema.
+var readModelTables = []string{"items", "members", "checkouts", "holds"}
+
+// Projector rebuilds the catalog, circulation and membership read models by
+// replaying the event stream into a separate schema. Progress is checkpointed
+// per batch, so an interrupted rebuild resumes where it left off.
+type Projector struct {
+	eventStore *eventstore.EventStore
+	db         *sql.DB
+	schema     string
+	batchSize  int
+}
+
+// NewProjector creates a projector that writes into the given schema. The
+// schema name doubles as the projection's checkpoint name.
+func NewProjector(es *eventstore.EventStore, db *sql.DB, schema string) *Projector {
+	return &Projector{
+		eventStore: es,
+		db:         db,
+		schema:     schema,
+		batchSize:  defaultBatchSize,
+	}
+}
+
+// Reset drops any previous rebuild and recreates empty read-model tables,
+// clearing the checkpoint so the next Run replays from the first event.
+func (p *Projector) Reset(ctx context.Context) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	schema := pq.QuoteIdentifier(p.schema)
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("DROP SCHEMA IF EXISTS %s CASCADE", schema)); err != nil {
+		return fmt.Errorf("failed to drop projection schema: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("CREATE SCHEMA %s", schema)); err != nil {
+		return fmt.Errorf("failed to create projection schema: %w", err)
+	}
+	for _, table := range readModelTables {
+		query := fmt.Sprintf("CREATE TABLE %s (LIKE public.%s INCLUDING DEFAULTS INCLUDING CONSTRAINTS)",
+			p.table(table), pq.QuoteIdentifier(table))
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to create projection table %s: %w", table, err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM projection_checkpoints WHERE name = $1`, p.schema); err != nil {
+		return fmt.Errorf("failed to clear checkpoint: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Run applies every event after the checkpoint and returns the number of
+// events applied. It reads the stream in commit order, as the outbox relay
+// does, so an event whose transaction committed after a later one was
+// checkpointed is still applied. It returns once the projection has caught
+// up with the stream, up to the oldest transaction still running.
+func (p *Projector) Run(ctx context.Context) (int, error) {
+	pos, err := p.checkpoint(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for {
+		events, err := p.eventStore.StreamCommitted(ctx, pos, p.batchSize)
+		if err != nil {
+			return applied, fmt.Errorf("failed to stream events: %w", err)
+		}
+		if len(events) > 0 {
+			if err := p.applyBatch(ctx, events); err != nil {
+				return applied, err
+			}
+			applied += len(events)
+			pos = events[len(events)-1].Position
+		}
+		if len(events) < p.batchSize {
+			return applied, nil
+		}
+	}
+}
+
+// applyBatch applies a batch of events and advances the checkpoint in a single
+// transaction, so a batch is either fully projected or not at all.
+func (p *Projector) applyBatch(ctx context.Context, events []eventstore.CommittedEvent) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, event := range events {
+		statements, err := p.statementsFor(event.Event)
+		if err != nil {
+			return fmt.Errorf("failed to project event %d: %w", event.ID, err)
+		}
+		for _, stmt := range statements {
+			if _, err := tx.ExecContext(ctx, stmt.query, stmt.args...); err != nil {
+				return fmt.Errorf("failed to apply event %d (%s): %w", event.ID, event.EventType, err)
+			}
+		}
+	}
+
+	last := events[len(events)-1].Position
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO projection_checkpoints (name, last_transaction_id, last_event_id, updated_at)
+		VALUES ($1, $2::xid8, $3, NOW())
+		ON CONFLICT (name) DO UPDATE
+		SET last_transaction_id = EXCLUDED.last_transaction_id,
+		    last_event_id = EXCLUDED.last_event_id,
+		    updated_at = EXCLUDED.updated_at
+	`, p.schema, strconv.FormatUint(last.TransactionID, 10), last.EventID)
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (p *Projector) checkpoint(ctx context.Context) (eventstore.Position, error) {
+	var pos eventstore.Position
+	err := p.db.QueryRowContext(ctx, `
+		SELECT last_transaction_id, last_event_id FROM projection_checkpoints WHERE name = $1
+	`, p.schema).Scan(&pos.TransactionID, &pos.EventID)
+	if err == sql.ErrNoRows {
+		return eventstore.Position{}, nil
+	}
+	if err != nil {
+		return eventstore.Position{}, fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+	return pos, nil
+}
+
+// table returns the schema-qualified, quoted name of a projection table.
+func (p *Projector) table(name string) string {
+	return pq.QuoteIdentifier(p.schema) + "." + pq.QuoteIdentifier(name)
+}
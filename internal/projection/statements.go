@@ -0,0 +1,317 @@
+// internal/projection/statements.go
+package projection
+
+import (
+	"encoding/json"
+	"fmt"
+	"libranexus/internal/catalog"
+	"libranexus/internal/circulation"
+	"libranexus/internal/membership"
+
+	"github.com/jules-labs/go-eventstore"
+)
+
+// statement is a single read-model write produced by an event.
+type statement struct {
+	query string
+	args  []interface{}
+}
+
+// statementsFor translates an event into the read-model writes that apply it.
+// Every write stamps the row with the event's version, so the rebuilt tables
+// carry the same optimistic-locking versions as the event stream. Events that
+// do not touch the projected tables yield no statements.
+func (p *Projector) statementsFor(event eventstore.Event) ([]statement, error) {
+	switch event.EventType {
+	// Catalog
+	case "ItemAdded":
+		var e catalog.ItemAddedEvent
+		if err := json.Unmarshal(event.EventData, &e); err != nil {
+			return nil, err
+		}
+		return []statement{{
+			query: fmt.Sprintf(`
+				INSERT INTO %s (id, isbn, title, author, category, total_copies, available, status, version, created_at, updated_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $6, 'active', $7, $8, $8)
+			`, p.table("items")),
+			args: []interface{}{e.ID, e.ISBN, e.Title, e.Author, catalog.NormalizeCategory(e.Category), e.TotalCopies, event.Version, event.CreatedAt},
+		}}, nil
+	case "ItemCopiesUpdated":
+		var e catalog.ItemCopiesUpdatedEvent
+		if err := json.Unmarshal(event.EventData, &e); err != nil {
+			return nil, err
+		}
+		return []statement{{
+			query: fmt.Sprintf(`
+				UPDATE %s SET total_copies = $1, available = $2, version = $3, updated_at = $4
+				WHERE id = $5
+			`, p.table("items")),
+			args: []interface{}{e.NewTotal, e.NewAvailable, event.Version, event.CreatedAt, event.AggregateID},
+		}}, nil
+	case "CopiesAdjusted":
+		var e catalog.CopiesAdjustedEvent
+		if err := json.Unmarshal(event.EventData, &e); err != nil {
+			return nil, err
+		}
+		return []statement{{
+			query: fmt.Sprintf(`
+				UPDATE %s SET total_copies = $1, available = $2, version = $3, updated_at = $4
+				WHERE id = $5
+			`, p.table("items")),
+			args: []interface{}{e.NewTotal, e.NewAvailable, event.Version, event.CreatedAt, event.AggregateID},
+		}}, nil
+	case "ItemDetailsUpdated":
+		var e catalog.ItemDetailsUpdatedEvent
+		if err := json.Unmarshal(event.EventData, &e); err != nil {
+			return nil, err
+		}
+		return []statement{{
+			query: fmt.Sprintf(`
+				UPDATE %s SET title = COALESCE($1, title), author = COALESCE($2, author),
+				publisher = COALESCE($3, publisher), published_year = COALESCE($4, published_year),
+				version = $5, updated_at = $6
+				WHERE id = $7
+			`, p.table("items")),
+			args: []interface{}{e.Title, e.Author, e.Publisher, e.PublishedYear, event.Version, event.CreatedAt, event.AggregateID},
+		}}, nil
+	case "ItemCopyReserved":
+		return []statement{{
+			query: fmt.Sprintf(`
+				UPDATE %s SET available = available - 1, version = $1, updated_at = $2
+				WHERE id = $3
+			`, p.table("items")),
+			args: []interface{}{event.Version, event.CreatedAt, event.AggregateID},
+		}}, nil
+	case "ItemCopyReleased":
+		return []statement{{
+			query: fmt.Sprintf(`
+				UPDATE %s SET available = available + 1, version = $1, updated_at = $2
+				WHERE id = $3
+			`, p.table("items")),
+			args: []interface{}{event.Version, event.CreatedAt, event.AggregateID},
+		}}, nil
+	case "ItemRemoved":
+		var e catalog.ItemRemovedEvent
+		if err := json.Unmarshal(event.EventData, &e); err != nil {
+			return nil, err
+		}
+		return []statement{{
+			query: fmt.Sprintf(`
+				UPDATE %s SET status = $1, version = $2, updated_at = $3
+				WHERE id = $4
+			`, p.table("items")),
+			args: []interface{}{e.Status, event.Version, event.CreatedAt, event.AggregateID},
+		}}, nil
+
+	case "ItemRestored":
+		return p.setStatus("items", "active", event), nil
+
+	// Circulation
+	case "ItemCheckedOut":
+		var e circulation.ItemCheckedOutEvent
+		if err := json.Unmarshal(event.EventData, &e); err != nil {
+			return nil, err
+		}
+		return []statement{{
+			query: fmt.Sprintf(`
+				INSERT INTO %s (id, member_id, item_id, checkout_date, due_date, status, version, created_at, updated_at)
+				VALUES ($1, $2, $3, $4, $5, 'active', $6, $4, $4)
+			`, p.table("checkouts")),
+			args: []interface{}{e.CheckoutID, e.MemberID, e.ItemID, event.CreatedAt, e.DueDate, event.Version},
+		}}, nil
+	case "ItemReturned":
+		var e circulation.ItemReturnedEvent
+		if err := json.Unmarshal(event.EventData, &e); err != nil {
+			return nil, err
+		}
+		return []statement{{
+			query: fmt.Sprintf(`
+				UPDATE %s SET status = 'returned', return_date = $1, version = $2, updated_at = $3
+				WHERE id = $4
+			`, p.table("checkouts")),
+			args: []interface{}{e.ReturnDate, event.Version, event.CreatedAt, event.AggregateID},
+		}}, nil
+	case "CheckoutRenewed":
+		var e circulation.CheckoutRenewedEvent
+		if err := json.Unmarshal(event.EventData, &e); err != nil {
+			return nil, err
+		}
+		return []statement{{
+			query: fmt.Sprintf(`
+				UPDATE %s SET status = 'active', due_date = $1, renewal_count = $2, version = $3, updated_at = $4
+				WHERE id = $5
+			`, p.table("checkouts")),
+			args: []interface{}{e.DueDate, e.RenewalCount, event.Version, event.CreatedAt, event.AggregateID},
+		}}, nil
+	case "ItemOverdue":
+		var e circulation.ItemOverdueEvent
+		if err := json.Unmarshal(event.EventData, &e); err != nil {
+			return nil, err
+		}
+		return []statement{{
+			query: fmt.Sprintf(`
+				UPDATE %s SET status = 'overdue', last_fine_date = $1, version = $2, updated_at = $3
+				WHERE id = $4
+			`, p.table("checkouts")),
+			args: []interface{}{e.FinedThrough, event.Version, event.CreatedAt, event.AggregateID},
+		}}, nil
+	case "ItemHeld":
+		var e circulation.ItemHeldEvent
+		if err := json.Unmarshal(event.EventData, &e); err != nil {
+			return nil, err
+		}
+		return []statement{{
+			query: fmt.Sprintf(`
+				INSERT INTO %s (id, member_id, item_id, placed_at, expires_at, status, version, created_at, updated_at)
+				VALUES ($1, $2, $3, $4, $5, 'pending', $6, $4, $4)
+			`, p.table("holds")),
+			args: []interface{}{e.HoldID, e.MemberID, e.ItemID, event.CreatedAt, e.ExpiresAt, event.Version},
+		}}, nil
+	case "HoldFulfilled":
+		var e circulation.HoldFulfilledEvent
+		if err := json.Unmarshal(event.EventData, &e); err != nil {
+			return nil, err
+		}
+		if e.PickupBy.IsZero() {
+			return []statement{{
+				query: fmt.Sprintf(`
+					UPDATE %s SET status = 'fulfilled', fulfilled_at = $1, version = $2, updated_at = $3
+					WHERE id = $4
+				`, p.table("holds")),
+				args: []interface{}{e.FulfilledAt, event.Version, event.CreatedAt, event.AggregateID},
+			}}, nil
+		}
+		return []statement{{
+			query: fmt.Sprintf(`
+				UPDATE %s SET status = 'fulfilled', fulfilled_at = $1, expires_at = $2, version = $3, updated_at = $4
+				WHERE id = $5
+			`, p.table("holds")),
+			args: []interface{}{e.FulfilledAt, e.PickupBy, event.Version, event.CreatedAt, event.AggregateID},
+		}}, nil
+	case "HoldCollected":
+		return p.setStatus("holds", "collected", event), nil
+	case "HoldExpired":
+		return p.setStatus("holds", "expired", event), nil
+
+	// Membership
+	case "MemberRegistered":
+		var e membership.MemberRegisteredEvent
+		if err := json.Unmarshal(event.EventData, &e); err != nil {
+			return nil, err
+		}
+		return []statement{{
+			query: fmt.Sprintf(`
+				INSERT INTO %s (id, email, name, membership_tier, status, max_checkouts, version, expires_at, created_at, updated_at)
+				VALUES ($1, $2, $3, 'basic', 'active', $4, $5, $6, $7, $7)
+			`, p.table("members")),
+			args: []interface{}{e.ID, membership.NormalizeEmail(e.Email), e.Name, maxCheckouts(e.MaxCheckouts), event.Version, event.CreatedAt.AddDate(1, 0, 0), event.CreatedAt},
+		}}, nil
+	case "MemberTierChanged":
+		var e membership.MemberTierChangedEvent
+		if err := json.Unmarshal(event.EventData, &e); err != nil {
+			return nil, err
+		}
+		return []statement{{
+			query: fmt.Sprintf(`
+				UPDATE %s SET membership_tier = $1, max_checkouts = $2, version = $3, updated_at = $4
+				WHERE id = $5
+			`, p.table("members")),
+			args: []interface{}{e.NewTier, maxCheckouts(e.MaxCheckouts), event.Version, event.CreatedAt, event.AggregateID},
+		}}, nil
+	case "MembershipRenewed":
+		var e membership.MembershipRenewedEvent
+		if err := json.Unmarshal(event.EventData, &e); err != nil {
+			return nil, err
+		}
+		return []statement{{
+			query: fmt.Sprintf(`
+				UPDATE %s SET expires_at = $1, status = $2, version = $3, updated_at = $4
+				WHERE id = $5
+			`, p.table("members")),
+			args: []interface{}{e.ExpiresAt, e.Status, event.Version, event.CreatedAt, event.AggregateID},
+		}}, nil
+	case "MembershipExpired":
+		return p.setStatus("members", "expired", event), nil
+	case "MemberRoleChanged":
+		var e membership.MemberRoleChangedEvent
+		if err := json.Unmarshal(event.EventData, &e); err != nil {
+			return nil, err
+		}
+		return []statement{{
+			query: fmt.Sprintf(`UPDATE %s SET role = $1, version = $2, updated_at = $3 WHERE id = $4`, p.table("members")),
+			args:  []interface{}{e.Role, event.Version, event.CreatedAt, event.AggregateID},
+		}}, nil
+	case "MemberSuspended":
+		var e membership.MemberSuspendedEvent
+		if err := json.Unmarshal(event.EventData, &e); err != nil {
+			return nil, err
+		}
+		return []statement{{
+			query: fmt.Sprintf(`
+				UPDATE %s SET status = 'suspended', suspension_reason = $1, version = $2, updated_at = $3
+				WHERE id = $4
+			`, p.table("members")),
+			args: []interface{}{e.Reason, event.Version, event.CreatedAt, event.AggregateID},
+		}}, nil
+	case "MemberReactivated":
+		var e membership.MemberReactivatedEvent
+		if err := json.Unmarshal(event.EventData, &e); err != nil {
+			return nil, err
+		}
+		return []statement{{
+			query: fmt.Sprintf(`
+				UPDATE %s SET status = $1, suspension_reason = NULL, version = $2, updated_at = $3
+				WHERE id = $4
+			`, p.table("members")),
+			args: []interface{}{e.Status, event.Version, event.CreatedAt, event.AggregateID},
+		}}, nil
+	case "FineCharged":
+		var e membership.FineChargedEvent
+		if err := json.Unmarshal(event.EventData, &e); err != nil {
+			return nil, err
+		}
+		return p.adjustFineBalance(e.Amount, event), nil
+	case "FinePaid":
+		var e membership.FinePaidEvent
+		if err := json.Unmarshal(event.EventData, &e); err != nil {
+			return nil, err
+		}
+		return p.adjustFineBalance(-e.Amount, event), nil
+	case "MFAEnabled", "MFADisabled", "PasswordChanged":
+		// Credentials are not rebuilt from events, but the member's version still advances.
+		return []statement{{
+			query: fmt.Sprintf(`UPDATE %s SET version = $1, updated_at = $2 WHERE id = $3`, p.table("members")),
+			args:  []interface{}{event.Version, event.CreatedAt, event.AggregateID},
+		}}, nil
+	}
+
+	return nil, nil
+}
+
+func (p *Projector) setStatus(table, status string, event eventstore.Event) []statement {
+	return []statement{{
+		query: fmt.Sprintf(`UPDATE %s SET status = $1, version = $2, updated_at = $3 WHERE id = $4`, p.table(table)),
+		args:  []interface{}{status, event.Version, event.CreatedAt, event.AggregateID},
+	}}
+}
+
+func (p *Projector) adjustFineBalance(delta float64, event eventstore.Event) []statement {
+	return []statement{{
+		query: fmt.Sprintf(`
+			UPDATE %s SET fine_balance = fine_balance + $1, version = $2, updated_at = $3
+			WHERE id = $4
+		`, p.table("members")),
+		args: []interface{}{delta, event.Version, event.CreatedAt, event.AggregateID},
+	}}
+}
+
+// legacyMaxCheckouts is the limit members had before it was derived from
+// their tier; events written back then do not record one.
+const legacyMaxCheckouts = 5
+
+func maxCheckouts(recorded int) int {
+	if recorded == 0 {
+		return legacyMaxCheckouts
+	}
+	return recorded
+}
@@ -0,0 +1,143 @@
+package projection
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"libranexus/internal/catalog"
+	"libranexus/internal/circulation"
+	"libranexus/internal/membership"
+)
+
+func buildEvent(t *testing.T, aggregateID uuid.UUID, eventType string, version int, data interface{}) eventstore.Event {
+	t.Helper()
+	jsonData, err := json.Marshal(data)
+	require.NoError(t, err)
+	return eventstore.Event{
+		ID:          1,
+		AggregateID: aggregateID,
+		EventType:   eventType,
+		EventData:   jsonData,
+		Version:     version,
+		CreatedAt:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestStatementsForTargetsProjectionSchema(t *testing.T) {
+	p := NewProjector(nil, nil, "rebuild")
+	id := uuid.New()
+
+	statements, err := p.statementsFor(buildEvent(t, id, "ItemAdded", 1, catalog.ItemAddedEvent{
+		ID: id, ISBN: "9780141439518", Title: "Pride and Prejudice", Author: "Jane Austen", TotalCopies: 3,
+	}))
+	require.NoError(t, err)
+	require.Len(t, statements, 1)
+	assert.Contains(t, statements[0].query, `INSERT INTO "rebuild"."items"`)
+	assert.Equal(t, []interface{}{id, "9780141439518", "Pride and Prejudice", "Jane Austen", "general", 3, 1, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}, statements[0].args)
+}
+
+func TestStatementsForFinePaidReducesBalance(t *testing.T) {
+	p := NewProjector(nil, nil, "rebuild")
+	id := uuid.New()
+
+	statements, err := p.statementsFor(buildEvent(t, id, "FinePaid", 4, membership.FinePaidEvent{ID: id, Amount: 1.5}))
+	require.NoError(t, err)
+	require.Len(t, statements, 1)
+	assert.Contains(t, statements[0].query, `"rebuild"."members"`)
+	assert.Equal(t, -1.5, statements[0].args[0])
+	assert.Equal(t, 4, statements[0].args[1])
+	assert.Equal(t, id, statements[0].args[3])
+}
+
+func TestStatementsForUnknownEventIsNoop(t *testing.T) {
+	p := NewProjector(nil, nil, "rebuild")
+
+	statements, err := p.statementsFor(buildEvent(t, uuid.New(), "SomethingElse", 1, struct{}{}))
+	require.NoError(t, err)
+	assert.Empty(t, statements)
+}
+
+func TestStatementsForMalformedPayload(t *testing.T) {
+	p := NewProjector(nil, nil, "rebuild")
+	event := eventstore.Event{EventType: "ItemAdded", EventData: json.RawMessage(`{"total_copies": "lots"}`)}
+
+	_, err := p.statementsFor(event)
+	assert.Error(t, err)
+}
+
+func TestStatementsForTierChangeSetsCheckoutLimit(t *testing.T) {
+	p := NewProjector(nil, nil, "rebuild")
+	id := uuid.New()
+
+	statements, err := p.statementsFor(buildEvent(t, id, "MemberTierChanged", 2, membership.MemberTierChangedEvent{ID: id, NewTier: "premium", MaxCheckouts: 10}))
+	require.NoError(t, err)
+	require.Len(t, statements, 1)
+	assert.Equal(t, 10, statements[0].args[1])
+
+	// Events from before limits followed the tier carry none.
+	statements, err = p.statementsFor(buildEvent(t, id, "MemberTierChanged", 2, membership.MemberTierChangedEvent{ID: id, NewTier: "premium"}))
+	require.NoError(t, err)
+	assert.Equal(t, legacyMaxCheckouts, statements[0].args[1])
+}
+
+func TestStatementsForMemberSuspendedStoresReason(t *testing.T) {
+	p := NewProjector(nil, nil, "rebuild")
+	id := uuid.New()
+
+	statements, err := p.statementsFor(buildEvent(t, id, "MemberSuspended", 3, membership.MemberSuspendedEvent{ID: id, Reason: "damaged items"}))
+	require.NoError(t, err)
+	require.Len(t, statements, 1)
+	assert.Contains(t, statements[0].query, "status = 'suspended'")
+	assert.Equal(t, []interface{}{"damaged items", 3, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), id}, statements[0].args)
+}
+
+func TestStatementsForMembershipRenewedSetsExpiryAndStatus(t *testing.T) {
+	p := NewProjector(nil, nil, "rebuild")
+	id := uuid.New()
+	expiresAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	statements, err := p.statementsFor(buildEvent(t, id, "MembershipRenewed", 6, membership.MembershipRenewedEvent{ID: id, ExpiresAt: expiresAt, Status: "active"}))
+	require.NoError(t, err)
+	require.Len(t, statements, 1)
+	assert.Contains(t, statements[0].query, `"rebuild"."members"`)
+	assert.Equal(t, []interface{}{expiresAt, "active", 6, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), id}, statements[0].args)
+}
+
+func TestStatementsForItemDetailsUpdatedKeepsUnsetFields(t *testing.T) {
+	p := NewProjector(nil, nil, "rebuild")
+	id := uuid.New()
+	publisher := "Penguin"
+
+	statements, err := p.statementsFor(buildEvent(t, id, "ItemDetailsUpdated", 3, catalog.ItemDetailsUpdatedEvent{ID: id, Publisher: &publisher}))
+	require.NoError(t, err)
+	require.Len(t, statements, 1)
+	assert.Contains(t, statements[0].query, `"rebuild"."items"`)
+	assert.Nil(t, statements[0].args[0], "an unset title leaves the column alone")
+	assert.Equal(t, &publisher, statements[0].args[2])
+	assert.Equal(t, 3, statements[0].args[4])
+}
+
+func TestStatementsForHoldFulfilledSetsPickupDeadline(t *testing.T) {
+	p := NewProjector(nil, nil, "rebuild")
+	id := uuid.New()
+	fulfilled := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	pickupBy := fulfilled.Add(72 * time.Hour)
+
+	statements, err := p.statementsFor(buildEvent(t, id, "HoldFulfilled", 2, circulation.HoldFulfilledEvent{HoldID: id, FulfilledAt: fulfilled, PickupBy: pickupBy}))
+	require.NoError(t, err)
+	require.Len(t, statements, 1)
+	assert.Contains(t, statements[0].query, "expires_at = $2")
+	assert.Equal(t, pickupBy, statements[0].args[1])
+
+	// Holds fulfilled before pickup windows keep the expiry they had.
+	statements, err = p.statementsFor(buildEvent(t, id, "HoldFulfilled", 2, circulation.HoldFulfilledEvent{HoldID: id, FulfilledAt: fulfilled}))
+	require.NoError(t, err)
+	require.Len(t, statements, 1)
+	assert.NotContains(t, statements[0].query, "expires_at")
+}
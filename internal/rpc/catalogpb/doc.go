@@ -0,0 +1,7 @@
+// internal/rpc/catalogpb/doc.go
+
+// Package catalogpb holds the gRPC API the catalog service serves to other
+// services, generated from catalog.proto.
+package catalogpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative catalog.proto
@@ -0,0 +1,7 @@
+// internal/rpc/membershippb/doc.go
+
+// Package membershippb holds the gRPC API the membership service serves to other
+// services, generated from membership.proto.
+package membershippb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative membership.proto
@@ -0,0 +1,342 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: membership.proto
+
+package membershippb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetMemberRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMemberRequest) Reset() {
+	*x = GetMemberRequest{}
+	mi := &file_membership_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMemberRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMemberRequest) ProtoMessage() {}
+
+func (x *GetMemberRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_membership_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMemberRequest.ProtoReflect.Descriptor instead.
+func (*GetMemberRequest) Descriptor() ([]byte, []int) {
+	return file_membership_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetMemberRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetMemberByEmailRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMemberByEmailRequest) Reset() {
+	*x = GetMemberByEmailRequest{}
+	mi := &file_membership_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMemberByEmailRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMemberByEmailRequest) ProtoMessage() {}
+
+func (x *GetMemberByEmailRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_membership_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMemberByEmailRequest.ProtoReflect.Descriptor instead.
+func (*GetMemberByEmailRequest) Descriptor() ([]byte, []int) {
+	return file_membership_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetMemberByEmailRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+// Member mirrors membership.Member.
+type Member struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Email            string                 `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	Name             string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	MembershipTier   string                 `protobuf:"bytes,4,opt,name=membership_tier,json=membershipTier,proto3" json:"membership_tier,omitempty"`
+	Role             string                 `protobuf:"bytes,5,opt,name=role,proto3" json:"role,omitempty"`
+	Status           string                 `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`
+	SuspensionReason string                 `protobuf:"bytes,7,opt,name=suspension_reason,json=suspensionReason,proto3" json:"suspension_reason,omitempty"`
+	FineBalance      float64                `protobuf:"fixed64,8,opt,name=fine_balance,json=fineBalance,proto3" json:"fine_balance,omitempty"`
+	MaxCheckouts     int32                  `protobuf:"varint,9,opt,name=max_checkouts,json=maxCheckouts,proto3" json:"max_checkouts,omitempty"`
+	ExpiresAt        *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	CreatedAt        *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt        *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Version          int32                  `protobuf:"varint,13,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Member) Reset() {
+	*x = Member{}
+	mi := &file_membership_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Member) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Member) ProtoMessage() {}
+
+func (x *Member) ProtoReflect() protoreflect.Message {
+	mi := &file_membership_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Member.ProtoReflect.Descriptor instead.
+func (*Member) Descriptor() ([]byte, []int) {
+	return file_membership_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Member) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Member) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *Member) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Member) GetMembershipTier() string {
+	if x != nil {
+		return x.MembershipTier
+	}
+	return ""
+}
+
+func (x *Member) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *Member) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Member) GetSuspensionReason() string {
+	if x != nil {
+		return x.SuspensionReason
+	}
+	return ""
+}
+
+func (x *Member) GetFineBalance() float64 {
+	if x != nil {
+		return x.FineBalance
+	}
+	return 0
+}
+
+func (x *Member) GetMaxCheckouts() int32 {
+	if x != nil {
+		return x.MaxCheckouts
+	}
+	return 0
+}
+
+func (x *Member) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *Member) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Member) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Member) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+var File_membership_proto protoreflect.FileDescriptor
+
+const file_membership_proto_rawDesc = "" +
+	"\n" +
+	"\x10membership.proto\x12\x18libranexus.membership.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\"\n" +
+	"\x10GetMemberRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"/\n" +
+	"\x17GetMemberByEmailRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\"\xd7\x03\n" +
+	"\x06Member\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05email\x18\x02 \x01(\tR\x05email\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12'\n" +
+	"\x0fmembership_tier\x18\x04 \x01(\tR\x0emembershipTier\x12\x12\n" +
+	"\x04role\x18\x05 \x01(\tR\x04role\x12\x16\n" +
+	"\x06status\x18\x06 \x01(\tR\x06status\x12+\n" +
+	"\x11suspension_reason\x18\a \x01(\tR\x10suspensionReason\x12!\n" +
+	"\ffine_balance\x18\b \x01(\x01R\vfineBalance\x12#\n" +
+	"\rmax_checkouts\x18\t \x01(\x05R\fmaxCheckouts\x129\n" +
+	"\n" +
+	"expires_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x129\n" +
+	"\n" +
+	"created_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x18\n" +
+	"\aversion\x18\r \x01(\x05R\aversion2\xd0\x01\n" +
+	"\n" +
+	"Membership\x12Y\n" +
+	"\tGetMember\x12*.libranexus.membership.v1.GetMemberRequest\x1a .libranexus.membership.v1.Member\x12g\n" +
+	"\x10GetMemberByEmail\x121.libranexus.membership.v1.GetMemberByEmailRequest\x1a .libranexus.membership.v1.MemberB&Z$libranexus/internal/rpc/membershippbb\x06proto3"
+
+var (
+	file_membership_proto_rawDescOnce sync.Once
+	file_membership_proto_rawDescData []byte
+)
+
+func file_membership_proto_rawDescGZIP() []byte {
+	file_membership_proto_rawDescOnce.Do(func() {
+		file_membership_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_membership_proto_rawDesc), len(file_membership_proto_rawDesc)))
+	})
+	return file_membership_proto_rawDescData
+}
+
+var file_membership_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_membership_proto_goTypes = []any{
+	(*GetMemberRequest)(nil),        // 0: libranexus.membership.v1.GetMemberRequest
+	(*GetMemberByEmailRequest)(nil), // 1: libranexus.membership.v1.GetMemberByEmailRequest
+	(*Member)(nil),                  // 2: libranexus.membership.v1.Member
+	(*timestamppb.Timestamp)(nil),   // 3: google.protobuf.Timestamp
+}
+var file_membership_proto_depIdxs = []int32{
+	3, // 0: libranexus.membership.v1.Member.expires_at:type_name -> google.protobuf.Timestamp
+	3, // 1: libranexus.membership.v1.Member.created_at:type_name -> google.protobuf.Timestamp
+	3, // 2: libranexus.membership.v1.Member.updated_at:type_name -> google.protobuf.Timestamp
+	0, // 3: libranexus.membership.v1.Membership.GetMember:input_type -> libranexus.membership.v1.GetMemberRequest
+	1, // 4: libranexus.membership.v1.Membership.GetMemberByEmail:input_type -> libranexus.membership.v1.GetMemberByEmailRequest
+	2, // 5: libranexus.membership.v1.Membership.GetMember:output_type -> libranexus.membership.v1.Member
+	2, // 6: libranexus.membership.v1.Membership.GetMemberByEmail:output_type -> libranexus.membership.v1.Member
+	5, // [5:7] is the sub-list for method output_type
+	3, // [3:5] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_membership_proto_init() }
+func file_membership_proto_init() {
+	if File_membership_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_membership_proto_rawDesc), len(file_membership_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_membership_proto_goTypes,
+		DependencyIndexes: file_membership_proto_depIdxs,
+		MessageInfos:      file_membership_proto_msgTypes,
+	}.Build()
+	File_membership_proto = out.File
+	file_membership_proto_goTypes = nil
+	file_membership_proto_depIdxs = nil
+}
@@ -0,0 +1,75 @@
+// internal/rpc/rpc.go
+
+// Package rpc runs the gRPC APIs the services serve each other alongside
+// their JSON APIs. Its subpackages hold the code generated for each API.
+package rpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Metadata keys by which a calling service identifies itself, matching the
+// X-Service-Token and X-Service-Name headers of the JSON APIs.
+const (
+	ServiceTokenMetadata = "x-service-token"
+	ServiceNameMetadata  = "x-service-name"
+)
+
+// Listen serves the APIs register adds on addr in the background, to callers
+// presenting token. Callers stop the returned server once their HTTP server
+// has drained.
+func Listen(name, addr, token string, register func(grpc.ServiceRegistrar)) (*grpc.Server, error) {
+	if token == "" {
+		return nil, errors.New("a service token is required to serve gRPC")
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := NewServer(token)
+	register(s)
+	go func() {
+		if err := s.Serve(ln); err != nil {
+			log.Printf("%s gRPC server stopped: %v", name, err)
+		}
+	}()
+	return s, nil
+}
+
+// NewServer returns a gRPC server that answers only calls presenting token
+// in their ServiceTokenMetadata; any other call is refused as UNAUTHENTICATED.
+// The APIs are for the services alone, so there are no other callers.
+func NewServer(token string) *grpc.Server {
+	return grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		presented := metadata.ValueFromIncomingContext(ctx, ServiceTokenMetadata)
+		if len(presented) != 1 || token == "" || subtle.ConstantTimeCompare([]byte(presented[0]), []byte(token)) != 1 {
+			log.Printf("Rejected gRPC call to %s without a valid service token", info.FullMethod)
+			return nil, status.Error(codes.Unauthenticated, "a valid service token is required")
+		}
+		return handler(ctx, req)
+	}))
+}
+
+// Error translates a service's error into a gRPC status. Errors matching
+// notFound become NOT_FOUND and a caller giving up keeps its own code; any
+// other error is logged and answered with a bare INTERNAL, so internal
+// details never reach the client.
+func Error(err, notFound error) error {
+	switch {
+	case errors.Is(err, notFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	}
+	log.Printf("Internal error: %v", err)
+	return status.Error(codes.Internal, "internal server error")
+}
@@ -0,0 +1,72 @@
+// internal/server/health.go
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// checkTimeout bounds each readiness check, so a hung dependency fails the
+// check instead of stalling the probe.
+const checkTimeout = 2 * time.Second
+
+// Check reports whether one dependency is usable.
+type Check func(ctx context.Context) error
+
+// Liveness answers 200 for as long as the process can serve requests.
+func Liveness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"status":"ok"}`))
+}
+
+// readinessReport is the body of a readiness response. Checks maps each
+// dependency to "ok" or the error it failed with.
+type readinessReport struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+	Failed []string          `json:"failed,omitempty"`
+}
+
+// Readiness runs every check concurrently and answers 200 when all pass, or
+// 503 naming the dependencies that failed.
+func Readiness(checks map[string]Check) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := readinessReport{Status: "ok", Checks: make(map[string]string, len(checks))}
+
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for name, check := range checks {
+			wg.Add(1)
+			go func(name string, check Check) {
+				defer wg.Done()
+				ctx, cancel := context.WithTimeout(r.Context(), checkTimeout)
+				defer cancel()
+
+				result := "ok"
+				if err := check(ctx); err != nil {
+					result = err.Error()
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+				report.Checks[name] = result
+				if result != "ok" {
+					report.Failed = append(report.Failed, name)
+				}
+			}(name, check)
+		}
+		wg.Wait()
+
+		w.Header().Set("Content-Type", "application/json")
+		if len(report.Failed) > 0 {
+			sort.Strings(report.Failed)
+			report.Status = "unavailable"
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(report)
+	})
+}
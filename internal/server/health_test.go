@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadiness(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	down := func(context.Context) error { return errors.New("connection refused") }
+
+	tests := []struct {
+		name       string
+		checks     map[string]Check
+		wantStatus int
+		wantFailed []string
+	}{
+		{"no dependencies", nil, http.StatusOK, nil},
+		{"all healthy", map[string]Check{"database": ok, "catalog": ok}, http.StatusOK, nil},
+		{"one down", map[string]Check{"database": ok, "catalog": down}, http.StatusServiceUnavailable, []string{"catalog"}},
+		{"several down", map[string]Check{"database": down, "catalog": down, "membership": ok}, http.StatusServiceUnavailable, []string{"catalog", "database"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			Readiness(tt.checks).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			var report readinessReport
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&report))
+			assert.Equal(t, tt.wantFailed, report.Failed)
+			assert.Len(t, report.Checks, len(tt.checks))
+			for _, name := range tt.wantFailed {
+				assert.Equal(t, "connection refused", report.Checks[name])
+			}
+		})
+	}
+}
+
+func TestReadinessStopsWithRequest(t *testing.T) {
+	hung := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	ctx, cancel := context.WithCancel(req.Context())
+	cancel()
+
+	rec := httptest.NewRecorder()
+	Readiness(map[string]Check{"database": hung}).ServeHTTP(rec, req.WithContext(ctx))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
@@ -0,0 +1,58 @@
+// internal/server/metadata.go
+package server
+
+import (
+	"context"
+	"libranexus/internal/gateway"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+)
+
+// Headers that carry event metadata between services.
+const (
+	CorrelationIDHeader = "X-Correlation-ID"
+	CausationIDHeader   = "X-Causation-ID"
+)
+
+// PropagateMetadata puts the request's correlation ID, causation ID and
+// authenticated member on its context, so events appended while handling it
+// record them. A request without a correlation ID is given a new one, which is
+// also set on the request (for proxies to forward) and echoed in the response.
+func PropagateMetadata(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		correlationID := r.Header.Get(CorrelationIDHeader)
+		if correlationID == "" {
+			correlationID = uuid.NewString()
+			r.Header.Set(CorrelationIDHeader, correlationID)
+		}
+		w.Header().Set(CorrelationIDHeader, correlationID)
+
+		ctx := eventstore.WithCorrelationID(r.Context(), correlationID)
+		if causationID := r.Header.Get(CausationIDHeader); causationID != "" {
+			ctx = eventstore.WithCausationID(ctx, causationID)
+		}
+		if memberID := r.Header.Get(gateway.MemberIDHeader); memberID != "" {
+			ctx = eventstore.WithActorID(ctx, memberID)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// SetMetadataHeaders copies the metadata on ctx onto an outgoing request's
+// headers, continuing the trace in the service being called.
+func SetMetadataHeaders(ctx context.Context, h http.Header) {
+	if id := eventstore.CorrelationIDFromContext(ctx); id != "" {
+		h.Set(CorrelationIDHeader, id)
+	}
+	if id := eventstore.CausationIDFromContext(ctx); id != "" {
+		h.Set(CausationIDHeader, id)
+	}
+	if id := eventstore.ActorIDFromContext(ctx); id != "" {
+		h.Set(gateway.MemberIDHeader, id)
+	}
+	if id := eventstore.RequestIDFromContext(ctx); id != "" {
+		h.Set(RequestIDHeader, id)
+	}
+}
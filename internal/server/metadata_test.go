@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"libranexus/internal/gateway"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jules-labs/go-eventstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPropagateMetadata(t *testing.T) {
+	var got map[string]interface{}
+	handler := PropagateMetadata(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = eventstore.MetadataFromContext(r.Context())
+	}))
+
+	t.Run("assigns a correlation ID", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items", nil))
+
+		id := rec.Header().Get(CorrelationIDHeader)
+		assert.NotEmpty(t, id)
+		assert.Equal(t, map[string]interface{}{eventstore.MetadataCorrelationID: id}, got)
+	})
+
+	t.Run("keeps incoming metadata", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/checkouts", nil)
+		req.Header.Set(CorrelationIDHeader, "req-1")
+		req.Header.Set(CausationIDHeader, "checkout-1")
+		req.Header.Set(gateway.MemberIDHeader, "member-1")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, "req-1", rec.Header().Get(CorrelationIDHeader))
+		assert.Equal(t, map[string]interface{}{
+			eventstore.MetadataCorrelationID: "req-1",
+			eventstore.MetadataCausationID:   "checkout-1",
+			eventstore.MetadataActorID:       "member-1",
+		}, got)
+	})
+}
+
+func TestSetMetadataHeaders(t *testing.T) {
+	ctx := eventstore.WithCorrelationID(context.Background(), "req-1")
+	ctx = eventstore.WithCausationID(ctx, "checkout-1")
+	ctx = eventstore.WithRequestID(ctx, "http-1")
+
+	h := http.Header{}
+	SetMetadataHeaders(ctx, h)
+
+	assert.Equal(t, "req-1", h.Get(CorrelationIDHeader))
+	assert.Equal(t, "checkout-1", h.Get(CausationIDHeader))
+	assert.Equal(t, "http-1", h.Get(RequestIDHeader))
+	assert.Empty(t, h.Get(gateway.MemberIDHeader))
+}
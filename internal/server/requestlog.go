@@ -0,0 +1,79 @@
+// internal/server/requestlog.go
+package server
+
+import (
+	"libranexus/internal/logging"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+)
+
+// RequestIDHeader identifies a single HTTP request, across every service it
+// passes through.
+const RequestIDHeader = "X-Request-ID"
+
+// LogRequests gives each request an ID, reusing the incoming X-Request-ID if
+// there is one, and logs one line per request once it completes. The ID is
+// echoed in the response and put on the request context, so events appended
+// while serving it record it. Probe and metrics endpoints are not logged.
+func LogRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if requestID == "" {
+			requestID = uuid.NewString()
+			r.Header.Set(RequestIDHeader, requestID)
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+		ctx := eventstore.WithRequestID(r.Context(), requestID)
+
+		switch r.URL.Path {
+		case "/metrics", "/healthz", "/readyz":
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		attrs := []any{
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"duration_ms", time.Since(start).Milliseconds(),
+			"request_id", requestID,
+		}
+		// PropagateMetadata, further in, sets the correlation ID header.
+		if id := w.Header().Get(CorrelationIDHeader); id != "" {
+			attrs = append(attrs, "correlation_id", id)
+		}
+		logging.FromContext(r.Context()).Info("request", attrs...)
+	})
+}
+
+// statusRecorder captures the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.status = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
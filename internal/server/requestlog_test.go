@@ -0,0 +1,69 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jules-labs/go-eventstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureLogs sends the default logger's output to a buffer for the test.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return &buf
+}
+
+func TestLogRequests(t *testing.T) {
+	var gotID string
+	handler := LogRequests(PropagateMetadata(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotID = eventstore.RequestIDFromContext(r.Context())
+		w.WriteHeader(http.StatusCreated)
+	})))
+
+	t.Run("assigns a request ID and logs the request", func(t *testing.T) {
+		logs := captureLogs(t)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/items", nil))
+
+		id := rec.Header().Get(RequestIDHeader)
+		assert.NotEmpty(t, id)
+		assert.Equal(t, id, gotID)
+
+		var line map[string]interface{}
+		require.NoError(t, json.Unmarshal(logs.Bytes(), &line))
+		assert.Equal(t, "request", line["msg"])
+		assert.Equal(t, "POST", line["method"])
+		assert.Equal(t, "/items", line["path"])
+		assert.Equal(t, float64(http.StatusCreated), line["status"])
+		assert.Equal(t, id, line["request_id"])
+		assert.Equal(t, rec.Header().Get(CorrelationIDHeader), line["correlation_id"])
+	})
+
+	t.Run("keeps an incoming request ID", func(t *testing.T) {
+		captureLogs(t)
+		req := httptest.NewRequest(http.MethodGet, "/items", nil)
+		req.Header.Set(RequestIDHeader, "req-1")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, "req-1", rec.Header().Get(RequestIDHeader))
+		assert.Equal(t, "req-1", gotID)
+	})
+
+	t.Run("does not log probes", func(t *testing.T) {
+		logs := captureLogs(t)
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+		assert.Empty(t, logs.String())
+	})
+}
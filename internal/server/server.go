@@ -0,0 +1,92 @@
+// internal/server/server.go
+
+// Package server runs the services' HTTP servers and shuts them down
+// gracefully, letting in-flight requests finish before the process exits.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// DefaultDrainTimeout is how long in-flight requests get to finish after a
+// shutdown signal. It stays under Kubernetes' default 30s termination grace
+// period so the drain completes before the pod is killed.
+const DefaultDrainTimeout = 25 * time.Second
+
+// DrainTimeoutFromEnv reads the drain timeout from SHUTDOWN_TIMEOUT, falling
+// back to DefaultDrainTimeout when it is unset.
+func DrainTimeoutFromEnv() (time.Duration, error) {
+	v := os.Getenv("SHUTDOWN_TIMEOUT")
+	if v == "" {
+		return DefaultDrainTimeout, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid SHUTDOWN_TIMEOUT: %w", err)
+	}
+	return d, nil
+}
+
+// Run serves handler on addr until the process receives SIGINT or SIGTERM. It
+// then stops accepting connections and waits up to drainTimeout for in-flight
+// requests to complete before returning. Callers should release resources the
+// handlers use, such as the database pool, only after Run returns.
+func Run(name, addr string, handler http.Handler, drainTimeout time.Duration) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return serve(ctx, name, ln, handler, drainTimeout)
+}
+
+// serve runs the server on ln until ctx is done, then drains it.
+func serve(ctx context.Context, name string, ln net.Listener, handler http.Handler, drainTimeout time.Duration) error {
+	var inFlight atomic.Int64
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			inFlight.Add(1)
+			defer inFlight.Add(-1)
+			handler.ServeHTTP(w, r)
+		}),
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutting down %s: %d request(s) in flight, draining for up to %s", name, inFlight.Load(), drainTimeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Drain timed out for %s with %d request(s) still in flight", name, inFlight.Load())
+		srv.Close()
+		return fmt.Errorf("shutdown: %w", err)
+	}
+
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	log.Printf("%s stopped", name)
+	return nil
+}
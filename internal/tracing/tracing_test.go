@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const traceparent = "00-4bf92f3577b34da6a3ce929b0e0e4736-00f067aa0ba902b7-01"
+
+func TestSetupWithoutCollector(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+
+	shutdown, err := Setup(context.Background(), "test")
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestTraceContextCrossesHops(t *testing.T) {
+	_, err := Setup(context.Background(), "test")
+	require.NoError(t, err)
+
+	// The downstream service sees the trace started by the original caller.
+	var downstream string
+	backend := httptest.NewServer(Handler("backend", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downstream = trace.SpanContextFromContext(r.Context()).TraceID().String()
+	})))
+	defer backend.Close()
+
+	client := &http.Client{Transport: Transport(nil)}
+	front := Handler("front", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, backend.URL, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/checkouts", nil)
+	req.Header.Set("traceparent", traceparent)
+	front.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929b0e0e4736", downstream)
+}
+
+func TestInject(t *testing.T) {
+	_, err := Setup(context.Background(), "test")
+	require.NoError(t, err)
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	})
+	h := http.Header{}
+	Inject(trace.ContextWithSpanContext(context.Background(), sc), h)
+
+	assert.Equal(t, "00-01000000000000000000000000000000-0200000000000000-01", h.Get("traceparent"))
+}
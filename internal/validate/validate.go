@@ -0,0 +1,75 @@
+// internal/validate/validate.go
+
+// Package validate checks the fields of a decoded request body before a
+// handler passes them to its service, so a missing field is reported by
+// name rather than surfacing later as a confusing service error.
+package validate
+
+import (
+	"fmt"
+	"libranexus/internal/httperr"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Fields collects the problems found with a request's fields, keyed by
+// field name. The zero value is ready to use.
+type Fields struct {
+	problems map[string]string
+}
+
+// Check records problem against name unless ok holds. Only a field's first
+// problem is kept.
+func (f *Fields) Check(ok bool, name, problem string) {
+	if ok {
+		return
+	}
+	if f.problems == nil {
+		f.problems = make(map[string]string)
+	}
+	if _, seen := f.problems[name]; !seen {
+		f.problems[name] = problem
+	}
+}
+
+// Required checks that the string field name is not blank.
+func (f *Fields) Required(name, value string) {
+	f.Check(strings.TrimSpace(value) != "", name, "is required")
+}
+
+// RequiredID checks that the ID field name is set. A missing UUID field
+// decodes to the nil UUID, which no entity has.
+func (f *Fields) RequiredID(name string, id uuid.UUID) {
+	f.Check(id != uuid.Nil, name, "is required")
+}
+
+// Summary lists the problems found, by field name, or is empty if there
+// were none.
+func (f *Fields) Summary() string {
+	names := make([]string, 0, len(f.problems))
+	for name := range f.problems {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	problems := make([]string, len(names))
+	for i, name := range names {
+		problems[i] = name + " " + f.problems[name]
+	}
+	return strings.Join(problems, "; ")
+}
+
+// Reject responds 400 "invalid_fields", listing each problem by field name,
+// if any were found, and reports whether it did.
+func (f *Fields) Reject(w http.ResponseWriter) bool {
+	if len(f.problems) == 0 {
+		return false
+	}
+	httperr.JSON(w, http.StatusBadRequest, struct {
+		httperr.Response
+		Fields map[string]string `json:"fields"`
+	}{httperr.Response{Error: fmt.Sprintf("invalid request: %s", f.Summary()), Code: "invalid_fields"}, f.problems})
+	return true
+}
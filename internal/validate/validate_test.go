@@ -0,0 +1,52 @@
+package validate
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldsReject(t *testing.T) {
+	var invalid Fields
+	invalid.RequiredID("item_id", uuid.Nil)
+	invalid.Required("title", "  ")
+	invalid.Check(false, "title", "is too long")
+	copies := -1
+	invalid.Check(copies >= 0, "total_copies", "must not be negative")
+	invalid.Required("author", "Jane Austen")
+
+	rec := httptest.NewRecorder()
+	require.True(t, invalid.Reject(rec))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	var resp struct {
+		Error  string            `json:"error"`
+		Code   string            `json:"code"`
+		Fields map[string]string `json:"fields"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "invalid_fields", resp.Code)
+	assert.Equal(t, "invalid request: item_id is required; title is required; total_copies must not be negative", resp.Error)
+	assert.Equal(t, map[string]string{
+		"item_id":      "is required",
+		"title":        "is required",
+		"total_copies": "must not be negative",
+	}, resp.Fields)
+}
+
+func TestFieldsRejectNothingWhenValid(t *testing.T) {
+	var invalid Fields
+	invalid.RequiredID("item_id", uuid.New())
+	invalid.Required("title", "Emma")
+	copies := 1
+	invalid.Check(copies >= 0, "total_copies", "must not be negative")
+
+	rec := httptest.NewRecorder()
+	assert.False(t, invalid.Reject(rec))
+	assert.Equal(t, 0, rec.Body.Len(), "nothing should be written")
+}
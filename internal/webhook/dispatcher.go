@@ -0,0 +1,215 @@
+// internal/webhook/dispatcher.go
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"libranexus/internal/logging"
+	"libranexus/internal/metrics"
+	"libranexus/internal/outbox"
+	"net/http"
+	"time"
+
+	"github.com/jules-labs/go-eventstore"
+)
+
+const (
+	defaultMaxAttempts     = 3
+	defaultBackoff         = time.Second
+	defaultDeliveryTimeout = 10 * time.Second
+	defaultPollInterval    = time.Second
+)
+
+// webhookDeliveries counts delivery attempts, so a failing receiver shows up
+// on the dashboards.
+var webhookDeliveries = metrics.NewCounter("webhook_deliveries_total",
+	"Webhook delivery attempts, by outcome (ok or error).", "outcome")
+
+// Subscriptions is what the dispatcher needs from the registry.
+type Subscriptions interface {
+	List(ctx context.Context) ([]*Subscription, error)
+	RecordDelivery(ctx context.Context, d Delivery) error
+}
+
+// Dispatcher delivers events to every subscription. Each subscription has
+// its own relay checkpoint, so a receiver that keeps failing is retried from
+// its first undelivered event without holding up the others.
+type Dispatcher struct {
+	subscriptions Subscriptions
+	source        outbox.EventSource
+	checkpoints   outbox.Checkpoints
+	client        *http.Client
+	maxAttempts   int
+	backoff       time.Duration
+	pollInterval  time.Duration
+	relayOpts     []outbox.Option
+}
+
+// DispatcherOption configures a Dispatcher.
+type DispatcherOption func(*Dispatcher)
+
+// WithHTTPClient sets the client deliveries are sent with.
+func WithHTTPClient(c *http.Client) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.client = c
+	}
+}
+
+// WithRetries sets how many times, within one pass, an event is sent to a
+// receiver before the pass gives up on it, and the wait before the first
+// retry, which doubles with each further one.
+func WithRetries(maxAttempts int, backoff time.Duration) DispatcherOption {
+	return func(d *Dispatcher) {
+		if maxAttempts > 0 {
+			d.maxAttempts = maxAttempts
+		}
+		if backoff >= 0 {
+			d.backoff = backoff
+		}
+	}
+}
+
+// WithPollInterval sets how long Run waits between passes.
+func WithPollInterval(interval time.Duration) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.pollInterval = interval
+	}
+}
+
+// WithRelayOptions configures the relays that read each subscription's
+// events, such as their batch size.
+func WithRelayOptions(opts ...outbox.Option) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.relayOpts = append(d.relayOpts, opts...)
+	}
+}
+
+// NewDispatcher creates a dispatcher delivering events from source to
+// subscriptions, checkpointing each subscription in checkpoints.
+func NewDispatcher(subscriptions Subscriptions, source outbox.EventSource, checkpoints outbox.Checkpoints, opts ...DispatcherOption) *Dispatcher {
+	d := &Dispatcher{
+		subscriptions: subscriptions,
+		source:        source,
+		checkpoints:   checkpoints,
+		client:        &http.Client{Timeout: defaultDeliveryTimeout},
+		maxAttempts:   defaultMaxAttempts,
+		backoff:       defaultBackoff,
+		pollInterval:  defaultPollInterval,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Drain delivers every committed event each subscription has not yet received
+// and returns how many events it delivered. A subscription whose receiver
+// keeps failing is logged and left at the failed event for the next pass.
+func (d *Dispatcher) Drain(ctx context.Context) (int, error) {
+	subs, err := d.subscriptions.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, sub := range subs {
+		relay := outbox.NewRelay(d.source, d.checkpoints, checkpointName(sub.ID), d.deliverTo(sub), d.relayOpts...)
+		n, err := relay.Drain(ctx)
+		delivered += n
+		if err != nil {
+			if ctx.Err() != nil {
+				return delivered, ctx.Err()
+			}
+			logging.FromContext(ctx).Warn("webhook delivery failed", "subscription_id", sub.ID, "url", sub.URL, "err", err)
+		}
+	}
+	return delivered, nil
+}
+
+// Run drains every poll interval until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.Drain(ctx); err != nil && ctx.Err() == nil {
+			logging.FromContext(ctx).Error("webhook dispatch failed", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliverTo returns the relay handler for sub. Events of other types are
+// skipped; matching ones are sent until the receiver accepts them or the
+// attempts run out, backing off between tries.
+func (d *Dispatcher) deliverTo(sub *Subscription) outbox.Handler {
+	return func(ctx context.Context, event eventstore.Event) error {
+		if event.EventType != sub.EventType {
+			return nil
+		}
+		body, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event: %w", err)
+		}
+
+		wait := d.backoff
+		for attempt := 1; ; attempt++ {
+			delivery := d.send(ctx, sub, event, body)
+			delivery.Attempt = attempt
+			if err := d.subscriptions.RecordDelivery(ctx, delivery); err != nil {
+				logging.FromContext(ctx).Warn("failed to record webhook delivery", "subscription_id", sub.ID, "event_id", event.ID, "err", err)
+			}
+			if delivery.Succeeded() {
+				webhookDeliveries.Inc("ok")
+				return nil
+			}
+			webhookDeliveries.Inc("error")
+			if attempt >= d.maxAttempts {
+				return fmt.Errorf("gave up after %d attempts: %s", attempt, delivery.Error)
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+			wait *= 2
+		}
+	}
+}
+
+// send POSTs body to sub's URL once and reports the outcome.
+func (d *Dispatcher) send(ctx context.Context, sub *Subscription, event eventstore.Event, body []byte) Delivery {
+	delivery := Delivery{SubscriptionID: sub.ID, EventID: event.ID, AttemptedAt: time.Now()}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(sub.Secret, body))
+	req.Header.Set(EventTypeHeader, event.EventType)
+	req.Header.Set(EventIDHeader, fmt.Sprint(event.ID))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	delivery.StatusCode = resp.StatusCode
+	if !delivery.Succeeded() {
+		delivery.Error = fmt.Sprintf("receiver responded %s", resp.Status)
+	}
+	return delivery
+}
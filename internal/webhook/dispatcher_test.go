@@ -0,0 +1,173 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jules-labs/go-eventstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"libranexus/internal/outbox"
+)
+
+type fakeSource struct {
+	events []eventstore.CommittedEvent
+}
+
+func (f *fakeSource) StreamCommitted(ctx context.Context, after eventstore.Position, batchSize int) ([]eventstore.CommittedEvent, error) {
+	var batch []eventstore.CommittedEvent
+	for _, e := range f.events {
+		if e.Position.EventID > after.EventID && len(batch) < batchSize {
+			batch = append(batch, e)
+		}
+	}
+	return batch, nil
+}
+
+type memoryCheckpoints map[string]eventstore.Position
+
+func (m memoryCheckpoints) Load(ctx context.Context, name string) (eventstore.Position, error) {
+	return m[name], nil
+}
+
+func (m memoryCheckpoints) Save(ctx context.Context, name string, last eventstore.Position) error {
+	m[name] = last
+	return nil
+}
+
+type memorySubscriptions struct {
+	subs       []*Subscription
+	deliveries []Delivery
+}
+
+func (m *memorySubscriptions) List(ctx context.Context) ([]*Subscription, error) {
+	return m.subs, nil
+}
+
+func (m *memorySubscriptions) RecordDelivery(ctx context.Context, d Delivery) error {
+	m.deliveries = append(m.deliveries, d)
+	return nil
+}
+
+// receiver records the deliveries it accepts, failing the first failFirst.
+type receiver struct {
+	mu        sync.Mutex
+	failFirst int
+	calls     int
+	accepted  []string
+	headers   []http.Header
+	bodies    [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.calls++
+	if rc.calls <= rc.failFirst {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	rc.accepted = append(rc.accepted, r.Header.Get(EventIDHeader))
+	rc.headers = append(rc.headers, r.Header.Clone())
+	rc.bodies = append(rc.bodies, body)
+}
+
+func committedEvents(types ...string) []eventstore.CommittedEvent {
+	out := make([]eventstore.CommittedEvent, len(types))
+	for i, eventType := range types {
+		id := int64(i + 1)
+		out[i] = eventstore.CommittedEvent{
+			Event:    eventstore.Event{ID: id, EventType: eventType, CreatedAt: time.Now().Add(-time.Minute)},
+			Position: eventstore.Position{TransactionID: 1, EventID: id},
+		}
+	}
+	return out
+}
+
+func newTestDispatcher(subs *memorySubscriptions, source outbox.EventSource, checkpoints outbox.Checkpoints) *Dispatcher {
+	return NewDispatcher(subs, source, checkpoints, WithRetries(3, 0))
+}
+
+func TestDispatcherDeliversSignedMatchingEvents(t *testing.T) {
+	rc := &receiver{}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	sub := &Subscription{ID: uuid.New(), URL: server.URL, EventType: "ItemCheckedOut", Secret: "s3cret"}
+	subs := &memorySubscriptions{subs: []*Subscription{sub}}
+	checkpoints := memoryCheckpoints{}
+	d := newTestDispatcher(subs, &fakeSource{events: committedEvents("ItemAdded", "ItemCheckedOut", "ItemReturned", "ItemCheckedOut")}, checkpoints)
+
+	n, err := d.Drain(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 4, n, "skipped events still advance the checkpoint")
+	assert.Equal(t, []string{"2", "4"}, rc.accepted)
+	assert.Equal(t, int64(4), checkpoints[checkpointName(sub.ID)].EventID)
+
+	for i, body := range rc.bodies {
+		assert.True(t, Verify("s3cret", body, rc.headers[i].Get(SignatureHeader)))
+		assert.Equal(t, "ItemCheckedOut", rc.headers[i].Get(EventTypeHeader))
+		assert.Equal(t, "application/json", rc.headers[i].Get("Content-Type"))
+	}
+	require.Len(t, subs.deliveries, 2)
+	assert.Equal(t, http.StatusOK, subs.deliveries[0].StatusCode)
+}
+
+func TestDispatcherRetriesUntilAccepted(t *testing.T) {
+	rc := &receiver{failFirst: 2}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	sub := &Subscription{ID: uuid.New(), URL: server.URL, EventType: "ItemAdded", Secret: "s3cret"}
+	subs := &memorySubscriptions{subs: []*Subscription{sub}}
+	d := newTestDispatcher(subs, &fakeSource{events: committedEvents("ItemAdded")}, memoryCheckpoints{})
+
+	_, err := d.Drain(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1"}, rc.accepted)
+
+	require.Len(t, subs.deliveries, 3)
+	for i, delivery := range subs.deliveries {
+		assert.Equal(t, i+1, delivery.Attempt)
+	}
+	assert.Equal(t, http.StatusServiceUnavailable, subs.deliveries[0].StatusCode)
+	assert.NotEmpty(t, subs.deliveries[0].Error)
+	assert.True(t, subs.deliveries[2].Succeeded())
+}
+
+func TestDispatcherHoldsFailingSubscriptionWithoutBlockingOthers(t *testing.T) {
+	down := &receiver{failFirst: 1000}
+	downServer := httptest.NewServer(down)
+	defer downServer.Close()
+	up := &receiver{}
+	upServer := httptest.NewServer(up)
+	defer upServer.Close()
+
+	failing := &Subscription{ID: uuid.New(), URL: downServer.URL, EventType: "ItemAdded", Secret: "a"}
+	healthy := &Subscription{ID: uuid.New(), URL: upServer.URL, EventType: "ItemAdded", Secret: "b"}
+	subs := &memorySubscriptions{subs: []*Subscription{failing, healthy}}
+	checkpoints := memoryCheckpoints{}
+	d := newTestDispatcher(subs, &fakeSource{events: committedEvents("ItemAdded", "ItemAdded")}, checkpoints)
+
+	_, err := d.Drain(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, down.calls, "gives up after the configured attempts")
+	assert.Zero(t, checkpoints[checkpointName(failing.ID)].EventID, "the failed event is redelivered next pass")
+	assert.Equal(t, []string{"1", "2"}, up.accepted)
+	assert.Equal(t, int64(2), checkpoints[checkpointName(healthy.ID)].EventID)
+
+	// Once the receiver recovers, it gets everything it missed, in order.
+	down.failFirst = 0
+	_, err = d.Drain(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, down.accepted)
+	assert.Equal(t, int64(2), checkpoints[checkpointName(failing.ID)].EventID)
+}
@@ -0,0 +1,110 @@
+// internal/webhook/handler.go
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"libranexus/internal/httperr"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// errorRules maps the registry's errors to HTTP responses.
+var errorRules = httperr.Rules{
+	{Err: ErrInvalidSubscription, Status: http.StatusBadRequest, Code: "invalid_subscription"},
+	{Err: ErrSubscriptionNotFound, Status: http.StatusNotFound, Code: "subscription_not_found"},
+}
+
+// Store is what the handler needs from the registry.
+type Store interface {
+	Register(ctx context.Context, rawURL, eventType string) (*Subscription, error)
+	List(ctx context.Context) ([]*Subscription, error)
+	Deliveries(ctx context.Context, id uuid.UUID) ([]Delivery, error)
+}
+
+type Handler struct {
+	store Store
+}
+
+func NewHandler(store Store) *Handler {
+	return &Handler{store: store}
+}
+
+// registeredSubscription is the response to a registration, the only one
+// that carries the secret.
+type registeredSubscription struct {
+	ID        uuid.UUID `json:"id"`
+	URL       string    `json:"url"`
+	EventType string    `json:"event_type"`
+	Secret    string    `json:"secret"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (h *Handler) HandleSubscriptions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		subs, err := h.store.List(r.Context())
+		if err != nil {
+			errorRules.Write(w, err)
+			return
+		}
+		json.NewEncoder(w).Encode(subs)
+	case http.MethodPost:
+		h.handleRegister(w, r)
+	default:
+		httperr.MethodNotAllowed(w)
+	}
+}
+
+func (h *Handler) handleRegister(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		URL       string `json:"url"`
+		EventType string `json:"event_type"`
+	}
+	if !httperr.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	sub, err := h.store.Register(r.Context(), req.URL, req.EventType)
+	if err != nil {
+		errorRules.Write(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(registeredSubscription{
+		ID:        sub.ID,
+		URL:       sub.URL,
+		EventType: sub.EventType,
+		Secret:    sub.Secret,
+		CreatedAt: sub.CreatedAt,
+	})
+}
+
+// HandleSubscription serves /subscriptions/{id}/deliveries, the delivery
+// attempt log.
+func (h *Handler) HandleSubscription(w http.ResponseWriter, r *http.Request) {
+	idStr, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/subscriptions/"), "/")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		httperr.BadRequest(w, "invalid subscription ID")
+		return
+	}
+	if action != "deliveries" {
+		httperr.NotFound(w)
+		return
+	}
+	if r.Method != http.MethodGet {
+		httperr.MethodNotAllowed(w)
+		return
+	}
+
+	deliveries, err := h.store.Deliveries(r.Context(), id)
+	if err != nil {
+		errorRules.Write(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(deliveries)
+}
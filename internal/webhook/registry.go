@@ -0,0 +1,161 @@
+// internal/webhook/registry.go
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"libranexus/internal/database"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxDeliveriesListed bounds how many attempts Deliveries returns.
+const maxDeliveriesListed = 100
+
+// Registry keeps subscriptions and their delivery attempts in the database.
+type Registry struct {
+	db *sql.DB
+}
+
+// NewRegistry returns a registry backed by db.
+func NewRegistry(db *sql.DB) *Registry {
+	return &Registry{db: db}
+}
+
+// checkpointName is the relay checkpoint tracking a subscription's delivery.
+func checkpointName(id uuid.UUID) string {
+	return "webhook:" + id.String()
+}
+
+// Register subscribes rawURL to eventType, generating the secret that signs
+// its deliveries. Delivery starts at the oldest transaction still running,
+// so a new subscription is not sent the whole history, only events from
+// about the time it registered on.
+func (r *Registry) Register(ctx context.Context, rawURL, eventType string) (*Subscription, error) {
+	if err := validateSubscription(rawURL, eventType); err != nil {
+		return nil, err
+	}
+	secret, err := newSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+	sub := &Subscription{
+		ID:        uuid.New(),
+		URL:       rawURL,
+		EventType: eventType,
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	}
+
+	err = database.InTx(ctx, r.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO webhook_subscriptions (id, url, event_type, secret, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`, sub.ID, sub.URL, sub.EventType, sub.Secret, sub.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to save subscription: %w", err)
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO projection_checkpoints (name, last_transaction_id, last_event_id, updated_at)
+			SELECT $1, pg_snapshot_xmin(pg_current_snapshot()), 0, NOW()
+		`, checkpointName(sub.ID))
+		if err != nil {
+			return fmt.Errorf("failed to save subscription checkpoint: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// List returns every subscription, oldest first.
+func (r *Registry) List(ctx context.Context) ([]*Subscription, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, url, event_type, secret, created_at
+		FROM webhook_subscriptions
+		ORDER BY created_at, id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subs := make([]*Subscription, 0)
+	for rows.Next() {
+		sub := &Subscription{}
+		if err := rows.Scan(&sub.ID, &sub.URL, &sub.EventType, &sub.Secret, &sub.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+	return subs, nil
+}
+
+// Deliveries returns a subscription's most recent delivery attempts, newest
+// first.
+func (r *Registry) Deliveries(ctx context.Context, id uuid.UUID) ([]Delivery, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `SELECT TRUE FROM webhook_subscriptions WHERE id = $1`, id).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrSubscriptionNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT subscription_id, event_id, attempt, COALESCE(status_code, 0), COALESCE(error, ''), attempted_at
+		FROM webhook_deliveries
+		WHERE subscription_id = $1
+		ORDER BY attempted_at DESC, id DESC
+		LIMIT $2
+	`, id, maxDeliveriesListed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]Delivery, 0)
+	for rows.Next() {
+		var d Delivery
+		if err := rows.Scan(&d.SubscriptionID, &d.EventID, &d.Attempt, &d.StatusCode, &d.Error, &d.AttemptedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// RecordDelivery logs one delivery attempt.
+func (r *Registry) RecordDelivery(ctx context.Context, d Delivery) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, attempt, status_code, error, attempted_at)
+		VALUES ($1, $2, $3, NULLIF($4, 0), NULLIF($5, ''), $6)
+	`, d.SubscriptionID, d.EventID, d.Attempt, d.StatusCode, d.Error, d.AttemptedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record delivery: %w", err)
+	}
+	return nil
+}
+
+// newSecret returns a random secret for signing deliveries.
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,87 @@
+// internal/webhook/webhook.go
+
+// Package webhook delivers events to external systems that subscribe to
+// them by URL. Each subscription names one event type; the dispatcher POSTs
+// every matching event, as JSON signed with the subscription's secret, at
+// least once and in order.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Headers sent with every delivery. The signature is "sha256=" followed by
+// the hex HMAC-SHA256 of the body, keyed with the subscription's secret.
+const (
+	SignatureHeader = "X-LibraNexus-Signature"
+	EventTypeHeader = "X-LibraNexus-Event"
+	EventIDHeader   = "X-LibraNexus-Event-ID"
+)
+
+const signaturePrefix = "sha256="
+
+var (
+	ErrInvalidSubscription  = errors.New("invalid subscription")
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+)
+
+// Subscription registers URL to receive every event of EventType. Secret
+// signs the deliveries; it is only shown when the subscription is created.
+type Subscription struct {
+	ID        uuid.UUID `json:"id"`
+	URL       string    `json:"url"`
+	EventType string    `json:"event_type"`
+	Secret    string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Delivery is one attempt to deliver an event to a subscription. StatusCode
+// is zero and Error set when no response arrived.
+type Delivery struct {
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+	EventID        int64     `json:"event_id"`
+	Attempt        int       `json:"attempt"`
+	StatusCode     int       `json:"status_code,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	AttemptedAt    time.Time `json:"attempted_at"`
+}
+
+// Succeeded reports whether the receiver accepted the delivery.
+func (d Delivery) Succeeded() bool {
+	return d.StatusCode >= 200 && d.StatusCode < 300
+}
+
+// validateSubscription checks that rawURL is an absolute http or https URL
+// and that an event type is given.
+func validateSubscription(rawURL, eventType string) error {
+	if strings.TrimSpace(eventType) == "" {
+		return fmt.Errorf("%w: event_type is required", ErrInvalidSubscription)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidSubscription)
+	}
+	return nil
+}
+
+// Sign returns the signature header value for body under secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is body's signature under secret.
+// Receivers written in Go can use it to authenticate deliveries.
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
@@ -0,0 +1,44 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"event_type":"ItemAdded"}`)
+	sig := Sign("s3cret", body)
+
+	assert.Regexp(t, `^sha256=[0-9a-f]{64}$`, sig)
+	assert.True(t, Verify("s3cret", body, sig))
+	assert.False(t, Verify("other", body, sig), "wrong secret")
+	assert.False(t, Verify("s3cret", []byte(`{"event_type":"ItemRemoved"}`), sig), "tampered body")
+}
+
+func TestValidateSubscription(t *testing.T) {
+	tests := []struct {
+		name      string
+		url       string
+		eventType string
+		wantErr   bool
+	}{
+		{"https", "https://hooks.example.com/libranexus", "ItemAdded", false},
+		{"http", "http://analytics:9000/events", "ItemCheckedOut", false},
+		{"missing event type", "https://hooks.example.com", " ", true},
+		{"relative url", "/events", "ItemAdded", true},
+		{"other scheme", "ftp://hooks.example.com", "ItemAdded", true},
+		{"no host", "https://", "ItemAdded", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSubscription(tt.url, tt.eventType)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidSubscription)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
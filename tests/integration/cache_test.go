@@ -0,0 +1,42 @@
+// tests/integration/cache_test.go
+package integration
+
+import (
+	"context"
+	"libranexus/internal/catalog"
+	"testing"
+	"time"
+
+	"github.com/jules-labs/go-eventstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestItemCacheInvalidatedByUpdateItemCopies(t *testing.T) {
+	ts := setupTestSuite(t)
+
+	// An hour-long TTL, so only invalidation can refresh the item.
+	svc := catalog.NewService(eventstore.NewEventStore(ts.db), ts.db, catalog.WithItemCache(10, time.Hour))
+	ctx := context.Background()
+
+	item, err := svc.AddItem(ctx, "9780141439518", "Pride and Prejudice", "Jane Austen", "", 3)
+	require.NoError(t, err)
+	_, err = svc.GetItem(ctx, item.ID, false)
+	require.NoError(t, err)
+
+	// A change behind the service's back is not seen while the item is cached.
+	_, err = ts.db.Exec(`UPDATE items SET title = 'Emma' WHERE id = $1`, item.ID)
+	require.NoError(t, err)
+	cached, err := svc.GetItem(ctx, item.ID, false)
+	require.NoError(t, err)
+	assert.Equal(t, "Pride and Prejudice", cached.Title, "the second read should come from the cache")
+
+	require.NoError(t, svc.UpdateItemCopies(ctx, item.ID, 5, 4, 0))
+
+	updated, err := svc.GetItem(ctx, item.ID, false)
+	require.NoError(t, err)
+	assert.Equal(t, 5, updated.TotalCopies)
+	assert.Equal(t, 4, updated.Available)
+	assert.Equal(t, item.Version+1, updated.Version)
+	assert.Equal(t, "Emma", updated.Title, "the update should have dropped the cached item")
+}
@@ -0,0 +1,323 @@
+// tests/integration/harness_test.go
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"libranexus/internal/database"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+)
+
+const (
+	// readyTimeout bounds how long a service may take to pass its readiness
+	// probe after starting.
+	readyTimeout = 60 * time.Second
+	// jwtSigningKey is shared by the membership service, which issues
+	// tokens, and the gateway, which checks them.
+	jwtSigningKey = "integration_jwt_secret"
+	// serviceToken identifies circulation to membership.
+	serviceToken = "integration_service_token"
+)
+
+// errNoDocker means the suite cannot run here, rather than that it failed.
+var errNoDocker = errors.New("docker is not available")
+
+// environment is the system under test: Postgres in a container, migrated,
+// and each service built from this tree and run as a local process on a
+// free port. It is started by the first test that needs it and shared by
+// the rest, which reset the database rather than the whole system.
+type environment struct {
+	postgres   *postgres.PostgresContainer
+	db         *sql.DB
+	gatewayURL string
+	workDir    string
+	services   []*service
+}
+
+var (
+	envOnce sync.Once
+	env     *environment
+	envErr  error
+)
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if env != nil {
+		env.stop()
+	}
+	os.Exit(code)
+}
+
+// startEnvironment returns the shared environment, starting it on first use.
+// The test is skipped if Docker is not available to run Postgres.
+func startEnvironment(tb testing.TB) *environment {
+	tb.Helper()
+	envOnce.Do(func() {
+		env, envErr = newEnvironment(context.Background())
+	})
+	if errors.Is(envErr, errNoDocker) {
+		tb.Skipf("skipping integration test: %v", envErr)
+	}
+	require.NoError(tb, envErr, "failed to start the integration environment")
+	return env
+}
+
+func newEnvironment(ctx context.Context) (_ *environment, err error) {
+	if err := dockerHealthy(ctx); err != nil {
+		return nil, fmt.Errorf("%w: %v", errNoDocker, err)
+	}
+
+	e := &environment{}
+	defer func() {
+		if err != nil {
+			e.stop()
+		}
+	}()
+
+	e.postgres, err = postgres.Run(ctx, "postgres:16-alpine",
+		postgres.WithDatabase("libranexus"),
+		postgres.WithUsername("libranexus"),
+		postgres.WithPassword("integration"),
+		postgres.BasicWaitStrategies(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start postgres: %w", err)
+	}
+	dbURL, err := e.postgres.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the postgres address: %w", err)
+	}
+	e.db, err = database.Connect(dbURL, database.DefaultPoolConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+	// The services would migrate on startup, but several tests use the
+	// database directly, and migrating once here keeps them from racing.
+	if _, err := database.MigrateUp(ctx, e.db); err != nil {
+		return nil, fmt.Errorf("failed to migrate the database: %w", err)
+	}
+
+	e.workDir, err = os.MkdirTemp("", "libranexus-integration-")
+	if err != nil {
+		return nil, err
+	}
+	if err := buildServices(ctx, e.workDir); err != nil {
+		return nil, err
+	}
+
+	ports := make(map[string]string)
+	for _, name := range []string{"gateway", "catalog", "circulation", "membership"} {
+		if ports[name], err = freePort(); err != nil {
+			return nil, err
+		}
+	}
+	local := func(name string) string { return "http://127.0.0.1:" + ports[name] }
+	common := []string{
+		"DATABASE_URL=" + dbURL,
+		"SERVICE_TOKEN=" + serviceToken,
+		"LOG_LEVEL=warn",
+	}
+
+	// Every request comes from one address, so the per-client limits that
+	// protect a real deployment are lifted. Caches are left off so each
+	// test sees every change at once.
+	for _, s := range []struct {
+		name, cmd string
+		env       []string
+	}{
+		{name: "membership", cmd: "membership", env: []string{
+			"JWT_SIGNING_KEY=" + jwtSigningKey,
+			"LOGIN_RATE_LIMIT=10000/1m",
+			"REGISTER_RATE_LIMIT=10000/1m",
+		}},
+		{name: "catalog", cmd: "catalog", env: []string{
+			"SEARCH_CACHE_TTL=0s",
+		}},
+		{name: "circulation", cmd: "circulation", env: []string{
+			"CATALOG_SERVICE_URL=" + local("catalog"),
+			"MEMBERSHIP_SERVICE_URL=" + local("membership"),
+		}},
+		{name: "gateway", cmd: "api", env: []string{
+			"JWT_SIGNING_KEY=" + jwtSigningKey,
+			"CATALOG_SERVICE_URL=" + local("catalog"),
+			"CIRCULATION_SERVICE_URL=" + local("circulation"),
+			"MEMBERSHIP_SERVICE_URL=" + local("membership"),
+			"GATEWAY_RATE_LIMIT=100000/1m",
+			"GATEWAY_RATE_BURST=10000",
+			"GATEWAY_MAX_CONCURRENT_PER_IP=0",
+		}},
+	} {
+		env := append(append([]string{"PORT=" + ports[s.name]}, common...), s.env...)
+		svc, err := startService(ctx, e.workDir, s.name, s.cmd, env)
+		if err != nil {
+			return nil, err
+		}
+		e.services = append(e.services, svc)
+		if err := svc.waitReady(local(s.name), readyTimeout); err != nil {
+			return nil, err
+		}
+	}
+	e.gatewayURL = local("gateway")
+	return e, nil
+}
+
+// reset empties every table but the migration history, so each test starts
+// from an empty library.
+func (e *environment) reset(tb testing.TB) {
+	tb.Helper()
+	rows, err := e.db.Query(`SELECT quote_ident(tablename) FROM pg_tables
+		WHERE schemaname = 'public' AND tablename <> 'schema_migrations'`)
+	require.NoError(tb, err)
+	var tables []string
+	for rows.Next() {
+		var table string
+		require.NoError(tb, rows.Scan(&table))
+		tables = append(tables, table)
+	}
+	require.NoError(tb, rows.Err())
+	rows.Close()
+
+	_, err = e.db.Exec("TRUNCATE TABLE " + strings.Join(tables, ", ") + " CASCADE")
+	require.NoError(tb, err)
+}
+
+func (e *environment) stop() {
+	for i := len(e.services) - 1; i >= 0; i-- {
+		e.services[i].stop()
+	}
+	if e.db != nil {
+		e.db.Close()
+	}
+	if e.postgres != nil {
+		testcontainers.TerminateContainer(e.postgres)
+	}
+	if e.workDir != "" {
+		os.RemoveAll(e.workDir)
+	}
+}
+
+// dockerHealthy reports whether testcontainers can reach a Docker daemon.
+func dockerHealthy(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	provider, err := testcontainers.ProviderDocker.GetProvider()
+	if err != nil {
+		return err
+	}
+	defer provider.Close()
+	return provider.Health(ctx)
+}
+
+// buildServices compiles the services under test into dir.
+func buildServices(ctx context.Context, dir string) error {
+	cmd := exec.CommandContext(ctx, "go", "build", "-o", dir+string(filepath.Separator),
+		"./cmd/api", "./cmd/catalog", "./cmd/circulation", "./cmd/membership")
+	cmd.Dir = filepath.Join("..", "..")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to build the services: %w\n%s", err, output)
+	}
+	return nil
+}
+
+// freePort returns a TCP port nothing is listening on.
+func freePort() (string, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", fmt.Errorf("failed to find a free port: %w", err)
+	}
+	defer l.Close()
+	_, port, err := net.SplitHostPort(l.Addr().String())
+	return port, err
+}
+
+// service is a service process started by the harness.
+type service struct {
+	name   string
+	cmd    *exec.Cmd
+	log    string
+	exited chan struct{}
+}
+
+func startService(ctx context.Context, dir, name, binary string, env []string) (*service, error) {
+	logPath := filepath.Join(dir, name+".log")
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, filepath.Join(dir, binary))
+	cmd.Env = env
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	if err := cmd.Start(); err != nil {
+		logFile.Close()
+		return nil, fmt.Errorf("failed to start %s: %w", name, err)
+	}
+
+	s := &service{name: name, cmd: cmd, log: logPath, exited: make(chan struct{})}
+	go func() {
+		cmd.Wait()
+		logFile.Close()
+		close(s.exited)
+	}()
+	return s, nil
+}
+
+// waitReady polls the service's readiness probe until it passes, the
+// service exits, or timeout elapses.
+func (s *service) waitReady(baseURL string, timeout time.Duration) error {
+	client := &http.Client{Timeout: time.Second}
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		select {
+		case <-s.exited:
+			return fmt.Errorf("%s exited during startup:\n%s", s.name, s.output())
+		default:
+		}
+		if resp, err := client.Get(baseURL + "/readyz"); err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return fmt.Errorf("%s was not ready after %s:\n%s", s.name, timeout, s.output())
+}
+
+// output returns what the service has logged.
+func (s *service) output() string {
+	b, _ := os.ReadFile(s.log)
+	return string(b)
+}
+
+func (s *service) stop() {
+	select {
+	case <-s.exited:
+		return
+	default:
+	}
+	s.cmd.Process.Signal(os.Interrupt)
+	select {
+	case <-s.exited:
+	case <-time.After(10 * time.Second):
+		s.cmd.Process.Kill()
+		<-s.exited
+	}
+}